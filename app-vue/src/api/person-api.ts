@@ -65,6 +65,48 @@ class PersonApi {
       console.log(`error while create new person, firstName=${user.firstName}, lastName=${user.lastName}, age=${user.age}`, err)
     }
   }
+
+  /**
+   * Load avatar thumbnail of person, undefined when person has no avatar.
+   * @param id person identifier, uuid type.
+   * @param size size of square thumbnail: 64, 128 or 256.
+   */
+  async loadAvatar(id: string, size: number) {
+    try {
+      const response = await client.get(`/api/v1/person/${id}/avatar?size=${size}`, { responseType: 'blob' })
+      return response.data as Blob
+    } catch (err) {
+      if (err.response?.status !== 404) {
+        console.log('error while load avatar of person with id=', id, err)
+      }
+    }
+  }
+
+  /**
+   * Load persons which may duplicate person, ordered by score.
+   * @param id person identifier, uuid type.
+   */
+  async loadDuplicates(id: string) {
+    try {
+      return await client.get(`/api/v1/person/${id}/duplicates`)
+    } catch (err) {
+      console.log('error while load duplicates of person with id=', id, err)
+    }
+  }
+
+  /**
+   * Merge duplicate into survivor, duplicate is deleted and its id redirects to survivor.
+   * @param survivorId identifier of surviving person.
+   * @param duplicateId identifier of merged person.
+   * @param fields source of fields: { "firstName": "survivor" | "duplicate", ... }
+   */
+  async mergePersons(survivorId: string, duplicateId: string, fields: object = {}) {
+    try {
+      return await client.post(`/api/v1/person/${survivorId}/merge`, { duplicateId, fields })
+    } catch (err) {
+      console.log('error while merge person with id=', duplicateId, 'into', survivorId, err)
+    }
+  }
 }
 
 const personApi = new PersonApi()
@@ -0,0 +1,20 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNotFound object does not exist in store.
+var ErrNotFound = errors.New("blob not found")
+
+// Store storage of binary objects by key, keys are paths separated by "/".
+type Store interface {
+	// Put write object of size bytes read from content, existing object is replaced.
+	Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error
+	// Get open object for reading, returns ErrNotFound for missing object. Reader must be closed.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete delete object, missing object is not an error.
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"golang.org/x/exp/slog"
+	"io"
+	"os"
+	"path/filepath"
+	"person-service/db/entity"
+	"person-service/db/repository"
+	"person-service/importer"
+	"person-service/utils"
+	"strings"
+)
+
+// runImport import persons from file, usage:
+//
+//	person-service import [-format csv|ndjson] [-delimiter ;] [-columns field:column,...] [-dry-run] [-report rejected.csv] [-tenant id] file
+func runImport(args []string) int {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := flags.String("format", "", "file format: csv or ndjson, by default taken from file extension")
+	delimiter := flags.String("delimiter", ",", "csv delimiter, tab for tab")
+	columns := flags.String("columns", "", "csv column mapping field:column,... (fields: id, login, firstName, lastName, age)")
+	dryRun := flags.Bool("dry-run", false, "validate and roll back import")
+	reportPath := flags.String("report", "", "path of csv report of rejected rows, stdout when empty")
+	tenant := flags.String("tenant", entity.DefaultTenantId.String(), "id of tenant persons are imported into")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: person-service import [flags] file")
+		flags.PrintDefaults()
+		return 2
+	}
+
+	path := flags.Arg(0)
+	file, err := os.Open(path)
+	if err != nil {
+		logger.Error("Failed to open import file", utils.Err(err))
+		return 1
+	}
+	defer file.Close()
+
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+
+	var reader importer.Reader
+	switch *format {
+	case "csv":
+		var options importer.CSVOptions
+		if options.Delimiter, err = importer.ParseDelimiter(*delimiter); err != nil {
+			logger.Error("Failed to parse delimiter", utils.Err(err))
+			return 2
+		}
+		if options.Columns, err = importer.ParseColumns(*columns); err != nil {
+			logger.Error("Failed to parse column mapping", utils.Err(err))
+			return 2
+		}
+		if reader, err = importer.NewCSVReader(file, options); err != nil {
+			logger.Error("Failed to read csv header", utils.Err(err))
+			return 1
+		}
+	case "ndjson", "jsonl":
+		reader = importer.NewNDJSONReader(file)
+	default:
+		logger.Error("Unknown import format", slog.String("format", *format))
+		return 2
+	}
+
+	/* persons imported without tenancy belong to default tenant */
+	ctx := context.Background()
+	if configuration.Tenancy.Enabled {
+		tenantId, err := tenantService.ResolveTenant(ctx, "", *tenant, false)
+		if err != nil {
+			logger.Error("Failed to resolve tenant", slog.String("tenant", *tenant), utils.Err(err))
+			return 2
+		}
+		ctx = repository.WithTenant(ctx, tenantId)
+	}
+
+	report, err := importer.Import(ctx, personService, reader, importer.Options{
+		DryRun:    *dryRun,
+		ChunkSize: configuration.Api.BatchLimit,
+	})
+	if err != nil {
+		logger.Error("Import was interrupted", utils.Err(err))
+		return 1
+	}
+
+	logger.Info("Import is finished",
+		slog.Bool("dry_run", report.DryRun),
+		slog.Int("total", report.Total),
+		slog.Int("created", report.Created),
+		slog.Int("updated", report.Updated),
+		slog.Int("rejected", len(report.Rejected)))
+
+	var out io.Writer = os.Stdout
+	if *reportPath != "" {
+		reportFile, err := os.Create(*reportPath)
+		if err != nil {
+			logger.Error("Failed to create report file", utils.Err(err))
+			return 1
+		}
+		defer reportFile.Close()
+		out = reportFile
+	}
+	if err := importer.WriteReport(out, report); err != nil {
+		logger.Error("Failed to write import report", utils.Err(err))
+		return 1
+	}
+
+	if len(report.Rejected) > 0 {
+		return 3
+	}
+	return 0
+}
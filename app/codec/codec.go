@@ -0,0 +1,142 @@
+package codec
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+	"io"
+	"reflect"
+)
+
+// Codec encodes response bodies and decodes request bodies of single media type.
+type Codec interface {
+	// Name short name of codec, it is used as URL format extension.
+	Name() string
+	// ContentType media type written to Content-Type header.
+	ContentType() string
+	Encode(w io.Writer, v any) error
+	Decode(r io.Reader, v any) error
+}
+
+var (
+	JSON    Codec = jsonCodec{}
+	XML     Codec = xmlCodec{}
+	MsgPack Codec = msgpackCodec{}
+	CBOR    Codec = cborCodec{}
+)
+
+var (
+	ErrNotAcceptable        = errors.New("none of accepted media types is supported")
+	ErrUnsupportedMediaType = errors.New("media type of request is not supported")
+)
+
+/* codecs in order of server preference, the first one is default */
+var codecs = []Codec{JSON, XML, MsgPack, CBOR}
+
+/* media types with aliases used by clients in the wild */
+var mediaTypes = map[string]Codec{
+	"application/json":        JSON,
+	"application/xml":         XML,
+	"text/xml":                XML,
+	"application/msgpack":     MsgPack,
+	"application/x-msgpack":   MsgPack,
+	"application/vnd.msgpack": MsgPack,
+	"application/cbor":        CBOR,
+}
+
+// ByName find codec by short name (json, xml, msgpack, cbor).
+func ByName(name string) (Codec, bool) {
+	for _, codec := range codecs {
+		if codec.Name() == name {
+			return codec, true
+		}
+	}
+	return nil, false
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string        { return "json" }
+func (jsonCodec) ContentType() string { return "application/json" }
+
+func (jsonCodec) Encode(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(true)
+	return encoder.Encode(v)
+}
+
+func (jsonCodec) Decode(r io.Reader, v any) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+type xmlCodec struct{}
+
+func (xmlCodec) Name() string        { return "xml" }
+func (xmlCodec) ContentType() string { return "application/xml" }
+
+// Encode writes xml document, slices have no root element of their own so they are wrapped with items element.
+func (xmlCodec) Encode(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return encoder.Encode(v)
+	}
+
+	items := xml.StartElement{Name: xml.Name{Local: "items"}}
+	if err := encoder.EncodeToken(items); err != nil {
+		return err
+	}
+	for i := 0; i < value.Len(); i++ {
+		if err := encoder.Encode(value.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	if err := encoder.EncodeToken(items.End()); err != nil {
+		return err
+	}
+	return encoder.Flush()
+}
+
+func (xmlCodec) Decode(r io.Reader, v any) error {
+	return xml.NewDecoder(r).Decode(v)
+}
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Name() string        { return "msgpack" }
+func (msgpackCodec) ContentType() string { return "application/msgpack" }
+
+// Encode field names are taken from json tags, so every encoding shares the same names.
+func (msgpackCodec) Encode(w io.Writer, v any) error {
+	encoder := msgpack.NewEncoder(w)
+	encoder.SetCustomStructTag("json")
+	return encoder.Encode(v)
+}
+
+func (msgpackCodec) Decode(r io.Reader, v any) error {
+	decoder := msgpack.NewDecoder(r)
+	decoder.SetCustomStructTag("json")
+	return decoder.Decode(v)
+}
+
+type cborCodec struct{}
+
+/* cbor falls back to json tags, timestamps are encoded as tagged RFC 3339 strings */
+var cborEncMode, _ = cbor.EncOptions{Time: cbor.TimeRFC3339Nano, TimeTag: cbor.EncTagRequired}.EncMode()
+
+func (cborCodec) Name() string        { return "cbor" }
+func (cborCodec) ContentType() string { return "application/cbor" }
+
+func (cborCodec) Encode(w io.Writer, v any) error {
+	return cborEncMode.NewEncoder(w).Encode(v)
+}
+
+func (cborCodec) Decode(r io.Reader, v any) error {
+	return cbor.NewDecoder(r).Decode(v)
+}
@@ -0,0 +1,115 @@
+package codec
+
+import (
+	"bytes"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"person-service/model"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Negotiate(t *testing.T) {
+	cases := []struct {
+		accept string
+		codec  Codec
+	}{
+		{"", JSON},
+		{"*/*", JSON},
+		{"application/*", JSON},
+		{"application/xml", XML},
+		{"text/xml", XML},
+		{"text/*", XML},
+		{"text/html, text/*;q=0.5, application/cbor;q=0.4", XML},
+		{"application/x-msgpack", MsgPack},
+		{"application/cbor", CBOR},
+		{"text/html, application/xhtml+xml, application/xml;q=0.9, */*;q=0.8", XML},
+		{"application/json;q=0.5, application/cbor", CBOR},
+		{"*/*;q=0.1, application/msgpack;q=0.2", MsgPack},
+		{"application/json;q=0, application/*", XML},
+		{"application/json;q=oops, application/cbor", CBOR},
+	}
+
+	for _, c := range cases {
+		codec, err := Negotiate(c.accept)
+		assert.NoError(t, err, c.accept)
+		assert.Equal(t, c.codec, codec, c.accept)
+	}
+
+	_, err := Negotiate("text/html, image/png")
+	assert.ErrorIs(t, err, ErrNotAcceptable)
+	_, err = Negotiate("application/xml;q=0, text/*")
+	assert.ErrorIs(t, err, ErrNotAcceptable)
+}
+
+func Test_ForContentType(t *testing.T) {
+	codec, err := ForContentType("")
+	assert.NoError(t, err)
+	assert.Equal(t, JSON, codec)
+
+	codec, err = ForContentType("application/xml; charset=utf-8")
+	assert.NoError(t, err)
+	assert.Equal(t, XML, codec)
+
+	_, err = ForContentType("text/plain")
+	assert.ErrorIs(t, err, ErrUnsupportedMediaType)
+}
+
+func Test_RoundTrip(t *testing.T) {
+	person := model.PersonResponse{
+		Id:        uuid.New(),
+		FirstName: "Алексей",
+		LastName:  "Сидоров",
+		Age:       18,
+		Login:     "a.sidorov",
+		/* string values only, XML does not keep types of attributes */
+		Attributes: model.Attributes{"department": "sales"},
+		CreatedAt:  time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+		UpdatedAt:  time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC),
+	}
+
+	for _, codec := range codecs {
+		t.Run(codec.Name(), func(t *testing.T) {
+			var buf bytes.Buffer
+			assert.NoError(t, codec.Encode(&buf, person))
+
+			var decoded model.PersonResponse
+			assert.NoError(t, codec.Decode(&buf, &decoded))
+			assert.True(t, person.CreatedAt.Equal(decoded.CreatedAt))
+			assert.True(t, person.UpdatedAt.Equal(decoded.UpdatedAt))
+			decoded.XMLName, decoded.CreatedAt, decoded.UpdatedAt = person.XMLName, person.CreatedAt, person.UpdatedAt
+			assert.Equal(t, person, decoded)
+		})
+	}
+}
+
+func Test_XMLEncoding(t *testing.T) {
+	t.Run("person uses json field names", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, XML.Encode(&buf, model.PersonResponse{FirstName: "Иван"}))
+		assert.Contains(t, buf.String(), "<person><id>00000000-0000-0000-0000-000000000000</id><firstName>Иван</firstName>")
+	})
+
+	t.Run("list is wrapped with items element", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, XML.Encode(&buf, []model.PersonResponse{{Age: 1}, {Age: 2}}))
+		body := buf.String()
+		assert.True(t, strings.HasPrefix(body, "<?xml"))
+		assert.Contains(t, body, "<items><person>")
+		assert.Equal(t, 2, strings.Count(body, "</person>"))
+		assert.True(t, strings.HasSuffix(body, "</items>"))
+	})
+
+	t.Run("attributes are elements with name", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, XML.Encode(&buf, model.PersonResponse{Attributes: model.Attributes{"level": "senior", "badge": 17}}))
+		assert.Contains(t, buf.String(), `<attributes><attribute name="badge">17</attribute><attribute name="level">senior</attribute></attributes>`)
+	})
+
+	t.Run("error body", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, XML.Encode(&buf, model.Error("Person not found", model.NotFoundError)))
+		assert.Contains(t, buf.String(), "<error><status>404</status><message>Person not found</message></error>")
+	})
+}
@@ -0,0 +1,119 @@
+package codec
+
+import (
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type mediaRange struct {
+	mediaType   string
+	quality     float64
+	specificity int
+}
+
+// Negotiate pick codec for Accept header, media ranges are ordered by quality and specificity.
+// Empty header resolves to JSON, wildcards to the first codec in server preference with matching media type or alias.
+func Negotiate(accept string) (Codec, error) {
+	if strings.TrimSpace(accept) == "" {
+		return JSON, nil
+	}
+
+	ranges := parseAccept(accept)
+	/* media types excluded with q=0 are not chosen by wildcards */
+	excluded := make(map[Codec]bool)
+	for _, r := range ranges {
+		if codec, ok := mediaTypes[r.mediaType]; ok && r.quality == 0 {
+			excluded[codec] = true
+		}
+	}
+
+	for _, r := range ranges {
+		if r.quality == 0 {
+			continue
+		}
+		if codec, ok := mediaTypes[r.mediaType]; ok {
+			return codec, nil
+		}
+		if prefix, ok := strings.CutSuffix(r.mediaType, "*"); ok {
+			prefix = strings.TrimPrefix(prefix, "*/")
+			for _, codec := range codecs {
+				if !excluded[codec] && matchesPrefix(codec, prefix) {
+					return codec, nil
+				}
+			}
+		}
+	}
+
+	return nil, ErrNotAcceptable
+}
+
+// ForContentType pick codec for Content-Type of request, request without Content-Type is decoded as JSON.
+func ForContentType(contentType string) (Codec, error) {
+	if strings.TrimSpace(contentType) == "" {
+		return JSON, nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, ErrUnsupportedMediaType
+	}
+	if codec, ok := mediaTypes[mediaType]; ok {
+		return codec, nil
+	}
+	return nil, ErrUnsupportedMediaType
+}
+
+// MediaTypes supported media types, used for error messages.
+func MediaTypes() []string {
+	types := make([]string, len(codecs))
+	for i, codec := range codecs {
+		types[i] = codec.ContentType()
+	}
+	return types
+}
+
+/* aliases take part in wildcards too, text/* selects XML by text/xml */
+func matchesPrefix(codec Codec, prefix string) bool {
+	for mediaType, c := range mediaTypes {
+		if c == codec && strings.HasPrefix(mediaType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil || quality < 0 || quality > 1 {
+				continue
+			}
+		}
+
+		specificity := 2
+		switch {
+		case mediaType == "*/*":
+			specificity = 0
+		case strings.HasSuffix(mediaType, "/*"):
+			specificity = 1
+		}
+		ranges = append(ranges, mediaRange{mediaType: mediaType, quality: quality, specificity: specificity})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].quality != ranges[j].quality {
+			return ranges[i].quality > ranges[j].quality
+		}
+		return ranges[i].specificity > ranges[j].specificity
+	})
+	return ranges
+}
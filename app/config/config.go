@@ -8,10 +8,26 @@ import (
 )
 
 type Config struct {
-	Env        string `yaml:"env" env-required:"true"`
-	Server     `yaml:"server"`
-	Datasource `yaml:"datasource"`
-	Security   `yaml:"security" env-required:"false"`
+	Env           string `yaml:"env" env-required:"true"`
+	Server        `yaml:"server"`
+	Cors          `yaml:"cors"`
+	Datasource    `yaml:"datasource"`
+	Security      `yaml:"security" env-required:"false"`
+	Outbox        `yaml:"outbox"`
+	Webhooks      `yaml:"webhooks"`
+	Api           `yaml:"api"`
+	Idempotency   `yaml:"idempotency"`
+	Grpc          `yaml:"grpc"`
+	Graphql       `yaml:"graphql"`
+	Stream        `yaml:"stream"`
+	Person        `yaml:"person"`
+	Groups        `yaml:"groups"`
+	Relationships `yaml:"relationships"`
+	Avatar        `yaml:"avatar"`
+	Blob          `yaml:"blob"`
+	Attachments   `yaml:"attachments"`
+	Duplicates    `yaml:"duplicates"`
+	Tenancy       `yaml:"tenancy"`
 }
 
 type Datasource struct {
@@ -20,6 +36,17 @@ type Datasource struct {
 	User     string `yaml:"user" env-required:"true"`
 	Password string `yaml:"password" env-required:"true"`
 	DbName   string `yaml:"db-name" env-required:"true"`
+	/* pgx pool settings */
+	MaxConns               int32 `yaml:"max-conns" env-default:"10"`
+	StatementCacheCapacity int   `yaml:"statement-cache-capacity" env-default:"512"`
+	Transaction            `yaml:"transaction"`
+}
+
+type Transaction struct {
+	/* one of: read committed, repeatable read, serializable */
+	IsolationLevel string        `yaml:"isolation-level" env-default:"read committed"`
+	MaxRetries     int           `yaml:"max-retries" env-default:"3"`
+	RetryBackoff   time.Duration `yaml:"retry-backoff" env-default:"50ms"`
 }
 
 type Server struct {
@@ -28,11 +55,150 @@ type Server struct {
 	IdleTimeout time.Duration `yaml:"idle-timeout" env-default:"60s"`
 }
 
+type Cors struct {
+	/* browser origins allowed to call API and open WebSocket, "*" allows any */
+	AllowedOrigins []string `yaml:"allowed-origins" env-default:"*"`
+	MaxAge         int      `yaml:"max-age" env-default:"3600"`
+}
+
 type Security struct {
 	Exponent string `yaml:"exponent" env-required:"false"`
 	Module   string `yaml:"module" env-required:"false"`
 }
 
+type Outbox struct {
+	Enabled bool `yaml:"enabled" env-default:"false"`
+	/* one of: stdout, file, webhook */
+	Publisher    string        `yaml:"publisher" env-default:"stdout"`
+	FilePath     string        `yaml:"file-path"`
+	WebhookUrl   string        `yaml:"webhook-url"`
+	BatchSize    int           `yaml:"batch-size" env-default:"100"`
+	PollInterval time.Duration `yaml:"poll-interval" env-default:"1s"`
+	RetryBackoff time.Duration `yaml:"retry-backoff" env-default:"1s"`
+	MaxBackoff   time.Duration `yaml:"max-backoff" env-default:"5m"`
+	/* claimed batch is published within lease, it must exceed batch-size times publish timeout */
+	Lease time.Duration `yaml:"lease" env-default:"1m"`
+}
+
+type Webhooks struct {
+	Enabled      bool          `yaml:"enabled" env-default:"false"`
+	BatchSize    int           `yaml:"batch-size" env-default:"20"`
+	PollInterval time.Duration `yaml:"poll-interval" env-default:"2s"`
+	Timeout      time.Duration `yaml:"timeout" env-default:"5s"`
+	MaxAttempts  int           `yaml:"max-attempts" env-default:"10"`
+	RetryBackoff time.Duration `yaml:"retry-backoff" env-default:"5s"`
+	MaxBackoff   time.Duration `yaml:"max-backoff" env-default:"1h"`
+	/* claimed batch is sent within lease, it must exceed batch-size times timeout */
+	Lease time.Duration `yaml:"lease" env-default:"5m"`
+	/* subscribers on loopback, link-local and private addresses are rejected unless allowed */
+	AllowPrivateNetworks bool `yaml:"allow-private-networks" env-default:"false"`
+}
+
+type Api struct {
+	/* announced in Sunset header of deprecated v1 person routes */
+	V1Sunset time.Time `yaml:"v1-sunset"`
+	/* max operations of one batch request */
+	BatchLimit int `yaml:"batch-limit" env-default:"1000"`
+}
+
+type Idempotency struct {
+	/* stored response is replayed for retries during ttl */
+	TTL             time.Duration `yaml:"ttl" env-default:"24h"`
+	CleanupInterval time.Duration `yaml:"cleanup-interval" env-default:"1h"`
+}
+
+type Grpc struct {
+	Enabled bool `yaml:"enabled" env-default:"false"`
+	Port    int  `yaml:"port" env-default:"9903"`
+	/* how often WatchPersons polls outbox for new events */
+	WatchPollInterval time.Duration `yaml:"watch-poll-interval" env-default:"1s"`
+}
+
+type Graphql struct {
+	/* queries deeper than max depth or more complex than max complexity are rejected before execution */
+	MaxDepth      int `yaml:"max-depth" env-default:"8"`
+	MaxComplexity int `yaml:"max-complexity" env-default:"1000"`
+	/* max value of first argument of persons connection */
+	MaxPageSize int `yaml:"max-page-size" env-default:"100"`
+}
+
+type Stream struct {
+	HeartbeatInterval time.Duration `yaml:"heartbeat-interval" env-default:"15s"`
+	/* client behind the last event by more than replay-limit events gets reset instead of replay */
+	ReplayLimit int `yaml:"replay-limit" env-default:"1000"`
+	/* events buffered for slow connection, connection is closed when buffer overflows */
+	BufferSize       int           `yaml:"buffer-size" env-default:"256"`
+	ReconnectBackoff time.Duration `yaml:"reconnect-backoff" env-default:"1s"`
+	/* skipped event id is awaited so long, transaction which allocated it commits or rolls back meanwhile */
+	GapTimeout time.Duration `yaml:"gap-timeout" env-default:"30s"`
+}
+
+type Person struct {
+	/* IANA time zone of "today" when age is computed from birth date and age filters are translated to dates */
+	AgeTimeZone string `yaml:"age-time-zone" env-default:"UTC"`
+}
+
+type Groups struct {
+	/* one of: restrict (group with children or members is not deleted), cascade (descendants and memberships are deleted too) */
+	DeleteMode string `yaml:"delete-mode" env-default:"restrict"`
+}
+
+type Relationships struct {
+	/* max levels of reports tree and chain of command, it is also depth of them when depth is not requested */
+	MaxDepth int `yaml:"max-depth" env-default:"10"`
+}
+
+type Avatar struct {
+	/* max bytes of uploaded image and max pixels (width * height) checked before image is decoded */
+	MaxSize   int64 `yaml:"max-size" env-default:"5242880"`
+	MaxPixels int   `yaml:"max-pixels" env-default:"40000000"`
+	/* stored original is scaled down to fit max-dimension, thumbnails are squares of sizes */
+	MaxDimension int           `yaml:"max-dimension" env-default:"1024"`
+	Sizes        []int         `yaml:"sizes" env-default:"64,128,256"`
+	CacheMaxAge  time.Duration `yaml:"cache-max-age" env-default:"24h"`
+}
+
+type Blob struct {
+	/* one of: filesystem (files under path), s3 (bucket of S3-compatible endpoint) */
+	Type      string `yaml:"type" env-default:"filesystem"`
+	Path      string `yaml:"path" env-default:"data/blobs"`
+	Endpoint  string `yaml:"endpoint"`
+	Bucket    string `yaml:"bucket"`
+	Region    string `yaml:"region" env-default:"us-east-1"`
+	AccessKey string `yaml:"access-key"`
+	SecretKey string `yaml:"secret-key"`
+}
+
+type Attachments struct {
+	MaxSize int64 `yaml:"max-size" env-default:"26214400"`
+	/* one of: none, clamd (content is streamed to clamd INSTREAM before it is stored) */
+	Scanner      string        `yaml:"scanner" env-default:"none"`
+	ClamdAddress string        `yaml:"clamd-address" env-default:"localhost:3310"`
+	ScanTimeout  time.Duration `yaml:"scan-timeout" env-default:"30s"`
+	/* upload is spooled to temporary directory while checksum is computed and content is scanned, empty is system one */
+	TempDir string `yaml:"temp-dir"`
+	/* how often attachments past retention date are deleted and files of deleted attachments are purged */
+	PurgeInterval time.Duration `yaml:"purge-interval" env-default:"1h"`
+}
+
+type Duplicates struct {
+	/* candidates scored below min-score (0..1: name 0.5, shared email or phone 0.4, birth date 0.1) are not returned */
+	MinScore float64 `yaml:"min-score" env-default:"0.4"`
+	Limit    int     `yaml:"limit" env-default:"20"`
+}
+
+type Tenancy struct {
+	/* disabled tenancy serves every request by default tenant */
+	Enabled bool `yaml:"enabled" env-default:"false"`
+	/* tenant id is taken from claim of bearer token, header is used for tokens without the claim */
+	Claim  string `yaml:"claim" env-default:"tenant_id"`
+	Header string `yaml:"header" env-default:"X-Tenant-ID"`
+	/* request without tenant is rejected when required, otherwise it is served by default tenant */
+	Required bool `yaml:"required" env-default:"false"`
+	/* subjects of tokens allowed to provision and suspend tenants, nobody may when security is disabled */
+	Admins []string `yaml:"admins"`
+}
+
 func LoadConfiguration() *Config {
 	configPath := os.Getenv("CONFIG_PATH")
 
@@ -0,0 +1,24 @@
+package controllers
+
+import (
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/exp/slog"
+	"person-service/config"
+	"person-service/handlers"
+	"person-service/services"
+)
+
+// RegisterAttachmentHandlers upload and download stream binary content, metadata is negotiated as other resources.
+func RegisterAttachmentHandlers(logger *slog.Logger, router *chi.Mux, service *services.AttachmentService, attachments config.Attachments) {
+	router.Route(handlers.PersonsPath+"/{id}/attachments", func(r chi.Router) {
+		r.Post("/", handlers.UploadAttachment(logger, service, attachments.MaxSize))
+		r.Get("/{attachmentId}/content", handlers.DownloadAttachment(logger, service))
+		r.Group(func(r chi.Router) {
+			r.Use(handlers.Negotiation)
+			r.Get("/", handlers.LoadAttachments(logger, service))
+			r.Get("/{attachmentId}", handlers.FindAttachment(logger, service))
+			r.Put("/{attachmentId}/retention", handlers.UpdateAttachmentRetention(logger, service))
+			r.Delete("/{attachmentId}", handlers.DeleteAttachment(logger, service))
+		})
+	})
+}
@@ -0,0 +1,19 @@
+package controllers
+
+import (
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/exp/slog"
+	"person-service/handlers"
+	"person-service/services"
+)
+
+func RegisterAttributeHandlers(logger *slog.Logger, router *chi.Mux, service *services.AttributeService) {
+	router.Group(func(r chi.Router) {
+		r.Use(handlers.Negotiation)
+		r.Post(handlers.AttributesPath, handlers.CreateAttributeDefinition(logger, service))
+		r.Get(handlers.AttributesPath, handlers.LoadAttributeDefinitions(logger, service))
+		r.Get(handlers.AttributesPath+"/{name}", handlers.FindAttributeDefinition(logger, service))
+		r.Put(handlers.AttributesPath+"/{name}", handlers.UpdateAttributeDefinition(logger, service))
+		r.Delete(handlers.AttributesPath+"/{name}", handlers.DeleteAttributeDefinition(logger, service))
+	})
+}
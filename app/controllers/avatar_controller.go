@@ -0,0 +1,25 @@
+package controllers
+
+import (
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/exp/slog"
+	"person-service/config"
+	"person-service/handlers"
+	"person-service/services"
+)
+
+// RegisterAvatarHandlers avatars are binary resources, so they are served outside of content negotiation.
+func RegisterAvatarHandlers(logger *slog.Logger, router *chi.Mux, service *services.AvatarService, avatar config.Avatar, api config.Api) {
+	router.Route(handlers.PersonsPath+"/{id}/avatar", func(r chi.Router) {
+		r.Put("/", handlers.SaveAvatar(logger, service, avatar.MaxSize))
+		r.Get("/", handlers.FindAvatar(logger, service, avatar.CacheMaxAge))
+		r.Delete("/", handlers.DeleteAvatar(logger, service))
+	})
+
+	router.Group(func(r chi.Router) {
+		r.Use(handlers.Deprecation(api.V1Sunset, handlers.PersonsPath))
+		r.Put("/api/v1/person/{id}/avatar", handlers.SaveAvatar(logger, service, avatar.MaxSize))
+		r.Get("/api/v1/person/{id}/avatar", handlers.FindAvatar(logger, service, avatar.CacheMaxAge))
+		r.Delete("/api/v1/person/{id}/avatar", handlers.DeleteAvatar(logger, service))
+	})
+}
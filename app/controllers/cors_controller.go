@@ -3,15 +3,16 @@ package controllers
 import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
+	"person-service/config"
 )
 
-func RegisterCorsMiddlewareHandlers(router *chi.Mux) {
+func RegisterCorsMiddlewareHandlers(router *chi.Mux, origins config.Cors) {
 	router.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedOrigins:   origins.AllowedOrigins,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "Idempotency-Key", "X-Tenant-ID"},
+		ExposedHeaders:   []string{"Link", "Location", "Deprecation", "Sunset", "Idempotent-Replayed"},
 		AllowCredentials: true,
-		MaxAge:           3600,
+		MaxAge:           origins.MaxAge,
 	}))
 }
@@ -0,0 +1,13 @@
+package controllers
+
+import (
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/exp/slog"
+	"person-service/graph"
+	"person-service/handlers"
+)
+
+func RegisterGraphqlHandlers(logger *slog.Logger, router *chi.Mux, schema *graph.Schema) {
+	router.Get(handlers.GraphqlPath, handlers.Graphql(logger, schema))
+	router.Post(handlers.GraphqlPath, handlers.Graphql(logger, schema))
+}
@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/exp/slog"
+	"person-service/config"
+	"person-service/handlers"
+	"person-service/services"
+)
+
+func RegisterGroupHandlers(logger *slog.Logger, router *chi.Mux, groups *services.GroupService, tags *services.TagService, api config.Api) {
+	router.Group(func(r chi.Router) {
+		r.Use(handlers.Negotiation)
+		r.Route(handlers.GroupsPath, func(r chi.Router) {
+			r.Post("/", handlers.CreateGroup(logger, groups))
+			r.Get("/", handlers.LoadGroups(logger, groups))
+			r.Route("/{id}", func(r chi.Router) {
+				r.Get("/", handlers.FindGroup(logger, groups))
+				r.Put("/", handlers.UpdateGroup(logger, groups))
+				r.Delete("/", handlers.DeleteGroup(logger, groups))
+				r.Get("/members", handlers.LoadGroupMembers(logger, groups))
+				r.Post("/members", handlers.AddGroupMembers(logger, groups, api.BatchLimit))
+				r.Delete("/members", handlers.RemoveGroupMembers(logger, groups, api.BatchLimit))
+			})
+		})
+		r.Route(handlers.TagsPath, func(r chi.Router) {
+			r.Get("/", handlers.LoadTags(logger, tags))
+			r.Get("/{tag}/members", handlers.LoadTagMembers(logger, tags))
+			r.Post("/{tag}/members", handlers.AddTagMembers(logger, tags, api.BatchLimit))
+			r.Delete("/{tag}/members", handlers.RemoveTagMembers(logger, tags, api.BatchLimit))
+		})
+	})
+}
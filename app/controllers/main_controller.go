@@ -6,12 +6,14 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	httpSwagger "github.com/swaggo/http-swagger"
 	"golang.org/x/exp/slog"
+	"person-service/config"
 	_ "person-service/docs"
 	"person-service/handlers"
+	"person-service/services"
 	"person-service/utils"
 )
 
-func RegisterMiddlewareHandlers(logger *slog.Logger, router *chi.Mux, rsaPubKey *rsa.PublicKey) {
+func RegisterMiddlewareHandlers(logger *slog.Logger, router *chi.Mux, rsaPubKey *rsa.PublicKey, tenants *services.TenantService, tenancy config.Tenancy) {
 	/* register middleware filters */
 	handlers.Init(rsaPubKey)
 	router.Use(middleware.RequestID)
@@ -21,6 +23,7 @@ func RegisterMiddlewareHandlers(logger *slog.Logger, router *chi.Mux, rsaPubKey
 	router.Use(utils.New(logger))
 	router.Use(middleware.Recoverer)
 	router.Use(middleware.URLFormat)
+	router.Use(handlers.Tenancy(logger, tenants, tenancy))
 	router.Get("/swagger/*", httpSwagger.Handler(
 		httpSwagger.URL("http://localhost:9902/swagger/doc.json"), //The url pointing to API definition
 	))
@@ -0,0 +1,26 @@
+package controllers
+
+import (
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/exp/slog"
+	"person-service/config"
+	"person-service/handlers"
+	"person-service/services"
+)
+
+func RegisterMergeHandlers(logger *slog.Logger, router *chi.Mux, service *services.MergeService, api config.Api) {
+	/* full paths, mount of person sub-path would hide other routes of person */
+	router.Group(func(r chi.Router) {
+		r.Use(handlers.Negotiation)
+		r.Get(handlers.PersonsPath+"/{id}/duplicates", handlers.FindDuplicates(logger, service))
+		r.Post(handlers.PersonsPath+"/{id}/merge", handlers.MergePersons(logger, service))
+		r.Get(handlers.PersonsPath+"/{id}/merges", handlers.LoadMerges(logger, service))
+	})
+
+	router.Group(func(r chi.Router) {
+		r.Use(handlers.Deprecation(api.V1Sunset, handlers.PersonsPath))
+		r.Use(handlers.Negotiation)
+		r.Get("/api/v1/person/{id}/duplicates", handlers.FindDuplicates(logger, service))
+		r.Post("/api/v1/person/{id}/merge", handlers.MergePersons(logger, service))
+	})
+}
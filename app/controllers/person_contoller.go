@@ -3,15 +3,82 @@ package controllers
 import (
 	"github.com/go-chi/chi/v5"
 	"golang.org/x/exp/slog"
-	"person-service/db/repository"
+	"person-service/config"
 	"person-service/handlers"
+	"person-service/services"
 )
 
-func RegisterPersonHandlers(logger *slog.Logger, router *chi.Mux, storage *repository.PersonRepositoryImpl) {
-	router.Post("/api/v1/person/create", handlers.CreatePerson(logger, storage))
-	router.Delete("/api/v1/person/delete", handlers.DeletePerson(logger, storage))
-	router.Put("/api/v1/person/update", handlers.UpdatePerson(logger, storage))
-	router.Get("/api/v1/person/get/id", handlers.FindPersonById(logger, storage))
-	router.Get("/api/v1/persons", handlers.LoadPersons(logger, storage))
-	router.Get("/api/v1/person/get/login", handlers.FindPersonByLogin(logger, storage))
+func RegisterPersonHandlers(
+	logger *slog.Logger,
+	router *chi.Mux,
+	service *services.PersonService,
+	contacts *services.ContactService,
+	addresses *services.AddressService,
+	relationships *services.RelationshipService,
+	idempotency *services.IdempotencyService,
+	api config.Api,
+) {
+	idempotent := handlers.Idempotency(logger, idempotency)
+	expansion := handlers.PersonExpansion{Contacts: contacts, Addresses: addresses}
+
+	router.Route(handlers.PersonsPath, func(r chi.Router) {
+		/* import and export negotiate file formats on their own */
+		r.Post("/import", handlers.ImportPersons(logger, service, api.BatchLimit))
+		r.Get("/export", handlers.ExportPersons(logger, service))
+		r.Group(func(r chi.Router) {
+			r.Use(handlers.Negotiation)
+			r.With(idempotent).Post("/", handlers.CreatePerson(logger, service))
+			r.Get("/", handlers.LoadPersons(logger, service, expansion))
+			r.With(idempotent).Post("/batch", handlers.BatchPersons(logger, service, api.BatchLimit))
+			r.Route("/{id}", func(r chi.Router) {
+				r.Get("/", handlers.FindPersonById(logger, service, expansion))
+				r.Put("/", handlers.UpdatePerson(logger, service))
+				r.Patch("/", handlers.PatchPerson(logger, service))
+				r.Delete("/", handlers.DeletePerson(logger, service))
+				r.Route("/emails", func(r chi.Router) {
+					r.Post("/", handlers.CreateEmail(logger, contacts))
+					r.Get("/", handlers.LoadEmails(logger, contacts))
+					r.Get("/{emailId}", handlers.FindEmail(logger, contacts))
+					r.Put("/{emailId}", handlers.UpdateEmail(logger, contacts))
+					r.Delete("/{emailId}", handlers.DeleteEmail(logger, contacts))
+				})
+				r.Route("/phones", func(r chi.Router) {
+					r.Post("/", handlers.CreatePhone(logger, contacts))
+					r.Get("/", handlers.LoadPhones(logger, contacts))
+					r.Get("/{phoneId}", handlers.FindPhone(logger, contacts))
+					r.Put("/{phoneId}", handlers.UpdatePhone(logger, contacts))
+					r.Delete("/{phoneId}", handlers.DeletePhone(logger, contacts))
+				})
+				r.Route("/addresses", func(r chi.Router) {
+					r.Post("/", handlers.CreateAddress(logger, addresses))
+					r.Get("/", handlers.LoadAddresses(logger, addresses))
+					r.Get("/{addressId}", handlers.FindAddress(logger, addresses))
+					r.Put("/{addressId}", handlers.UpdateAddress(logger, addresses))
+					r.Delete("/{addressId}", handlers.DeleteAddress(logger, addresses))
+				})
+				r.Route("/relationships", func(r chi.Router) {
+					r.Post("/", handlers.CreateRelationship(logger, relationships))
+					r.Get("/", handlers.LoadRelationships(logger, relationships))
+					r.Delete("/{relationshipId}", handlers.DeleteRelationship(logger, relationships))
+				})
+				r.Get("/reports", handlers.LoadReports(logger, relationships))
+				r.Get("/chain", handlers.LoadChain(logger, relationships))
+			})
+		})
+	})
+
+	/* v1 compatibility shim, served by the same handlers */
+	router.Group(func(r chi.Router) {
+		r.Use(handlers.Deprecation(api.V1Sunset, handlers.PersonsPath))
+		r.Get("/api/v1/persons/export", handlers.ExportPersons(logger, service))
+		r.Group(func(r chi.Router) {
+			r.Use(handlers.Negotiation)
+			r.With(idempotent).Post("/api/v1/person/create", handlers.LegacyV1(handlers.CreatePerson(logger, service)))
+			r.Delete("/api/v1/person/delete", handlers.LegacyV1(handlers.DeletePerson(logger, service)))
+			r.Put("/api/v1/person/update", handlers.LegacyV1(handlers.UpdatePerson(logger, service)))
+			r.Get("/api/v1/person/get/id", handlers.LegacyV1(handlers.FindPersonById(logger, service, expansion)))
+			r.Get("/api/v1/persons", handlers.LegacyV1(handlers.LoadPersons(logger, service, expansion)))
+			r.Get("/api/v1/person/get/login", handlers.FindPersonByLogin(logger, service))
+		})
+	})
 }
@@ -0,0 +1,14 @@
+package controllers
+
+import (
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/exp/slog"
+	"person-service/config"
+	"person-service/events"
+	"person-service/handlers"
+)
+
+func RegisterPersonStreamHandlers(logger *slog.Logger, router *chi.Mux, feed *events.Feed, stream config.Stream, origins config.Cors) {
+	router.Get("/api/v1/persons/stream", handlers.StreamPersonEvents(logger, feed, stream))
+	router.Get("/api/v1/persons/stream/ws", handlers.StreamPersonEventsWs(logger, feed, stream, origins.AllowedOrigins))
+}
@@ -0,0 +1,23 @@
+package controllers
+
+import (
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/exp/slog"
+	"person-service/config"
+	"person-service/handlers"
+	"person-service/services"
+)
+
+// RegisterTenantHandlers tenant admin api, requests are bound to tenants by middleware of RegisterMiddlewareHandlers.
+func RegisterTenantHandlers(logger *slog.Logger, router *chi.Mux, service *services.TenantService, tenancy config.Tenancy) {
+	router.Group(func(r chi.Router) {
+		r.Use(handlers.RequireTenantAdmin(tenancy.Admins))
+		r.Use(handlers.Negotiation)
+		r.Post(handlers.TenantsPath, handlers.CreateTenant(logger, service))
+		r.Get(handlers.TenantsPath, handlers.LoadTenants(logger, service))
+		r.Get(handlers.TenantsPath+"/{id}", handlers.FindTenant(logger, service))
+		r.Put(handlers.TenantsPath+"/{id}", handlers.UpdateTenant(logger, service))
+		r.Post(handlers.TenantsPath+"/{id}/suspend", handlers.SuspendTenant(logger, service))
+		r.Post(handlers.TenantsPath+"/{id}/resume", handlers.ResumeTenant(logger, service))
+	})
+}
@@ -0,0 +1,21 @@
+package controllers
+
+import (
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/exp/slog"
+	"person-service/handlers"
+	"person-service/services"
+)
+
+func RegisterWebhookHandlers(logger *slog.Logger, router *chi.Mux, service *services.WebhookService) {
+	router.Group(func(r chi.Router) {
+		r.Use(handlers.Negotiation)
+		r.Post("/api/v1/webhooks", handlers.CreateWebhook(logger, service))
+		r.Get("/api/v1/webhooks", handlers.LoadWebhooks(logger, service))
+		r.Get("/api/v1/webhooks/{id}", handlers.FindWebhook(logger, service))
+		r.Put("/api/v1/webhooks/{id}", handlers.UpdateWebhook(logger, service))
+		r.Delete("/api/v1/webhooks/{id}", handlers.DeleteWebhook(logger, service))
+		r.Get("/api/v1/webhooks/{id}/deliveries", handlers.LoadWebhookDeliveries(logger, service))
+		r.Post("/api/v1/webhooks/{id}/deliveries/{deliveryId}/retry", handlers.RetryWebhookDelivery(logger, service))
+	})
+}
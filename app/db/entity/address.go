@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Address postal address of person, ValidFrom and ValidTo are dates, nil means open end.
+type Address struct {
+	Id         uuid.UUID
+	PersonId   uuid.UUID
+	Type       string
+	Lines      []string
+	City       string
+	Region     string
+	PostalCode string
+	Country    string
+	ValidFrom  *time.Time
+	ValidTo    *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
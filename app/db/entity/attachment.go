@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Attachment document of person, content is kept in blob store under BlobKey.
+// Attachment is purged after RetainUntil when it is set.
+type Attachment struct {
+	Id          uuid.UUID
+	PersonId    uuid.UUID
+	FileName    string
+	ContentType string
+	Size        int64
+	Sha256      string
+	BlobKey     string
+	RetainUntil *time.Time
+	CreatedAt   time.Time
+}
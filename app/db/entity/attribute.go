@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+type AttributeType string
+
+const (
+	AttributeString AttributeType = "string"
+	AttributeInt    AttributeType = "int"
+	AttributeBool   AttributeType = "bool"
+	AttributeDate   AttributeType = "date"
+	AttributeEnum   AttributeType = "enum"
+)
+
+// AttributeDefinition custom attribute of person, Pattern applies to string attributes, EnumValues to enum ones.
+type AttributeDefinition struct {
+	Name       string
+	Type       AttributeType
+	Required   bool
+	Pattern    string
+	EnumValues []string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
@@ -0,0 +1,17 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Avatar image of person, ETag is version of image shared by original and thumbnails of Sizes.
+type Avatar struct {
+	PersonId    uuid.UUID
+	ContentType string
+	ETag        string
+	Width       int
+	Height      int
+	Sizes       []int
+	UpdatedAt   time.Time
+}
@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// ContactKind kind of person contact, every kind is stored in its own table.
+type ContactKind string
+
+const (
+	ContactEmail ContactKind = "email"
+	ContactPhone ContactKind = "phone"
+)
+
+// Contact email or phone of person, value is normalized by service.
+type Contact struct {
+	Id        uuid.UUID
+	PersonId  uuid.UUID
+	Kind      ContactKind
+	Value     string
+	Type      string
+	Primary   bool
+	Verified  bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Group named group of persons, ParentId is nil for root group.
+type Group struct {
+	Id          uuid.UUID
+	ParentId    *uuid.UUID
+	Name        string
+	Description string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Tag free-form label with count of tagged persons.
+type Tag struct {
+	Name    string
+	Persons int
+}
@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+// IdempotencyKey stored response of request with Idempotency-Key header.
+type IdempotencyKey struct {
+	Principal   string
+	Key         string
+	RequestHash []byte
+	Status      int
+	Headers     map[string]string
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
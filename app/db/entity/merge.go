@@ -0,0 +1,34 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// DuplicateCandidate person which may duplicate searched one, NameSimilarity is trigram similarity of name keys,
+// Swapped reports whether first name of candidate is closer to last name of person and back.
+type DuplicateCandidate struct {
+	Person         Person
+	NameSimilarity float64
+	Swapped        bool
+	SharedEmail    bool
+	SharedPhone    bool
+}
+
+// Duplicate scored candidate, Score is between 0 and 1 and Reasons name matched signals.
+type Duplicate struct {
+	Person  Person
+	Score   float64
+	Reasons []string
+}
+
+// PersonMerge merge of person into survivor, id of merged person redirects to survivor.
+// Merged is JSON of merged person as it was before merge, Fields is source of every merged field.
+type PersonMerge struct {
+	Id         uuid.UUID
+	SurvivorId uuid.UUID
+	MergedId   uuid.UUID
+	Merged     []byte
+	Fields     map[string]string
+	MergedAt   time.Time
+}
@@ -0,0 +1,16 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type OutboxEvent struct {
+	Id          int64
+	TenantId    uuid.UUID
+	AggregateId uuid.UUID
+	EventType   string
+	Payload     []byte
+	CreatedAt   time.Time
+	Attempts    int
+}
@@ -10,6 +10,39 @@ type Person struct {
 	Login     string
 	FirstName string
 	LastName  string
-	Age       int
-	Timestamp *time.Time
+	/* computed from BirthDate at read time, on write it is used only when BirthDate is nil */
+	Age int
+	/* date at midnight UTC, approximate one is derived from age of person created without birth date */
+	BirthDate            *time.Time
+	BirthDateApproximate bool
+	/* values of custom attributes by name: string, int64, bool or date as YYYY-MM-DD string */
+	Attributes map[string]any
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// AgeAt full years between birth date and today, both are dates at midnight UTC.
+func AgeAt(birthDate time.Time, today time.Time) int {
+	age := today.Year() - birthDate.Year()
+	if today.Month() < birthDate.Month() || (today.Month() == birthDate.Month() && today.Day() < birthDate.Day()) {
+		age--
+	}
+	return age
+}
+
+// BirthDateAt the latest birth date of person having at least age today,
+// person born on 29 February becomes one year older on 1 March of common year.
+func BirthDateAt(age int, today time.Time) time.Time {
+	date := time.Date(today.Year()-age, today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	if date.Month() != today.Month() {
+		/* 29 February of common year, the last day of February is taken */
+		date = time.Date(today.Year()-age, today.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+	}
+	return date
+}
+
+// DateOf calendar date of moment in location as midnight UTC, dates are compared and stored in this form.
+func DateOf(moment time.Time, location *time.Location) time.Time {
+	year, month, day := moment.In(location).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
 }
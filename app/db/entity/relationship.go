@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Relationship directed relationship, related person is Type of person (manager of person).
+// Relationship seen from related person is Incoming, its Type is inverse type when it has one.
+type Relationship struct {
+	Id        uuid.UUID
+	PersonId  uuid.UUID
+	RelatedId uuid.UUID
+	Type      string
+	Incoming  bool
+	CreatedAt time.Time
+}
+
+// HierarchyNode person of hierarchical relationships at Depth from requested person, LinkId is person of the previous level.
+type HierarchyNode struct {
+	Person    Person
+	LinkId    uuid.UUID
+	Depth     int
+	Nodes     []HierarchyNode
+	Truncated bool
+}
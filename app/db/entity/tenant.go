@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	TenantActive    = "active"
+	TenantSuspended = "suspended"
+)
+
+// DefaultTenantId tenant of rows written before tenancy, it serves every request when tenancy is disabled.
+var DefaultTenantId = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+// Tenant organization owning persons. Zero MaxPersons means no quota, zero MaxAge and empty LoginPattern keep
+// default validation of persons.
+type Tenant struct {
+	Id           uuid.UUID
+	Name         string
+	Status       string
+	MaxPersons   int
+	MaxAge       int
+	LoginPattern string
+	RequireLogin bool
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	/* count of persons, filled for tenants loaded by admin */
+	Persons int
+}
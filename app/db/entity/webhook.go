@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+type WebhookSubscription struct {
+	Id         uuid.UUID
+	Url        string
+	EventTypes []string
+	Secret     string
+	Active     bool
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type WebhookDelivery struct {
+	Id             int64
+	SubscriptionId uuid.UUID
+	EventId        int64
+	EventType      string
+	Payload        []byte
+	Status         string
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastError      *string
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+	/* filled for deliveries fetched by worker */
+	Url    string
+	Secret string
+}
+
+type WebhookDeliveryAttempt struct {
+	Id             int64
+	DeliveryId     int64
+	ResponseStatus *int
+	Error          *string
+	Duration       time.Duration
+	AttemptedAt    time.Time
+}
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS person(
+    id          uuid        PRIMARY KEY,
+    first_name  text        NOT NULL,
+    last_name   text        NOT NULL,
+    age         int         NOT NULL,
+    last_update timestamp   NOT NULL,
+    login       text        UNIQUE
+);
+
+ALTER TABLE person ADD COLUMN IF NOT EXISTS login text UNIQUE;
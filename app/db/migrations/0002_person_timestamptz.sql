@@ -0,0 +1,9 @@
+/* rows written before this migration carry no zone, they are treated as UTC */
+ALTER TABLE person RENAME COLUMN last_update TO updated_at;
+ALTER TABLE person ALTER COLUMN updated_at TYPE timestamptz USING updated_at AT TIME ZONE 'UTC';
+ALTER TABLE person ALTER COLUMN updated_at SET DEFAULT now();
+
+ALTER TABLE person ADD COLUMN created_at timestamptz;
+UPDATE person SET created_at = updated_at;
+ALTER TABLE person ALTER COLUMN created_at SET NOT NULL;
+ALTER TABLE person ALTER COLUMN created_at SET DEFAULT now();
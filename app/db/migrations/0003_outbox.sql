@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS outbox(
+    id              bigserial   PRIMARY KEY,
+    aggregate_id    uuid        NOT NULL,
+    event_type      text        NOT NULL,
+    payload         jsonb       NOT NULL,
+    created_at      timestamptz NOT NULL DEFAULT now(),
+    attempts        int         NOT NULL DEFAULT 0,
+    next_attempt_at timestamptz NOT NULL DEFAULT now(),
+    published_at    timestamptz,
+    last_error      text
+);
+
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox(aggregate_id, id) WHERE published_at IS NULL;
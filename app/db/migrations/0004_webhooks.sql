@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS webhook_subscription(
+    id          uuid        PRIMARY KEY,
+    url         text        NOT NULL,
+    event_types text[]      NOT NULL DEFAULT '{}',
+    secret      text        NOT NULL,
+    active      boolean     NOT NULL DEFAULT true,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    updated_at  timestamptz NOT NULL DEFAULT now()
+);
+
+/* status: pending -> delivered | dead (dead-letter, delivery parked after max attempts) */
+CREATE TABLE IF NOT EXISTS webhook_delivery(
+    id              bigserial   PRIMARY KEY,
+    subscription_id uuid        NOT NULL REFERENCES webhook_subscription(id) ON DELETE CASCADE,
+    event_id        bigint      NOT NULL,
+    event_type      text        NOT NULL,
+    payload         jsonb       NOT NULL,
+    status          text        NOT NULL DEFAULT 'pending',
+    attempts        int         NOT NULL DEFAULT 0,
+    next_attempt_at timestamptz NOT NULL DEFAULT now(),
+    last_error      text,
+    created_at      timestamptz NOT NULL DEFAULT now(),
+    delivered_at    timestamptz,
+    UNIQUE (subscription_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_delivery_pending_idx ON webhook_delivery(next_attempt_at) WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempt(
+    id              bigserial   PRIMARY KEY,
+    delivery_id     bigint      NOT NULL REFERENCES webhook_delivery(id) ON DELETE CASCADE,
+    response_status int,
+    error           text,
+    duration_ms     bigint      NOT NULL,
+    attempted_at    timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS webhook_delivery_attempt_delivery_idx ON webhook_delivery_attempt(delivery_id);
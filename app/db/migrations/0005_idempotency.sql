@@ -0,0 +1,14 @@
+/* responses of requests with Idempotency-Key, row is committed together with changes of request */
+CREATE TABLE IF NOT EXISTS idempotency_key(
+    principal    text        NOT NULL,
+    key          text        NOT NULL,
+    request_hash bytea       NOT NULL,
+    status       int         NOT NULL DEFAULT 0,
+    headers      jsonb       NOT NULL DEFAULT '{}',
+    body         bytea       NOT NULL DEFAULT '',
+    created_at   timestamptz NOT NULL DEFAULT now(),
+    expires_at   timestamptz NOT NULL,
+    PRIMARY KEY (principal, key)
+);
+
+CREATE INDEX IF NOT EXISTS idempotency_key_expires_idx ON idempotency_key(expires_at);
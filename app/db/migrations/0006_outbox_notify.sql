@@ -0,0 +1,12 @@
+/* change feed of every replica listens person_events and reloads outbox after commit, payload is the last inserted id */
+CREATE OR REPLACE FUNCTION notify_person_events() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('person_events', (SELECT max(id) FROM inserted)::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS outbox_notify ON outbox;
+CREATE TRIGGER outbox_notify AFTER INSERT ON outbox
+    REFERENCING NEW TABLE AS inserted
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_person_events();
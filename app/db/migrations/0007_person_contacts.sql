@@ -0,0 +1,30 @@
+/* contacts of person, email is stored normalized (lower case domain), phone in E.164 */
+CREATE TABLE IF NOT EXISTS person_email(
+    id          uuid        PRIMARY KEY,
+    person_id   uuid        NOT NULL REFERENCES person(id) ON DELETE CASCADE,
+    email       text        NOT NULL,
+    type        text        NOT NULL,
+    is_primary  boolean     NOT NULL DEFAULT false,
+    verified    boolean     NOT NULL DEFAULT false,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    updated_at  timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS person_email_unique_idx ON person_email(person_id, lower(email));
+/* person has at most one primary email and primary email belongs to one person */
+CREATE UNIQUE INDEX IF NOT EXISTS person_email_primary_idx ON person_email(person_id) WHERE is_primary;
+CREATE UNIQUE INDEX IF NOT EXISTS person_email_primary_unique_idx ON person_email(lower(email)) WHERE is_primary;
+
+CREATE TABLE IF NOT EXISTS person_phone(
+    id          uuid        PRIMARY KEY,
+    person_id   uuid        NOT NULL REFERENCES person(id) ON DELETE CASCADE,
+    phone       text        NOT NULL,
+    type        text        NOT NULL,
+    is_primary  boolean     NOT NULL DEFAULT false,
+    verified    boolean     NOT NULL DEFAULT false,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    updated_at  timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS person_phone_unique_idx ON person_phone(person_id, phone);
+CREATE UNIQUE INDEX IF NOT EXISTS person_phone_primary_idx ON person_phone(person_id) WHERE is_primary;
@@ -0,0 +1,20 @@
+/* country is ISO 3166-1 alpha-2 code, validity period is inclusive, open ends are NULL */
+CREATE TABLE IF NOT EXISTS person_address(
+    id          uuid        PRIMARY KEY,
+    person_id   uuid        NOT NULL REFERENCES person(id) ON DELETE CASCADE,
+    type        text        NOT NULL,
+    lines       text[]      NOT NULL,
+    city        text        NOT NULL,
+    region      text        NOT NULL DEFAULT '',
+    postal_code text        NOT NULL DEFAULT '',
+    country     char(2)     NOT NULL,
+    valid_from  date,
+    valid_to    date,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    updated_at  timestamptz NOT NULL DEFAULT now(),
+    CHECK (valid_from IS NULL OR valid_to IS NULL OR valid_from <= valid_to)
+);
+
+CREATE INDEX IF NOT EXISTS person_address_person_idx ON person_address(person_id);
+/* filter of person listing by country and city */
+CREATE INDEX IF NOT EXISTS person_address_location_idx ON person_address(country, lower(city));
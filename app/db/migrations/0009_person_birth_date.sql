@@ -0,0 +1,10 @@
+/* age of existing rows becomes approximate birth date, the latest date giving this age on migration day in zone of ages */
+ALTER TABLE person ADD COLUMN IF NOT EXISTS birth_date date;
+ALTER TABLE person ADD COLUMN IF NOT EXISTS birth_date_approximate boolean NOT NULL DEFAULT false;
+UPDATE person SET birth_date = ((now() AT TIME ZONE current_setting('app.age_time_zone'))::date - make_interval(years => age))::date, birth_date_approximate = true
+    WHERE birth_date IS NULL;
+ALTER TABLE person ALTER COLUMN birth_date SET NOT NULL;
+ALTER TABLE person DROP COLUMN IF EXISTS age;
+
+/* age filters and sorting by age are translated to ranges of birth date */
+CREATE INDEX IF NOT EXISTS person_birth_date_idx ON person(birth_date, id);
@@ -0,0 +1,15 @@
+/* registry of custom person attributes, values are kept in person.attributes keyed by name */
+CREATE TABLE IF NOT EXISTS attribute_definition(
+    name        text        PRIMARY KEY,
+    type        text        NOT NULL,
+    required    boolean     NOT NULL DEFAULT false,
+    pattern     text        NOT NULL DEFAULT '',
+    enum_values text[]      NOT NULL DEFAULT '{}',
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    updated_at  timestamptz NOT NULL DEFAULT now()
+);
+
+ALTER TABLE person ADD COLUMN IF NOT EXISTS attributes jsonb NOT NULL DEFAULT '{}';
+
+/* attribute filters of listing are containment queries */
+CREATE INDEX IF NOT EXISTS person_attributes_idx ON person USING gin (attributes jsonb_path_ops);
@@ -0,0 +1,33 @@
+/* groups form a tree, root groups have no parent; restrict or cascade of delete is decided by service */
+CREATE TABLE IF NOT EXISTS person_group(
+    id          uuid        PRIMARY KEY,
+    parent_id   uuid        REFERENCES person_group(id) ON DELETE CASCADE,
+    name        text        NOT NULL,
+    description text        NOT NULL DEFAULT '',
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    updated_at  timestamptz NOT NULL DEFAULT now()
+);
+
+/* names are unique among siblings */
+CREATE UNIQUE INDEX IF NOT EXISTS person_group_name_idx
+    ON person_group(COALESCE(parent_id, '00000000-0000-0000-0000-000000000000'), lower(name));
+CREATE INDEX IF NOT EXISTS person_group_parent_idx ON person_group(parent_id);
+
+CREATE TABLE IF NOT EXISTS person_group_member(
+    group_id   uuid        NOT NULL REFERENCES person_group(id) ON DELETE CASCADE,
+    person_id  uuid        NOT NULL REFERENCES person(id) ON DELETE CASCADE,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (group_id, person_id)
+);
+
+CREATE INDEX IF NOT EXISTS person_group_member_person_idx ON person_group_member(person_id);
+
+/* tags are free-form lower-case labels, tag exists while it has members */
+CREATE TABLE IF NOT EXISTS person_tag(
+    tag        text        NOT NULL,
+    person_id  uuid        NOT NULL REFERENCES person(id) ON DELETE CASCADE,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (tag, person_id)
+);
+
+CREATE INDEX IF NOT EXISTS person_tag_person_idx ON person_tag(person_id);
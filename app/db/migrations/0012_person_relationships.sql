@@ -0,0 +1,14 @@
+/* directed relationship, related person is <type> of person (manager of person); inverse types are not stored */
+CREATE TABLE IF NOT EXISTS person_relationship(
+    id         uuid        PRIMARY KEY,
+    person_id  uuid        NOT NULL REFERENCES person(id) ON DELETE CASCADE,
+    related_id uuid        NOT NULL REFERENCES person(id) ON DELETE CASCADE,
+    type       text        NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    CHECK (person_id <> related_id),
+    UNIQUE (person_id, related_id, type)
+);
+
+/* hierarchy is walked in both directions */
+CREATE INDEX IF NOT EXISTS person_relationship_person_idx ON person_relationship(person_id, type);
+CREATE INDEX IF NOT EXISTS person_relationship_related_idx ON person_relationship(related_id, type);
@@ -0,0 +1,10 @@
+/* avatar image of person, files are kept in blob store under avatars/<person_id>/<etag>/ */
+CREATE TABLE IF NOT EXISTS person_avatar(
+    person_id    uuid        PRIMARY KEY REFERENCES person(id) ON DELETE CASCADE,
+    content_type text        NOT NULL,
+    etag         text        NOT NULL,
+    width        integer     NOT NULL,
+    height       integer     NOT NULL,
+    sizes        integer[]   NOT NULL,
+    updated_at   timestamptz NOT NULL DEFAULT now()
+);
@@ -0,0 +1,35 @@
+/* document of person, content is kept in blob store under blob_key */
+CREATE TABLE IF NOT EXISTS person_attachment(
+    id           uuid        PRIMARY KEY,
+    person_id    uuid        NOT NULL REFERENCES person(id) ON DELETE CASCADE,
+    file_name    text        NOT NULL,
+    content_type text        NOT NULL,
+    size         bigint      NOT NULL,
+    sha256       text        NOT NULL,
+    blob_key     text        NOT NULL,
+    /* attachment is purged after this date */
+    retain_until date,
+    created_at   timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS person_attachment_person_idx ON person_attachment(person_id, created_at);
+CREATE INDEX IF NOT EXISTS person_attachment_retain_idx ON person_attachment(retain_until) WHERE retain_until IS NOT NULL;
+
+/* files of deleted rows, purge worker deletes them from blob store */
+CREATE TABLE IF NOT EXISTS blob_purge(
+    blob_key   text        PRIMARY KEY,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+/* every way of delete (api, retention, cascade of person delete) leaves key of file */
+CREATE OR REPLACE FUNCTION purge_attachment_blob() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO blob_purge(blob_key) SELECT blob_key FROM deleted ON CONFLICT DO NOTHING;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS person_attachment_purge ON person_attachment;
+CREATE TRIGGER person_attachment_purge AFTER DELETE ON person_attachment
+    REFERENCING OLD TABLE AS deleted
+    FOR EACH STATEMENT EXECUTE FUNCTION purge_attachment_blob();
@@ -0,0 +1,39 @@
+/* trigram similarity of names, pg_trgm is trusted extension since PostgreSQL 13 */
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+/* transliteration-aware key of name: Cyrillic is spelled in Latin and ambiguous Latin spellings are unified
+   (x = ks, w = v, j = y, final iy = y), so "Юрий Щукин", "Yuriy Shchukin" and "Jurij Schukin" get close keys;
+   upper case Cyrillic is translated explicitly as lower() depends on ctype of database */
+CREATE OR REPLACE FUNCTION person_name_key(first_name text, last_name text) RETURNS text AS $$
+    SELECT btrim(regexp_replace(regexp_replace(regexp_replace(
+        translate(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(
+            lower(translate(first_name || ' ' || last_name,
+                'АБВГДЕЁЖЗИЙКЛМНОПРСТУФХЦЧШЩЪЫЬЭЮЯ', 'абвгдеёжзийклмнопрстуфхцчшщъыьэюя')),
+            'щ', 'shch'), 'ж', 'zh'), 'х', 'kh'), 'ц', 'ts'), 'ч', 'ch'), 'ш', 'sh'), 'ю', 'yu'), 'я', 'ya'),
+            'x', 'ks'), 'ъ', ''), 'ь', ''),
+            'абвгдеёзийклмнопрстуфыэwj', 'abvgdeeziyklmnoprstufyevy'),
+        'iy\M', 'y', 'g'), '[^a-z ]+', '', 'g'), ' +', ' ', 'g'))
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE;
+
+ALTER TABLE person ADD COLUMN IF NOT EXISTS name_key text
+    GENERATED ALWAYS AS (person_name_key(first_name, last_name)) STORED;
+
+CREATE INDEX IF NOT EXISTS person_name_key_trgm_idx ON person USING gin (name_key gin_trgm_ops);
+
+/* shared contact points of candidates */
+CREATE INDEX IF NOT EXISTS person_email_lower_idx ON person_email(lower(email));
+CREATE INDEX IF NOT EXISTS person_phone_phone_idx ON person_phone(phone);
+
+/* history of merges, merged person is gone so rows have no foreign keys; merged_id redirects to survivor_id */
+CREATE TABLE IF NOT EXISTS person_merge(
+    id          uuid        PRIMARY KEY,
+    survivor_id uuid        NOT NULL,
+    merged_id   uuid        NOT NULL UNIQUE,
+    /* merged person as it was before merge */
+    merged      jsonb       NOT NULL,
+    /* source of every merged field: survivor, duplicate or both */
+    fields      jsonb       NOT NULL,
+    merged_at   timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS person_merge_survivor_idx ON person_merge(survivor_id, merged_at);
@@ -0,0 +1,97 @@
+/* organization owning persons, suspended tenant is not served; zero and empty settings keep defaults */
+CREATE TABLE IF NOT EXISTS tenant(
+    id            uuid        PRIMARY KEY,
+    name          text        NOT NULL UNIQUE,
+    status        text        NOT NULL DEFAULT 'active',
+    max_persons   int         NOT NULL DEFAULT 0,
+    max_age       int         NOT NULL DEFAULT 0,
+    login_pattern text        NOT NULL DEFAULT '',
+    require_login boolean     NOT NULL DEFAULT false,
+    created_at    timestamptz NOT NULL DEFAULT now(),
+    updated_at    timestamptz NOT NULL DEFAULT now()
+);
+
+/* rows written before tenancy belong to default tenant, it serves requests when tenancy is disabled */
+INSERT INTO tenant(id, name) VALUES ('00000000-0000-0000-0000-000000000001', 'default') ON CONFLICT DO NOTHING;
+
+/* tenant of connection, it is set while connection serves request of tenant and is null for background workers */
+CREATE OR REPLACE FUNCTION current_tenant() RETURNS uuid AS $$
+    SELECT NULLIF(current_setting('app.tenant_id', true), '')::uuid
+$$ LANGUAGE sql STABLE;
+
+/* connections serving tenant switch to this role, policies apply to it while owner of tables (workers) sees every tenant */
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'person_tenant') THEN
+        CREATE ROLE person_tenant NOLOGIN;
+    END IF;
+END $$;
+
+GRANT person_tenant TO CURRENT_USER;
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO person_tenant;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO person_tenant;
+ALTER DEFAULT PRIVILEGES GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO person_tenant;
+ALTER DEFAULT PRIVILEGES GRANT USAGE, SELECT ON SEQUENCES TO person_tenant;
+/* tenant reads its own settings, quota and status are changed by admin only */
+REVOKE INSERT, UPDATE, DELETE ON tenant FROM person_tenant;
+
+ALTER TABLE tenant ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON tenant;
+CREATE POLICY tenant_isolation ON tenant TO person_tenant USING (id = current_tenant());
+
+/* existing rows and rows written without tenant (workers, import tools) get default tenant, new ones get tenant of connection */
+DO $$
+DECLARE
+    relation text;
+BEGIN
+    FOREACH relation IN ARRAY ARRAY['person', 'person_email', 'person_phone', 'person_address', 'attribute_definition',
+        'person_group', 'person_group_member', 'person_tag', 'person_relationship', 'person_avatar', 'person_attachment',
+        'person_merge', 'outbox', 'webhook_subscription', 'webhook_delivery', 'webhook_delivery_attempt', 'idempotency_key']
+    LOOP
+        EXECUTE format('ALTER TABLE %I ADD COLUMN IF NOT EXISTS tenant_id uuid NOT NULL
+            DEFAULT ''00000000-0000-0000-0000-000000000001'' REFERENCES tenant(id)', relation);
+        EXECUTE format('ALTER TABLE %I ALTER COLUMN tenant_id
+            SET DEFAULT COALESCE(current_tenant(), ''00000000-0000-0000-0000-000000000001'')', relation);
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', relation);
+        EXECUTE format('DROP POLICY IF EXISTS tenant_isolation ON %I', relation);
+        EXECUTE format('CREATE POLICY tenant_isolation ON %I TO person_tenant
+            USING (tenant_id = current_tenant()) WITH CHECK (tenant_id = current_tenant())', relation);
+    END LOOP;
+END $$;
+
+/* foreign keys are checked without policies, so referenced rows must be visible to tenant as well */
+DO $$
+DECLARE
+    reference text[];
+BEGIN
+    FOREACH reference SLICE 1 IN ARRAY ARRAY[
+        ['person_email', 'person_id', 'person'], ['person_phone', 'person_id', 'person'],
+        ['person_address', 'person_id', 'person'], ['person_avatar', 'person_id', 'person'],
+        ['person_attachment', 'person_id', 'person'], ['person_tag', 'person_id', 'person'],
+        ['person_group_member', 'person_id', 'person'], ['person_group_member', 'group_id', 'person_group'],
+        ['person_relationship', 'person_id', 'person'], ['person_relationship', 'related_id', 'person']]
+    LOOP
+        EXECUTE format('DROP POLICY IF EXISTS %I ON %I', 'tenant_' || reference[2], reference[1]);
+        EXECUTE format('CREATE POLICY %I ON %I AS RESTRICTIVE TO person_tenant
+            USING (true) WITH CHECK (EXISTS (SELECT 1 FROM %I r WHERE r.id = %I))',
+            'tenant_' || reference[2], reference[1], reference[3], reference[2]);
+    END LOOP;
+END $$;
+
+/* unique values are unique within tenant */
+ALTER TABLE person DROP CONSTRAINT IF EXISTS person_login_key;
+CREATE UNIQUE INDEX IF NOT EXISTS person_login_idx ON person(tenant_id, login);
+CREATE INDEX IF NOT EXISTS person_tenant_idx ON person(tenant_id, id);
+
+DROP INDEX IF EXISTS person_email_primary_unique_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS person_email_primary_unique_idx ON person_email(tenant_id, lower(email)) WHERE is_primary;
+
+ALTER TABLE attribute_definition DROP CONSTRAINT IF EXISTS attribute_definition_pkey;
+ALTER TABLE attribute_definition ADD PRIMARY KEY (tenant_id, name);
+
+DROP INDEX IF EXISTS person_group_name_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS person_group_name_idx
+    ON person_group(tenant_id, COALESCE(parent_id, '00000000-0000-0000-0000-000000000000'), lower(name));
+
+ALTER TABLE idempotency_key DROP CONSTRAINT IF EXISTS idempotency_key_pkey;
+ALTER TABLE idempotency_key ADD PRIMARY KEY (tenant_id, principal, key);
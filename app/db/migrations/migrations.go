@@ -0,0 +1,87 @@
+package migrations
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// lockId key of advisory lock, guards migrations when several replicas start at once.
+const lockId = 7_340_112
+
+// Migrate applies embedded sql files in lexical order, every file is applied once in own transaction.
+// Zone of person ages is available to files as app.age_time_zone setting.
+func Migrate(ctx context.Context, pool *pgxpool.Pool, ageTimeZone string) error {
+	const op = "storage.migrations.Migrate"
+
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer conn.Release()
+
+	if _, err = conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, lockId); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		_, _ = conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, lockId)
+	}()
+
+	_, err = conn.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations(
+			version 	text 		PRIMARY KEY,
+			applied_at 	timestamptz NOT NULL DEFAULT now()
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	names, err := fs.Glob(files, "*.sql")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := apply(ctx, conn.Conn(), name, ageTimeZone); err != nil {
+			return fmt.Errorf("%s: %s: %w", op, name, err)
+		}
+	}
+
+	return nil
+}
+
+func apply(ctx context.Context, conn *pgx.Conn, name string, ageTimeZone string) error {
+	version := strings.TrimSuffix(name, ".sql")
+
+	var applied bool
+	err := conn.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM schema_migrations WHERE version = $1)`, version).Scan(&applied)
+	if err != nil || applied {
+		return err
+	}
+
+	content, err := files.ReadFile(name)
+	if err != nil {
+		return err
+	}
+
+	return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT set_config('app.age_time_zone', $1, true)`, ageTimeZone); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, string(content)); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `INSERT INTO schema_migrations(version) VALUES ($1)`, version)
+		return err
+	})
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"person-service/db/entity"
+)
+
+type AddressRepositoryImpl struct {
+	db DBTX
+}
+
+const addressColumns = `a.id, a.person_id, a.type, a.lines, a.city, a.region, a.postal_code, a.country,
+	a.valid_from, a.valid_to, a.created_at, a.updated_at`
+
+// SaveAddress save new address of person.
+func (s *AddressRepositoryImpl) SaveAddress(ctx context.Context, address entity.Address) (entity.Address, error) {
+	const op = "storage.postgres.Addresses.SaveAddress"
+
+	sqlStatement := `INSERT INTO person_address AS a(id, person_id, type, lines, city, region, postal_code, country, valid_from, valid_to)
+						VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+							RETURNING ` + addressColumns
+
+	saved, err := scanAddress(s.db.QueryRow(ctx, sqlStatement,
+		newId(&address.Id), address.PersonId, address.Type, address.Lines, address.City, address.Region,
+		address.PostalCode, address.Country, address.ValidFrom, address.ValidTo,
+	))
+	if err != nil {
+		return entity.Address{}, fmt.Errorf("error while save address: %s: %w", op, err)
+	}
+
+	return saved, nil
+}
+
+// UpdateAddress update address of person, returns pgx.ErrNoRows for unknown id.
+func (s *AddressRepositoryImpl) UpdateAddress(ctx context.Context, address entity.Address) (entity.Address, error) {
+	const op = "storage.postgres.Addresses.UpdateAddress"
+
+	sqlStatement := `UPDATE person_address a SET type = $3, lines = $4, city = $5, region = $6, postal_code = $7, country = $8,
+						valid_from = $9, valid_to = $10, updated_at = now()
+						WHERE a.id = $1 AND a.person_id = $2
+							RETURNING ` + addressColumns
+
+	updated, err := scanAddress(s.db.QueryRow(ctx, sqlStatement,
+		address.Id, address.PersonId, address.Type, address.Lines, address.City, address.Region,
+		address.PostalCode, address.Country, address.ValidFrom, address.ValidTo,
+	))
+	if err != nil {
+		return entity.Address{}, fmt.Errorf("error while update address: %s: %w", op, err)
+	}
+
+	return updated, nil
+}
+
+// DeleteAddress delete address of person, returns false for unknown id.
+func (s *AddressRepositoryImpl) DeleteAddress(ctx context.Context, personId uuid.UUID, id uuid.UUID) (bool, error) {
+	const op = "storage.postgres.Addresses.DeleteAddress"
+
+	tag, err := s.db.Exec(ctx, `DELETE FROM person_address WHERE id = $1 AND person_id = $2`, id, personId)
+	if err != nil {
+		return false, fmt.Errorf("error while delete address: %s: %w", op, err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// FindAddress find address of person by id.
+func (s *AddressRepositoryImpl) FindAddress(ctx context.Context, personId uuid.UUID, id uuid.UUID) (entity.Address, error) {
+	const op = "storage.postgres.Addresses.FindAddress"
+
+	address, err := scanAddress(s.db.QueryRow(ctx,
+		`SELECT `+addressColumns+` FROM person_address a WHERE a.id = $1 AND a.person_id = $2`, id, personId,
+	))
+	if err != nil {
+		return entity.Address{}, fmt.Errorf("error while find address: %s: %w", op, err)
+	}
+
+	return address, nil
+}
+
+// LoadAddresses load addresses of persons grouped by person id in order of creation.
+func (s *AddressRepositoryImpl) LoadAddresses(ctx context.Context, personIds []uuid.UUID) (map[uuid.UUID][]entity.Address, error) {
+	const op = "storage.postgres.Addresses.LoadAddresses"
+
+	sqlStatement := `SELECT ` + addressColumns + ` FROM person_address a
+					WHERE a.person_id = ANY($1)
+					ORDER BY a.person_id, a.created_at, a.id`
+
+	rows, err := s.db.Query(ctx, sqlStatement, personIds)
+	if err != nil {
+		return nil, fmt.Errorf("error while load addresses: %s: %w", op, err)
+	}
+	defer rows.Close()
+
+	addresses := make(map[uuid.UUID][]entity.Address)
+	for rows.Next() {
+		address, err := scanAddress(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error while scan address: %s: %w", op, err)
+		}
+		addresses[address.PersonId] = append(addresses[address.PersonId], address)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error while load addresses: %s: %w", op, err)
+	}
+
+	return addresses, nil
+}
+
+func scanAddress(row pgx.Row) (entity.Address, error) {
+	var a entity.Address
+	err := row.Scan(&a.Id, &a.PersonId, &a.Type, &a.Lines, &a.City, &a.Region, &a.PostalCode, &a.Country,
+		&a.ValidFrom, &a.ValidTo, &a.CreatedAt, &a.UpdatedAt)
+	return a, err
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"person-service/db/entity"
+	"time"
+)
+
+type AttachmentRepositoryImpl struct {
+	db DBTX
+}
+
+const attachmentColumns = `a.id, a.person_id, a.file_name, a.content_type, a.size, a.sha256, a.blob_key, a.retain_until, a.created_at`
+
+// SaveAttachment save metadata of attachment, content must be stored under blob key before.
+func (s *AttachmentRepositoryImpl) SaveAttachment(ctx context.Context, attachment entity.Attachment) (entity.Attachment, error) {
+	const op = "storage.postgres.Attachments.SaveAttachment"
+
+	sqlStatement := `INSERT INTO person_attachment AS a(id, person_id, file_name, content_type, size, sha256, blob_key, retain_until)
+						VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+							RETURNING ` + attachmentColumns
+
+	saved, err := scanAttachment(s.db.QueryRow(ctx, sqlStatement,
+		attachment.Id, attachment.PersonId, attachment.FileName, attachment.ContentType, attachment.Size,
+		attachment.Sha256, attachment.BlobKey, attachment.RetainUntil,
+	))
+	if err != nil {
+		return entity.Attachment{}, fmt.Errorf("error while save attachment: %s: %w", op, err)
+	}
+
+	return saved, nil
+}
+
+// UpdateRetention set or clear retention date of attachment, returns pgx.ErrNoRows for unknown id.
+func (s *AttachmentRepositoryImpl) UpdateRetention(ctx context.Context, personId uuid.UUID, id uuid.UUID, retainUntil *time.Time) (entity.Attachment, error) {
+	const op = "storage.postgres.Attachments.UpdateRetention"
+
+	sqlStatement := `UPDATE person_attachment a SET retain_until = $3
+						WHERE a.id = $1 AND a.person_id = $2
+							RETURNING ` + attachmentColumns
+
+	updated, err := scanAttachment(s.db.QueryRow(ctx, sqlStatement, id, personId, retainUntil))
+	if err != nil {
+		return entity.Attachment{}, fmt.Errorf("error while update retention of attachment: %s: %w", op, err)
+	}
+
+	return updated, nil
+}
+
+// DeleteAttachment delete attachment of person, its file is queued for purge. Returns false for unknown id.
+func (s *AttachmentRepositoryImpl) DeleteAttachment(ctx context.Context, personId uuid.UUID, id uuid.UUID) (bool, error) {
+	const op = "storage.postgres.Attachments.DeleteAttachment"
+
+	tag, err := s.db.Exec(ctx, `DELETE FROM person_attachment WHERE id = $1 AND person_id = $2`, id, personId)
+	if err != nil {
+		return false, fmt.Errorf("error while delete attachment: %s: %w", op, err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// FindAttachment find attachment of person by id.
+func (s *AttachmentRepositoryImpl) FindAttachment(ctx context.Context, personId uuid.UUID, id uuid.UUID) (entity.Attachment, error) {
+	const op = "storage.postgres.Attachments.FindAttachment"
+
+	attachment, err := scanAttachment(s.db.QueryRow(ctx,
+		`SELECT `+attachmentColumns+` FROM person_attachment a WHERE a.id = $1 AND a.person_id = $2`, id, personId,
+	))
+	if err != nil {
+		return entity.Attachment{}, fmt.Errorf("error while find attachment: %s: %w", op, err)
+	}
+
+	return attachment, nil
+}
+
+// LoadAttachments load attachments of person in order of upload.
+func (s *AttachmentRepositoryImpl) LoadAttachments(ctx context.Context, personId uuid.UUID) ([]entity.Attachment, error) {
+	const op = "storage.postgres.Attachments.LoadAttachments"
+
+	rows, err := s.db.Query(ctx,
+		`SELECT `+attachmentColumns+` FROM person_attachment a WHERE a.person_id = $1 ORDER BY a.created_at, a.id`, personId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error while load attachments: %s: %w", op, err)
+	}
+	defer rows.Close()
+
+	attachments := make([]entity.Attachment, 0)
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error while load attachments: %s: %w", op, err)
+		}
+		attachments = append(attachments, attachment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error while load attachments: %s: %w", op, err)
+	}
+
+	return attachments, nil
+}
+
+// DeleteExpired delete attachments retained until the day before today, their files are queued for purge.
+func (s *AttachmentRepositoryImpl) DeleteExpired(ctx context.Context, today time.Time) (int64, error) {
+	const op = "storage.postgres.Attachments.DeleteExpired"
+
+	tag, err := s.db.Exec(ctx, `DELETE FROM person_attachment WHERE retain_until < $1`, today)
+	if err != nil {
+		return 0, fmt.Errorf("error while delete expired attachments: %s: %w", op, err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// LockPurge lock up to limit keys of files queued for purge, keys locked by other replica are skipped.
+func (s *AttachmentRepositoryImpl) LockPurge(ctx context.Context, limit int) ([]string, error) {
+	const op = "storage.postgres.Attachments.LockPurge"
+
+	rows, err := s.db.Query(ctx, `SELECT blob_key FROM blob_purge ORDER BY created_at LIMIT $1 FOR UPDATE SKIP LOCKED`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error while lock purge queue: %s: %w", op, err)
+	}
+
+	keys, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("error while lock purge queue: %s: %w", op, err)
+	}
+
+	return keys, nil
+}
+
+// DeletePurge remove keys of purged files from queue.
+func (s *AttachmentRepositoryImpl) DeletePurge(ctx context.Context, keys []string) error {
+	const op = "storage.postgres.Attachments.DeletePurge"
+
+	if _, err := s.db.Exec(ctx, `DELETE FROM blob_purge WHERE blob_key = ANY($1)`, keys); err != nil {
+		return fmt.Errorf("error while delete from purge queue: %s: %w", op, err)
+	}
+	return nil
+}
+
+func scanAttachment(row pgx.Row) (entity.Attachment, error) {
+	var attachment entity.Attachment
+	err := row.Scan(&attachment.Id, &attachment.PersonId, &attachment.FileName, &attachment.ContentType, &attachment.Size,
+		&attachment.Sha256, &attachment.BlobKey, &attachment.RetainUntil, &attachment.CreatedAt)
+	return attachment, err
+}
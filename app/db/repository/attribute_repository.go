@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"person-service/db/entity"
+)
+
+type AttributeRepositoryImpl struct {
+	db DBTX
+}
+
+const definitionColumns = `d.name, d.type, d.required, d.pattern, d.enum_values, d.created_at, d.updated_at`
+
+// SaveDefinition save new attribute definition, existing name violates primary key.
+func (s *AttributeRepositoryImpl) SaveDefinition(ctx context.Context, definition entity.AttributeDefinition) (entity.AttributeDefinition, error) {
+	const op = "storage.postgres.Attributes.SaveDefinition"
+
+	sqlStatement := `INSERT INTO attribute_definition AS d(name, type, required, pattern, enum_values)
+						VALUES ($1, $2, $3, $4, $5)
+							RETURNING ` + definitionColumns
+
+	saved, err := scanDefinition(s.db.QueryRow(ctx, sqlStatement,
+		definition.Name, definition.Type, definition.Required, definition.Pattern, enumValues(definition),
+	))
+	if err != nil {
+		return entity.AttributeDefinition{}, fmt.Errorf("error while save attribute definition: %s: %w", op, err)
+	}
+
+	return saved, nil
+}
+
+// UpdateDefinition update existing attribute definition, returns pgx.ErrNoRows for unknown name.
+func (s *AttributeRepositoryImpl) UpdateDefinition(ctx context.Context, definition entity.AttributeDefinition) (entity.AttributeDefinition, error) {
+	const op = "storage.postgres.Attributes.UpdateDefinition"
+
+	sqlStatement := `UPDATE attribute_definition d SET type = $2, required = $3, pattern = $4, enum_values = $5, updated_at = now()
+						WHERE d.name = $1
+							RETURNING ` + definitionColumns
+
+	updated, err := scanDefinition(s.db.QueryRow(ctx, sqlStatement,
+		definition.Name, definition.Type, definition.Required, definition.Pattern, enumValues(definition),
+	))
+	if err != nil {
+		return entity.AttributeDefinition{}, fmt.Errorf("error while update attribute definition: %s: %w", op, err)
+	}
+
+	return updated, nil
+}
+
+// DeleteDefinition delete attribute definition together with its values, returns false for unknown name.
+func (s *AttributeRepositoryImpl) DeleteDefinition(ctx context.Context, name string) (bool, error) {
+	const op = "storage.postgres.Attributes.DeleteDefinition"
+
+	tag, err := s.db.Exec(ctx, `DELETE FROM attribute_definition WHERE name = $1`, name)
+	if err != nil {
+		return false, fmt.Errorf("error while delete attribute definition: %s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	_, err = s.db.Exec(ctx, `UPDATE person SET attributes = attributes - $1::text WHERE attributes ? $1::text`, name)
+	if err != nil {
+		return false, fmt.Errorf("error while delete attribute values: %s: %w", op, err)
+	}
+
+	return true, nil
+}
+
+// FindDefinition find attribute definition by name.
+func (s *AttributeRepositoryImpl) FindDefinition(ctx context.Context, name string) (entity.AttributeDefinition, error) {
+	const op = "storage.postgres.Attributes.FindDefinition"
+
+	definition, err := scanDefinition(s.db.QueryRow(ctx,
+		`SELECT `+definitionColumns+` FROM attribute_definition d WHERE d.name = $1`, name,
+	))
+	if err != nil {
+		return entity.AttributeDefinition{}, fmt.Errorf("error while find attribute definition: %s: %w", op, err)
+	}
+
+	return definition, nil
+}
+
+// LoadDefinitions load all attribute definitions ordered by name.
+func (s *AttributeRepositoryImpl) LoadDefinitions(ctx context.Context) ([]entity.AttributeDefinition, error) {
+	const op = "storage.postgres.Attributes.LoadDefinitions"
+
+	rows, err := s.db.Query(ctx, `SELECT `+definitionColumns+` FROM attribute_definition d ORDER BY d.name`)
+	if err != nil {
+		return nil, fmt.Errorf("error while load attribute definitions: %s: %w", op, err)
+	}
+	defer rows.Close()
+
+	definitions := make([]entity.AttributeDefinition, 0)
+	for rows.Next() {
+		definition, err := scanDefinition(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error while load attribute definitions: %s: %w", op, err)
+		}
+		definitions = append(definitions, definition)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error while load attribute definitions: %s: %w", op, err)
+	}
+
+	return definitions, nil
+}
+
+func scanDefinition(row pgx.Row) (entity.AttributeDefinition, error) {
+	var definition entity.AttributeDefinition
+	err := row.Scan(&definition.Name, &definition.Type, &definition.Required, &definition.Pattern,
+		&definition.EnumValues, &definition.CreatedAt, &definition.UpdatedAt)
+	return definition, err
+}
+
+func enumValues(definition entity.AttributeDefinition) []string {
+	if definition.EnumValues == nil {
+		return []string{}
+	}
+	return definition.EnumValues
+}
+
+// encodeAttributes JSON document of attribute values, absent values are stored as empty object.
+func encodeAttributes(attributes map[string]any) (string, error) {
+	if len(attributes) == 0 {
+		return "{}", nil
+	}
+	document, err := json.Marshal(attributes)
+	return string(document), err
+}
+
+// decodeAttributes read attribute values, numbers are integers as int attribute is the only numeric type.
+func decodeAttributes(document []byte) (map[string]any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+
+	var attributes map[string]any
+	if err := decoder.Decode(&attributes); err != nil {
+		return nil, err
+	}
+	for name, value := range attributes {
+		if number, ok := value.(json.Number); ok {
+			integer, err := number.Int64()
+			if err != nil {
+				return nil, fmt.Errorf("attribute %s: %w", name, err)
+			}
+			attributes[name] = integer
+		}
+	}
+	return attributes, nil
+}
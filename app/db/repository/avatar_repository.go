@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"person-service/db/entity"
+)
+
+type AvatarRepositoryImpl struct {
+	db DBTX
+}
+
+// avatarLockId first key of transaction advisory lock, second one is hash of person id.
+const avatarLockId = 7_340_115
+
+const avatarColumns = `a.person_id, a.content_type, a.etag, a.width, a.height, a.sizes, a.updated_at`
+
+// SaveAvatar save avatar of person, existing avatar is replaced.
+func (s *AvatarRepositoryImpl) SaveAvatar(ctx context.Context, avatar entity.Avatar) (entity.Avatar, error) {
+	const op = "storage.postgres.Avatars.SaveAvatar"
+
+	sqlStatement := `INSERT INTO person_avatar AS a(person_id, content_type, etag, width, height, sizes)
+						VALUES ($1, $2, $3, $4, $5, $6)
+						ON CONFLICT (person_id) DO UPDATE SET content_type = excluded.content_type, etag = excluded.etag,
+							width = excluded.width, height = excluded.height, sizes = excluded.sizes, updated_at = now()
+							RETURNING ` + avatarColumns
+
+	saved, err := scanAvatar(s.db.QueryRow(ctx, sqlStatement,
+		avatar.PersonId, avatar.ContentType, avatar.ETag, avatar.Width, avatar.Height, avatar.Sizes,
+	))
+	if err != nil {
+		return entity.Avatar{}, fmt.Errorf("error while save avatar: %s: %w", op, err)
+	}
+
+	return saved, nil
+}
+
+// DeleteAvatar delete avatar of person and return it, returns pgx.ErrNoRows when person has no avatar.
+func (s *AvatarRepositoryImpl) DeleteAvatar(ctx context.Context, personId uuid.UUID) (entity.Avatar, error) {
+	const op = "storage.postgres.Avatars.DeleteAvatar"
+
+	deleted, err := scanAvatar(s.db.QueryRow(ctx,
+		`DELETE FROM person_avatar a WHERE a.person_id = $1 RETURNING `+avatarColumns, personId,
+	))
+	if err != nil {
+		return entity.Avatar{}, fmt.Errorf("error while delete avatar: %s: %w", op, err)
+	}
+
+	return deleted, nil
+}
+
+// FindAvatar find avatar of person.
+func (s *AvatarRepositoryImpl) FindAvatar(ctx context.Context, personId uuid.UUID) (entity.Avatar, error) {
+	const op = "storage.postgres.Avatars.FindAvatar"
+
+	avatar, err := scanAvatar(s.db.QueryRow(ctx,
+		`SELECT `+avatarColumns+` FROM person_avatar a WHERE a.person_id = $1`, personId,
+	))
+	if err != nil {
+		return entity.Avatar{}, fmt.Errorf("error while find avatar: %s: %w", op, err)
+	}
+
+	return avatar, nil
+}
+
+// LockAvatar lock avatar of person until the end of transaction, so replaced files are known to the last writer.
+func (s *AvatarRepositoryImpl) LockAvatar(ctx context.Context, personId uuid.UUID) error {
+	const op = "storage.postgres.Avatars.LockAvatar"
+
+	if _, err := s.db.Exec(ctx, `SELECT pg_advisory_xact_lock($1, hashtext($2))`, avatarLockId, personId.String()); err != nil {
+		return fmt.Errorf("error while lock avatar: %s: %w", op, err)
+	}
+	return nil
+}
+
+func scanAvatar(row pgx.Row) (entity.Avatar, error) {
+	var avatar entity.Avatar
+	err := row.Scan(&avatar.PersonId, &avatar.ContentType, &avatar.ETag, &avatar.Width, &avatar.Height,
+		&avatar.Sizes, &avatar.UpdatedAt)
+	return avatar, err
+}
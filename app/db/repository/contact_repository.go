@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"person-service/db/entity"
+)
+
+type ContactRepositoryImpl struct {
+	db DBTX
+}
+
+/* table and value column of every contact kind, both tables have the same shape otherwise */
+var contactTables = map[entity.ContactKind]struct{ table, column string }{
+	entity.ContactEmail: {table: "person_email", column: "email"},
+	entity.ContactPhone: {table: "person_phone", column: "phone"},
+}
+
+// SaveContact save new contact of person.
+func (s *ContactRepositoryImpl) SaveContact(ctx context.Context, contact entity.Contact) (entity.Contact, error) {
+	const op = "storage.postgres.Contacts.SaveContact"
+
+	table, column, err := contactTable(contact.Kind)
+	if err != nil {
+		return entity.Contact{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	sqlStatement := fmt.Sprintf(`INSERT INTO %s(id, person_id, %s, type, is_primary, verified)
+						VALUES ($1, $2, $3, $4, $5, $6)
+							RETURNING %s`, table, column, contactColumns(column))
+
+	saved, err := scanContact(s.db.QueryRow(ctx, sqlStatement,
+		newId(&contact.Id), contact.PersonId, contact.Value, contact.Type, contact.Primary, contact.Verified,
+	), contact.Kind)
+	if err != nil {
+		return entity.Contact{}, fmt.Errorf("error while save contact: %s: %w", op, err)
+	}
+
+	return saved, nil
+}
+
+// UpdateContact update contact of person, returns pgx.ErrNoRows for unknown id.
+func (s *ContactRepositoryImpl) UpdateContact(ctx context.Context, contact entity.Contact) (entity.Contact, error) {
+	const op = "storage.postgres.Contacts.UpdateContact"
+
+	table, column, err := contactTable(contact.Kind)
+	if err != nil {
+		return entity.Contact{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	sqlStatement := fmt.Sprintf(`UPDATE %s SET %s = $3, type = $4, is_primary = $5, verified = $6, updated_at = now()
+						WHERE id = $1 AND person_id = $2
+							RETURNING %s`, table, column, contactColumns(column))
+
+	updated, err := scanContact(s.db.QueryRow(ctx, sqlStatement,
+		contact.Id, contact.PersonId, contact.Value, contact.Type, contact.Primary, contact.Verified,
+	), contact.Kind)
+	if err != nil {
+		return entity.Contact{}, fmt.Errorf("error while update contact: %s: %w", op, err)
+	}
+
+	return updated, nil
+}
+
+// DeleteContact delete contact of person, returns false for unknown id.
+func (s *ContactRepositoryImpl) DeleteContact(ctx context.Context, kind entity.ContactKind, personId uuid.UUID, id uuid.UUID) (bool, error) {
+	const op = "storage.postgres.Contacts.DeleteContact"
+
+	table, _, err := contactTable(kind)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	tag, err := s.db.Exec(ctx, fmt.Sprintf(`DELETE FROM %s WHERE id = $1 AND person_id = $2`, table), id, personId)
+	if err != nil {
+		return false, fmt.Errorf("error while delete contact: %s: %w", op, err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// FindContact find contact of person by id.
+func (s *ContactRepositoryImpl) FindContact(ctx context.Context, kind entity.ContactKind, personId uuid.UUID, id uuid.UUID) (entity.Contact, error) {
+	const op = "storage.postgres.Contacts.FindContact"
+
+	table, column, err := contactTable(kind)
+	if err != nil {
+		return entity.Contact{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	sqlStatement := fmt.Sprintf(`SELECT %s FROM %s WHERE id = $1 AND person_id = $2`, contactColumns(column), table)
+	contact, err := scanContact(s.db.QueryRow(ctx, sqlStatement, id, personId), kind)
+	if err != nil {
+		return entity.Contact{}, fmt.Errorf("error while find contact: %s: %w", op, err)
+	}
+
+	return contact, nil
+}
+
+// LoadContacts load contacts of persons grouped by person id, primary contact goes first.
+func (s *ContactRepositoryImpl) LoadContacts(ctx context.Context, kind entity.ContactKind, personIds []uuid.UUID) (map[uuid.UUID][]entity.Contact, error) {
+	const op = "storage.postgres.Contacts.LoadContacts"
+
+	table, column, err := contactTable(kind)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	sqlStatement := fmt.Sprintf(`SELECT %s FROM %s
+					WHERE person_id = ANY($1)
+					ORDER BY person_id, is_primary DESC, created_at, id`, contactColumns(column), table)
+
+	rows, err := s.db.Query(ctx, sqlStatement, personIds)
+	if err != nil {
+		return nil, fmt.Errorf("error while load contacts: %s: %w", op, err)
+	}
+	defer rows.Close()
+
+	contacts := make(map[uuid.UUID][]entity.Contact)
+	for rows.Next() {
+		contact, err := scanContact(rows, kind)
+		if err != nil {
+			return nil, fmt.Errorf("error while scan contact: %s: %w", op, err)
+		}
+		contacts[contact.PersonId] = append(contacts[contact.PersonId], contact)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error while load contacts: %s: %w", op, err)
+	}
+
+	return contacts, nil
+}
+
+// ClearPrimary reset primary flag of person contacts of kind except one with exceptId.
+func (s *ContactRepositoryImpl) ClearPrimary(ctx context.Context, kind entity.ContactKind, personId uuid.UUID, exceptId uuid.UUID) error {
+	const op = "storage.postgres.Contacts.ClearPrimary"
+
+	table, _, err := contactTable(kind)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	sqlStatement := fmt.Sprintf(`UPDATE %s SET is_primary = false, updated_at = now()
+						WHERE person_id = $1 AND is_primary AND id <> $2`, table)
+	if _, err = s.db.Exec(ctx, sqlStatement, personId, exceptId); err != nil {
+		return fmt.Errorf("error while clear primary contact: %s: %w", op, err)
+	}
+
+	return nil
+}
+
+func contactTable(kind entity.ContactKind) (string, string, error) {
+	t, ok := contactTables[kind]
+	if !ok {
+		return "", "", fmt.Errorf("unknown contact kind: %s", kind)
+	}
+	return t.table, t.column, nil
+}
+
+func contactColumns(column string) string {
+	return `id, person_id, ` + column + `, type, is_primary, verified, created_at, updated_at`
+}
+
+func scanContact(row pgx.Row, kind entity.ContactKind) (entity.Contact, error) {
+	c := entity.Contact{Kind: kind}
+	err := row.Scan(&c.Id, &c.PersonId, &c.Value, &c.Type, &c.Primary, &c.Verified, &c.CreatedAt, &c.UpdatedAt)
+	return c, err
+}
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"person-service/db/entity"
+)
+
+type GroupRepositoryImpl struct {
+	db DBTX
+}
+
+// treeLockId key of transaction advisory lock, moves of groups are serialized so concurrent moves do not make cycle.
+const treeLockId = 7_340_113
+
+const groupColumns = `g.id, g.parent_id, g.name, g.description, g.created_at, g.updated_at`
+
+/* ids of group $n and all its descendants, format argument is number of parameter */
+const groupSubtree = `WITH RECURSIVE subtree(id) AS (
+		SELECT $%[1]d::uuid
+		UNION SELECT c.id FROM person_group c JOIN subtree s ON c.parent_id = s.id
+	) SELECT id FROM subtree`
+
+// SaveGroup save new group, name existing among siblings violates unique index.
+func (s *GroupRepositoryImpl) SaveGroup(ctx context.Context, group entity.Group) (entity.Group, error) {
+	const op = "storage.postgres.Groups.SaveGroup"
+
+	sqlStatement := `INSERT INTO person_group AS g(id, parent_id, name, description)
+						VALUES ($1, $2, $3, $4)
+							RETURNING ` + groupColumns
+
+	saved, err := scanGroup(s.db.QueryRow(ctx, sqlStatement, newId(&group.Id), group.ParentId, group.Name, group.Description))
+	if err != nil {
+		return entity.Group{}, fmt.Errorf("error while save group: %s: %w", op, err)
+	}
+
+	return saved, nil
+}
+
+// UpdateGroup update name, description and parent of group, returns pgx.ErrNoRows for unknown id.
+func (s *GroupRepositoryImpl) UpdateGroup(ctx context.Context, group entity.Group) (entity.Group, error) {
+	const op = "storage.postgres.Groups.UpdateGroup"
+
+	sqlStatement := `UPDATE person_group g SET parent_id = $2, name = $3, description = $4, updated_at = now()
+						WHERE g.id = $1
+							RETURNING ` + groupColumns
+
+	updated, err := scanGroup(s.db.QueryRow(ctx, sqlStatement, group.Id, group.ParentId, group.Name, group.Description))
+	if err != nil {
+		return entity.Group{}, fmt.Errorf("error while update group: %s: %w", op, err)
+	}
+
+	return updated, nil
+}
+
+// DeleteGroup delete group, its descendants and memberships of all of them, returns false for unknown id.
+func (s *GroupRepositoryImpl) DeleteGroup(ctx context.Context, id uuid.UUID) (bool, error) {
+	const op = "storage.postgres.Groups.DeleteGroup"
+
+	tag, err := s.db.Exec(ctx, `DELETE FROM person_group WHERE id = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("error while delete group: %s: %w", op, err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// FindGroup find group by id.
+func (s *GroupRepositoryImpl) FindGroup(ctx context.Context, id uuid.UUID) (entity.Group, error) {
+	const op = "storage.postgres.Groups.FindGroup"
+
+	group, err := scanGroup(s.db.QueryRow(ctx, `SELECT `+groupColumns+` FROM person_group g WHERE g.id = $1`, id))
+	if err != nil {
+		return entity.Group{}, fmt.Errorf("error while find group: %s: %w", op, err)
+	}
+
+	return group, nil
+}
+
+// FindGroupForUpdate find group by id and lock it until the end of transaction,
+// new children and members of locked group wait for the lock.
+func (s *GroupRepositoryImpl) FindGroupForUpdate(ctx context.Context, id uuid.UUID) (entity.Group, error) {
+	const op = "storage.postgres.Groups.FindGroupForUpdate"
+
+	group, err := scanGroup(s.db.QueryRow(ctx, `SELECT `+groupColumns+` FROM person_group g WHERE g.id = $1 FOR UPDATE`, id))
+	if err != nil {
+		return entity.Group{}, fmt.Errorf("error while find group: %s: %w", op, err)
+	}
+
+	return group, nil
+}
+
+// LoadGroups load all groups ordered by name.
+func (s *GroupRepositoryImpl) LoadGroups(ctx context.Context) ([]entity.Group, error) {
+	const op = "storage.postgres.Groups.LoadGroups"
+
+	rows, err := s.db.Query(ctx, `SELECT `+groupColumns+` FROM person_group g ORDER BY lower(g.name), g.id`)
+	if err != nil {
+		return nil, fmt.Errorf("error while load groups: %s: %w", op, err)
+	}
+	defer rows.Close()
+
+	groups := make([]entity.Group, 0)
+	for rows.Next() {
+		group, err := scanGroup(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error while load groups: %s: %w", op, err)
+		}
+		groups = append(groups, group)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error while load groups: %s: %w", op, err)
+	}
+
+	return groups, nil
+}
+
+// InSubtree reports whether group id is ancestor itself or one of its descendants.
+func (s *GroupRepositoryImpl) InSubtree(ctx context.Context, ancestor uuid.UUID, id uuid.UUID) (bool, error) {
+	const op = "storage.postgres.Groups.InSubtree"
+
+	var found bool
+	err := s.db.QueryRow(ctx, `SELECT $2::uuid IN (`+fmt.Sprintf(groupSubtree, 1)+`)`, ancestor, id).Scan(&found)
+	if err != nil {
+		return false, fmt.Errorf("error while check group subtree: %s: %w", op, err)
+	}
+
+	return found, nil
+}
+
+// LockTree lock hierarchy of groups until the end of transaction.
+func (s *GroupRepositoryImpl) LockTree(ctx context.Context) error {
+	const op = "storage.postgres.Groups.LockTree"
+
+	if _, err := s.db.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, treeLockId); err != nil {
+		return fmt.Errorf("error while lock groups: %s: %w", op, err)
+	}
+	return nil
+}
+
+// IsEmpty reports whether group has neither children nor members.
+func (s *GroupRepositoryImpl) IsEmpty(ctx context.Context, id uuid.UUID) (bool, error) {
+	const op = "storage.postgres.Groups.IsEmpty"
+
+	var empty bool
+	err := s.db.QueryRow(ctx, `SELECT NOT EXISTS (SELECT 1 FROM person_group WHERE parent_id = $1)
+									AND NOT EXISTS (SELECT 1 FROM person_group_member WHERE group_id = $1)`, id).Scan(&empty)
+	if err != nil {
+		return false, fmt.Errorf("error while check group members: %s: %w", op, err)
+	}
+
+	return empty, nil
+}
+
+// AddMembers add persons to group, returns count of persons which were not members yet.
+func (s *GroupRepositoryImpl) AddMembers(ctx context.Context, id uuid.UUID, personIds []uuid.UUID) (int64, error) {
+	const op = "storage.postgres.Groups.AddMembers"
+
+	tag, err := s.db.Exec(ctx, `INSERT INTO person_group_member(group_id, person_id)
+									SELECT $1, unnest($2::uuid[])
+										ON CONFLICT DO NOTHING`, id, personIds)
+	if err != nil {
+		return 0, fmt.Errorf("error while add group members: %s: %w", op, err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// RemoveMembers remove persons from group, returns count of removed members.
+func (s *GroupRepositoryImpl) RemoveMembers(ctx context.Context, id uuid.UUID, personIds []uuid.UUID) (int64, error) {
+	const op = "storage.postgres.Groups.RemoveMembers"
+
+	tag, err := s.db.Exec(ctx, `DELETE FROM person_group_member WHERE group_id = $1 AND person_id = ANY($2)`, id, personIds)
+	if err != nil {
+		return 0, fmt.Errorf("error while remove group members: %s: %w", op, err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+func scanGroup(row pgx.Row) (entity.Group, error) {
+	var group entity.Group
+	err := row.Scan(&group.Id, &group.ParentId, &group.Name, &group.Description, &group.CreatedAt, &group.UpdatedAt)
+	return group, err
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"person-service/db/entity"
+	"time"
+)
+
+type IdempotencyRepositoryImpl struct {
+	db DBTX
+}
+
+// Acquire insert key or take over expired one, returns false when valid key already exists.
+// Insert waits for uncommitted row of the same key, so concurrent requests with the same key are serialized.
+// Keys are scoped to tenant of connection.
+func (s *IdempotencyRepositoryImpl) Acquire(ctx context.Context, principal string, key string, requestHash []byte, ttl time.Duration) (bool, error) {
+	const op = "storage.postgres.Idempotency.Acquire"
+
+	sqlStatement := `INSERT INTO idempotency_key AS i(principal, key, request_hash, expires_at)
+						VALUES ($1, $2, $3, now() + make_interval(secs => $4))
+						ON CONFLICT (tenant_id, principal, key) DO UPDATE
+							SET request_hash = excluded.request_hash, status = 0, headers = '{}', body = '',
+								created_at = now(), expires_at = excluded.expires_at
+							WHERE i.expires_at <= now()`
+
+	tag, err := s.db.Exec(ctx, sqlStatement, principal, key, requestHash, ttl.Seconds())
+	if err != nil {
+		return false, fmt.Errorf("error while acquire idempotency key: %s: %w", op, err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// Find find stored key, returns pgx.ErrNoRows for unknown key.
+func (s *IdempotencyRepositoryImpl) Find(ctx context.Context, principal string, key string) (entity.IdempotencyKey, error) {
+	const op = "storage.postgres.Idempotency.Find"
+
+	var stored entity.IdempotencyKey
+	sqlStatement := `SELECT i.principal, i.key, i.request_hash, i.status, i.headers, i.body, i.created_at, i.expires_at
+						FROM idempotency_key i WHERE i.principal = $1 AND i.key = $2`
+	err := s.db.QueryRow(ctx, sqlStatement, principal, key).Scan(
+		&stored.Principal, &stored.Key, &stored.RequestHash, &stored.Status, &stored.Headers, &stored.Body, &stored.CreatedAt, &stored.ExpiresAt,
+	)
+	if err != nil {
+		return entity.IdempotencyKey{}, fmt.Errorf("error while find idempotency key: %s: %w", op, err)
+	}
+
+	return stored, nil
+}
+
+// Complete store response of acquired key.
+func (s *IdempotencyRepositoryImpl) Complete(ctx context.Context, response entity.IdempotencyKey) error {
+	const op = "storage.postgres.Idempotency.Complete"
+
+	sqlStatement := `UPDATE idempotency_key SET status = $3, headers = $4, body = $5 WHERE principal = $1 AND key = $2`
+	_, err := s.db.Exec(ctx, sqlStatement, response.Principal, response.Key, response.Status, response.Headers, response.Body)
+	if err != nil {
+		return fmt.Errorf("error while complete idempotency key: %s: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteExpired delete keys after their TTL.
+func (s *IdempotencyRepositoryImpl) DeleteExpired(ctx context.Context) (int64, error) {
+	const op = "storage.postgres.Idempotency.DeleteExpired"
+
+	tag, err := s.db.Exec(ctx, `DELETE FROM idempotency_key WHERE expires_at <= now()`)
+	if err != nil {
+		return 0, fmt.Errorf("error while delete expired idempotency keys: %s: %w", op, err)
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"person-service/db/entity"
+	"time"
+)
+
+type MergeRepositoryImpl struct {
+	db DBTX
+	/* time zone of today, ages of candidates are computed for it */
+	location *time.Location
+}
+
+const mergeColumns = `m.id, m.survivor_id, m.merged_id, m.merged, m.fields, m.merged_at`
+
+/* persons with name similar to name of $1 or sharing email or phone with it, up to $2 ordered by similarity */
+const duplicatesQuery = `WITH target AS (
+		SELECT p.id, p.name_key, person_name_key(p.first_name, '') AS first_key, person_name_key(p.last_name, '') AS last_key
+			FROM person p WHERE p.id = $1
+	), emails AS (
+		SELECT e.person_id FROM person_email e JOIN person_email t ON lower(e.email) = lower(t.email)
+			WHERE t.person_id = $1 AND e.person_id <> $1
+	), phones AS (
+		SELECT f.person_id FROM person_phone f JOIN person_phone t ON f.phone = t.phone
+			WHERE t.person_id = $1 AND f.person_id <> $1
+	), candidates AS (
+		SELECT p.id FROM person p JOIN target t ON p.id <> t.id WHERE p.name_key % t.name_key
+		UNION SELECT person_id FROM emails
+		UNION SELECT person_id FROM phones
+	), scored AS (
+		/* trigrams do not depend on order of words, name is swapped when first name is closer to last name and back */
+		SELECT c.id, similarity(p.name_key, t.name_key)::float8 AS name_similarity,
+			similarity(person_name_key(p.first_name, ''), t.last_key) + similarity(person_name_key(p.last_name, ''), t.first_key) >
+				similarity(person_name_key(p.first_name, ''), t.first_key) + similarity(person_name_key(p.last_name, ''), t.last_key) AS swapped,
+			c.id IN (SELECT person_id FROM emails) AS shared_email, c.id IN (SELECT person_id FROM phones) AS shared_phone
+		FROM candidates c JOIN person p ON p.id = c.id CROSS JOIN target t
+	)
+	SELECT ` + personSelect + `, s.name_similarity, s.swapped, s.shared_email, s.shared_phone
+		FROM scored s JOIN person p ON p.id = s.id
+		ORDER BY s.name_similarity + CASE WHEN s.shared_email OR s.shared_phone THEN 1 ELSE 0 END DESC, p.id
+		LIMIT $2`
+
+/* records of person $1 are moved to person $2, conflicting ones are dropped and left for cascade of person delete */
+var moveStatements = []string{
+	`DELETE FROM person_email d USING person_email s
+		WHERE d.person_id = $1 AND s.person_id = $2 AND lower(s.email) = lower(d.email)`,
+	/* survivor keeps its primary email */
+	`UPDATE person_email d SET person_id = $2, updated_at = now(),
+		is_primary = d.is_primary AND NOT EXISTS (SELECT 1 FROM person_email s WHERE s.person_id = $2 AND s.is_primary)
+		WHERE d.person_id = $1`,
+	`DELETE FROM person_phone d USING person_phone s WHERE d.person_id = $1 AND s.person_id = $2 AND s.phone = d.phone`,
+	`UPDATE person_phone d SET person_id = $2, updated_at = now(),
+		is_primary = d.is_primary AND NOT EXISTS (SELECT 1 FROM person_phone s WHERE s.person_id = $2 AND s.is_primary)
+		WHERE d.person_id = $1`,
+	`UPDATE person_address SET person_id = $2, updated_at = now() WHERE person_id = $1`,
+	`UPDATE person_attachment SET person_id = $2 WHERE person_id = $1`,
+	`INSERT INTO person_group_member(group_id, person_id, created_at)
+		SELECT group_id, $2, created_at FROM person_group_member WHERE person_id = $1 ON CONFLICT DO NOTHING`,
+	`INSERT INTO person_tag(tag, person_id, created_at)
+		SELECT tag, $2, created_at FROM person_tag WHERE person_id = $1 ON CONFLICT DO NOTHING`,
+}
+
+// moveRelationships copy relationships of person $1 to person $2, symmetric types $3 are stored in canonical direction;
+// relationships between both persons are dropped as well as ones of single types $4 which survivor already has.
+const moveRelationships = `INSERT INTO person_relationship(id, person_id, related_id, type, created_at)
+	SELECT gen_random_uuid(),
+		CASE WHEN r.type = ANY($3) THEN least(m.person_id, m.related_id) ELSE m.person_id END,
+		CASE WHEN r.type = ANY($3) THEN greatest(m.person_id, m.related_id) ELSE m.related_id END,
+		r.type, r.created_at
+	FROM person_relationship r,
+		LATERAL (SELECT CASE WHEN r.person_id = $1 THEN $2 ELSE r.person_id END AS person_id,
+						CASE WHEN r.related_id = $1 THEN $2 ELSE r.related_id END AS related_id) m
+	WHERE (r.person_id = $1 OR r.related_id = $1) AND m.person_id <> m.related_id
+		AND NOT (r.person_id = $1 AND r.type = ANY($4)
+			AND EXISTS (SELECT 1 FROM person_relationship s WHERE s.person_id = $2 AND s.type = r.type))
+	ON CONFLICT DO NOTHING`
+
+// FindDuplicates find up to limit candidates which may duplicate person.
+func (s *MergeRepositoryImpl) FindDuplicates(ctx context.Context, personId uuid.UUID, limit int) ([]entity.DuplicateCandidate, error) {
+	const op = "storage.postgres.Merges.FindDuplicates"
+
+	rows, err := s.db.Query(ctx, duplicatesQuery, personId, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error while find duplicates: %s: %w", op, err)
+	}
+	defer rows.Close()
+
+	today := entity.DateOf(time.Now(), s.location)
+	candidates := make([]entity.DuplicateCandidate, 0)
+	for rows.Next() {
+		var candidate entity.DuplicateCandidate
+		candidate.Person, err = scanPerson(rows, today,
+			&candidate.NameSimilarity, &candidate.Swapped, &candidate.SharedEmail, &candidate.SharedPhone)
+		if err != nil {
+			return nil, fmt.Errorf("error while find duplicates: %s: %w", op, err)
+		}
+		candidates = append(candidates, candidate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error while find duplicates: %s: %w", op, err)
+	}
+
+	return candidates, nil
+}
+
+// MoveRecords move contacts, addresses, attachments, memberships and relationships of person to survivor,
+// records which conflict with ones of survivor stay with person and are deleted with it.
+func (s *MergeRepositoryImpl) MoveRecords(ctx context.Context, personId uuid.UUID, survivorId uuid.UUID, symmetricTypes []string, singleTypes []string) error {
+	const op = "storage.postgres.Merges.MoveRecords"
+
+	for _, sqlStatement := range moveStatements {
+		if _, err := s.db.Exec(ctx, sqlStatement, personId, survivorId); err != nil {
+			return fmt.Errorf("error while move records: %s: %w", op, err)
+		}
+	}
+	if _, err := s.db.Exec(ctx, moveRelationships, personId, survivorId, symmetricTypes, singleTypes); err != nil {
+		return fmt.Errorf("error while move relationships: %s: %w", op, err)
+	}
+
+	return nil
+}
+
+// SaveMerge save merge into history, earlier merges into merged person redirect to survivor from now on.
+func (s *MergeRepositoryImpl) SaveMerge(ctx context.Context, merge entity.PersonMerge) (entity.PersonMerge, error) {
+	const op = "storage.postgres.Merges.SaveMerge"
+
+	if _, err := s.db.Exec(ctx, `UPDATE person_merge SET survivor_id = $2 WHERE survivor_id = $1`,
+		merge.MergedId, merge.SurvivorId); err != nil {
+		return entity.PersonMerge{}, fmt.Errorf("error while save merge: %s: %w", op, err)
+	}
+
+	sqlStatement := `INSERT INTO person_merge AS m(id, survivor_id, merged_id, merged, fields)
+						VALUES ($1, $2, $3, $4, $5)
+							RETURNING ` + mergeColumns
+
+	saved, err := scanMerge(s.db.QueryRow(ctx, sqlStatement,
+		newId(&merge.Id), merge.SurvivorId, merge.MergedId, merge.Merged, merge.Fields,
+	))
+	if err != nil {
+		return entity.PersonMerge{}, fmt.Errorf("error while save merge: %s: %w", op, err)
+	}
+
+	return saved, nil
+}
+
+// FindSurvivor find id of person which merged person was merged into, returns pgx.ErrNoRows for not merged id.
+func (s *MergeRepositoryImpl) FindSurvivor(ctx context.Context, mergedId uuid.UUID) (uuid.UUID, error) {
+	const op = "storage.postgres.Merges.FindSurvivor"
+
+	var survivorId uuid.UUID
+	err := s.db.QueryRow(ctx, `SELECT survivor_id FROM person_merge WHERE merged_id = $1`, mergedId).Scan(&survivorId)
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("error while find survivor: %s: %w", op, err)
+	}
+
+	return survivorId, nil
+}
+
+// LoadMerges load merges into person in order of merge.
+func (s *MergeRepositoryImpl) LoadMerges(ctx context.Context, survivorId uuid.UUID) ([]entity.PersonMerge, error) {
+	const op = "storage.postgres.Merges.LoadMerges"
+
+	rows, err := s.db.Query(ctx,
+		`SELECT `+mergeColumns+` FROM person_merge m WHERE m.survivor_id = $1 ORDER BY m.merged_at, m.id`, survivorId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error while load merges: %s: %w", op, err)
+	}
+	defer rows.Close()
+
+	merges := make([]entity.PersonMerge, 0)
+	for rows.Next() {
+		merge, err := scanMerge(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error while load merges: %s: %w", op, err)
+		}
+		merges = append(merges, merge)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error while load merges: %s: %w", op, err)
+	}
+
+	return merges, nil
+}
+
+func scanMerge(row pgx.Row) (entity.PersonMerge, error) {
+	var merge entity.PersonMerge
+	err := row.Scan(&merge.Id, &merge.SurvivorId, &merge.MergedId, &merge.Merged, &merge.Fields, &merge.MergedAt)
+	return merge, err
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+)
+
+// PersonEventsChannel is notified by trigger of outbox table when transaction with events is committed.
+const PersonEventsChannel = "person_events"
+
+// Listen subscribes dedicated connection to channel and calls fn once LISTEN is executed and then on every
+// notification, so changes committed before subscription are not missed. Returns when ctx is done or connection fails.
+func (m *TxManager) Listen(ctx context.Context, channel string, fn func()) error {
+	const op = "storage.postgres.Listen"
+
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	/* listening connection never returns to pool, otherwise notifications would reach other statements */
+	listener := conn.Hijack()
+	defer func() {
+		_ = listener.Close(context.Background())
+	}()
+
+	if _, err = listener.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return fmt.Errorf("error while listen channel: %s: %w", op, err)
+	}
+
+	fn()
+	for {
+		if _, err = listener.WaitForNotification(ctx); err != nil {
+			return fmt.Errorf("error while wait for notification: %s: %w", op, err)
+		}
+		fn()
+	}
+}
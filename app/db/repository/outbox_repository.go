@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"person-service/db/entity"
+	"time"
+)
+
+type OutboxRepositoryImpl struct {
+	db DBTX
+}
+
+// Append write new event to outbox, must be called in transaction of changed entity.
+func (s *OutboxRepositoryImpl) Append(ctx context.Context, event entity.OutboxEvent) (int64, error) {
+	const op = "storage.postgres.Outbox.Append"
+
+	var id int64
+	sqlStatement := `INSERT INTO outbox(aggregate_id, event_type, payload) VALUES ($1, $2, $3) RETURNING id`
+	err := s.db.QueryRow(ctx, sqlStatement, event.AggregateId, event.EventType, event.Payload).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("error while append event: %s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// AppendAll write events to outbox with COPY, must be called in transaction of changed entities.
+// Row level security does not allow COPY, so events of ctx bound to tenant are inserted from arrays in order of events.
+func (s *OutboxRepositoryImpl) AppendAll(ctx context.Context, events []entity.OutboxEvent) error {
+	const op = "storage.postgres.Outbox.AppendAll"
+
+	if _, bound := TenantOf(ctx); !bound {
+		_, err := s.db.CopyFrom(
+			ctx,
+			pgx.Identifier{"outbox"},
+			[]string{"aggregate_id", "event_type", "payload"},
+			pgx.CopyFromSlice(len(events), func(i int) ([]any, error) {
+				return []any{events[i].AggregateId, events[i].EventType, events[i].Payload}, nil
+			}),
+		)
+		if err != nil {
+			return fmt.Errorf("error while append events: %s: %w", op, err)
+		}
+		return nil
+	}
+
+	aggregateIds := make([]uuid.UUID, len(events))
+	eventTypes := make([]string, len(events))
+	payloads := make([]string, len(events))
+	for index, event := range events {
+		aggregateIds[index] = event.AggregateId
+		eventTypes[index] = event.EventType
+		payloads[index] = string(event.Payload)
+	}
+
+	sqlStatement := `INSERT INTO outbox(aggregate_id, event_type, payload)
+						SELECT u.aggregate_id, u.event_type, u.payload::jsonb
+							FROM unnest($1::uuid[], $2::text[], $3::text[]) WITH ORDINALITY AS u(aggregate_id, event_type, payload, position)
+								ORDER BY u.position`
+
+	if _, err := s.db.Exec(ctx, sqlStatement, aggregateIds, eventTypes, payloads); err != nil {
+		return fmt.Errorf("error while append events: %s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ClaimPending lease batch of events ready for publishing by moving their next attempt after lease, ordered by id.
+// Event is skipped while older event of the same aggregate is not published, so order per aggregate is kept,
+// event of crashed relay is published again after lease.
+func (s *OutboxRepositoryImpl) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]entity.OutboxEvent, error) {
+	const op = "storage.postgres.Outbox.ClaimPending"
+
+	sqlStatement := `WITH due AS (
+						SELECT o.id FROM outbox o
+						WHERE o.published_at IS NULL AND o.next_attempt_at <= now()
+							AND NOT EXISTS (
+								SELECT 1 FROM outbox e
+								WHERE e.aggregate_id = o.aggregate_id AND e.published_at IS NULL AND e.id < o.id
+							)
+						ORDER BY o.id
+						LIMIT $1
+						FOR UPDATE SKIP LOCKED
+					), claimed AS (
+						UPDATE outbox o SET next_attempt_at = now() + $2::interval
+							FROM due
+						WHERE o.id = due.id
+						RETURNING o.id, o.tenant_id, o.aggregate_id, o.event_type, o.payload, o.created_at, o.attempts
+					)
+					SELECT id, tenant_id, aggregate_id, event_type, payload, created_at, attempts FROM claimed ORDER BY id`
+
+	rows, err := s.db.Query(ctx, sqlStatement, limit, lease)
+	if err != nil {
+		return nil, fmt.Errorf("error while fetch events: %s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var events []entity.OutboxEvent
+	for rows.Next() {
+		var event entity.OutboxEvent
+		err := rows.Scan(&event.Id, &event.TenantId, &event.AggregateId, &event.EventType, &event.Payload, &event.CreatedAt, &event.Attempts)
+		if err != nil {
+			return nil, fmt.Errorf("error while scan event: %s: %w", op, err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error while fetch events: %s: %w", op, err)
+	}
+
+	return events, nil
+}
+
+// MarkPublished mark event as delivered.
+func (s *OutboxRepositoryImpl) MarkPublished(ctx context.Context, id int64) error {
+	const op = "storage.postgres.Outbox.MarkPublished"
+
+	_, err := s.db.Exec(ctx, `UPDATE outbox SET published_at = now(), last_error = NULL WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("error while mark event published: %s: %w", op, err)
+	}
+
+	return nil
+}
+
+// MarkFailed save publishing error and postpone next attempt.
+func (s *OutboxRepositoryImpl) MarkFailed(ctx context.Context, id int64, reason string, retryAfter time.Duration) error {
+	const op = "storage.postgres.Outbox.MarkFailed"
+
+	sqlStatement := `UPDATE outbox SET attempts = attempts + 1, last_error = $2, next_attempt_at = now() + $3::interval
+					WHERE id = $1`
+	_, err := s.db.Exec(ctx, sqlStatement, id, reason, retryAfter)
+	if err != nil {
+		return fmt.Errorf("error while mark event failed: %s: %w", op, err)
+	}
+
+	return nil
+}
+
+// FetchAfter load events with id greater than afterId ordered by id, published or not.
+// Optional aggregateId limits events to single aggregate.
+func (s *OutboxRepositoryImpl) FetchAfter(ctx context.Context, afterId int64, aggregateId *uuid.UUID, limit int) ([]entity.OutboxEvent, error) {
+	const op = "storage.postgres.Outbox.FetchAfter"
+
+	sqlStatement := `SELECT id, tenant_id, aggregate_id, event_type, payload, created_at, attempts FROM outbox
+					WHERE id > $1 AND ($2::uuid IS NULL OR aggregate_id = $2)
+					ORDER BY id
+					LIMIT $3`
+
+	rows, err := s.db.Query(ctx, sqlStatement, afterId, aggregateId, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error while fetch events: %s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var events []entity.OutboxEvent
+	for rows.Next() {
+		var event entity.OutboxEvent
+		err := rows.Scan(&event.Id, &event.TenantId, &event.AggregateId, &event.EventType, &event.Payload, &event.CreatedAt, &event.Attempts)
+		if err != nil {
+			return nil, fmt.Errorf("error while scan event: %s: %w", op, err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error while fetch events: %s: %w", op, err)
+	}
+
+	return events, nil
+}
+
+// LastId id of the latest event, zero for empty outbox.
+func (s *OutboxRepositoryImpl) LastId(ctx context.Context) (int64, error) {
+	const op = "storage.postgres.Outbox.LastId"
+
+	var id int64
+	if err := s.db.QueryRow(ctx, `SELECT coalesce(max(id), 0) FROM outbox`).Scan(&id); err != nil {
+		return 0, fmt.Errorf("error while load last event id: %s: %w", op, err)
+	}
+
+	return id, nil
+}
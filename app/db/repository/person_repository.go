@@ -1,27 +1,168 @@
 package repository
 
 import (
-	"database/sql"
+	"context"
 	"fmt"
 	"github.com/google/uuid"
-	_ "github.com/lib/pq"
-	"log"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"person-service/config"
 	"person-service/db/entity"
+	"person-service/db/migrations"
 	"person-service/utils"
 	"strconv"
+	"strings"
 	"time"
 )
 
+// PersonFilter filters of person listing, empty value disables filter.
+type PersonFilter struct {
+	Login string
+	/* case-insensitive prefixes of names */
+	FirstName string
+	LastName  string
+	/* ages are translated to ranges of birth date for today */
+	MinAge *int
+	MaxAge *int
+	/* person has address in country (ISO 3166-1 alpha-2) and city (case-insensitive), both must match the same address */
+	Country string
+	City    string
+	/* person has all attribute values, values are normalized by attribute definitions */
+	Attributes map[string]any
+	/* person has all tags, tags are lower-case */
+	Tags []string
+	/* person is member of group or of one of its descendants, only direct members with DirectMembers */
+	Group         *uuid.UUID
+	DirectMembers bool
+}
+
+// PersonSortField column persons are sorted by, id is always used as tie-breaker.
+type PersonSortField string
+
+const (
+	SortById        PersonSortField = "id"
+	SortByFirstName PersonSortField = "first_name"
+	SortByLastName  PersonSortField = "last_name"
+	/* sorted by birth date in reverse direction */
+	SortByAge       PersonSortField = "age"
+	SortByCreatedAt PersonSortField = "created_at"
+)
+
+type PersonSort struct {
+	Field PersonSortField
+	Desc  bool
+}
+
+// PersonCursor position of keyset pagination, Value is value of sort column of the last returned person.
+type PersonCursor struct {
+	Value any
+	Id    uuid.UUID
+}
+
+// CursorOf cursor pointing after person for the sort.
+func (s PersonSort) CursorOf(person entity.Person) PersonCursor {
+	cursor := PersonCursor{Id: *person.Id}
+	switch s.Field {
+	case SortByFirstName:
+		cursor.Value = person.FirstName
+	case SortByLastName:
+		cursor.Value = person.LastName
+	case SortByAge:
+		cursor.Value = *person.BirthDate
+	case SortByCreatedAt:
+		cursor.Value = person.CreatedAt
+	}
+	return cursor
+}
+
+// column sort column and direction, age is sorted by birth date in reverse direction.
+func (s PersonSort) column() (string, bool) {
+	if s.Field == SortByAge {
+		return "birth_date", !s.Desc
+	}
+	return string(s.Field), s.Desc
+}
+
+// conditions SQL conditions of filter joined with AND, args are appended to passed ones.
+// Ages are compared as birth dates so index on birth_date is used.
+func (f PersonFilter) conditions(args []any, today time.Time) (string, []any) {
+	conditions := []string{"TRUE"}
+	add := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if f.Login != "" {
+		add("p.login = $%d", f.Login)
+	}
+	if f.FirstName != "" {
+		add("p.first_name ILIKE ($%d || '%%')", escapeLike(f.FirstName))
+	}
+	if f.LastName != "" {
+		add("p.last_name ILIKE ($%d || '%%')", escapeLike(f.LastName))
+	}
+	if f.MinAge != nil {
+		add("p.birth_date <= $%d", entity.BirthDateAt(*f.MinAge, today))
+	}
+	if f.MaxAge != nil {
+		/* older than MaxAge means at least MaxAge + 1 years */
+		add("p.birth_date > $%d", entity.BirthDateAt(*f.MaxAge+1, today))
+	}
+	if f.Country != "" || f.City != "" {
+		address := []string{"a.person_id = p.id"}
+		if f.Country != "" {
+			args = append(args, f.Country)
+			address = append(address, fmt.Sprintf("a.country = $%d", len(args)))
+		}
+		if f.City != "" {
+			args = append(args, f.City)
+			address = append(address, fmt.Sprintf("lower(a.city) = lower($%d)", len(args)))
+		}
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM person_address a WHERE "+strings.Join(address, " AND ")+")")
+	}
+	if len(f.Attributes) > 0 {
+		/* containment is served by gin index */
+		document, _ := encodeAttributes(f.Attributes)
+		add("p.attributes @> $%d::jsonb", document)
+	}
+	for _, tag := range f.Tags {
+		add("EXISTS (SELECT 1 FROM person_tag t WHERE t.person_id = p.id AND t.tag = $%d)", tag)
+	}
+	if f.Group != nil && f.DirectMembers {
+		add("EXISTS (SELECT 1 FROM person_group_member m WHERE m.person_id = p.id AND m.group_id = $%d)", *f.Group)
+	} else if f.Group != nil {
+		add("EXISTS (SELECT 1 FROM person_group_member m WHERE m.person_id = p.id AND m.group_id IN ("+groupSubtree+"))", *f.Group)
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
 type PersonRepositoryImpl struct {
-	db *sql.DB
+	pool *pgxpool.Pool
+	/* pool or active transaction, all statements are executed through it */
+	db DBTX
+	/* time zone of today, ages are computed for it */
+	location *time.Location
 }
 
-const TimeFormat = "2006-01-02 15:04:05.000000000"
+var personColumns = []string{"id", "first_name", "last_name", "birth_date", "birth_date_approximate", "login", "attributes"}
 
-func New(datasource config.Datasource) (*PersonRepositoryImpl, error) {
+/* selected columns of person, they are read by scanPerson */
+const personSelect = `p.id, p.first_name, p.last_name, p.birth_date, p.birth_date_approximate, COALESCE(p.login, ''),
+	p.attributes, p.created_at, p.updated_at`
+
+func New(ctx context.Context, datasource config.Datasource, person config.Person) (*PersonRepositoryImpl, error) {
 	const op = "storage.postgres.New"
 
+	location, err := time.LoadLocation(person.AgeTimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
 	connection := fmt.Sprintf(
 		"host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
 		datasource.Host,
@@ -31,38 +172,82 @@ func New(datasource config.Datasource) (*PersonRepositoryImpl, error) {
 		datasource.DbName,
 	)
 
-	db, err := sql.Open("postgres", connection)
+	poolConfig, err := pgxpool.ParseConfig(connection)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	stmt, err := db.Prepare(`
-		CREATE TABLE IF NOT EXISTS person(
-	    	id 			uuid 		PRIMARY KEY,
-	    	first_name 	text 		NOT NULL,
-	    	last_name 	text 		NOT NULL,
-	    	age 		int			NOT NULL,
-	    	last_update timestamp 	NOT NULL 
-		);
-	`)
+	/* cache prepared statements per connection, pgx prepares them on first use */
+	poolConfig.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
+	poolConfig.ConnConfig.StatementCacheCapacity = datasource.StatementCacheCapacity
+	if datasource.MaxConns > 0 {
+		poolConfig.MaxConns = datasource.MaxConns
+	}
+	/* connection is switched to tenant of request before it is used, see WithTenant */
+	binding := &tenantBinding{}
+	poolConfig.BeforeAcquire = binding.beforeAcquire
+	poolConfig.BeforeClose = binding.beforeClose
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	_, err = stmt.Exec()
-	if err != nil {
+	if err = migrations.Migrate(ctx, pool, location.String()); err != nil {
+		pool.Close()
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return &PersonRepositoryImpl{db: db}, nil
+	return &PersonRepositoryImpl{pool: pool, db: pool, location: location}, nil
+}
+
+// Close releases all connections of the pool.
+func (s *PersonRepositoryImpl) Close() {
+	s.pool.Close()
+}
+
+// withDB returns copy of repository bound to transaction or pool.
+func (s *PersonRepositoryImpl) withDB(db DBTX) *PersonRepositoryImpl {
+	return &PersonRepositoryImpl{pool: s.pool, db: db, location: s.location}
+}
+
+// Today current date in configured time zone as midnight UTC.
+func (s *PersonRepositoryImpl) Today() time.Time {
+	return entity.DateOf(time.Now(), s.location)
+}
+
+// scanPerson read row of personSelect columns followed by extra destinations, age is computed for today.
+func scanPerson(row pgx.Row, today time.Time, extra ...any) (entity.Person, error) {
+	var person entity.Person
+	var attributes []byte
+	destinations := append([]any{&person.Id, &person.FirstName, &person.LastName, &person.BirthDate,
+		&person.BirthDateApproximate, &person.Login, &attributes, &person.CreatedAt, &person.UpdatedAt}, extra...)
+	if err := row.Scan(destinations...); err != nil {
+		return entity.Person{}, err
+	}
+	person.Age = entity.AgeAt(*person.BirthDate, today)
+
+	var err error
+	if person.Attributes, err = decodeAttributes(attributes); err != nil {
+		return entity.Person{}, err
+	}
+	return person, nil
+}
+
+// birthDate birth date of written person, person without it gets approximate one derived from age.
+func birthDate(person entity.Person, today time.Time) (time.Time, bool) {
+	if person.BirthDate != nil {
+		return *person.BirthDate, person.BirthDateApproximate
+	}
+	return entity.BirthDateAt(person.Age, today), true
 }
 
 // DeletePerson delete person with selected id.
-func (s *PersonRepositoryImpl) DeletePerson(id uuid.UUID) (string, error) {
+func (s *PersonRepositoryImpl) DeletePerson(ctx context.Context, id uuid.UUID) (string, error) {
 	const op = "storage.postgres.DeletePerson"
 
 	sqlStatement := `DELETE FROM person WHERE id = $1`
-	_, err := s.db.Exec(sqlStatement, id.String())
+	_, err := s.db.Exec(ctx, sqlStatement, id)
 	if err != nil {
 		return "", fmt.Errorf("error while delete person: %s: %w", op, err)
 	}
@@ -71,14 +256,25 @@ func (s *PersonRepositoryImpl) DeletePerson(id uuid.UUID) (string, error) {
 }
 
 // FindPersonById find person by id.
-func (s *PersonRepositoryImpl) FindPersonById(id *uuid.UUID) (entity.Person, error) {
+func (s *PersonRepositoryImpl) FindPersonById(ctx context.Context, id *uuid.UUID) (entity.Person, error) {
 	const op = "storage.postgres.FindPersonById"
 
-	var person entity.Person
+	sqlStatement := `SELECT ` + personSelect + ` FROM person p WHERE p.id = $1`
+	person, err := scanPerson(s.db.QueryRow(ctx, sqlStatement, id), s.Today())
+
+	if err != nil {
+		return entity.Person{}, fmt.Errorf("error while find person: %s: %w", op, err)
+	} else {
+		return person, nil
+	}
+}
+
+// FindPersonByIdForUpdate find person by id and lock row until end of transaction.
+func (s *PersonRepositoryImpl) FindPersonByIdForUpdate(ctx context.Context, id *uuid.UUID) (entity.Person, error) {
+	const op = "storage.postgres.FindPersonByIdForUpdate"
 
-	sqlStatement := `SELECT * FROM person p WHERE p.id = $1`
-	err := s.db.QueryRow(sqlStatement, id.String()).
-		Scan(&person.Id, &person.FirstName, &person.LastName, &person.Age, &person.Timestamp, &person.Login)
+	sqlStatement := `SELECT ` + personSelect + ` FROM person p WHERE p.id = $1 FOR UPDATE`
+	person, err := scanPerson(s.db.QueryRow(ctx, sqlStatement, id), s.Today())
 
 	if err != nil {
 		return entity.Person{}, fmt.Errorf("error while find person: %s: %w", op, err)
@@ -88,14 +284,11 @@ func (s *PersonRepositoryImpl) FindPersonById(id *uuid.UUID) (entity.Person, err
 }
 
 // FindPersonByLogin find person by login.
-func (s *PersonRepositoryImpl) FindPersonByLogin(login string) (entity.Person, error) {
+func (s *PersonRepositoryImpl) FindPersonByLogin(ctx context.Context, login string) (entity.Person, error) {
 	const op = "storage.postgres.FindPersonByLogin"
 
-	var person entity.Person
-
-	sqlStatement := `SELECT * FROM person p WHERE p.login = $1`
-	err := s.db.QueryRow(sqlStatement, login).
-		Scan(&person.Id, &person.FirstName, &person.LastName, &person.Age, &person.Timestamp, &person.Login)
+	sqlStatement := `SELECT ` + personSelect + ` FROM person p WHERE p.login = $1`
+	person, err := scanPerson(s.db.QueryRow(ctx, sqlStatement, login), s.Today())
 
 	if err != nil {
 		return entity.Person{}, fmt.Errorf("error while find person: %s: %w", op, err)
@@ -105,21 +298,26 @@ func (s *PersonRepositoryImpl) FindPersonByLogin(login string) (entity.Person, e
 }
 
 // UpdatePerson method update existing person in database or creates new if id of argument is null.
-func (s *PersonRepositoryImpl) UpdatePerson(person entity.Person) (entity.Person, error) {
+func (s *PersonRepositoryImpl) UpdatePerson(ctx context.Context, person entity.Person) (entity.Person, error) {
 	const op = "storage.postgres.UpdatePerson"
 
-	if person.Id == nil {
-		return s.SavePerson(person)
+	if person.Id == nil || utils.IsNullableUUID(person.Id) {
+		return s.SavePerson(ctx, person)
 	}
 
-	var updatedPerson entity.Person
-	sqlStatement := `UPDATE person p SET first_name = $1, last_name = $2, age=$3, last_update = $4 
-              WHERE id = $5 
-              RETURNING p.id, p.first_name, p.last_name, p.age, p.last_update`
-	timestamp := time.Now().Format(TimeFormat)
+	today := s.Today()
+	birthDate, approximate := birthDate(person, today)
+	attributes, err := encodeAttributes(person.Attributes)
+	if err != nil {
+		return entity.Person{}, fmt.Errorf("error while update existing person: %s: %w", op, err)
+	}
+	sqlStatement := `UPDATE person p SET first_name = $1, last_name = $2, birth_date = $3, birth_date_approximate = $4,
+              login = NULLIF($6, ''), attributes = $7::jsonb, updated_at = now()
+              WHERE id = $5
+              RETURNING ` + personSelect
 
-	err := s.db.QueryRow(sqlStatement, person.FirstName, person.LastName, person.Age, timestamp, person.Id).
-		Scan(&updatedPerson.Id, &updatedPerson.FirstName, &updatedPerson.LastName, &updatedPerson.Age, &updatedPerson.Timestamp)
+	updatedPerson, err := scanPerson(s.db.QueryRow(ctx, sqlStatement,
+		person.FirstName, person.LastName, birthDate, approximate, person.Id, person.Login, attributes), today)
 
 	if err != nil {
 		return entity.Person{}, fmt.Errorf("error while update existing person: %s: %w", op, err)
@@ -128,25 +326,97 @@ func (s *PersonRepositoryImpl) UpdatePerson(person entity.Person) (entity.Person
 	}
 }
 
-// SavePerson save new person to database or updated existing row.
-func (s *PersonRepositoryImpl) SavePerson(p entity.Person) (entity.Person, error) {
-	const op = "storage.postgres.SavePerson"
-	var person entity.Person
+// TouchPerson set updated_at of person to now, used when sub-resource of person is changed.
+func (s *PersonRepositoryImpl) TouchPerson(ctx context.Context, id uuid.UUID) (entity.Person, error) {
+	const op = "storage.postgres.TouchPerson"
+
+	sqlStatement := `UPDATE person p SET updated_at = now()
+              WHERE id = $1
+              RETURNING ` + personSelect
+
+	person, err := scanPerson(s.db.QueryRow(ctx, sqlStatement, id), s.Today())
+
+	if err != nil {
+		return entity.Person{}, fmt.Errorf("error while touch person: %s: %w", op, err)
+	}
+	return person, nil
+}
+
+// PatchPerson update only columns which differ between original and patched person,
+// returns original person and false when nothing was changed.
+func (s *PersonRepositoryImpl) PatchPerson(ctx context.Context, original entity.Person, patched entity.Person) (entity.Person, bool, error) {
+	const op = "storage.postgres.PatchPerson"
+
+	var columns []string
+	var args []any
+	/* assignment holds %s placeholder for positional argument */
+	set := func(assignment string, value any) {
+		args = append(args, value)
+		columns = append(columns, fmt.Sprintf(assignment, "$"+strconv.Itoa(len(args))))
+	}
+
+	if original.FirstName != patched.FirstName {
+		set("first_name = %s", patched.FirstName)
+	}
+	if original.LastName != patched.LastName {
+		set("last_name = %s", patched.LastName)
+	}
+	today := s.Today()
+	birthDate, approximate := birthDate(patched, today)
+	if !original.BirthDate.Equal(birthDate) {
+		set("birth_date = %s", birthDate)
+	}
+	if original.BirthDateApproximate != approximate {
+		set("birth_date_approximate = %s", approximate)
+	}
+	if original.Login != patched.Login {
+		set("login = NULLIF(%s, '')", patched.Login)
+	}
+	originalAttributes, err := encodeAttributes(original.Attributes)
+	if err != nil {
+		return entity.Person{}, false, fmt.Errorf("error while patch person: %s: %w", op, err)
+	}
+	/* json.Marshal sorts keys, so equal values give equal documents */
+	if patchedAttributes, err := encodeAttributes(patched.Attributes); err != nil {
+		return entity.Person{}, false, fmt.Errorf("error while patch person: %s: %w", op, err)
+	} else if patchedAttributes != originalAttributes {
+		set("attributes = %s::jsonb", patchedAttributes)
+	}
+
+	if len(columns) == 0 {
+		return original, false, nil
+	}
+
+	args = append(args, original.Id)
+	sqlStatement := fmt.Sprintf(`UPDATE person p SET %s, updated_at = now()
+              WHERE id = $%d
+              RETURNING %s`,
+		strings.Join(columns, ", "), len(args), personSelect)
 
-	var id string
-	sqlStatement := `INSERT INTO person(id, first_name, last_name, age, last_update) 
-						VALUES ($1, $2, $3, $4, $5) 
-							RETURNING id, first_name, last_name, age, last_update`
-	timestamp := time.Now().Format(TimeFormat)
+	person, err := scanPerson(s.db.QueryRow(ctx, sqlStatement, args...), today)
 
-	if utils.IsNullableUUID(p.Id) {
-		id = uuid.New().String()
+	if err != nil {
+		return entity.Person{}, false, fmt.Errorf("error while patch person: %s: %w", op, err)
 	} else {
-		id = p.Id.String()
+		return person, true, nil
 	}
+}
 
-	err := s.db.QueryRow(sqlStatement, id, p.FirstName, p.LastName, p.Age, timestamp).
-		Scan(&person.Id, &person.FirstName, &person.LastName, &person.Age, &person.Timestamp)
+// SavePerson save new person to database or updated existing row.
+func (s *PersonRepositoryImpl) SavePerson(ctx context.Context, p entity.Person) (entity.Person, error) {
+	const op = "storage.postgres.SavePerson"
+	today := s.Today()
+	birthDate, approximate := birthDate(p, today)
+	attributes, err := encodeAttributes(p.Attributes)
+	if err != nil {
+		return entity.Person{}, fmt.Errorf("error while save new person: %s: %w", op, err)
+	}
+	sqlStatement := `INSERT INTO person AS p (id, first_name, last_name, birth_date, birth_date_approximate, login, attributes)
+						VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7::jsonb)
+							RETURNING ` + personSelect
+
+	person, err := scanPerson(s.db.QueryRow(ctx, sqlStatement,
+		newId(p.Id), p.FirstName, p.LastName, birthDate, approximate, p.Login, attributes), today)
 
 	if err != nil {
 		return entity.Person{}, fmt.Errorf("error while save new person: %s: %w", op, err)
@@ -155,13 +425,258 @@ func (s *PersonRepositoryImpl) SavePerson(p entity.Person) (entity.Person, error
 	}
 }
 
-// LoadPersons load first 50 persons from database.
-func (s *PersonRepositoryImpl) LoadPersons(page *string) ([]entity.Person, error) {
+// SavePersons bulk insert persons with COPY protocol, returns count of inserted rows.
+// Row level security does not allow COPY, so persons of ctx bound to tenant are inserted from arrays.
+func (s *PersonRepositoryImpl) SavePersons(ctx context.Context, persons []entity.Person) (int64, error) {
+	const op = "storage.postgres.SavePersons"
+
+	if _, bound := TenantOf(ctx); bound {
+		return s.insertPersons(ctx, persons)
+	}
+
+	today := s.Today()
+	count, err := s.db.CopyFrom(
+		ctx,
+		pgx.Identifier{"person"},
+		personColumns,
+		pgx.CopyFromSlice(len(persons), func(i int) ([]any, error) {
+			p := persons[i]
+			var login *string
+			if p.Login != "" {
+				login = &p.Login
+			}
+			birthDate, approximate := birthDate(p, today)
+			attributes := p.Attributes
+			if attributes == nil {
+				attributes = map[string]any{}
+			}
+			return []any{newId(p.Id), p.FirstName, p.LastName, birthDate, approximate, login, attributes}, nil
+		}),
+	)
+
+	if err != nil {
+		return 0, fmt.Errorf("error while copy persons: %s: %w", op, err)
+	}
+
+	return count, nil
+}
+
+// insertPersons bulk insert persons from arrays with one statement, returns count of inserted rows.
+func (s *PersonRepositoryImpl) insertPersons(ctx context.Context, persons []entity.Person) (int64, error) {
+	const op = "storage.postgres.InsertPersons"
+
+	arrays, err := personArrays(persons, s.Today())
+	if err != nil {
+		return 0, fmt.Errorf("error while save persons: %s: %w", op, err)
+	}
+
+	sqlStatement := `INSERT INTO person(id, first_name, last_name, birth_date, birth_date_approximate, login, attributes)
+						SELECT u.id, u.first_name, u.last_name, u.birth_date, u.birth_date_approximate, NULLIF(u.login, ''), u.attributes::jsonb
+							FROM unnest($1::uuid[], $2::text[], $3::text[], $4::date[], $5::bool[], $6::text[], $7::text[])
+								AS u(id, first_name, last_name, birth_date, birth_date_approximate, login, attributes)`
+
+	tag, err := s.db.Exec(ctx, sqlStatement, arrays...)
+	if err != nil {
+		return 0, fmt.Errorf("error while save persons: %s: %w", op, err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// FindPersonsByIds find persons by ids, order of result is not defined.
+func (s *PersonRepositoryImpl) FindPersonsByIds(ctx context.Context, ids []uuid.UUID) ([]entity.Person, error) {
+	const op = "storage.postgres.FindPersonsByIds"
+
+	sqlStatement := `SELECT ` + personSelect + ` FROM person p WHERE p.id = ANY($1)`
+	persons, err := s.queryPersons(ctx, sqlStatement, ids)
+	if err != nil {
+		return nil, fmt.Errorf("error while find persons: %s: %w", op, err)
+	}
+
+	return persons, nil
+}
+
+// FindPersonsByLogins find persons by logins, order of result is not defined.
+func (s *PersonRepositoryImpl) FindPersonsByLogins(ctx context.Context, logins []string) ([]entity.Person, error) {
+	const op = "storage.postgres.FindPersonsByLogins"
+
+	sqlStatement := `SELECT ` + personSelect + ` FROM person p WHERE p.login = ANY($1)`
+	persons, err := s.queryPersons(ctx, sqlStatement, logins)
+	if err != nil {
+		return nil, fmt.Errorf("error while find persons: %s: %w", op, err)
+	}
+
+	return persons, nil
+}
+
+// UpsertPersons insert persons or update existing rows with the same id in one statement,
+// second value holds ids of inserted rows. Order of result is not defined.
+func (s *PersonRepositoryImpl) UpsertPersons(ctx context.Context, persons []entity.Person) ([]entity.Person, map[uuid.UUID]bool, error) {
+	const op = "storage.postgres.UpsertPersons"
+
+	today := s.Today()
+	arrays, err := personArrays(persons, today)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error while upsert persons: %s: %w", op, err)
+	}
+
+	/* stored birth date is kept when approximate one gives the same age */
+	sqlStatement := `INSERT INTO person AS p (id, first_name, last_name, birth_date, birth_date_approximate, login, attributes)
+						SELECT u.id, u.first_name, u.last_name, u.birth_date, u.birth_date_approximate, NULLIF(u.login, ''), u.attributes::jsonb
+							FROM unnest($1::uuid[], $2::text[], $3::text[], $4::date[], $5::bool[], $6::text[], $7::text[])
+								AS u(id, first_name, last_name, birth_date, birth_date_approximate, login, attributes)
+						ON CONFLICT (id) DO UPDATE SET first_name = excluded.first_name, last_name = excluded.last_name,
+							birth_date = CASE WHEN ` + keepBirthDate + ` THEN p.birth_date ELSE excluded.birth_date END,
+							birth_date_approximate = CASE WHEN ` + keepBirthDate + ` THEN p.birth_date_approximate ELSE excluded.birth_date_approximate END,
+							login = excluded.login, attributes = excluded.attributes, updated_at = now()
+						RETURNING ` + personSelect + `, p.xmax = 0`
+
+	rows, err := s.db.Query(ctx, sqlStatement, append(arrays, today)...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error while upsert persons: %s: %w", op, err)
+	}
+	defer rows.Close()
+
+	upserted := make([]entity.Person, 0, len(persons))
+	inserted := make(map[uuid.UUID]bool)
+	for rows.Next() {
+		var isInserted bool
+		person, err := scanPerson(rows, today, &isInserted)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error while upsert persons: %s: %w", op, err)
+		}
+		if isInserted {
+			inserted[*person.Id] = true
+		}
+		upserted = append(upserted, person)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error while upsert persons: %s: %w", op, err)
+	}
+
+	return upserted, inserted, nil
+}
+
+// DeletePersons delete persons by ids, returns deleted rows. Order of result is not defined.
+func (s *PersonRepositoryImpl) DeletePersons(ctx context.Context, ids []uuid.UUID) ([]entity.Person, error) {
+	const op = "storage.postgres.DeletePersons"
+
+	sqlStatement := `DELETE FROM person p WHERE p.id = ANY($1)
+						RETURNING ` + personSelect
+	persons, err := s.queryPersons(ctx, sqlStatement, ids)
+	if err != nil {
+		return nil, fmt.Errorf("error while delete persons: %s: %w", op, err)
+	}
+
+	return persons, nil
+}
+
+func (s *PersonRepositoryImpl) queryPersons(ctx context.Context, sqlStatement string, args ...any) ([]entity.Person, error) {
+	rows, err := s.db.Query(ctx, sqlStatement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	today := s.Today()
+	var persons []entity.Person
+	for rows.Next() {
+		person, err := scanPerson(rows, today)
+		if err != nil {
+			return nil, err
+		}
+		persons = append(persons, person)
+	}
+
+	return persons, rows.Err()
+}
+
+// StreamPersons read persons ordered by id with server-side cursor by fetchSize rows and pass them to fn one by one,
+// must be called in transaction. Error of fn stops reading.
+func (s *PersonRepositoryImpl) StreamPersons(ctx context.Context, filter PersonFilter, fetchSize int, fn func(person entity.Person) error) error {
+	const op = "storage.postgres.StreamPersons"
+
+	where, args := filter.conditions(nil, s.Today())
+	sqlStatement := `DECLARE person_export NO SCROLL CURSOR FOR
+						SELECT ` + personSelect + ` FROM person p
+						WHERE ` + where + `
+						ORDER BY p.id`
+	if _, err := s.db.Exec(ctx, sqlStatement, args...); err != nil {
+		return fmt.Errorf("error while declare cursor: %s: %w", op, err)
+	}
+
+	fetchStatement := fmt.Sprintf(`FETCH FORWARD %d FROM person_export`, fetchSize)
+	for {
+		persons, err := s.queryPersons(ctx, fetchStatement)
+		if err != nil {
+			return fmt.Errorf("error while fetch persons: %s: %w", op, err)
+		}
+		if len(persons) == 0 {
+			break
+		}
+
+		for _, person := range persons {
+			if err := fn(person); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := s.db.Exec(ctx, `CLOSE person_export`); err != nil {
+		return fmt.Errorf("error while close cursor: %s: %w", op, err)
+	}
+	return nil
+}
+
+// SearchPersons load up to limit persons matching filter in sort order after cursor (keyset pagination).
+func (s *PersonRepositoryImpl) SearchPersons(ctx context.Context, filter PersonFilter, sort PersonSort, after *PersonCursor, limit int) ([]entity.Person, error) {
+	const op = "storage.postgres.SearchPersons"
+
+	where, args := filter.conditions(nil, s.Today())
+	column, desc := sort.column()
+	direction, comparison := "ASC", ">"
+	if desc {
+		direction, comparison = "DESC", "<"
+	}
+
+	/* field is one of constants, it is never taken from input as is */
+	var order string
+	if sort.Field == "" || sort.Field == SortById {
+		order = fmt.Sprintf("p.id %s", direction)
+		if after != nil {
+			args = append(args, after.Id)
+			where += fmt.Sprintf(" AND p.id %s $%d", comparison, len(args))
+		}
+	} else {
+		order = fmt.Sprintf("p.%s %s, p.id %s", column, direction, direction)
+		if after != nil {
+			args = append(args, after.Value, after.Id)
+			where += fmt.Sprintf(" AND (p.%s, p.id) %s ($%d, $%d)", column, comparison, len(args)-1, len(args))
+		}
+	}
+	args = append(args, limit)
+
+	sqlStatement := fmt.Sprintf(`SELECT %s FROM person p
+						WHERE %s
+						ORDER BY %s
+						LIMIT $%d`, personSelect, where, order, len(args))
+	persons, err := s.queryPersons(ctx, sqlStatement, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error while search persons: %s: %w", op, err)
+	}
+
+	return persons, nil
+}
+
+// LoadPersons load page of 50 persons matching filter from database.
+func (s *PersonRepositoryImpl) LoadPersons(ctx context.Context, filter PersonFilter, page *string) ([]entity.Person, error) {
 	const op = "storage.postgres.LoadPersons"
 
-	var person entity.Person
 	pageInt, _ := strconv.Atoi(*page)
-	sqlStatement := `SELECT p.id, p.first_name, p.last_name, p.age, p.last_update, p.login FROM person p LIMIT 50 OFFSET $1`
+	today := s.Today()
+	where, args := filter.conditions(nil, today)
+	sqlStatement := fmt.Sprintf(`SELECT %s FROM person p
+					WHERE %s LIMIT 50 OFFSET $%d`, personSelect, where, len(args)+1)
 
 	var offset int
 	if pageInt <= 1 {
@@ -170,37 +685,60 @@ func (s *PersonRepositoryImpl) LoadPersons(page *string) ([]entity.Person, error
 		offset = (pageInt - 1) * 50
 	}
 
-	rows, err := s.db.Query(sqlStatement, offset)
+	rows, err := s.db.Query(ctx, sqlStatement, append(args, offset)...)
 	if err != nil {
 		return nil, fmt.Errorf("error whole load persons: %s: %w", op, err)
 	}
-
-	defer func(rows *sql.Rows) {
-		err := rows.Close()
-		if err != nil {
-			log.Fatal(err)
-		}
-	}(rows)
+	defer rows.Close()
 
 	var persons []entity.Person
 	for rows.Next() {
-		err := rows.Scan(&person.Id, &person.FirstName, &person.LastName, &person.Age, &person.Timestamp, &person.Login)
+		person, err := scanPerson(rows, today)
 		if err != nil {
-			log.Fatal(err)
+			return nil, fmt.Errorf("error while scan person: %s: %w", op, err)
 		}
 
-		persons = append(persons, entity.Person{
-			Id:        person.Id,
-			FirstName: person.FirstName,
-			LastName:  person.LastName,
-			Age:       person.Age,
-			Timestamp: person.Timestamp,
-			Login:     person.Login,
-		})
+		persons = append(persons, person)
 	}
 	if err := rows.Err(); err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("error whole load persons: %s: %w", op, err)
 	}
 
 	return persons, nil
 }
+
+/* age of stored birth date equals age of written approximate one, $8 is today */
+const keepBirthDate = `excluded.birth_date_approximate
+								AND date_part('year', age($8::date, p.birth_date)) = date_part('year', age($8::date, excluded.birth_date))`
+
+// personArrays columns of persons as arrays in order id, first_name, last_name, birth_date, birth_date_approximate,
+// login, attributes; persons are written from them by unnest.
+func personArrays(persons []entity.Person, today time.Time) ([]any, error) {
+	ids := make([]uuid.UUID, len(persons))
+	firstNames := make([]string, len(persons))
+	lastNames := make([]string, len(persons))
+	birthDates := make([]time.Time, len(persons))
+	approximates := make([]bool, len(persons))
+	logins := make([]string, len(persons))
+	attributes := make([]string, len(persons))
+	for index, person := range persons {
+		ids[index] = newId(person.Id)
+		firstNames[index] = person.FirstName
+		lastNames[index] = person.LastName
+		birthDates[index], approximates[index] = birthDate(person, today)
+		logins[index] = person.Login
+		var err error
+		if attributes[index], err = encodeAttributes(person.Attributes); err != nil {
+			return nil, err
+		}
+	}
+	return []any{ids, firstNames, lastNames, birthDates, approximates, logins, attributes}, nil
+}
+
+// newId returns id or generates new one for empty id.
+func newId(id *uuid.UUID) uuid.UUID {
+	if id == nil || utils.IsNullableUUID(id) {
+		return uuid.New()
+	}
+	return *id
+}
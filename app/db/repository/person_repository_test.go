@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_PersonFilterConditions(t *testing.T) {
+	today := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	minAge, maxAge := 18, 30
+
+	where, args := PersonFilter{MinAge: &minAge, MaxAge: &maxAge}.conditions([]any{"first"}, today)
+
+	/* ages become ranges of indexed birth_date column */
+	assert.Equal(t, "TRUE AND p.birth_date <= $2 AND p.birth_date > $3", where)
+	assert.Equal(t, []any{
+		"first",
+		time.Date(2006, 5, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(1993, 5, 1, 0, 0, 0, 0, time.UTC),
+	}, args)
+}
+
+func Test_PersonSortColumn(t *testing.T) {
+	column, desc := PersonSort{Field: SortByAge}.column()
+	assert.Equal(t, "birth_date", column)
+	assert.True(t, desc)
+
+	column, desc = PersonSort{Field: SortByLastName, Desc: true}.column()
+	assert.Equal(t, "last_name", column)
+	assert.True(t, desc)
+}
+
+func Test_PersonFilterMembership(t *testing.T) {
+	group := uuid.New()
+
+	where, args := PersonFilter{Tags: []string{"vip", "beta"}, Group: &group}.conditions(nil, time.Now())
+
+	assert.Equal(t, 3, strings.Count(where, "EXISTS"))
+	assert.Contains(t, where, "t.tag = $1")
+	assert.Contains(t, where, "t.tag = $2")
+	/* descendants of group are resolved by recursive query */
+	assert.Contains(t, where, "WITH RECURSIVE subtree(id) AS (\n\t\tSELECT $3::uuid")
+	assert.Equal(t, []any{"vip", "beta", group}, args)
+
+	where, _ = PersonFilter{Group: &group, DirectMembers: true}.conditions(nil, time.Now())
+	assert.NotContains(t, where, "RECURSIVE")
+	assert.Contains(t, where, "m.group_id = $1")
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"person-service/db/entity"
+	"time"
+)
+
+type RelationshipRepositoryImpl struct {
+	db DBTX
+	/* time zone of today, ages of persons in hierarchy are computed for it */
+	location *time.Location
+}
+
+// relationshipLockId first key of transaction advisory lock, second one is hash of relationship type.
+const relationshipLockId = 7_340_114
+
+const relationshipColumns = `r.id, r.person_id, r.related_id, r.type, r.created_at`
+
+/* persons above $1 by relationships of type $2 up to depth $3, path stops walk on cycle */
+const chainQuery = `WITH RECURSIVE chain(id, link_id, depth, path) AS (
+		SELECT r.related_id, r.person_id, 1, ARRAY[r.person_id, r.related_id]
+			FROM person_relationship r WHERE r.person_id = $1 AND r.type = $2
+		UNION ALL
+		SELECT r.related_id, r.person_id, c.depth + 1, c.path || r.related_id
+			FROM person_relationship r JOIN chain c ON r.person_id = c.id
+			WHERE r.type = $2 AND c.depth < $3 AND NOT r.related_id = ANY(c.path)
+	)
+	SELECT ` + personSelect + `, c.link_id, c.depth FROM chain c JOIN person p ON p.id = c.id
+		ORDER BY c.depth, p.last_name, p.first_name, p.id`
+
+/* persons below $1 by relationships of type $2 up to depth $3 */
+const reportsQuery = `WITH RECURSIVE tree(id, link_id, depth, path) AS (
+		SELECT r.person_id, r.related_id, 1, ARRAY[r.related_id, r.person_id]
+			FROM person_relationship r WHERE r.related_id = $1 AND r.type = $2
+		UNION ALL
+		SELECT r.person_id, r.related_id, t.depth + 1, t.path || r.person_id
+			FROM person_relationship r JOIN tree t ON r.related_id = t.id
+			WHERE r.type = $2 AND t.depth < $3 AND NOT r.person_id = ANY(t.path)
+	)
+	SELECT ` + personSelect + `, t.link_id, t.depth FROM tree t JOIN person p ON p.id = t.id
+		ORDER BY t.depth, p.last_name, p.first_name, p.id`
+
+// SaveRelationship save new relationship, the same relationship violates unique constraint.
+func (s *RelationshipRepositoryImpl) SaveRelationship(ctx context.Context, relationship entity.Relationship) (entity.Relationship, error) {
+	const op = "storage.postgres.Relationships.SaveRelationship"
+
+	sqlStatement := `INSERT INTO person_relationship AS r(id, person_id, related_id, type)
+						VALUES ($1, $2, $3, $4)
+							RETURNING ` + relationshipColumns
+
+	saved, err := scanRelationship(s.db.QueryRow(ctx, sqlStatement,
+		newId(&relationship.Id), relationship.PersonId, relationship.RelatedId, relationship.Type,
+	))
+	if err != nil {
+		return entity.Relationship{}, fmt.Errorf("error while save relationship: %s: %w", op, err)
+	}
+
+	return saved, nil
+}
+
+// DeleteRelationship delete relationship of person in any direction, returns false for unknown id.
+func (s *RelationshipRepositoryImpl) DeleteRelationship(ctx context.Context, personId uuid.UUID, id uuid.UUID) (bool, error) {
+	const op = "storage.postgres.Relationships.DeleteRelationship"
+
+	tag, err := s.db.Exec(ctx, `DELETE FROM person_relationship WHERE id = $1 AND (person_id = $2 OR related_id = $2)`, id, personId)
+	if err != nil {
+		return false, fmt.Errorf("error while delete relationship: %s: %w", op, err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// LoadRelationships load relationships of person in both directions in order of creation.
+func (s *RelationshipRepositoryImpl) LoadRelationships(ctx context.Context, personId uuid.UUID) ([]entity.Relationship, error) {
+	const op = "storage.postgres.Relationships.LoadRelationships"
+
+	rows, err := s.db.Query(ctx, `SELECT `+relationshipColumns+` FROM person_relationship r
+									WHERE r.person_id = $1 OR r.related_id = $1
+									ORDER BY r.created_at, r.id`, personId)
+	if err != nil {
+		return nil, fmt.Errorf("error while load relationships: %s: %w", op, err)
+	}
+	defer rows.Close()
+
+	relationships := make([]entity.Relationship, 0)
+	for rows.Next() {
+		relationship, err := scanRelationship(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error while load relationships: %s: %w", op, err)
+		}
+		relationships = append(relationships, relationship)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error while load relationships: %s: %w", op, err)
+	}
+
+	return relationships, nil
+}
+
+// CountRelationships count relationships of type from person.
+func (s *RelationshipRepositoryImpl) CountRelationships(ctx context.Context, personId uuid.UUID, relationshipType string) (int, error) {
+	const op = "storage.postgres.Relationships.CountRelationships"
+
+	var count int
+	err := s.db.QueryRow(ctx, `SELECT count(*) FROM person_relationship WHERE person_id = $1 AND type = $2`,
+		personId, relationshipType).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("error while count relationships: %s: %w", op, err)
+	}
+
+	return count, nil
+}
+
+// InChain reports whether target is person itself or one of persons above it by relationships of type.
+func (s *RelationshipRepositoryImpl) InChain(ctx context.Context, personId uuid.UUID, relationshipType string, target uuid.UUID) (bool, error) {
+	const op = "storage.postgres.Relationships.InChain"
+
+	var found bool
+	err := s.db.QueryRow(ctx, `WITH RECURSIVE chain(id) AS (
+									SELECT $1::uuid
+									UNION SELECT r.related_id FROM person_relationship r JOIN chain c ON r.person_id = c.id WHERE r.type = $2
+								) SELECT $3::uuid IN (SELECT id FROM chain)`, personId, relationshipType, target).Scan(&found)
+	if err != nil {
+		return false, fmt.Errorf("error while check chain of relationships: %s: %w", op, err)
+	}
+
+	return found, nil
+}
+
+// LockType lock relationships of type until the end of transaction, checks of hierarchy are serialized.
+func (s *RelationshipRepositoryImpl) LockType(ctx context.Context, relationshipType string) error {
+	const op = "storage.postgres.Relationships.LockType"
+
+	if _, err := s.db.Exec(ctx, `SELECT pg_advisory_xact_lock($1, hashtext($2))`, relationshipLockId, relationshipType); err != nil {
+		return fmt.Errorf("error while lock relationships: %s: %w", op, err)
+	}
+	return nil
+}
+
+// LoadChain load persons above person by relationships of type up to depth ordered by depth.
+func (s *RelationshipRepositoryImpl) LoadChain(ctx context.Context, personId uuid.UUID, relationshipType string, depth int) ([]entity.HierarchyNode, error) {
+	const op = "storage.postgres.Relationships.LoadChain"
+
+	nodes, err := s.queryHierarchy(ctx, chainQuery, personId, relationshipType, depth)
+	if err != nil {
+		return nil, fmt.Errorf("error while load chain: %s: %w", op, err)
+	}
+
+	return nodes, nil
+}
+
+// LoadReports load persons below person by relationships of type up to depth ordered by depth.
+func (s *RelationshipRepositoryImpl) LoadReports(ctx context.Context, personId uuid.UUID, relationshipType string, depth int) ([]entity.HierarchyNode, error) {
+	const op = "storage.postgres.Relationships.LoadReports"
+
+	nodes, err := s.queryHierarchy(ctx, reportsQuery, personId, relationshipType, depth)
+	if err != nil {
+		return nil, fmt.Errorf("error while load reports: %s: %w", op, err)
+	}
+
+	return nodes, nil
+}
+
+func (s *RelationshipRepositoryImpl) queryHierarchy(ctx context.Context, sqlStatement string, args ...any) ([]entity.HierarchyNode, error) {
+	rows, err := s.db.Query(ctx, sqlStatement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	today := entity.DateOf(time.Now(), s.location)
+	var nodes []entity.HierarchyNode
+	for rows.Next() {
+		var node entity.HierarchyNode
+		if node.Person, err = scanPerson(rows, today, &node.LinkId, &node.Depth); err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+
+	return nodes, rows.Err()
+}
+
+func scanRelationship(row pgx.Row) (entity.Relationship, error) {
+	var relationship entity.Relationship
+	err := row.Scan(&relationship.Id, &relationship.PersonId, &relationship.RelatedId, &relationship.Type, &relationship.CreatedAt)
+	return relationship, err
+}
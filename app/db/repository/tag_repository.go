@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"person-service/db/entity"
+)
+
+type TagRepositoryImpl struct {
+	db DBTX
+}
+
+// AddTag tag persons, returns count of persons which were not tagged yet.
+func (s *TagRepositoryImpl) AddTag(ctx context.Context, tag string, personIds []uuid.UUID) (int64, error) {
+	const op = "storage.postgres.Tags.AddTag"
+
+	result, err := s.db.Exec(ctx, `INSERT INTO person_tag(tag, person_id)
+										SELECT $1, unnest($2::uuid[])
+											ON CONFLICT DO NOTHING`, tag, personIds)
+	if err != nil {
+		return 0, fmt.Errorf("error while add tag: %s: %w", op, err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// RemoveTag untag persons, returns count of persons which were tagged.
+func (s *TagRepositoryImpl) RemoveTag(ctx context.Context, tag string, personIds []uuid.UUID) (int64, error) {
+	const op = "storage.postgres.Tags.RemoveTag"
+
+	result, err := s.db.Exec(ctx, `DELETE FROM person_tag WHERE tag = $1 AND person_id = ANY($2)`, tag, personIds)
+	if err != nil {
+		return 0, fmt.Errorf("error while remove tag: %s: %w", op, err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// LoadTags load all tags with count of tagged persons ordered by tag.
+func (s *TagRepositoryImpl) LoadTags(ctx context.Context) ([]entity.Tag, error) {
+	const op = "storage.postgres.Tags.LoadTags"
+
+	rows, err := s.db.Query(ctx, `SELECT t.tag, count(*) FROM person_tag t GROUP BY t.tag ORDER BY t.tag`)
+	if err != nil {
+		return nil, fmt.Errorf("error while load tags: %s: %w", op, err)
+	}
+	defer rows.Close()
+
+	tags := make([]entity.Tag, 0)
+	for rows.Next() {
+		var tag entity.Tag
+		if err := rows.Scan(&tag.Name, &tag.Persons); err != nil {
+			return nil, fmt.Errorf("error while load tags: %s: %w", op, err)
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error while load tags: %s: %w", op, err)
+	}
+
+	return tags, nil
+}
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"person-service/db/entity"
+	"sync"
+)
+
+// tenantRole role of connections serving tenant, row level security policies of person tables apply to it.
+const tenantRole = "person_tenant"
+
+// quotaLockId first key of transaction advisory lock, second one is hash of tenant id. Tenant role may not lock
+// tenant row, since it has no UPDATE privilege on tenant.
+const quotaLockId = 7_340_116
+
+type tenantKey struct{}
+
+// WithTenant ctx whose statements are executed for tenant, rows of other tenants are neither seen nor written.
+func WithTenant(ctx context.Context, tenantId uuid.UUID) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantId)
+}
+
+// TenantOf tenant of ctx, statements of ctx without tenant (background workers, admin) see rows of every tenant.
+func TenantOf(ctx context.Context) (uuid.UUID, bool) {
+	tenantId, ok := ctx.Value(tenantKey{}).(uuid.UUID)
+	return tenantId, ok
+}
+
+// tenantBinding binds pooled connection to tenant of ctx it is acquired with. Tenant of every connection is
+// remembered, so statements are executed only when connection serves other tenant than before.
+type tenantBinding struct {
+	tenants sync.Map
+}
+
+func (b *tenantBinding) beforeAcquire(ctx context.Context, conn *pgx.Conn) bool {
+	tenantId, ok := TenantOf(ctx)
+	bound, wasBound := b.tenants.Load(conn)
+	if ok == wasBound && (!ok || bound == tenantId) {
+		return true
+	}
+
+	sqlStatement := `RESET ROLE; RESET app.tenant_id`
+	if ok {
+		sqlStatement = fmt.Sprintf(`SET app.tenant_id = '%s'; SET ROLE %s`, tenantId, tenantRole)
+	}
+	/* connection which failed to switch is destroyed, pool acquires other one */
+	if _, err := conn.Exec(ctx, sqlStatement); err != nil {
+		b.tenants.Delete(conn)
+		return false
+	}
+
+	if ok {
+		b.tenants.Store(conn, tenantId)
+	} else {
+		b.tenants.Delete(conn)
+	}
+	return true
+}
+
+func (b *tenantBinding) beforeClose(conn *pgx.Conn) {
+	b.tenants.Delete(conn)
+}
+
+type TenantRepositoryImpl struct {
+	db DBTX
+}
+
+const tenantColumns = `t.id, t.name, t.status, t.max_persons, t.max_age, t.login_pattern, t.require_login, t.created_at, t.updated_at`
+
+/* persons are counted by admin connection, which sees every tenant */
+const tenantPersons = `(SELECT count(*) FROM person p WHERE p.tenant_id = t.id)`
+
+// SaveTenant save new tenant, existing name violates unique constraint.
+func (s *TenantRepositoryImpl) SaveTenant(ctx context.Context, tenant entity.Tenant) (entity.Tenant, error) {
+	const op = "storage.postgres.Tenants.SaveTenant"
+
+	sqlStatement := `INSERT INTO tenant AS t(id, name, status, max_persons, max_age, login_pattern, require_login)
+						VALUES ($1, $2, $3, $4, $5, $6, $7)
+							RETURNING ` + tenantColumns + `, 0`
+
+	saved, err := scanTenant(s.db.QueryRow(ctx, sqlStatement, newId(&tenant.Id), tenant.Name, entity.TenantActive,
+		tenant.MaxPersons, tenant.MaxAge, tenant.LoginPattern, tenant.RequireLogin,
+	))
+	if err != nil {
+		return entity.Tenant{}, fmt.Errorf("error while save tenant: %s: %w", op, err)
+	}
+
+	return saved, nil
+}
+
+// UpdateTenant update name and settings of tenant, returns pgx.ErrNoRows for unknown id.
+func (s *TenantRepositoryImpl) UpdateTenant(ctx context.Context, tenant entity.Tenant) (entity.Tenant, error) {
+	const op = "storage.postgres.Tenants.UpdateTenant"
+
+	sqlStatement := `UPDATE tenant t SET name = $2, max_persons = $3, max_age = $4, login_pattern = $5, require_login = $6,
+						updated_at = now()
+						WHERE t.id = $1
+							RETURNING ` + tenantColumns + `, ` + tenantPersons
+
+	updated, err := scanTenant(s.db.QueryRow(ctx, sqlStatement, tenant.Id, tenant.Name,
+		tenant.MaxPersons, tenant.MaxAge, tenant.LoginPattern, tenant.RequireLogin,
+	))
+	if err != nil {
+		return entity.Tenant{}, fmt.Errorf("error while update tenant: %s: %w", op, err)
+	}
+
+	return updated, nil
+}
+
+// UpdateStatus suspend or resume tenant, returns pgx.ErrNoRows for unknown id.
+func (s *TenantRepositoryImpl) UpdateStatus(ctx context.Context, id uuid.UUID, status string) (entity.Tenant, error) {
+	const op = "storage.postgres.Tenants.UpdateStatus"
+
+	sqlStatement := `UPDATE tenant t SET status = $2, updated_at = now()
+						WHERE t.id = $1
+							RETURNING ` + tenantColumns + `, ` + tenantPersons
+
+	updated, err := scanTenant(s.db.QueryRow(ctx, sqlStatement, id, status))
+	if err != nil {
+		return entity.Tenant{}, fmt.Errorf("error while update tenant status: %s: %w", op, err)
+	}
+
+	return updated, nil
+}
+
+// FindTenant find tenant by id together with count of its persons.
+func (s *TenantRepositoryImpl) FindTenant(ctx context.Context, id uuid.UUID) (entity.Tenant, error) {
+	const op = "storage.postgres.Tenants.FindTenant"
+
+	tenant, err := scanTenant(s.db.QueryRow(ctx,
+		`SELECT `+tenantColumns+`, `+tenantPersons+` FROM tenant t WHERE t.id = $1`, id,
+	))
+	if err != nil {
+		return entity.Tenant{}, fmt.Errorf("error while find tenant: %s: %w", op, err)
+	}
+
+	return tenant, nil
+}
+
+// FindCurrentTenant find tenant the connection is bound to, returns pgx.ErrNoRows without tenant.
+func (s *TenantRepositoryImpl) FindCurrentTenant(ctx context.Context) (entity.Tenant, error) {
+	const op = "storage.postgres.Tenants.FindCurrentTenant"
+
+	tenant, err := scanTenant(s.db.QueryRow(ctx, `SELECT `+tenantColumns+`, 0 FROM tenant t WHERE t.id = current_tenant()`))
+	if err != nil {
+		return entity.Tenant{}, fmt.Errorf("error while find current tenant: %s: %w", op, err)
+	}
+
+	return tenant, nil
+}
+
+// LoadTenants load all tenants ordered by name together with counts of their persons.
+func (s *TenantRepositoryImpl) LoadTenants(ctx context.Context) ([]entity.Tenant, error) {
+	const op = "storage.postgres.Tenants.LoadTenants"
+
+	rows, err := s.db.Query(ctx, `SELECT `+tenantColumns+`, `+tenantPersons+` FROM tenant t ORDER BY t.name`)
+	if err != nil {
+		return nil, fmt.Errorf("error while load tenants: %s: %w", op, err)
+	}
+	defer rows.Close()
+
+	tenants := make([]entity.Tenant, 0)
+	for rows.Next() {
+		tenant, err := scanTenant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error while load tenants: %s: %w", op, err)
+		}
+		tenants = append(tenants, tenant)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error while load tenants: %s: %w", op, err)
+	}
+
+	return tenants, nil
+}
+
+// LockQuota lock quota of current tenant until the end of transaction, concurrent creates are counted one after another.
+func (s *TenantRepositoryImpl) LockQuota(ctx context.Context) error {
+	const op = "storage.postgres.Tenants.LockQuota"
+
+	if _, err := s.db.Exec(ctx, `SELECT pg_advisory_xact_lock($1, hashtext(current_tenant()::text))`, quotaLockId); err != nil {
+		return fmt.Errorf("error while lock tenant: %s: %w", op, err)
+	}
+	return nil
+}
+
+// CountPersons count persons of current tenant.
+func (s *TenantRepositoryImpl) CountPersons(ctx context.Context) (int, error) {
+	const op = "storage.postgres.Tenants.CountPersons"
+
+	var count int
+	if err := s.db.QueryRow(ctx, `SELECT count(*) FROM person WHERE tenant_id = current_tenant()`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("error while count persons: %s: %w", op, err)
+	}
+
+	return count, nil
+}
+
+func scanTenant(row pgx.Row) (entity.Tenant, error) {
+	var tenant entity.Tenant
+	err := row.Scan(&tenant.Id, &tenant.Name, &tenant.Status, &tenant.MaxPersons, &tenant.MaxAge, &tenant.LoginPattern,
+		&tenant.RequireLogin, &tenant.CreatedAt, &tenant.UpdatedAt, &tenant.Persons)
+	return tenant, err
+}
@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"person-service/config"
+	"strings"
+	"time"
+)
+
+// DBTX common statements of pgxpool.Pool and pgx.Tx, repositories work with both of them.
+type DBTX interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+const (
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+// UnitOfWork repositories bound to one transaction (or to pool, see Repositories).
+type UnitOfWork struct {
+	Persons       *PersonRepositoryImpl
+	Outbox        *OutboxRepositoryImpl
+	Webhooks      *WebhookRepositoryImpl
+	Idempotency   *IdempotencyRepositoryImpl
+	Contacts      *ContactRepositoryImpl
+	Addresses     *AddressRepositoryImpl
+	Attributes    *AttributeRepositoryImpl
+	Groups        *GroupRepositoryImpl
+	Tags          *TagRepositoryImpl
+	Relationships *RelationshipRepositoryImpl
+	Avatars       *AvatarRepositoryImpl
+	Attachments   *AttachmentRepositoryImpl
+	Merges        *MergeRepositoryImpl
+	Tenants       *TenantRepositoryImpl
+}
+
+// TxOptions options of single transaction, empty values are taken from configuration.
+type TxOptions struct {
+	IsoLevel   pgx.TxIsoLevel
+	AccessMode pgx.TxAccessMode
+}
+
+// ReadCommitted options of transactions checking rows after advisory lock. Snapshot of repeatable read is taken
+// by the first statement before lock is granted, every statement of read committed sees rows of previous lock holder.
+var ReadCommitted = TxOptions{IsoLevel: pgx.ReadCommitted}
+
+type TxManager struct {
+	pool         *pgxpool.Pool
+	persons      *PersonRepositoryImpl
+	isoLevel     pgx.TxIsoLevel
+	maxRetries   int
+	retryBackoff time.Duration
+}
+
+type txKey struct{}
+
+func NewTxManager(persons *PersonRepositoryImpl, transaction config.Transaction) (*TxManager, error) {
+	const op = "storage.postgres.NewTxManager"
+
+	isoLevel, err := parseIsoLevel(transaction.IsolationLevel)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &TxManager{
+		pool:         persons.pool,
+		persons:      persons,
+		isoLevel:     isoLevel,
+		maxRetries:   transaction.MaxRetries,
+		retryBackoff: transaction.RetryBackoff,
+	}, nil
+}
+
+// WithinTransaction runs fn with repositories bound to transaction, commits when fn returns nil.
+// Nested call (ctx of running transaction) creates savepoint, which is rolled back alone on error.
+// Outermost transaction is retried on serialization failure or deadlock.
+func (m *TxManager) WithinTransaction(ctx context.Context, opts TxOptions, fn func(ctx context.Context, uow *UnitOfWork) error) error {
+	const op = "storage.postgres.WithinTransaction"
+
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		if err := m.run(ctx, tx.Begin, fn); err != nil {
+			return fmt.Errorf("%s: savepoint: %w", op, err)
+		}
+		return nil
+	}
+
+	txOptions := pgx.TxOptions{IsoLevel: opts.IsoLevel, AccessMode: opts.AccessMode}
+	if txOptions.IsoLevel == "" {
+		txOptions.IsoLevel = m.isoLevel
+	}
+	begin := func(ctx context.Context) (pgx.Tx, error) {
+		return m.pool.BeginTx(ctx, txOptions)
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = m.run(ctx, begin, fn)
+		if err == nil || !isRetryable(err) || attempt >= m.maxRetries {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s: %w", op, ctx.Err())
+		case <-time.After(m.retryBackoff * time.Duration(attempt+1)):
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// InTransaction reports whether ctx belongs to running transaction.
+func InTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(pgx.Tx)
+	return ok
+}
+
+func (m *TxManager) run(
+	ctx context.Context,
+	begin func(ctx context.Context) (pgx.Tx, error),
+	fn func(ctx context.Context, uow *UnitOfWork) error,
+) (err error) {
+	tx, err := begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(context.Background())
+			panic(p)
+		}
+		if err != nil {
+			_ = tx.Rollback(context.Background())
+		}
+	}()
+
+	if err = fn(context.WithValue(ctx, txKey{}, tx), m.unitOfWork(tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Repositories returns repositories working without transaction, every statement is committed alone.
+func (m *TxManager) Repositories() *UnitOfWork {
+	return m.unitOfWork(m.pool)
+}
+
+func (m *TxManager) unitOfWork(db DBTX) *UnitOfWork {
+	return &UnitOfWork{
+		Persons:       m.persons.withDB(db),
+		Outbox:        &OutboxRepositoryImpl{db: db},
+		Webhooks:      &WebhookRepositoryImpl{db: db},
+		Idempotency:   &IdempotencyRepositoryImpl{db: db},
+		Contacts:      &ContactRepositoryImpl{db: db},
+		Addresses:     &AddressRepositoryImpl{db: db},
+		Attributes:    &AttributeRepositoryImpl{db: db},
+		Groups:        &GroupRepositoryImpl{db: db},
+		Tags:          &TagRepositoryImpl{db: db},
+		Relationships: &RelationshipRepositoryImpl{db: db, location: m.persons.location},
+		Avatars:       &AvatarRepositoryImpl{db: db},
+		Attachments:   &AttachmentRepositoryImpl{db: db},
+		Merges:        &MergeRepositoryImpl{db: db, location: m.persons.location},
+		Tenants:       &TenantRepositoryImpl{db: db},
+	}
+}
+
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == serializationFailure || pgErr.Code == deadlockDetected
+}
+
+func parseIsoLevel(level string) (pgx.TxIsoLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "", "read committed":
+		return pgx.ReadCommitted, nil
+	case "repeatable read":
+		return pgx.RepeatableRead, nil
+	case "serializable":
+		return pgx.Serializable, nil
+	case "read uncommitted":
+		return pgx.ReadUncommitted, nil
+	default:
+		return "", fmt.Errorf("unknown isolation level: %s", level)
+	}
+}
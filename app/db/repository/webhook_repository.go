@@ -0,0 +1,304 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"person-service/db/entity"
+	"time"
+)
+
+type WebhookRepositoryImpl struct {
+	db DBTX
+}
+
+const subscriptionColumns = `s.id, s.url, s.event_types, s.secret, s.active, s.created_at, s.updated_at`
+
+const deliveryColumns = `d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+	d.next_attempt_at, d.last_error, d.created_at, d.delivered_at`
+
+// SaveSubscription save new webhook subscription.
+func (s *WebhookRepositoryImpl) SaveSubscription(ctx context.Context, subscription entity.WebhookSubscription) (entity.WebhookSubscription, error) {
+	const op = "storage.postgres.Webhooks.SaveSubscription"
+
+	sqlStatement := `INSERT INTO webhook_subscription AS s(id, url, event_types, secret, active)
+						VALUES ($1, $2, $3, $4, $5)
+							RETURNING ` + subscriptionColumns
+
+	saved, err := scanSubscription(s.db.QueryRow(ctx, sqlStatement,
+		newId(&subscription.Id), subscription.Url, subscription.EventTypes, subscription.Secret, subscription.Active,
+	))
+	if err != nil {
+		return entity.WebhookSubscription{}, fmt.Errorf("error while save subscription: %s: %w", op, err)
+	}
+
+	return saved, nil
+}
+
+// UpdateSubscription update existing webhook subscription, returns pgx.ErrNoRows for unknown id.
+func (s *WebhookRepositoryImpl) UpdateSubscription(ctx context.Context, subscription entity.WebhookSubscription) (entity.WebhookSubscription, error) {
+	const op = "storage.postgres.Webhooks.UpdateSubscription"
+
+	sqlStatement := `UPDATE webhook_subscription s SET url = $2, event_types = $3, secret = $4, active = $5, updated_at = now()
+						WHERE s.id = $1
+							RETURNING ` + subscriptionColumns
+
+	updated, err := scanSubscription(s.db.QueryRow(ctx, sqlStatement,
+		subscription.Id, subscription.Url, subscription.EventTypes, subscription.Secret, subscription.Active,
+	))
+	if err != nil {
+		return entity.WebhookSubscription{}, fmt.Errorf("error while update subscription: %s: %w", op, err)
+	}
+
+	return updated, nil
+}
+
+// DeleteSubscription delete subscription together with its deliveries, returns false for unknown id.
+func (s *WebhookRepositoryImpl) DeleteSubscription(ctx context.Context, id uuid.UUID) (bool, error) {
+	const op = "storage.postgres.Webhooks.DeleteSubscription"
+
+	tag, err := s.db.Exec(ctx, `DELETE FROM webhook_subscription WHERE id = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("error while delete subscription: %s: %w", op, err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// FindSubscriptionById find subscription by id.
+func (s *WebhookRepositoryImpl) FindSubscriptionById(ctx context.Context, id uuid.UUID) (entity.WebhookSubscription, error) {
+	const op = "storage.postgres.Webhooks.FindSubscriptionById"
+
+	subscription, err := scanSubscription(s.db.QueryRow(ctx,
+		`SELECT `+subscriptionColumns+` FROM webhook_subscription s WHERE s.id = $1`, id,
+	))
+	if err != nil {
+		return entity.WebhookSubscription{}, fmt.Errorf("error while find subscription: %s: %w", op, err)
+	}
+
+	return subscription, nil
+}
+
+// LoadSubscriptions load all subscriptions.
+func (s *WebhookRepositoryImpl) LoadSubscriptions(ctx context.Context) ([]entity.WebhookSubscription, error) {
+	const op = "storage.postgres.Webhooks.LoadSubscriptions"
+
+	return s.querySubscriptions(ctx, op, `SELECT `+subscriptionColumns+` FROM webhook_subscription s ORDER BY s.created_at`)
+}
+
+// FindActiveSubscriptions find active subscriptions of tenant for event type, empty event types means all events.
+// Dispatcher runs without tenant, so tenant is filtered explicitly.
+func (s *WebhookRepositoryImpl) FindActiveSubscriptions(ctx context.Context, tenantId uuid.UUID, eventType string) ([]entity.WebhookSubscription, error) {
+	const op = "storage.postgres.Webhooks.FindActiveSubscriptions"
+
+	sqlStatement := `SELECT ` + subscriptionColumns + ` FROM webhook_subscription s
+						WHERE s.tenant_id = $1 AND s.active AND (cardinality(s.event_types) = 0 OR $2 = ANY(s.event_types))`
+
+	return s.querySubscriptions(ctx, op, sqlStatement, tenantId, eventType)
+}
+
+// EnqueueDelivery create pending delivery of subscription tenant, repeated event for the same subscription is ignored.
+func (s *WebhookRepositoryImpl) EnqueueDelivery(ctx context.Context, delivery entity.WebhookDelivery) error {
+	const op = "storage.postgres.Webhooks.EnqueueDelivery"
+
+	sqlStatement := `INSERT INTO webhook_delivery(tenant_id, subscription_id, event_id, event_type, payload)
+						SELECT s.tenant_id, s.id, $2, $3, $4 FROM webhook_subscription s WHERE s.id = $1
+							ON CONFLICT (subscription_id, event_id) DO NOTHING`
+
+	_, err := s.db.Exec(ctx, sqlStatement, delivery.SubscriptionId, delivery.EventId, delivery.EventType, delivery.Payload)
+	if err != nil {
+		return fmt.Errorf("error while enqueue delivery: %s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ClaimDueDeliveries lease batch of pending deliveries of active subscriptions by moving their next attempt
+// after lease, other workers skip them until lease ends, so delivery of crashed worker is retried after it.
+func (s *WebhookRepositoryImpl) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]entity.WebhookDelivery, error) {
+	const op = "storage.postgres.Webhooks.ClaimDueDeliveries"
+
+	sqlStatement := `WITH due AS (
+						SELECT d.id FROM webhook_delivery d
+							JOIN webhook_subscription s ON s.id = d.subscription_id
+						WHERE d.status = 'pending' AND d.next_attempt_at <= now() AND s.active
+						ORDER BY d.id
+						LIMIT $1
+						FOR UPDATE OF d SKIP LOCKED
+					)
+					UPDATE webhook_delivery d SET next_attempt_at = now() + $2::interval
+						FROM due, webhook_subscription s
+					WHERE d.id = due.id AND s.id = d.subscription_id
+					RETURNING ` + deliveryColumns + `, s.url, s.secret`
+
+	rows, err := s.db.Query(ctx, sqlStatement, limit, lease)
+	if err != nil {
+		return nil, fmt.Errorf("error while fetch deliveries: %s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var deliveries []entity.WebhookDelivery
+	for rows.Next() {
+		var d entity.WebhookDelivery
+		err := rows.Scan(&d.Id, &d.SubscriptionId, &d.EventId, &d.EventType, &d.Payload, &d.Status, &d.Attempts,
+			&d.NextAttemptAt, &d.LastError, &d.CreatedAt, &d.DeliveredAt, &d.Url, &d.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("error while scan delivery: %s: %w", op, err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error while fetch deliveries: %s: %w", op, err)
+	}
+
+	return deliveries, nil
+}
+
+// SaveAttempt write attempt of delivery to log, attempt belongs to tenant of delivery.
+func (s *WebhookRepositoryImpl) SaveAttempt(ctx context.Context, attempt entity.WebhookDeliveryAttempt) error {
+	const op = "storage.postgres.Webhooks.SaveAttempt"
+
+	sqlStatement := `INSERT INTO webhook_delivery_attempt(tenant_id, delivery_id, response_status, error, duration_ms)
+						SELECT d.tenant_id, d.id, $2, $3, $4 FROM webhook_delivery d WHERE d.id = $1`
+	_, err := s.db.Exec(ctx, sqlStatement, attempt.DeliveryId, attempt.ResponseStatus, attempt.Error, attempt.Duration.Milliseconds())
+	if err != nil {
+		return fmt.Errorf("error while save attempt: %s: %w", op, err)
+	}
+
+	return nil
+}
+
+// MarkDelivered mark delivery as successfully sent.
+func (s *WebhookRepositoryImpl) MarkDelivered(ctx context.Context, id int64) error {
+	const op = "storage.postgres.Webhooks.MarkDelivered"
+
+	sqlStatement := `UPDATE webhook_delivery SET status = 'delivered', attempts = attempts + 1, last_error = NULL, delivered_at = now()
+						WHERE id = $1`
+	if _, err := s.db.Exec(ctx, sqlStatement, id); err != nil {
+		return fmt.Errorf("error while mark delivered: %s: %w", op, err)
+	}
+
+	return nil
+}
+
+// MarkFailed postpone delivery or park it in dead-letter status when dead is true.
+func (s *WebhookRepositoryImpl) MarkFailed(ctx context.Context, id int64, reason string, dead bool, retryAfter time.Duration) error {
+	const op = "storage.postgres.Webhooks.MarkFailed"
+
+	sqlStatement := `UPDATE webhook_delivery
+						SET attempts = attempts + 1, last_error = $2, next_attempt_at = now() + $3::interval,
+							status = CASE WHEN $4::boolean THEN 'dead' ELSE 'pending' END
+						WHERE id = $1`
+	if _, err := s.db.Exec(ctx, sqlStatement, id, reason, retryAfter, dead); err != nil {
+		return fmt.Errorf("error while mark failed: %s: %w", op, err)
+	}
+
+	return nil
+}
+
+// RequeueDelivery move dead delivery back to pending, returns false when delivery is not dead.
+func (s *WebhookRepositoryImpl) RequeueDelivery(ctx context.Context, subscriptionId uuid.UUID, id int64) (bool, error) {
+	const op = "storage.postgres.Webhooks.RequeueDelivery"
+
+	sqlStatement := `UPDATE webhook_delivery SET status = 'pending', attempts = 0, next_attempt_at = now()
+						WHERE id = $1 AND subscription_id = $2 AND status = 'dead'`
+	tag, err := s.db.Exec(ctx, sqlStatement, id, subscriptionId)
+	if err != nil {
+		return false, fmt.Errorf("error while requeue delivery: %s: %w", op, err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// LoadDeliveries load latest deliveries of subscription, optionally filtered by status.
+func (s *WebhookRepositoryImpl) LoadDeliveries(ctx context.Context, subscriptionId uuid.UUID, status string, limit int) ([]entity.WebhookDelivery, error) {
+	const op = "storage.postgres.Webhooks.LoadDeliveries"
+
+	sqlStatement := `SELECT ` + deliveryColumns + ` FROM webhook_delivery d
+						WHERE d.subscription_id = $1 AND ($2 = '' OR d.status = $2)
+					ORDER BY d.id DESC
+					LIMIT $3`
+
+	rows, err := s.db.Query(ctx, sqlStatement, subscriptionId, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error while load deliveries: %s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var deliveries []entity.WebhookDelivery
+	for rows.Next() {
+		var d entity.WebhookDelivery
+		err := rows.Scan(&d.Id, &d.SubscriptionId, &d.EventId, &d.EventType, &d.Payload, &d.Status, &d.Attempts,
+			&d.NextAttemptAt, &d.LastError, &d.CreatedAt, &d.DeliveredAt)
+		if err != nil {
+			return nil, fmt.Errorf("error while scan delivery: %s: %w", op, err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error while load deliveries: %s: %w", op, err)
+	}
+
+	return deliveries, nil
+}
+
+// LoadAttempts load attempts of deliveries grouped by delivery id.
+func (s *WebhookRepositoryImpl) LoadAttempts(ctx context.Context, deliveryIds []int64) (map[int64][]entity.WebhookDeliveryAttempt, error) {
+	const op = "storage.postgres.Webhooks.LoadAttempts"
+
+	sqlStatement := `SELECT a.id, a.delivery_id, a.response_status, a.error, a.duration_ms, a.attempted_at
+						FROM webhook_delivery_attempt a
+					WHERE a.delivery_id = ANY($1)
+					ORDER BY a.id`
+
+	rows, err := s.db.Query(ctx, sqlStatement, deliveryIds)
+	if err != nil {
+		return nil, fmt.Errorf("error while load attempts: %s: %w", op, err)
+	}
+	defer rows.Close()
+
+	attempts := make(map[int64][]entity.WebhookDeliveryAttempt)
+	for rows.Next() {
+		var a entity.WebhookDeliveryAttempt
+		var durationMs int64
+		if err := rows.Scan(&a.Id, &a.DeliveryId, &a.ResponseStatus, &a.Error, &durationMs, &a.AttemptedAt); err != nil {
+			return nil, fmt.Errorf("error while scan attempt: %s: %w", op, err)
+		}
+		a.Duration = time.Duration(durationMs) * time.Millisecond
+		attempts[a.DeliveryId] = append(attempts[a.DeliveryId], a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error while load attempts: %s: %w", op, err)
+	}
+
+	return attempts, nil
+}
+
+func (s *WebhookRepositoryImpl) querySubscriptions(ctx context.Context, op string, sql string, args ...any) ([]entity.WebhookSubscription, error) {
+	rows, err := s.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error while load subscriptions: %s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var subscriptions []entity.WebhookSubscription
+	for rows.Next() {
+		subscription, err := scanSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error while scan subscription: %s: %w", op, err)
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error while load subscriptions: %s: %w", op, err)
+	}
+
+	return subscriptions, nil
+}
+
+func scanSubscription(row pgx.Row) (entity.WebhookSubscription, error) {
+	var s entity.WebhookSubscription
+	err := row.Scan(&s.Id, &s.Url, &s.EventTypes, &s.Secret, &s.Active, &s.CreatedAt, &s.UpdatedAt)
+	return s, err
+}
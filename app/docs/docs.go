@@ -18,14 +18,20 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/person/create": {
+        "/v1/person/create": {
             "post": {
-                "description": "Create new person entity",
+                "description": "Create new person entity, Location header points to created resource",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
                 ],
                 "tags": [
                     "persons"
@@ -40,74 +46,149 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/model.PersonRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key of request, retry with the same key replays first response.",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.PersonResponse"
-                            }
+                            "$ref": "#/definitions/model.PersonResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/person/delete": {
+        "/v1/person/delete": {
             "delete": {
                 "description": "Delete existing persons",
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Delete existing persons",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/person/get/id": {
+            "get": {
+                "description": "Find existing persons",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
                 ],
                 "tags": [
                     "persons"
                 ],
-                "summary": "Delete existing persons",
+                "summary": "Find existing persons",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID for remove person entity",
+                        "description": "ID of person entity.",
                         "name": "id",
-                        "in": "query",
+                        "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Embedded sub-resources, comma separated: contacts, addresses.",
+                        "name": "expand",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.PersonDeleteResponse"
-                            }
+                            "$ref": "#/definitions/model.PersonResponse"
+                        }
+                    },
+                    "308": {
+                        "description": "Person was merged, Location is URL of person it was merged into.",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/person/get/id": {
+        "/v1/person/get/login": {
             "get": {
-                "description": "Find existing persons by id",
+                "description": "Find existing persons",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
                 ],
                 "tags": [
                     "persons"
                 ],
-                "summary": "Find existing persons by id",
+                "summary": "Find existing persons",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID of person entity.",
-                        "name": "id",
+                        "description": "Login of person entity.",
+                        "name": "login",
                         "in": "query",
                         "required": true
                     }
@@ -116,34 +197,95 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.PersonResponse"
-                            }
+                            "$ref": "#/definitions/model.PersonResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
                         }
                     }
                 }
             }
         },
-		"/person/get/login": {
-            "get": {
-                "description": "Find existing persons by login",
+        "/v1/person/update": {
+            "put": {
+                "description": "Replace existing person, v1 route takes id from body and creates person when id is empty",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
                 ],
                 "tags": [
                     "persons"
                 ],
-                "summary": "Find existing persons by login",
+                "summary": "Update existing persons",
+                "parameters": [
+                    {
+                        "description": "Model for update person entity",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.PersonRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.PersonResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/person/{id}/duplicates": {
+            "get": {
+                "description": "Find persons which may duplicate person ordered by score. Names are compared by trigram similarity of\ntransliterated names (Cyrillic and Latin spellings match, order of first and last name does not\nmatter), shared email or phone and the same birth date raise score. Candidates below\nduplicates.min-score are skipped, at most duplicates.limit are returned.",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "duplicates"
+                ],
+                "summary": "Find duplicates of person",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Login of person entity.",
-                        "name": "login",
-                        "in": "query",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
@@ -153,34 +295,59 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/model.PersonResponse"
+                                "$ref": "#/definitions/model.DuplicateResponse"
                             }
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/person/update": {
-            "put": {
-                "description": "Update existing persons",
+        "/v1/person/{id}/merge": {
+            "post": {
+                "description": "Merge duplicate into person in path (survivor). Fields choose source of person fields, contacts,\naddresses, attachments, group and tag memberships and relationships of duplicate are moved to survivor\n(conflicting ones are dropped, survivor keeps its primary contacts), avatar of duplicate is dropped.\nDuplicate is deleted, merge is recorded in history and requests of duplicate id are redirected to\nsurvivor with 308.",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
                 ],
                 "tags": [
-                    "persons"
+                    "duplicates"
                 ],
-                "summary": "Update existing persons",
+                "summary": "Merge duplicate into person",
                 "parameters": [
                     {
-                        "description": "Model for update person entity",
+                        "type": "string",
+                        "description": "ID of surviving person.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Duplicate and sources of fields.",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.PersonRequest"
+                            "$ref": "#/definitions/model.MergeRequest"
                         }
                     }
                 ],
@@ -188,35 +355,110 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.PersonResponse"
-                            }
+                            "$ref": "#/definitions/model.MergeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/persons": {
+        "/v1/persons": {
             "get": {
-                "description": "Load persons",
+                "description": "Load page of persons by 50 rows, login filters persons by login, country and city by address\nCustom attributes are filtered by attr.\u003cname\u003e=value parameters (attr.department=sales), all of them must match.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
                 ],
                 "tags": [
                     "persons"
                 ],
-                "summary": "Load first 50 persons",
+                "summary": "Load persons",
                 "parameters": [
                     {
                         "type": "string",
                         "description": "Page of person table, when load by 50 rows.",
                         "name": "page",
-                        "in": "query",
-                        "required": true
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Login of person entity.",
+                        "name": "login",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 3166-1 alpha-2 country of person address.",
+                        "name": "country",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "City of person address (case-insensitive), matched with country on the same address.",
+                        "name": "city",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Tag of person, repeated parameter requires all tags.",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of group, members of its descendant groups match too.",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimal age of person in full years.",
+                        "name": "minAge",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximal age of person in full years.",
+                        "name": "maxAge",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Embedded sub-resources, comma separated: contacts, addresses.",
+                        "name": "expand",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -228,59 +470,4766 @@ const docTemplate = `{
                                 "$ref": "#/definitions/model.PersonResponse"
                             }
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "model.PersonDeleteResponse": {
-            "description": "Model for response on delete operation.",
-            "type": "object",
-            "properties": {
-                "message": {
-                    "type": "string"
+        },
+        "/v1/persons/export": {
+            "get": {
+                "description": "Stream all persons ordered by id as file, rows are read from server-side cursor and flushed by chunks.\nAccepts the same filters as listing, including attr.\u003cname\u003e=value of custom attributes.",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Export persons",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "File format: csv (default), ndjson, xlsx.",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Login of person entity.",
+                        "name": "login",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 3166-1 alpha-2 country of person address.",
+                        "name": "country",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "City of person address (case-insensitive).",
+                        "name": "city",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Tag of person, repeated parameter requires all tags.",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of group, members of its descendant groups match too.",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimal age of person in full years.",
+                        "name": "minAge",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximal age of person in full years.",
+                        "name": "maxAge",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
                 }
             }
         },
-        "model.PersonRequest": {
-            "description": "Model for create or update person entity.",
-            "type": "object",
-            "properties": {
-                "age": {
-                    "type": "integer"
-                },
-                "firstName": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "lastName": {
-                    "type": "string"
-                },
-                "timestamp": {
-                    "type": "string"
+        "/v1/persons/stream": {
+            "get": {
+                "description": "Server-Sent Events of person changes, event id is id of domain event. Events after Last-Event-ID\nare replayed when client is behind by no more than stream.replay-limit events, otherwise ` + "`" + `reset` + "`" + ` event\nis sent and client must reload persons. Heartbeat comment is sent every stream.heartbeat-interval,\nstream is closed when bearer token expires. WebSocket variant is served on /v1/persons/stream/ws.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Stream person events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of the last received event.",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Id of the last received event, used when header is absent.",
+                        "name": "lastEventId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
                 }
             }
         },
-        "model.PersonResponse": {
-            "description": "Model for response on API operations.",
-            "type": "object",
-            "properties": {
-                "age": {
-                    "type": "integer"
-                },
-                "firstName": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "lastName": {
+        "/v1/webhooks": {
+            "get": {
+                "description": "Load all webhook subscriptions",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Load webhook subscriptions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.WebhookResponse"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create webhook subscription, generated secret is returned only in this response",
+                "consumes": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Create webhook subscription",
+                "parameters": [
+                    {
+                        "description": "Model for create webhook subscription.",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.WebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks/{id}": {
+            "get": {
+                "description": "Find webhook subscription by id",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Find webhook subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of webhook subscription.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.WebhookResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update webhook subscription, empty secret keeps current one",
+                "consumes": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Update webhook subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of webhook subscription.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Model for update webhook subscription.",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.WebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete webhook subscription with its delivery log",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete webhook subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of webhook subscription.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks/{id}/deliveries": {
+            "get": {
+                "description": "Load latest 100 deliveries with attempts, status=dead returns dead-letter store",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Load delivery log of webhook subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of webhook subscription.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Delivery status: pending, delivered, dead.",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.WebhookDeliveryResponse"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks/{id}/deliveries/{deliveryId}/retry": {
+            "post": {
+                "description": "Move delivery from dead-letter store back to queue",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Retry dead webhook delivery",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of webhook subscription.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID of delivery.",
+                        "name": "deliveryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/admin/attributes": {
+            "get": {
+                "description": "Load all attribute definitions ordered by name",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "attributes"
+                ],
+                "summary": "Load attribute definitions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.AttributeDefinitionResponse"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Register custom person attribute, values of persons are validated against it on create and update.",
+                "consumes": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "attributes"
+                ],
+                "summary": "Create attribute definition",
+                "parameters": [
+                    {
+                        "description": "Model of attribute definition.",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.AttributeDefinitionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.AttributeDefinitionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/admin/attributes/{name}": {
+            "get": {
+                "description": "Find attribute definition by name",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "attributes"
+                ],
+                "summary": "Find attribute definition",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name of attribute.",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.AttributeDefinitionResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update required flag, pattern or enum values of attribute, type must not be changed.\nStored values are validated against changed definition when person is written next time.",
+                "consumes": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "attributes"
+                ],
+                "summary": "Update attribute definition",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name of attribute.",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Model of attribute definition.",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.AttributeDefinitionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.AttributeDefinitionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete attribute definition together with values of all persons.",
+                "tags": [
+                    "attributes"
+                ],
+                "summary": "Delete attribute definition",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name of attribute.",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/admin/tenants": {
+            "get": {
+                "description": "Load all tenants ordered by name together with counts of their persons",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "tenants"
+                ],
+                "summary": "Load tenants",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.TenantResponse"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Provision active tenant, its persons are isolated from persons of other tenants.",
+                "consumes": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "tenants"
+                ],
+                "summary": "Create tenant",
+                "parameters": [
+                    {
+                        "description": "Model of tenant.",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.TenantRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.TenantResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/admin/tenants/{id}": {
+            "get": {
+                "description": "Find tenant by id together with count of its persons",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "tenants"
+                ],
+                "summary": "Find tenant",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of tenant.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.TenantResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update name, quota and person validation settings of tenant.\nLowered quota keeps existing persons, new ones are rejected until count is below it.",
+                "consumes": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "tenants"
+                ],
+                "summary": "Update tenant",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of tenant.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Model of tenant.",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.TenantRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.TenantResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/admin/tenants/{id}/resume": {
+            "post": {
+                "description": "Serve requests of suspended tenant again.",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "tenants"
+                ],
+                "summary": "Resume tenant",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of tenant.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.TenantResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/admin/tenants/{id}/suspend": {
+            "post": {
+                "description": "Reject requests of tenant with 403 until it is resumed, data of tenant is kept.",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "tenants"
+                ],
+                "summary": "Suspend tenant",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of tenant.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.TenantResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/groups": {
+            "get": {
+                "description": "Load all groups ordered by name, hierarchy is given by parentId",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Load groups",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.GroupResponse"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create group of persons, group with parentId is child of existing group. Names are unique among siblings.",
+                "consumes": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Create group",
+                "parameters": [
+                    {
+                        "description": "Model of group.",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.GroupRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.GroupResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/groups/{id}": {
+            "get": {
+                "description": "Find group by id",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Find group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of group.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GroupResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Rename group or move it under another parent, group without parentId becomes root group.\nGroup must not be moved under itself or its descendant.",
+                "consumes": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Update group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of group.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Model of group.",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.GroupRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GroupResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete group by groups.delete-mode: restrict responds 409 for group with children or members,\ncascade deletes descendants and memberships together with group. Persons are never deleted.",
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Delete group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of group.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/groups/{id}/members": {
+            "get": {
+                "description": "Load page of group members by 50 rows, members of descendant groups are included with descendants=true",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Load members of group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of group.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Page of members, when load by 50 rows.",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include members of descendant groups.",
+                        "name": "descendants",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.PersonResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add up to api.batch-limit existing persons to group, persons which already are members are skipped.",
+                "consumes": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Add members of group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of group.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Ids of persons.",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.MembersRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.MembersResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove up to api.batch-limit persons from group, persons which are not members are skipped.",
+                "consumes": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Remove members of group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of group.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Ids of persons.",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.MembersRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.MembersResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/persons": {
+            "get": {
+                "description": "Load page of persons by 50 rows, login filters persons by login, country and city by address\nCustom attributes are filtered by attr.\u003cname\u003e=value parameters (attr.department=sales), all of them must match.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Load persons",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Page of person table, when load by 50 rows.",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Login of person entity.",
+                        "name": "login",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 3166-1 alpha-2 country of person address.",
+                        "name": "country",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "City of person address (case-insensitive), matched with country on the same address.",
+                        "name": "city",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Tag of person, repeated parameter requires all tags.",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of group, members of its descendant groups match too.",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimal age of person in full years.",
+                        "name": "minAge",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximal age of person in full years.",
+                        "name": "maxAge",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Embedded sub-resources, comma separated: contacts, addresses.",
+                        "name": "expand",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.PersonResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create new person entity, Location header points to created resource",
+                "consumes": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Create new person entity",
+                "parameters": [
+                    {
+                        "description": "Model for create new person entity.",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.PersonRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key of request, retry with the same key replays first response.",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.PersonResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/persons/batch": {
+            "post": {
+                "description": "Execute up to api.batch-limit operations, results are returned in request order.\nAtomic mode responds 200 when all operations were committed, otherwise status of first failed operation.\nBest-effort mode commits every successful operation and responds 200.",
+                "consumes": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Create, upsert or delete persons in one request",
+                "parameters": [
+                    {
+                        "description": "Operations of batch.",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.PersonBatchRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key of request, retry with the same key replays first response.",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.PersonBatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.PersonBatchResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.PersonBatchResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.PersonBatchResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/persons/export": {
+            "get": {
+                "description": "Stream all persons ordered by id as file, rows are read from server-side cursor and flushed by chunks.\nAccepts the same filters as listing, including attr.\u003cname\u003e=value of custom attributes.",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Export persons",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "File format: csv (default), ndjson, xlsx.",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Login of person entity.",
+                        "name": "login",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 3166-1 alpha-2 country of person address.",
+                        "name": "country",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "City of person address (case-insensitive).",
+                        "name": "city",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Tag of person, repeated parameter requires all tags.",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of group, members of its descendant groups match too.",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimal age of person in full years.",
+                        "name": "minAge",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximal age of person in full years.",
+                        "name": "maxAge",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/persons/import": {
+            "post": {
+                "description": "Stream import file, every row is validated like created person and upserted by id or login.\nFormat is taken from format param or Content-Type (text/csv, application/x-ndjson).\nreport=csv responds with downloadable CSV of rejected rows instead of JSON summary.",
+                "consumes": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Import persons from CSV or NDJSON",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "File format: csv, ndjson.",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Validate and roll back import.",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "CSV delimiter, default is comma, tab for tab.",
+                        "name": "delimiter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "CSV column mapping field:column,... (fields: id, login, firstName, lastName, age).",
+                        "name": "columns",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "csv for downloadable report of rejected rows.",
+                        "name": "report",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.PersonImportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/persons/{id}": {
+            "get": {
+                "description": "Find existing persons",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Find existing persons",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Embedded sub-resources, comma separated: contacts, addresses.",
+                        "name": "expand",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.PersonResponse"
+                        }
+                    },
+                    "308": {
+                        "description": "Person was merged, Location is URL of person it was merged into.",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace existing person, v1 route takes id from body and creates person when id is empty",
+                "consumes": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Update existing persons",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Model for update person entity",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.PersonRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.PersonResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete existing persons",
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Delete existing persons",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID for remove person entity",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Apply JSON Merge Patch (RFC 7396, also used for application/json) or JSON Patch (RFC 6902) to stored person,\nonly changed columns are updated. Failed JSON Patch test operation responds with 409.",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Partially update existing person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Merge patch document or array of JSON Patch operations",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.PersonRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.PersonResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/persons/{id}/addresses": {
+            "get": {
+                "description": "Load postal addresses of person in order of creation",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "addresses"
+                ],
+                "summary": "Load postal addresses of person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.AddressResponse"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add postal address of person, postal code is validated by rules of country. Change of address updates\nupdatedAt of person and writes PersonUpdated event with all addresses of person.",
+                "consumes": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "addresses"
+                ],
+                "summary": "Add postal address of person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Model for create address.",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.AddressRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.AddressResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/persons/{id}/addresses/{addressId}": {
+            "get": {
+                "description": "Find postal address of person by id",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "addresses"
+                ],
+                "summary": "Find postal address of person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of address.",
+                        "name": "addressId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.AddressResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace postal address of person, validation rules are the same as on create",
+                "consumes": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "addresses"
+                ],
+                "summary": "Update postal address of person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of address.",
+                        "name": "addressId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Model for update address.",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.AddressRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.AddressResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete postal address of person",
+                "tags": [
+                    "addresses"
+                ],
+                "summary": "Delete postal address of person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of address.",
+                        "name": "addressId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/persons/{id}/attachments": {
+            "get": {
+                "description": "Load metadata of person attachments in order of upload",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "Load attachments of person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.AttachmentResponse"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Stream document as multipart/form-data field file or as raw body with file name in fileName param or\nContent-Disposition header. Content is scanned before it is stored, SHA-256 is verified against\nX-Checksum-Sha256 header when it is sent. Generic content type is sniffed from content.",
+                "consumes": [
+                    "multipart/form-data",
+                    "application/octet-stream"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "Upload attachment of person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Document of multipart upload.",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "File name of raw upload.",
+                        "name": "fileName",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date (YYYY-MM-DD) after which attachment is purged.",
+                        "name": "retainUntil",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Expected SHA-256 of content in hex.",
+                        "name": "X-Checksum-Sha256",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.AttachmentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/persons/{id}/attachments/{attachmentId}": {
+            "get": {
+                "description": "Find metadata of person attachment by id",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "Find attachment of person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of attachment.",
+                        "name": "attachmentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.AttachmentResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete attachment of person, its content is purged from blob store in background",
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "Delete attachment of person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of attachment.",
+                        "name": "attachmentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/persons/{id}/attachments/{attachmentId}/content": {
+            "get": {
+                "description": "Stream content of attachment, X-Checksum-Sha256 and Digest headers carry its SHA-256 which is ETag too.",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "Download attachment of person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of attachment.",
+                        "name": "attachmentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/persons/{id}/attachments/{attachmentId}/retention": {
+            "put": {
+                "description": "Set date after which attachment is purged, empty retainUntil keeps attachment until it is deleted",
+                "consumes": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "Change retention date of attachment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of attachment.",
+                        "name": "attachmentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Retention date.",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.AttachmentRetentionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.AttachmentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/persons/{id}/avatar": {
+            "get": {
+                "description": "Download original image or square thumbnail of size. Response is cacheable by ETag and Last-Modified,\nIf-None-Match and If-Modified-Since are answered with 304.",
+                "produces": [
+                    "image/jpeg",
+                    "image/png"
+                ],
+                "tags": [
+                    "avatars"
+                ],
+                "summary": "Download avatar of person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Size of thumbnail, original when absent.",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Upload image as multipart/form-data field file or as raw body. Format is sniffed from content (JPEG, PNG, GIF),\nimage is scaled down, thumbnails are made and metadata as EXIF is stripped. Existing avatar is replaced.",
+                "consumes": [
+                    "multipart/form-data",
+                    "image/jpeg",
+                    "image/png",
+                    "image/gif"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "avatars"
+                ],
+                "summary": "Upload avatar of person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Image of multipart upload.",
+                        "name": "file",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.AvatarResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete avatar of person together with its thumbnails",
+                "tags": [
+                    "avatars"
+                ],
+                "summary": "Delete avatar of person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/persons/{id}/chain": {
+            "get": {
+                "description": "Load managers of person from direct one upwards (parents, grandparents for type parent) up to depth levels,\ndepth is limited by relationships.max-depth which is also used when depth is absent",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "relationships"
+                ],
+                "summary": "Load chain of command of person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Hierarchical relationship type: manager (default), parent.",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Levels of tree.",
+                        "name": "depth",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.HierarchyNodeResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/persons/{id}/duplicates": {
+            "get": {
+                "description": "Find persons which may duplicate person ordered by score. Names are compared by trigram similarity of\ntransliterated names (Cyrillic and Latin spellings match, order of first and last name does not\nmatter), shared email or phone and the same birth date raise score. Candidates below\nduplicates.min-score are skipped, at most duplicates.limit are returned.",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "duplicates"
+                ],
+                "summary": "Find duplicates of person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.DuplicateResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/persons/{id}/emails": {
+            "get": {
+                "description": "Load emails of person, primary email goes first",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "contacts"
+                ],
+                "summary": "Load emails of person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.EmailResponse"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add email of person, address must be bare RFC 5322 address, its domain is stored in lower case.\nPrimary email replaces current primary one of person and must not be primary email of another person.",
+                "consumes": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "contacts"
+                ],
+                "summary": "Add email of person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Model for create email.",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.EmailRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.EmailResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/persons/{id}/emails/{emailId}": {
+            "get": {
+                "description": "Find email of person by id",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "contacts"
+                ],
+                "summary": "Find email of person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of email.",
+                        "name": "emailId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.EmailResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace email of person, validation and primary rules are the same as on create",
+                "consumes": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "contacts"
+                ],
+                "summary": "Update email of person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of email.",
+                        "name": "emailId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Model for update email.",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.EmailRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.EmailResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete email of person",
+                "tags": [
+                    "contacts"
+                ],
+                "summary": "Delete email of person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of email.",
+                        "name": "emailId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/persons/{id}/merge": {
+            "post": {
+                "description": "Merge duplicate into person in path (survivor). Fields choose source of person fields, contacts,\naddresses, attachments, group and tag memberships and relationships of duplicate are moved to survivor\n(conflicting ones are dropped, survivor keeps its primary contacts), avatar of duplicate is dropped.\nDuplicate is deleted, merge is recorded in history and requests of duplicate id are redirected to\nsurvivor with 308.",
+                "consumes": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "duplicates"
+                ],
+                "summary": "Merge duplicate into person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of surviving person.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Duplicate and sources of fields.",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.MergeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.MergeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/persons/{id}/merges": {
+            "get": {
+                "description": "Load merges into person in order of merge, merged persons are as they were before merge",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "duplicates"
+                ],
+                "summary": "Load merge history of person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.MergeResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/persons/{id}/phones": {
+            "get": {
+                "description": "Load phones of person, primary phone goes first",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "contacts"
+                ],
+                "summary": "Load phones of person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.PhoneResponse"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add phone of person, number in international format is normalized to E.164 (+ and up to 15 digits).\nPrimary phone replaces current primary one of person.",
+                "consumes": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "contacts"
+                ],
+                "summary": "Add phone of person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Model for create phone.",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.PhoneRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.PhoneResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/persons/{id}/phones/{phoneId}": {
+            "get": {
+                "description": "Find phone of person by id",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "contacts"
+                ],
+                "summary": "Find phone of person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of phone.",
+                        "name": "phoneId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.PhoneResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace phone of person, validation and primary rules are the same as on create",
+                "consumes": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "contacts"
+                ],
+                "summary": "Update phone of person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of phone.",
+                        "name": "phoneId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Model for update phone.",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.PhoneRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.PhoneResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete phone of person",
+                "tags": [
+                    "contacts"
+                ],
+                "summary": "Delete phone of person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of phone.",
+                        "name": "phoneId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/persons/{id}/relationships": {
+            "get": {
+                "description": "Load relationships of person in both directions in order of creation, type filters by type seen from person",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "relationships"
+                ],
+                "summary": "Load relationships of person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Type of relationship seen from person.",
+                        "name": "type",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.RelationshipResponse"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Relate person of personId to person in path as manager, report, parent, child, spouse, sibling or\nemergency_contact. Inverse types are stored as their type in opposite direction. Person has at most\none manager, manager and parent relationships must not make cycle.",
+                "consumes": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "relationships"
+                ],
+                "summary": "Create relationship of person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Model of relationship.",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.RelationshipRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.RelationshipResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/persons/{id}/relationships/{relationshipId}": {
+            "delete": {
+                "description": "Delete relationship of person, relationship created from the other person is deleted too",
+                "tags": [
+                    "relationships"
+                ],
+                "summary": "Delete relationship of person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of relationship.",
+                        "name": "relationshipId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/persons/{id}/reports": {
+            "get": {
+                "description": "Load tree of all reports of person (children for type parent) up to depth levels,\ndepth is limited by relationships.max-depth which is also used when depth is absent",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "relationships"
+                ],
+                "summary": "Load reports of person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of person entity.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Hierarchical relationship type: manager (default), parent.",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Levels of tree.",
+                        "name": "depth",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.HierarchyNodeResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/tags": {
+            "get": {
+                "description": "Load all tags ordered by name with count of tagged persons, tag exists while some person has it",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Load tags",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.TagResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/tags/{tag}/members": {
+            "get": {
+                "description": "Load page of persons with tag by 50 rows",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Load tagged persons",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tag.",
+                        "name": "tag",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Page of persons, when load by 50 rows.",
+                        "name": "page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.PersonResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Tag up to api.batch-limit existing persons, tag is trimmed and lower-cased. Tagged persons are skipped.",
+                "consumes": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Tag persons",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tag.",
+                        "name": "tag",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Ids of persons.",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.MembersRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.MembersResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove tag of up to api.batch-limit persons, persons without tag are skipped.",
+                "consumes": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Untag persons",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tag.",
+                        "name": "tag",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Ids of persons.",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.MembersRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.MembersResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "model.AddressRequest": {
+            "description": "Model for create or update postal address of person, type is one of home, work, billing, shipping, other, country is ISO 3166-1 alpha-2 code, validFrom and validTo are dates (YYYY-MM-DD), absent means open end.",
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string"
+                },
+                "country": {
+                    "type": "string"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "postalCode": {
+                    "type": "string"
+                },
+                "region": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "validFrom": {
+                    "type": "string"
+                },
+                "validTo": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.AddressResponse": {
+            "description": "Model of postal address of person.",
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string"
+                },
+                "country": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "postalCode": {
+                    "type": "string"
+                },
+                "region": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "validFrom": {
+                    "type": "string"
+                },
+                "validTo": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.AttachmentResponse": {
+            "description": "Model of person attachment, content is downloaded from /content. Attachment is purged after retainUntil.",
+            "type": "object",
+            "properties": {
+                "contentType": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "fileName": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "retainUntil": {
+                    "type": "string",
+                    "example": "2030-12-31"
+                },
+                "sha256": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.AttachmentRetentionRequest": {
+            "description": "Model for change retention date of attachment, empty retainUntil keeps attachment until it is deleted.",
+            "type": "object",
+            "properties": {
+                "retainUntil": {
+                    "type": "string",
+                    "example": "2030-12-31"
+                }
+            }
+        },
+        "model.AttributeDefinitionRequest": {
+            "description": "Model for create or update definition of custom person attribute.",
+            "type": "object",
+            "properties": {
+                "enumValues": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "description": "name is taken from path on update",
+                    "type": "string"
+                },
+                "pattern": {
+                    "type": "string"
+                },
+                "required": {
+                    "type": "boolean"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "string",
+                        "int",
+                        "bool",
+                        "date",
+                        "enum"
+                    ]
+                }
+            }
+        },
+        "model.AttributeDefinitionResponse": {
+            "description": "Model of custom person attribute definition.",
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "enumValues": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "pattern": {
+                    "type": "string"
+                },
+                "required": {
+                    "type": "boolean"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.AvatarResponse": {
+            "description": "Model of stored avatar, original fits max dimension and thumbnails are squares of sizes.",
+            "type": "object",
+            "properties": {
+                "contentType": {
+                    "type": "string"
+                },
+                "etag": {
+                    "type": "string"
+                },
+                "height": {
+                    "type": "integer"
+                },
+                "sizes": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "width": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.ContactsResponse": {
+            "description": "Contacts of person embedded by expand=contacts.",
+            "type": "object",
+            "properties": {
+                "emails": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.EmailResponse"
+                    }
+                },
+                "phones": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.PhoneResponse"
+                    }
+                }
+            }
+        },
+        "model.DuplicateResponse": {
+            "description": "Person which may duplicate person in path, score is between 0 and 1. Reasons: similar_name, swapped_name (first and last name are swapped), shared_email, shared_phone, same_birth_date.",
+            "type": "object",
+            "properties": {
+                "person": {
+                    "$ref": "#/definitions/model.PersonResponse"
+                },
+                "reasons": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "score": {
+                    "type": "number",
+                    "example": 0.9
+                }
+            }
+        },
+        "model.EmailRequest": {
+            "description": "Model for create or update email of person, type is one of personal, work, other.",
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "primary": {
+                    "type": "boolean"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "verified": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.EmailResponse": {
+            "description": "Model of email of person.",
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "primary": {
+                    "type": "boolean"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "verified": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.GroupRequest": {
+            "description": "Model for create or update group of persons, group without parentId is root group.",
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parentId": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.GroupResponse": {
+            "description": "Model of group of persons.",
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parentId": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.HierarchyNodeResponse": {
+            "description": "Person of hierarchy at depth from requested person, nodes are the next level. Truncated node has more levels below depth limit.",
+            "type": "object",
+            "properties": {
+                "depth": {
+                    "type": "integer"
+                },
+                "nodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.HierarchyNodeResponse"
+                    }
+                },
+                "person": {
+                    "$ref": "#/definitions/model.PersonResponse"
+                },
+                "truncated": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.MembersRequest": {
+            "description": "Model for bulk add or remove of group members or tagged persons.",
+            "type": "object",
+            "properties": {
+                "personIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "model.MembersResponse": {
+            "description": "Count of persons which were added or removed, persons which already were in requested state are not counted.",
+            "type": "object",
+            "properties": {
+                "changed": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.MergeFields": {
+            "description": "Source of merged fields: survivor (person in path) or duplicate, attributes may be merged from both (survivor wins on the same name). Absent fields keep survivor values except empty login and approximate birth date, attributes are merged from both.",
+            "type": "object",
+            "properties": {
+                "attributes": {
+                    "type": "string",
+                    "enum": [
+                        "survivor",
+                        "duplicate",
+                        "both"
+                    ]
+                },
+                "birthDate": {
+                    "type": "string",
+                    "enum": [
+                        "survivor",
+                        "duplicate"
+                    ]
+                },
+                "firstName": {
+                    "type": "string",
+                    "enum": [
+                        "survivor",
+                        "duplicate"
+                    ]
+                },
+                "lastName": {
+                    "type": "string",
+                    "enum": [
+                        "survivor",
+                        "duplicate"
+                    ]
+                },
+                "login": {
+                    "type": "string",
+                    "enum": [
+                        "survivor",
+                        "duplicate"
+                    ]
+                }
+            }
+        },
+        "model.MergeRequest": {
+            "description": "Model for merge of duplicate person into person in path.",
+            "type": "object",
+            "properties": {
+                "duplicateId": {
+                    "type": "string"
+                },
+                "fields": {
+                    "$ref": "#/definitions/model.MergeFields"
+                }
+            }
+        },
+        "model.MergeResponse": {
+            "description": "Merge of person into survivor, merged is merged person as it was before merge, its id redirects to survivor. Person is survivor after merge, it is present only in response of merge.",
+            "type": "object",
+            "properties": {
+                "fields": {
+                    "$ref": "#/definitions/model.MergeFields"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "merged": {
+                    "$ref": "#/definitions/model.PersonResponse"
+                },
+                "mergedAt": {
+                    "type": "string"
+                },
+                "mergedId": {
+                    "type": "string"
+                },
+                "person": {
+                    "$ref": "#/definitions/model.PersonResponse"
+                },
+                "survivorId": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.PersonBatchOperation": {
+            "description": "Single operation of batch, delete requires only id.",
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "op": {
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "upsert",
+                        "delete"
+                    ]
+                },
+                "person": {
+                    "$ref": "#/definitions/model.PersonRequest"
+                }
+            }
+        },
+        "model.PersonBatchRequest": {
+            "description": "Batch of person operations, atomic mode (default) rolls back all operations on first failure.",
+            "type": "object",
+            "properties": {
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "atomic",
+                        "best-effort"
+                    ]
+                },
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.PersonBatchOperation"
+                    }
+                }
+            }
+        },
+        "model.PersonBatchResponse": {
+            "description": "Results of batch operations in request order.",
+            "type": "object",
+            "properties": {
+                "committed": {
+                    "type": "boolean"
+                },
+                "mode": {
+                    "type": "string"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.PersonBatchResult"
+                    }
+                }
+            }
+        },
+        "model.PersonBatchResult": {
+            "description": "Result of single batch operation, status is http status of the same single request.",
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "op": {
+                    "type": "string"
+                },
+                "person": {
+                    "$ref": "#/definitions/model.PersonResponse"
+                },
+                "status": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.PersonImportError": {
+            "description": "Rejected row of import file.",
+            "type": "object",
+            "properties": {
+                "line": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "row": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.PersonImportResponse": {
+            "description": "Result of import, in dry run counters show what would be imported.",
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "dryRun": {
+                    "type": "boolean"
+                },
+                "rejected": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.PersonImportError"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "updated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.PersonRequest": {
+            "description": "Model for create or update person entity.",
+            "type": "object",
+            "properties": {
+                "age": {
+                    "description": "ignored when birthDate is sent, age alone gives approximate birth date",
+                    "type": "integer"
+                },
+                "attributes": {
+                    "description": "values of attributes defined in registry, absent attributes keep stored ones on update",
+                    "type": "object"
+                },
+                "birthDate": {
+                    "description": "YYYY-MM-DD",
+                    "type": "string"
+                },
+                "birthDateApproximate": {
+                    "description": "birth date echoed from response with approximate flag is ignored, so it stays approximate",
+                    "type": "boolean"
+                },
+                "firstName": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "lastName": {
+                    "type": "string"
+                },
+                "login": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.PersonResponse": {
+            "description": "Model for response on API operations.",
+            "type": "object",
+            "properties": {
+                "addresses": {
+                    "description": "present when requested by expand=addresses and person has addresses, always present in events of address changes",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.AddressResponse"
+                    }
+                },
+                "age": {
+                    "description": "computed from birth date for today",
+                    "type": "integer"
+                },
+                "attributes": {
+                    "type": "object"
+                },
+                "birthDate": {
+                    "description": "YYYY-MM-DD, approximate one was derived from age",
+                    "type": "string"
+                },
+                "birthDateApproximate": {
+                    "type": "boolean"
+                },
+                "contacts": {
+                    "description": "present only when requested by expand=contacts",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.ContactsResponse"
+                        }
+                    ]
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "firstName": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "lastName": {
+                    "type": "string"
+                },
+                "login": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.PhoneRequest": {
+            "description": "Model for create or update phone of person, phone is normalized to E.164, type is one of mobile, home, work, fax, other.",
+            "type": "object",
+            "properties": {
+                "phone": {
+                    "type": "string"
+                },
+                "primary": {
+                    "type": "boolean"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "verified": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.PhoneResponse": {
+            "description": "Model of phone of person.",
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "primary": {
+                    "type": "boolean"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "verified": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.RelationshipRequest": {
+            "description": "Model for create relationship, person of personId is \u003ctype\u003e of person in path (manager, report, parent, child, spouse, sibling, emergency_contact).",
+            "type": "object",
+            "properties": {
+                "personId": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.RelationshipResponse": {
+            "description": "Model of relationship seen from person in path, person of personId is \u003ctype\u003e of it. Incoming relationship was created from the other person, its type is inverse type when there is one.",
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "incoming": {
+                    "type": "boolean"
+                },
+                "personId": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.TagResponse": {
+            "description": "Model of tag with count of tagged persons.",
+            "type": "object",
+            "properties": {
+                "persons": {
+                    "type": "integer"
+                },
+                "tag": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.TenantRequest": {
+            "description": "Model for create or update tenant, zero and empty settings keep defaults of person validation.",
+            "type": "object",
+            "properties": {
+                "loginPattern": {
+                    "type": "string"
+                },
+                "maxAge": {
+                    "type": "integer"
+                },
+                "maxPersons": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "requireLogin": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.TenantResponse": {
+            "description": "Model of tenant with count of its persons.",
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "loginPattern": {
+                    "type": "string"
+                },
+                "maxAge": {
+                    "type": "integer"
+                },
+                "maxPersons": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "persons": {
+                    "type": "integer"
+                },
+                "requireLogin": {
+                    "type": "boolean"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "suspended"
+                    ]
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.WebhookAttemptResponse": {
+            "description": "Model of single delivery attempt.",
+            "type": "object",
+            "properties": {
+                "attemptedAt": {
+                    "type": "string"
+                },
+                "durationMs": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "responseStatus": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.WebhookDeliveryResponse": {
+            "description": "Model of webhook delivery with log of attempts.",
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deliveredAt": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "integer"
+                },
+                "eventType": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lastError": {
+                    "type": "string"
+                },
+                "log": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.WebhookAttemptResponse"
+                    }
+                },
+                "nextAttemptAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.WebhookRequest": {
+            "description": "Model for create or update webhook subscription, empty eventTypes means all events.",
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "eventTypes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.WebhookResponse": {
+            "description": "Model of webhook subscription, secret is returned only when it was created or changed.",
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "eventTypes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "updatedAt": {
                     "type": "string"
                 },
-                "timestamp": {
+                "url": {
                     "type": "string"
                 }
             }
@@ -295,7 +5244,7 @@ const docTemplate = `{
 var SwaggerInfo = &swag.Spec{
 	Version:          "1.0",
 	Host:             "localhost:9902",
-	BasePath:         "/api/v1",
+	BasePath:         "/api",
 	Schemes:          []string{},
 	Title:            "person-service API",
 	Description:      "This is a sample server on go-lang.",
@@ -0,0 +1,44 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"person-service/db/entity"
+	"time"
+)
+
+type Type string
+
+const (
+	PersonCreated Type = "PersonCreated"
+	PersonUpdated Type = "PersonUpdated"
+	PersonDeleted Type = "PersonDeleted"
+)
+
+// Event domain event of person lifecycle, Id is unique and may be used by consumers for deduplication.
+type Event struct {
+	Id         int64           `json:"id"`
+	TenantId   uuid.UUID       `json:"tenantId"`
+	Type       Type            `json:"type"`
+	PersonId   uuid.UUID       `json:"personId"`
+	OccurredAt time.Time       `json:"occurredAt"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+// FromOutbox event of stored outbox row.
+func FromOutbox(e entity.OutboxEvent) Event {
+	return Event{
+		Id:         e.Id,
+		TenantId:   e.TenantId,
+		Type:       Type(e.EventType),
+		PersonId:   e.AggregateId,
+		OccurredAt: e.CreatedAt.UTC(),
+		Payload:    e.Payload,
+	}
+}
+
+// Publisher delivers events to consumers, returned error means event must be delivered again.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
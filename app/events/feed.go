@@ -0,0 +1,249 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"golang.org/x/exp/slog"
+	"person-service/config"
+	"person-service/db/entity"
+	"person-service/db/repository"
+	"person-service/utils"
+	"sync"
+	"time"
+)
+
+const (
+	/* events loaded from outbox by one query of catch up */
+	feedBatchSize = 100
+	/* ids skipped by delivered events which are awaited, larger jump of ids is not tracked beyond it */
+	maxFeedGaps = 10_000
+)
+
+// Feed fans out person events to live connections of this replica. Outbox trigger notifies every replica
+// after commit, feed reloads events written after the last delivered one, so events of other replicas are seen too.
+// Ids are allocated before commit, so events of concurrent transactions may be committed out of id order. Ids skipped
+// by delivered events are remembered as gaps and read again until they are committed or gap timeout passes.
+type Feed struct {
+	logger           *slog.Logger
+	transactions     *repository.TxManager
+	replayLimit      int
+	bufferSize       int
+	reconnectBackoff time.Duration
+	gapTimeout       time.Duration
+
+	mu          sync.Mutex
+	lastId      int64
+	gaps        map[int64]time.Time
+	subscribers map[*Subscription]struct{}
+}
+
+// Subscription events of one connection. Missed events are returned in Replay and precede live events,
+// channel of live events is closed when connection is too slow or feed is stopped.
+type Subscription struct {
+	/* events after Last-Event-ID of client */
+	Replay []Event
+	/* client is behind by more than replay limit and must reload state */
+	Reset bool
+
+	feed   *Feed
+	events chan Event
+	/* subscription of tenant receives only events of tenant */
+	tenantId  uuid.UUID
+	hasTenant bool
+}
+
+func NewFeed(logger *slog.Logger, transactions *repository.TxManager, stream config.Stream) *Feed {
+	return &Feed{
+		logger:           logger.With(slog.String("op", "events.feed")),
+		transactions:     transactions,
+		replayLimit:      stream.ReplayLimit,
+		bufferSize:       stream.BufferSize,
+		reconnectBackoff: stream.ReconnectBackoff,
+		gapTimeout:       stream.GapTimeout,
+		gaps:             make(map[int64]time.Time),
+		subscribers:      make(map[*Subscription]struct{}),
+	}
+}
+
+// Start remembers the latest event and listens for notifications until ctx is done,
+// connection is re-established after failure and events committed meanwhile are delivered.
+func (f *Feed) Start(ctx context.Context) error {
+	const op = "events.Feed.Start"
+
+	lastId, err := f.transactions.Repositories().Outbox.LastId(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	f.lastId = lastId
+
+	go f.run(ctx)
+	return nil
+}
+
+func (f *Feed) run(ctx context.Context) {
+	f.logger.Info("Change feed started")
+
+	for {
+		err := f.transactions.Listen(ctx, repository.PersonEventsChannel, func() { f.catchUp(ctx) })
+		if ctx.Err() != nil {
+			f.closeAll()
+			f.logger.Info("Change feed stopped")
+			return
+		}
+		f.logger.Error("Failed to listen person events", utils.Err(err))
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(f.reconnectBackoff):
+		}
+	}
+}
+
+// catchUp broadcasts events written after the last delivered one and events filling gaps, it is called by
+// listening goroutine only.
+func (f *Feed) catchUp(ctx context.Context) {
+	f.mu.Lock()
+	afterId := f.readFrom(time.Now())
+	f.mu.Unlock()
+
+	for {
+		loaded, err := f.transactions.Repositories().Outbox.FetchAfter(ctx, afterId, nil, feedBatchSize)
+		if err != nil {
+			f.logger.Error("Failed to load person events", utils.Err(err))
+			return
+		}
+
+		f.mu.Lock()
+		for _, event := range f.accept(loaded, time.Now()) {
+			for subscription := range f.subscribers {
+				if subscription.hasTenant && subscription.tenantId != event.TenantId {
+					continue
+				}
+				select {
+				case subscription.events <- event:
+				default:
+					f.logger.Warn("Subscriber is too slow, subscription is closed", slog.Int64("event_id", event.Id))
+					f.remove(subscription)
+				}
+			}
+		}
+		f.mu.Unlock()
+
+		if len(loaded) < feedBatchSize {
+			return
+		}
+		afterId = loaded[len(loaded)-1].Id
+	}
+}
+
+// readFrom id events are read after, it precedes the oldest awaited gap. Gaps older than gap timeout belong to
+// rolled back transactions and are forgotten. Must be called under lock.
+func (f *Feed) readFrom(now time.Time) int64 {
+	afterId := f.lastId
+	for id, skippedAt := range f.gaps {
+		if now.Sub(skippedAt) > f.gapTimeout {
+			delete(f.gaps, id)
+		} else if id <= afterId {
+			afterId = id - 1
+		}
+	}
+	return afterId
+}
+
+// accept events of loaded batch which were not delivered yet, ids skipped by them become gaps.
+// Must be called under lock.
+func (f *Feed) accept(loaded []entity.OutboxEvent, now time.Time) []Event {
+	var accepted []Event
+	for _, e := range loaded {
+		if e.Id > f.lastId {
+			for id := f.lastId + 1; id < e.Id && len(f.gaps) < maxFeedGaps; id++ {
+				f.gaps[id] = now
+			}
+			f.lastId = e.Id
+		} else if _, awaited := f.gaps[e.Id]; awaited {
+			delete(f.gaps, e.Id)
+		} else {
+			continue
+		}
+		accepted = append(accepted, FromOutbox(e))
+	}
+	return accepted
+}
+
+// Subscribe registers connection. Events after lastEventId are loaded from outbox when client is behind
+// by no more than replay limit, zero lastEventId means live events only. Connection of tenant (see repository.WithTenant)
+// receives events of tenant only.
+func (f *Feed) Subscribe(ctx context.Context, lastEventId int64) (*Subscription, error) {
+	const op = "events.Feed.Subscribe"
+
+	subscription := &Subscription{feed: f, events: make(chan Event, f.bufferSize)}
+	subscription.tenantId, subscription.hasTenant = repository.TenantOf(ctx)
+
+	f.mu.Lock()
+	liveFrom := f.lastId
+	/* events filling gaps are delivered as live ones */
+	awaited := make(map[int64]bool, len(f.gaps))
+	for id := range f.gaps {
+		awaited[id] = true
+	}
+	f.subscribers[subscription] = struct{}{}
+	f.mu.Unlock()
+
+	/* ids may have gaps, so distance is the upper bound of missed events */
+	if lastEventId <= 0 || lastEventId >= liveFrom {
+		return subscription, nil
+	}
+	if liveFrom-lastEventId > int64(f.replayLimit) {
+		subscription.Reset = true
+		return subscription, nil
+	}
+
+	loaded, err := f.transactions.Repositories().Outbox.FetchAfter(ctx, lastEventId, nil, f.replayLimit)
+	if err != nil {
+		subscription.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	for _, e := range loaded {
+		/* later events are delivered as live ones */
+		if e.Id > liveFrom {
+			break
+		}
+		if awaited[e.Id] {
+			continue
+		}
+		subscription.Replay = append(subscription.Replay, FromOutbox(e))
+	}
+
+	return subscription, nil
+}
+
+// Events live events of subscription.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Close unsubscribes connection, it is safe to call after subscription was closed by feed.
+func (s *Subscription) Close() {
+	s.feed.mu.Lock()
+	defer s.feed.mu.Unlock()
+
+	s.feed.remove(s)
+}
+
+/* must be called under lock */
+func (f *Feed) remove(subscription *Subscription) {
+	if _, ok := f.subscribers[subscription]; ok {
+		delete(f.subscribers, subscription)
+		close(subscription.events)
+	}
+}
+
+func (f *Feed) closeAll() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for subscription := range f.subscribers {
+		f.remove(subscription)
+	}
+}
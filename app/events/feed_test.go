@@ -0,0 +1,45 @@
+package events
+
+import (
+	"github.com/stretchr/testify/assert"
+	"person-service/db/entity"
+	"testing"
+	"time"
+)
+
+func Test_FeedGaps(t *testing.T) {
+	ids := func(events []Event) []int64 {
+		result := make([]int64, 0, len(events))
+		for _, event := range events {
+			result = append(result, event.Id)
+		}
+		return result
+	}
+	outbox := func(ids ...int64) []entity.OutboxEvent {
+		result := make([]entity.OutboxEvent, 0, len(ids))
+		for _, id := range ids {
+			result = append(result, entity.OutboxEvent{Id: id, EventType: string(PersonCreated)})
+		}
+		return result
+	}
+	now := time.Now()
+
+	t.Run("event committed after larger id must be delivered", func(t *testing.T) {
+		feed := &Feed{lastId: 10, gaps: make(map[int64]time.Time), gapTimeout: time.Minute}
+
+		assert.Equal(t, []int64{11, 13}, ids(feed.accept(outbox(11, 13), now)))
+		assert.Equal(t, int64(11), feed.readFrom(now))
+		assert.Equal(t, []int64{12}, ids(feed.accept(outbox(12, 13), now)))
+		assert.Equal(t, int64(13), feed.readFrom(now))
+	})
+
+	t.Run("gap must be forgotten after timeout", func(t *testing.T) {
+		feed := &Feed{lastId: 10, gaps: make(map[int64]time.Time), gapTimeout: time.Minute}
+
+		feed.accept(outbox(12), now)
+
+		assert.Equal(t, int64(10), feed.readFrom(now))
+		assert.Equal(t, int64(12), feed.readFrom(now.Add(2*time.Minute)))
+		assert.Empty(t, feed.accept(outbox(12), now))
+	})
+}
@@ -0,0 +1,76 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_Publishers(t *testing.T) {
+	event := Event{
+		Id:         1,
+		Type:       PersonCreated,
+		PersonId:   uuid.New(),
+		OccurredAt: time.Now().UTC(),
+		Payload:    json.RawMessage(`{"firstName":"Алексей"}`),
+	}
+
+	t.Run("webhook publisher must post event", func(t *testing.T) {
+		var received Event
+		var eventType string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			eventType = r.Header.Get("X-Event-Type")
+			body, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(body, &received)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		err := NewWebhookPublisher(server.URL, server.Client()).Publish(context.Background(), event)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "PersonCreated", eventType)
+		assert.Equal(t, event.PersonId, received.PersonId)
+	})
+
+	t.Run("webhook publisher must fail on not 2xx status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		err := NewWebhookPublisher(server.URL, server.Client()).Publish(context.Background(), event)
+
+		assert.EqualError(t, err, "webhook responded with status 503")
+	})
+
+	t.Run("writer publisher must write json line", func(t *testing.T) {
+		var buffer bytes.Buffer
+
+		err := NewWriterPublisher(&buffer).Publish(context.Background(), event)
+
+		assert.NoError(t, err)
+		assert.Contains(t, buffer.String(), `"type":"PersonCreated"`)
+		assert.Equal(t, byte('\n'), buffer.Bytes()[buffer.Len()-1])
+	})
+
+	t.Run("multi publisher must publish to others when one failed", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+		var buffer bytes.Buffer
+
+		err := MultiPublisher{NewWebhookPublisher(server.URL, server.Client()), NewWriterPublisher(&buffer)}.Publish(context.Background(), event)
+
+		assert.EqualError(t, err, "webhook responded with status 503")
+		assert.Contains(t, buffer.String(), `"type":"PersonCreated"`)
+	})
+}
@@ -0,0 +1,105 @@
+package events
+
+import (
+	"context"
+	"golang.org/x/exp/slog"
+	"person-service/config"
+	"person-service/db/entity"
+	"person-service/db/repository"
+	"person-service/utils"
+	"time"
+)
+
+// Relay moves events from outbox table to publisher with at-least-once delivery.
+// Events of one person are published strictly in order they were written.
+type Relay struct {
+	logger       *slog.Logger
+	transactions *repository.TxManager
+	publisher    Publisher
+	batchSize    int
+	pollInterval time.Duration
+	retryBackoff time.Duration
+	maxBackoff   time.Duration
+	lease        time.Duration
+}
+
+func NewRelay(logger *slog.Logger, transactions *repository.TxManager, publisher Publisher, outbox config.Outbox) *Relay {
+	return &Relay{
+		logger:       logger.With(slog.String("op", "events.relay")),
+		transactions: transactions,
+		publisher:    publisher,
+		batchSize:    outbox.BatchSize,
+		pollInterval: outbox.PollInterval,
+		retryBackoff: outbox.RetryBackoff,
+		maxBackoff:   outbox.MaxBackoff,
+		lease:        outbox.Lease,
+	}
+}
+
+// Run polls outbox until ctx is done.
+func (r *Relay) Run(ctx context.Context) {
+	r.logger.Info("Outbox relay started")
+
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		/* drain outbox until nothing is ready, then wait for next tick */
+		for {
+			processed, err := r.ProcessBatch(ctx)
+			if err != nil {
+				r.logger.Error("Failed to process outbox batch", utils.Err(err))
+				break
+			}
+			if processed == 0 {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			r.logger.Info("Outbox relay stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessBatch publish one batch of pending events, returns count of claimed events. Events are claimed
+// in one transaction, published outside of any transaction and result of every one is recorded in its own transaction,
+// so slow publisher holds neither locks nor connections.
+func (r *Relay) ProcessBatch(ctx context.Context) (int, error) {
+	var pending []entity.OutboxEvent
+	err := r.transactions.WithinTransaction(ctx, repository.TxOptions{}, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		var err error
+		pending, err = uow.Outbox.ClaimPending(ctx, r.batchSize, r.lease)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	/* claimed events are the oldest unpublished ones of their persons, so failed one delays nothing else of batch */
+	for _, e := range pending {
+		if err := r.record(ctx, e, r.publisher.Publish(ctx, FromOutbox(e))); err != nil {
+			return len(pending), err
+		}
+	}
+
+	return len(pending), nil
+}
+
+// record mark event published or postpone its next attempt.
+func (r *Relay) record(ctx context.Context, e entity.OutboxEvent, publishErr error) error {
+	return r.transactions.WithinTransaction(ctx, repository.TxOptions{}, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		if publishErr == nil {
+			return uow.Outbox.MarkPublished(ctx, e.Id)
+		}
+		r.logger.Warn("Failed to publish event",
+			slog.Int64("event_id", e.Id),
+			slog.Int("attempts", e.Attempts+1),
+			utils.Err(publishErr),
+		)
+		return uow.Outbox.MarkFailed(ctx, e.Id, publishErr.Error(), utils.Backoff(r.retryBackoff, r.maxBackoff, e.Attempts))
+	})
+}
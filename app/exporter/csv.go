@@ -0,0 +1,44 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"io"
+	"person-service/db/entity"
+	"strconv"
+	"time"
+)
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+// NewCSVWriter writer of CSV with header row, timestamps are written in RFC 3339 UTC.
+func NewCSVWriter(w io.Writer) (Writer, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return nil, err
+	}
+	return &csvWriter{writer: writer}, nil
+}
+
+func (c *csvWriter) Write(person entity.Person) error {
+	return c.writer.Write([]string{
+		person.Id.String(),
+		person.Login,
+		person.FirstName,
+		person.LastName,
+		strconv.Itoa(person.Age),
+		exactBirthDate(person),
+		person.CreatedAt.UTC().Format(time.RFC3339Nano),
+		person.UpdatedAt.UTC().Format(time.RFC3339Nano),
+	})
+}
+
+func (c *csvWriter) Flush() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+func (c *csvWriter) Close() error {
+	return c.Flush()
+}
@@ -0,0 +1,63 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"person-service/db/entity"
+	"person-service/mappers"
+)
+
+const (
+	FormatCsv    = "csv"
+	FormatNdjson = "ndjson"
+	FormatXlsx   = "xlsx"
+)
+
+/* birthDate is written only when it is exact, so exported file is imported back without turning approximations into dates */
+var header = []string{"id", "login", "firstName", "lastName", "age", "birthDate", "createdAt", "updatedAt"}
+
+// Writer streaming writer of persons, Close writes end of document and must be called after last person.
+type Writer interface {
+	Write(person entity.Person) error
+	// Flush write buffered rows to underlying writer.
+	Flush() error
+	Close() error
+}
+
+// Format content type and file extension of export format.
+type Format struct {
+	ContentType string
+	Extension   string
+	new         func(w io.Writer) (Writer, error)
+}
+
+var formats = map[string]Format{
+	FormatCsv:    {ContentType: "text/csv; charset=utf-8", Extension: "csv", new: NewCSVWriter},
+	FormatNdjson: {ContentType: "application/x-ndjson", Extension: "ndjson", new: NewNDJSONWriter},
+	FormatXlsx: {
+		ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		Extension:   "xlsx",
+		new:         NewXLSXWriter,
+	},
+}
+
+// Lookup find export format by name.
+func Lookup(name string) (Format, error) {
+	format, ok := formats[name]
+	if !ok {
+		return Format{}, fmt.Errorf("unknown export format %q, supported: csv, ndjson, xlsx", name)
+	}
+	return format, nil
+}
+
+// NewWriter create writer of format.
+func (f Format) NewWriter(w io.Writer) (Writer, error) {
+	return f.new(w)
+}
+
+func exactBirthDate(person entity.Person) string {
+	if person.BirthDate == nil || person.BirthDateApproximate {
+		return ""
+	}
+	return person.BirthDate.Format(mappers.DateLayout)
+}
@@ -0,0 +1,86 @@
+package exporter
+
+import (
+	"archive/zip"
+	"bytes"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"person-service/db/entity"
+	"strings"
+	"testing"
+	"time"
+)
+
+func export(t *testing.T, name string, persons ...entity.Person) []byte {
+	format, err := Lookup(name)
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	writer, err := format.NewWriter(&out)
+	assert.NoError(t, err)
+	for _, person := range persons {
+		assert.NoError(t, writer.Write(person))
+		assert.NoError(t, writer.Flush())
+	}
+	assert.NoError(t, writer.Close())
+
+	return out.Bytes()
+}
+
+func Test_Writers(t *testing.T) {
+	id := uuid.MustParse("7d444840-9dc0-11d1-b245-5ffdce74fad2")
+	timestamp := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	birthDate := time.Date(2006, 3, 15, 0, 0, 0, 0, time.UTC)
+	person := entity.Person{Id: &id, Login: "a.sidorov", FirstName: "Алексей", LastName: "Сидоров <&>", Age: 18,
+		BirthDate: &birthDate, CreatedAt: timestamp, UpdatedAt: timestamp}
+
+	t.Run("csv contains header and rows", func(t *testing.T) {
+		out := string(export(t, FormatCsv, person))
+
+		assert.Equal(t,
+			"id,login,firstName,lastName,age,birthDate,createdAt,updatedAt\n"+
+				"7d444840-9dc0-11d1-b245-5ffdce74fad2,a.sidorov,Алексей,Сидоров <&>,18,2006-03-15,2024-05-01T10:00:00Z,2024-05-01T10:00:00Z\n",
+			out)
+	})
+
+	t.Run("ndjson contains one person per line", func(t *testing.T) {
+		out := string(export(t, FormatNdjson, person, person))
+
+		assert.Equal(t, 2, strings.Count(out, "\n"))
+		assert.Contains(t, out, `"firstName":"Алексей"`)
+		assert.Contains(t, out, `"birthDate":"2006-03-15"`)
+	})
+
+	t.Run("xlsx is zip package with escaped sheet", func(t *testing.T) {
+		out := export(t, FormatXlsx, person)
+
+		archive, err := zip.NewReader(bytes.NewReader(out), int64(len(out)))
+		assert.NoError(t, err)
+
+		var names []string
+		var sheet string
+		for _, file := range archive.File {
+			names = append(names, file.Name)
+			if file.Name == "xl/worksheets/sheet1.xml" {
+				reader, err := file.Open()
+				assert.NoError(t, err)
+				content, _ := io.ReadAll(reader)
+				sheet = string(content)
+			}
+		}
+
+		assert.ElementsMatch(t, []string{
+			"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml",
+		}, names)
+		assert.Equal(t, 2, strings.Count(sheet, "<row>"))
+		assert.Contains(t, sheet, "Сидоров &lt;&amp;&gt;")
+		assert.Contains(t, sheet, "<c><v>18</v></c>")
+		assert.True(t, strings.HasSuffix(sheet, "</sheetData></worksheet>"))
+	})
+
+	t.Run("unknown format is rejected", func(t *testing.T) {
+		_, err := Lookup("pdf")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,32 @@
+package exporter
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"person-service/db/entity"
+	"person-service/mappers"
+)
+
+type ndjsonWriter struct {
+	buffer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+// NewNDJSONWriter writer of one person response per line.
+func NewNDJSONWriter(w io.Writer) (Writer, error) {
+	buffer := bufio.NewWriter(w)
+	return &ndjsonWriter{buffer: buffer, encoder: json.NewEncoder(buffer)}, nil
+}
+
+func (n *ndjsonWriter) Write(person entity.Person) error {
+	return n.encoder.Encode(mappers.ToPersonResponse(person))
+}
+
+func (n *ndjsonWriter) Flush() error {
+	return n.buffer.Flush()
+}
+
+func (n *ndjsonWriter) Close() error {
+	return n.Flush()
+}
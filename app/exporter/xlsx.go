@@ -0,0 +1,118 @@
+package exporter
+
+import (
+	"archive/zip"
+	"bufio"
+	"compress/flate"
+	"encoding/xml"
+	"io"
+	"person-service/db/entity"
+	"strconv"
+	"time"
+)
+
+/* minimal package parts of SpreadsheetML workbook with one sheet */
+var xlsxParts = []struct{ name, content string }{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="persons" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+type xlsxWriter struct {
+	archive *zip.Writer
+	/* compressor of sheet entry, flushed together with buffer */
+	compressor *flate.Writer
+	sheet      *bufio.Writer
+}
+
+// NewXLSXWriter writer of XLSX workbook, rows of sheet are streamed as inline strings,
+// so memory does not depend on number of rows.
+func NewXLSXWriter(w io.Writer) (Writer, error) {
+	x := &xlsxWriter{archive: zip.NewWriter(w)}
+	x.archive.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
+		compressor, err := flate.NewWriter(out, flate.DefaultCompression)
+		x.compressor = compressor
+		return compressor, err
+	})
+
+	for _, part := range xlsxParts {
+		entry, err := x.archive.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(entry, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	entry, err := x.archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	x.sheet = bufio.NewWriter(entry)
+	_, _ = x.sheet.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	err = x.writeRow(func() {
+		for _, name := range header {
+			x.writeString(name)
+		}
+	})
+	return x, err
+}
+
+func (x *xlsxWriter) Write(person entity.Person) error {
+	return x.writeRow(func() {
+		x.writeString(person.Id.String())
+		x.writeString(person.Login)
+		x.writeString(person.FirstName)
+		x.writeString(person.LastName)
+		_, _ = x.sheet.WriteString(`<c><v>` + strconv.Itoa(person.Age) + `</v></c>`)
+		x.writeString(exactBirthDate(person))
+		x.writeString(person.CreatedAt.UTC().Format(time.RFC3339Nano))
+		x.writeString(person.UpdatedAt.UTC().Format(time.RFC3339Nano))
+	})
+}
+
+func (x *xlsxWriter) Flush() error {
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+	if err := x.compressor.Flush(); err != nil {
+		return err
+	}
+	return x.archive.Flush()
+}
+
+func (x *xlsxWriter) Close() error {
+	_, _ = x.sheet.WriteString(`</sheetData></worksheet>`)
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+	return x.archive.Close()
+}
+
+// writeRow write row element, bufio.Writer keeps first error, so it is returned by the last write.
+func (x *xlsxWriter) writeRow(cells func()) error {
+	_, _ = x.sheet.WriteString(`<row>`)
+	cells()
+	_, err := x.sheet.WriteString(`</row>`)
+	return err
+}
+
+func (x *xlsxWriter) writeString(value string) {
+	_, _ = x.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+	_ = xml.EscapeText(x.sheet, []byte(value))
+	_, _ = x.sheet.WriteString(`</t></is></c>`)
+}
@@ -0,0 +1,54 @@
+package graph
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"person-service/db/repository"
+	"time"
+)
+
+/* cursor is opaque for clients, it keeps sort value of the last person of page and its id */
+type cursorPayload struct {
+	Value any       `json:"v,omitempty"`
+	Id    uuid.UUID `json:"id"`
+}
+
+func encodeCursor(cursor repository.PersonCursor) string {
+	payload, _ := json.Marshal(cursorPayload{Value: cursor.Value, Id: cursor.Id})
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+// decodeCursor restores cursor of sort, value is converted to type of sort column.
+func decodeCursor(encoded string, sort repository.PersonSort) (*repository.PersonCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("cursor is not valid")
+	}
+
+	var payload cursorPayload
+	if err = json.Unmarshal(raw, &payload); err != nil {
+		return nil, fmt.Errorf("cursor is not valid")
+	}
+
+	cursor := &repository.PersonCursor{Id: payload.Id}
+	switch sort.Field {
+	case repository.SortByFirstName, repository.SortByLastName:
+		value, ok := payload.Value.(string)
+		if !ok {
+			return nil, fmt.Errorf("cursor does not match sort")
+		}
+		cursor.Value = value
+	case repository.SortByAge, repository.SortByCreatedAt:
+		/* persons are sorted by age through birth date */
+		value, ok := payload.Value.(string)
+		if !ok {
+			return nil, fmt.Errorf("cursor does not match sort")
+		}
+		if cursor.Value, err = time.Parse(time.RFC3339Nano, value); err != nil {
+			return nil, fmt.Errorf("cursor does not match sort")
+		}
+	}
+	return cursor, nil
+}
@@ -0,0 +1,68 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"golang.org/x/exp/slog"
+	"person-service/services"
+	"person-service/utils"
+)
+
+const uniqueViolation = "23505"
+
+// error codes returned in extensions.code of graphql errors
+const (
+	CodeBadUserInput  = "BAD_USER_INPUT"
+	CodeNotFound      = "NOT_FOUND"
+	CodeConflict      = "CONFLICT"
+	CodeInternal      = "INTERNAL"
+	CodeLimitExceeded = "QUERY_LIMIT_EXCEEDED"
+	CodeBadRequest    = "BAD_REQUEST"
+	CodeForbidden     = "FORBIDDEN"
+)
+
+// Error graphql error with code in extensions, the same classification as http status of REST handlers.
+type Error struct {
+	Message string
+	Code    string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Extensions() map[string]any {
+	return map[string]any{"code": e.Code}
+}
+
+func badUserInput(msg string) error {
+	return &Error{Message: msg, Code: CodeBadUserInput}
+}
+
+// toError maps service errors to graphql errors, unexpected errors are logged and hidden from client.
+func toError(logger *slog.Logger, err error, msg string) error {
+	var validationErr *services.ValidationError
+	var pgErr *pgconn.PgError
+
+	switch {
+	case errors.As(err, &validationErr):
+		logger.Error("Person request is not valid", utils.Err(err))
+		return badUserInput(validationErr.Message)
+	case errors.Is(err, pgx.ErrNoRows):
+		logger.Error("Person not found", utils.Err(err))
+		return &Error{Message: "Person not found", Code: CodeNotFound}
+	case errors.As(err, &pgErr) && pgErr.Code == uniqueViolation:
+		logger.Error("Person conflicts with existing one", utils.Err(err))
+		return &Error{Message: "Person with the same login already exists", Code: CodeConflict}
+	case errors.Is(err, services.ErrQuotaExceeded):
+		logger.Error("Quota of tenant is exceeded", utils.Err(err))
+		return &Error{Message: "Quota of persons is exceeded", Code: CodeForbidden}
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return &Error{Message: err.Error(), Code: CodeInternal}
+	default:
+		logger.Error(msg, utils.Err(err))
+		return &Error{Message: msg, Code: CodeInternal}
+	}
+}
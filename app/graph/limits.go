@@ -0,0 +1,127 @@
+package graph
+
+import (
+	"fmt"
+	"github.com/graphql-go/graphql/language/ast"
+	"person-service/config"
+	"strconv"
+	"strings"
+)
+
+// operationLimits walks fields of executed operation, depth counts nested selection sets and complexity counts
+// fields, fields of list selected with first argument are counted first times. Introspection fields are free.
+type operationLimits struct {
+	fragments   map[string]*ast.FragmentDefinition
+	variables   map[string]any
+	maxPageSize int
+}
+
+// checkLimits returns error when operation exceeds max depth or max complexity.
+func checkLimits(document *ast.Document, operation *ast.OperationDefinition, variables map[string]any, limits config.Graphql) error {
+	walker := operationLimits{
+		fragments:   make(map[string]*ast.FragmentDefinition),
+		variables:   variables,
+		maxPageSize: limits.MaxPageSize,
+	}
+	for _, definition := range document.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			walker.fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	if depth := walker.depth(operation.SelectionSet); depth > limits.MaxDepth {
+		return fmt.Errorf("query depth %d exceeds max depth %d", depth, limits.MaxDepth)
+	}
+	if complexity := walker.complexity(operation.SelectionSet); complexity > limits.MaxComplexity {
+		return fmt.Errorf("query complexity %d exceeds max complexity %d", complexity, limits.MaxComplexity)
+	}
+	return nil
+}
+
+func (l operationLimits) depth(selectionSet *ast.SelectionSet) int {
+	if selectionSet == nil {
+		return 0
+	}
+
+	max := 0
+	for _, selection := range l.selections(selectionSet) {
+		if strings.HasPrefix(selection.Name.Value, "__") {
+			continue
+		}
+		if depth := 1 + l.depth(selection.SelectionSet); depth > max {
+			max = depth
+		}
+	}
+	return max
+}
+
+func (l operationLimits) complexity(selectionSet *ast.SelectionSet) int {
+	if selectionSet == nil {
+		return 0
+	}
+
+	total := 0
+	for _, selection := range l.selections(selectionSet) {
+		if strings.HasPrefix(selection.Name.Value, "__") {
+			continue
+		}
+		total += 1 + l.multiplier(selection)*l.complexity(selection.SelectionSet)
+	}
+	return total
+}
+
+// selections fields of selection set with fragments expanded, validation guarantees fragments exist without cycles.
+func (l operationLimits) selections(selectionSet *ast.SelectionSet) []*ast.Field {
+	var fields []*ast.Field
+	for _, selection := range selectionSet.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			fields = append(fields, selection)
+		case *ast.InlineFragment:
+			fields = append(fields, l.selections(selection.SelectionSet)...)
+		case *ast.FragmentSpread:
+			if fragment, ok := l.fragments[selection.Name.Value]; ok {
+				fields = append(fields, l.selections(fragment.SelectionSet)...)
+			}
+		}
+	}
+	return fields
+}
+
+/*
+	page size of connection field clamped to 0..max page size, other fields are selected once; out of range
+
+first is rejected by resolver, it must not make complexity negative or overflow it here
+*/
+func (l operationLimits) multiplier(field *ast.Field) int {
+	if field.Name.Value != "persons" {
+		return 1
+	}
+
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != "first" {
+			continue
+		}
+		switch value := argument.Value.(type) {
+		case *ast.IntValue:
+			if size, err := strconv.ParseFloat(value.Value, 64); err == nil {
+				return l.pageSize(size)
+			}
+		case *ast.Variable:
+			if size, ok := l.variables[value.Name.Value].(float64); ok {
+				return l.pageSize(size)
+			}
+		}
+	}
+	return defaultPageSize
+}
+
+func (l operationLimits) pageSize(size float64) int {
+	switch {
+	case size < 0:
+		return 0
+	case size > float64(l.maxPageSize):
+		return l.maxPageSize
+	}
+	return int(size)
+}
@@ -0,0 +1,113 @@
+package graph
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"person-service/db/entity"
+	"person-service/mappers"
+	"person-service/model"
+	"person-service/services"
+	"sync"
+)
+
+/* result of single key, missing key is not an error */
+type loaded[V any] struct {
+	value V
+	found bool
+	err   error
+}
+
+// batchLoader collects keys requested while one level of query is resolved and loads all of them
+// with single fetch when the first result is needed, results are cached until end of request.
+type batchLoader[K comparable, V any] struct {
+	mu      sync.Mutex
+	fetch   func(ctx context.Context, keys []K) (map[K]V, error)
+	pending []K
+	results map[K]loaded[V]
+}
+
+func newBatchLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *batchLoader[K, V] {
+	return &batchLoader[K, V]{fetch: fetch, results: make(map[K]loaded[V])}
+}
+
+// Load registers key and returns thunk, graphql executor calls thunks after all fields of the level are resolved.
+func (l *batchLoader[K, V]) Load(ctx context.Context, key K) func() (V, bool, error) {
+	l.mu.Lock()
+	if _, ok := l.results[key]; !ok && !l.isPending(key) {
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, bool, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if _, ok := l.results[key]; !ok {
+			l.dispatch(ctx)
+		}
+		result := l.results[key]
+		return result.value, result.found, result.err
+	}
+}
+
+// Prime caches value loaded by other query, e.g. persons of listing.
+func (l *batchLoader[K, V]) Prime(key K, value V) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.results[key] = loaded[V]{value: value, found: true}
+}
+
+func (l *batchLoader[K, V]) dispatch(ctx context.Context) {
+	keys := l.pending
+	l.pending = nil
+
+	values, err := l.fetch(ctx, keys)
+	for _, key := range keys {
+		value, found := values[key]
+		l.results[key] = loaded[V]{value: value, found: found, err: err}
+	}
+}
+
+func (l *batchLoader[K, V]) isPending(key K) bool {
+	for _, pending := range l.pending {
+		if pending == key {
+			return true
+		}
+	}
+	return false
+}
+
+// loaders batch loaders of one request.
+type loaders struct {
+	personsById    *batchLoader[uuid.UUID, model.PersonResponse]
+	personsByLogin *batchLoader[string, model.PersonResponse]
+}
+
+type loadersKey struct{}
+
+func newLoaders(service *services.PersonService) *loaders {
+	return &loaders{
+		personsById: newBatchLoader(func(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]model.PersonResponse, error) {
+			persons, err := service.FindPersonsByIds(ctx, ids)
+			return indexPersons(persons, func(person model.PersonResponse) uuid.UUID { return person.Id }), err
+		}),
+		personsByLogin: newBatchLoader(func(ctx context.Context, logins []string) (map[string]model.PersonResponse, error) {
+			persons, err := service.FindPersonsByLogins(ctx, logins)
+			return indexPersons(persons, func(person model.PersonResponse) string { return person.Login }), err
+		}),
+	}
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+func indexPersons[K comparable](persons []entity.Person, key func(person model.PersonResponse) K) map[K]model.PersonResponse {
+	index := make(map[K]model.PersonResponse, len(persons))
+	for _, person := range persons {
+		response := mappers.ToPersonResponse(person)
+		index[key(response)] = response
+	}
+	return index
+}
@@ -0,0 +1,320 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"golang.org/x/exp/slog"
+	"person-service/config"
+	"person-service/db/repository"
+	"person-service/mappers"
+	"person-service/services"
+)
+
+/* page size of persons connection without first argument */
+const defaultPageSize = 50
+
+// Request graphql request, POST body or parameters of GET request.
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// Schema graphql schema of persons, it shares validation and events with REST handlers.
+type Schema struct {
+	schema  graphql.Schema
+	logger  *slog.Logger
+	service *services.PersonService
+	limits  config.Graphql
+}
+
+func NewSchema(logger *slog.Logger, service *services.PersonService, limits config.Graphql) (*Schema, error) {
+	const op = "graph.NewSchema"
+
+	s := &Schema{logger: logger, service: service, limits: limits}
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query:    s.queryType(),
+		Mutation: s.mutationType(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	s.schema = schema
+
+	return s, nil
+}
+
+// Execute validates request, checks depth and complexity of operation and executes it with loaders of the request.
+// Mutations are executed only when allowMutation is set, GET requests must not change state.
+func (s *Schema) Execute(ctx context.Context, req Request, allowMutation bool) *graphql.Result {
+	document, err := parser.Parse(parser.ParseParams{Source: req.Query})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	validation := graphql.ValidateDocument(&s.schema, document, nil)
+	if !validation.IsValid {
+		return &graphql.Result{Errors: validation.Errors}
+	}
+
+	/* unknown operation is reported by executor */
+	if operation := findOperation(document, req.OperationName); operation != nil {
+		if operation.Operation == ast.OperationTypeMutation && !allowMutation {
+			return errorResult(&Error{Message: "Mutations are allowed only with POST", Code: CodeBadRequest})
+		}
+		if err = checkLimits(document, operation, req.Variables, s.limits); err != nil {
+			return errorResult(&Error{Message: err.Error(), Code: CodeLimitExceeded})
+		}
+	}
+
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        s.schema,
+		AST:           document,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       context.WithValue(ctx, loadersKey{}, newLoaders(s.service)),
+	})
+}
+
+func findOperation(document *ast.Document, name string) *ast.OperationDefinition {
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if name == "" || operation.Name != nil && operation.Name.Value == name {
+			return operation
+		}
+	}
+	return nil
+}
+
+func errorResult(err *Error) *graphql.Result {
+	formatted := gqlerrors.FormatError(err)
+	formatted.Extensions = err.Extensions()
+	return &graphql.Result{Errors: []gqlerrors.FormattedError{formatted}}
+}
+
+func (s *Schema) queryType() *graphql.Object {
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"person": &graphql.Field{
+				Type: personType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: s.resolvePerson,
+			},
+			"personByLogin": &graphql.Field{
+				Type: personType,
+				Args: graphql.FieldConfigArgument{
+					"login": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: s.resolvePersonByLogin,
+			},
+			"persons": &graphql.Field{
+				Type: graphql.NewNonNull(personConnectionType),
+				Args: graphql.FieldConfigArgument{
+					"filter": &graphql.ArgumentConfig{Type: personFilterInput},
+					"sort":   &graphql.ArgumentConfig{Type: personSortInput},
+					"first":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultPageSize},
+					"after":  &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: s.resolvePersons,
+			},
+		},
+	})
+}
+
+func (s *Schema) mutationType() *graphql.Object {
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createPerson": &graphql.Field{
+				Type: graphql.NewNonNull(personType),
+				Args: graphql.FieldConfigArgument{
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(personInput)},
+				},
+				Resolve: s.resolveCreatePerson,
+			},
+			"updatePerson": &graphql.Field{
+				Type: graphql.NewNonNull(personType),
+				Args: graphql.FieldConfigArgument{
+					"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(personInput)},
+				},
+				Resolve: s.resolveUpdatePerson,
+			},
+			"deletePerson": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: s.resolveDeletePerson,
+			},
+		},
+	})
+}
+
+// resolvePerson returns thunk, persons requested by the same query are loaded together.
+func (s *Schema) resolvePerson(p graphql.ResolveParams) (any, error) {
+	const op = "graphql.person"
+	logger := s.logger.With(slog.String("op", op))
+
+	id, err := parseId(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+
+	load := loadersFrom(p.Context).personsById.Load(p.Context, id)
+	return func() (any, error) {
+		person, found, err := load()
+		if err != nil {
+			return nil, toError(logger, err, fmt.Sprintf("Error while find entity with id %s", id))
+		}
+		if !found {
+			return nil, nil
+		}
+		return person, nil
+	}, nil
+}
+
+func (s *Schema) resolvePersonByLogin(p graphql.ResolveParams) (any, error) {
+	const op = "graphql.personByLogin"
+	logger := s.logger.With(slog.String("op", op))
+
+	login, _ := p.Args["login"].(string)
+	if login == "" {
+		return nil, badUserInput("Argument login must not be empty")
+	}
+
+	load := loadersFrom(p.Context).personsByLogin.Load(p.Context, login)
+	return func() (any, error) {
+		person, found, err := load()
+		if err != nil {
+			return nil, toError(logger, err, fmt.Sprintf("Error while find person by login, with %s", login))
+		}
+		if !found {
+			return nil, nil
+		}
+		return person, nil
+	}, nil
+}
+
+// resolvePersons loads one more person than requested to know whether next page exists.
+func (s *Schema) resolvePersons(p graphql.ResolveParams) (any, error) {
+	const op = "graphql.persons"
+	logger := s.logger.With(slog.String("op", op))
+
+	first, _ := p.Args["first"].(int)
+	if first < 0 || first > s.limits.MaxPageSize {
+		return nil, badUserInput(fmt.Sprintf("Argument first must be between 0 and %d", s.limits.MaxPageSize))
+	}
+
+	filter := toFilter(p.Args["filter"])
+	sort := toSort(p.Args["sort"])
+	var after *repository.PersonCursor
+	if encoded, ok := p.Args["after"].(string); ok && encoded != "" {
+		cursor, err := decodeCursor(encoded, sort)
+		if err != nil {
+			return nil, badUserInput(err.Error())
+		}
+		after = cursor
+	}
+
+	persons, err := s.service.SearchPersons(p.Context, filter, sort, after, first+1)
+	if err != nil {
+		return nil, toError(logger, err, "Error while search persons")
+	}
+
+	connection := personConnection{Edges: []personEdge{}}
+	if len(persons) > first {
+		connection.PageInfo.HasNextPage = true
+		persons = persons[:first]
+	}
+
+	loaders := loadersFrom(p.Context)
+	for _, person := range persons {
+		response := mappers.ToPersonResponse(person)
+		loaders.personsById.Prime(response.Id, response)
+		connection.Edges = append(connection.Edges, personEdge{Cursor: encodeCursor(sort.CursorOf(person)), Node: response})
+	}
+	if len(connection.Edges) > 0 {
+		connection.PageInfo.EndCursor = connection.Edges[len(connection.Edges)-1].Cursor
+	}
+
+	return connection, nil
+}
+
+func (s *Schema) resolveCreatePerson(p graphql.ResolveParams) (any, error) {
+	const op = "graphql.createPerson"
+	logger := s.logger.With(slog.String("op", op))
+
+	person, err := mappers.ToPerson(toPersonRequest(p.Args["input"]))
+	if err != nil {
+		return nil, badUserInput(err.Error())
+	}
+	saved, err := s.service.CreatePerson(p.Context, person)
+	if err != nil {
+		return nil, toError(logger, err, "Error while save new entity")
+	}
+
+	logger.Info("Successfully save new person", slog.String("id", saved.Id.String()))
+	return mappers.ToPersonResponse(saved), nil
+}
+
+func (s *Schema) resolveUpdatePerson(p graphql.ResolveParams) (any, error) {
+	const op = "graphql.updatePerson"
+	logger := s.logger.With(slog.String("op", op))
+
+	id, err := parseId(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+
+	request := toPersonRequest(p.Args["input"])
+	request.Id = id
+	person, err := mappers.ToPerson(request)
+	if err != nil {
+		return nil, badUserInput(err.Error())
+	}
+	updated, err := s.service.UpdatePerson(p.Context, person)
+	if err != nil {
+		return nil, toError(logger, err, "Error while update entity")
+	}
+
+	logger.Info("Successfully update person", slog.String("id", id.String()))
+	return mappers.ToPersonResponse(updated), nil
+}
+
+func (s *Schema) resolveDeletePerson(p graphql.ResolveParams) (any, error) {
+	const op = "graphql.deletePerson"
+	logger := s.logger.With(slog.String("op", op))
+
+	id, err := parseId(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = s.service.DeletePerson(p.Context, id); err != nil {
+		return nil, toError(logger, err, fmt.Sprintf("Error while delete entity with id %s", id))
+	}
+
+	logger.Info("Person with id was successfully deleted", slog.String("id", id.String()))
+	return id.String(), nil
+}
+
+func parseId(arg any) (uuid.UUID, error) {
+	value, _ := arg.(string)
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, badUserInput(fmt.Sprintf("Argument id is not valid uuid: %s", value))
+	}
+	return id, nil
+}
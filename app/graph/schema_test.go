@@ -0,0 +1,172 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/exp/slog"
+	"io"
+	"person-service/config"
+	"person-service/db/repository"
+	"testing"
+	"time"
+)
+
+func Test_Limits(t *testing.T) {
+	/* service is not reached, requests are rejected before execution */
+	schema, err := NewSchema(slog.New(slog.NewTextHandler(io.Discard, nil)), nil, config.Graphql{
+		MaxDepth: 3, MaxComplexity: 30, MaxPageSize: 100,
+	})
+	assert.NoError(t, err)
+
+	code := func(t *testing.T, req Request, allowMutation bool) any {
+		result := schema.Execute(context.Background(), req, allowMutation)
+		assert.Nil(t, result.Data)
+		assert.Len(t, result.Errors, 1)
+		return result.Errors[0].Extensions["code"]
+	}
+
+	t.Run("must reject too deep query", func(t *testing.T) {
+		assert.Equal(t, CodeLimitExceeded, code(t, Request{
+			Query: `{ persons { edges { node { id } } } }`,
+		}, true))
+	})
+
+	t.Run("must count fragments in depth", func(t *testing.T) {
+		assert.Equal(t, CodeLimitExceeded, code(t, Request{
+			Query: `query { persons { ...page } } fragment page on PersonConnection { edges { node { id } } }`,
+		}, true))
+	})
+
+	t.Run("must multiply complexity by page size of variable", func(t *testing.T) {
+		assert.Equal(t, CodeLimitExceeded, code(t, Request{
+			Query:     `query ($first: Int) { persons(first: $first) { pageInfo { hasNextPage endCursor } } }`,
+			Variables: map[string]any{"first": float64(20)},
+		}, true))
+	})
+
+	t.Run("must not let negative page size lower complexity", func(t *testing.T) {
+		walker := operationLimits{variables: map[string]any{"first": float64(-1_000_000)}, maxPageSize: 100}
+		field := &ast.Field{
+			Name:      &ast.Name{Value: "persons"},
+			Arguments: []*ast.Argument{{Name: &ast.Name{Value: "first"}, Value: &ast.Variable{Name: &ast.Name{Value: "first"}}}},
+		}
+		assert.Equal(t, 0, walker.multiplier(field))
+
+		walker.variables["first"] = float64(1e300)
+		assert.Equal(t, 100, walker.multiplier(field))
+
+		assert.Equal(t, CodeLimitExceeded, code(t, Request{
+			Query: `{ a: persons(first: -100) { pageInfo { hasNextPage endCursor } }
+				b: persons(first: 20) { pageInfo { hasNextPage endCursor } } }`,
+		}, true))
+	})
+
+	t.Run("must reject mutation without permission", func(t *testing.T) {
+		assert.Equal(t, CodeBadRequest, code(t, Request{
+			Query: `mutation { deletePerson(id: "6ba7b810-9dad-11d1-80b4-00c04fd430c8") }`,
+		}, false))
+	})
+
+	t.Run("must reject query not matching schema", func(t *testing.T) {
+		result := schema.Execute(context.Background(), Request{Query: `{ person(id: "1") { unknown } }`}, true)
+		assert.True(t, result.HasErrors())
+	})
+
+	t.Run("must not count introspection", func(t *testing.T) {
+		result := schema.Execute(context.Background(), Request{
+			Query: `{ __schema { types { name fields { name type { name ofType { name } } } } } }`,
+		}, false)
+		assert.False(t, result.HasErrors())
+	})
+}
+
+func Test_Cursor(t *testing.T) {
+	id := uuid.New()
+
+	t.Run("must restore typed value of sort", func(t *testing.T) {
+		createdAt := time.Date(2023, 5, 1, 10, 30, 0, 123456000, time.UTC)
+		sorts := map[repository.PersonSortField]any{
+			repository.SortByFirstName: "Анна",
+			repository.SortByAge:       time.Date(1981, 2, 28, 0, 0, 0, 0, time.UTC),
+			repository.SortByCreatedAt: createdAt,
+			repository.SortById:        nil,
+		}
+		for field, value := range sorts {
+			sort := repository.PersonSort{Field: field}
+			cursor, err := decodeCursor(encodeCursor(repository.PersonCursor{Value: value, Id: id}), sort)
+			assert.NoError(t, err)
+			assert.Equal(t, id, cursor.Id)
+			assert.Equal(t, value, cursor.Value)
+		}
+	})
+
+	t.Run("must reject cursor of other sort", func(t *testing.T) {
+		encoded := encodeCursor(repository.PersonCursor{Value: "Анна", Id: id})
+		_, err := decodeCursor(encoded, repository.PersonSort{Field: repository.SortByAge})
+		assert.Error(t, err)
+	})
+
+	t.Run("must reject malformed cursor", func(t *testing.T) {
+		_, err := decodeCursor("not a cursor", repository.PersonSort{Field: repository.SortById})
+		assert.Error(t, err)
+	})
+}
+
+func Test_BatchLoader(t *testing.T) {
+	var batches [][]int
+	loader := newBatchLoader(func(ctx context.Context, keys []int) (map[int]string, error) {
+		batches = append(batches, keys)
+		values := make(map[int]string)
+		for _, key := range keys {
+			if key > 0 {
+				values[key] = "value"
+			}
+		}
+		return values, nil
+	})
+
+	t.Run("must load pending keys with single fetch", func(t *testing.T) {
+		first := loader.Load(context.Background(), 1)
+		second := loader.Load(context.Background(), 2)
+		missing := loader.Load(context.Background(), -1)
+		loader.Load(context.Background(), 1)
+
+		value, found, err := first()
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, "value", value)
+
+		_, found, _ = second()
+		assert.True(t, found)
+		_, found, _ = missing()
+		assert.False(t, found)
+
+		assert.Equal(t, [][]int{{1, 2, -1}}, batches)
+	})
+
+	t.Run("must not fetch cached and primed keys", func(t *testing.T) {
+		loader.Prime(3, "primed")
+
+		value, _, _ := loader.Load(context.Background(), 3)()
+		assert.Equal(t, "primed", value)
+		_, _, _ = loader.Load(context.Background(), 2)()
+
+		assert.Len(t, batches, 1)
+	})
+
+	t.Run("must return fetch error for all keys of batch", func(t *testing.T) {
+		failing := newBatchLoader(func(ctx context.Context, keys []int) (map[int]string, error) {
+			return nil, errors.New("connection refused")
+		})
+		first := failing.Load(context.Background(), 1)
+		second := failing.Load(context.Background(), 2)
+
+		_, _, err := first()
+		assert.Error(t, err)
+		_, _, err = second()
+		assert.Error(t, err)
+	})
+}
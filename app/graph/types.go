@@ -0,0 +1,184 @@
+package graph
+
+import (
+	"github.com/graphql-go/graphql"
+	"person-service/db/repository"
+	"person-service/model"
+	"strings"
+)
+
+/* Person objects are resolved from model.PersonResponse, fields without resolver are read by json tags */
+var personType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Person",
+	Fields: graphql.Fields{
+		"id": &graphql.Field{
+			Type: graphql.NewNonNull(graphql.ID),
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(model.PersonResponse).Id.String(), nil
+			},
+		},
+		"firstName": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"lastName":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"age":       &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"birthDate": &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.String),
+			Description: "YYYY-MM-DD, approximate one is derived from age.",
+		},
+		"birthDateApproximate": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+		"login": &graphql.Field{
+			Type: graphql.String,
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				if login := p.Source.(model.PersonResponse).Login; login != "" {
+					return login, nil
+				}
+				return nil, nil
+			},
+		},
+		"createdAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+		"updatedAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+	},
+})
+
+type personConnection struct {
+	Edges    []personEdge `json:"edges"`
+	PageInfo pageInfo     `json:"pageInfo"`
+}
+
+type personEdge struct {
+	Cursor string               `json:"cursor"`
+	Node   model.PersonResponse `json:"node"`
+}
+
+type pageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+var personEdgeType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "PersonEdge",
+	Fields: graphql.Fields{
+		"cursor": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"node":   &graphql.Field{Type: graphql.NewNonNull(personType)},
+	},
+})
+
+var pageInfoType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "PageInfo",
+	Fields: graphql.Fields{
+		"hasNextPage": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+		"endCursor": &graphql.Field{
+			Type: graphql.String,
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				if cursor := p.Source.(pageInfo).EndCursor; cursor != "" {
+					return cursor, nil
+				}
+				return nil, nil
+			},
+		},
+	},
+})
+
+var personConnectionType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "PersonConnection",
+	Fields: graphql.Fields{
+		"edges":    &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(personEdgeType)))},
+		"pageInfo": &graphql.Field{Type: graphql.NewNonNull(pageInfoType)},
+	},
+})
+
+var personFilterInput = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name:        "PersonFilter",
+	Description: "Filters are combined with AND, names are matched by case-insensitive prefix, country and city match the same address.",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"login":     &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"firstName": &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"lastName":  &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"minAge":    &graphql.InputObjectFieldConfig{Type: graphql.Int},
+		"maxAge":    &graphql.InputObjectFieldConfig{Type: graphql.Int},
+		"country":   &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"city":      &graphql.InputObjectFieldConfig{Type: graphql.String},
+	},
+})
+
+var personSortFieldEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "PersonSortField",
+	Values: graphql.EnumValueConfigMap{
+		"ID":         &graphql.EnumValueConfig{Value: string(repository.SortById)},
+		"FIRST_NAME": &graphql.EnumValueConfig{Value: string(repository.SortByFirstName)},
+		"LAST_NAME":  &graphql.EnumValueConfig{Value: string(repository.SortByLastName)},
+		"AGE":        &graphql.EnumValueConfig{Value: string(repository.SortByAge)},
+		"CREATED_AT": &graphql.EnumValueConfig{Value: string(repository.SortByCreatedAt)},
+	},
+})
+
+var sortDirectionEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "SortDirection",
+	Values: graphql.EnumValueConfigMap{
+		"ASC":  &graphql.EnumValueConfig{Value: "ASC"},
+		"DESC": &graphql.EnumValueConfig{Value: "DESC"},
+	},
+})
+
+var personSortInput = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "PersonSort",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"field":     &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(personSortFieldEnum)},
+		"direction": &graphql.InputObjectFieldConfig{Type: sortDirectionEnum, DefaultValue: "ASC"},
+	},
+})
+
+var personInput = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "PersonInput",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"firstName": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		"lastName":  &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		"age":       &graphql.InputObjectFieldConfig{Type: graphql.Int, DefaultValue: 0},
+		"birthDate": &graphql.InputObjectFieldConfig{Type: graphql.String, Description: "YYYY-MM-DD, age is ignored when it is set."},
+		"login":     &graphql.InputObjectFieldConfig{Type: graphql.String},
+	},
+})
+
+// toFilter converts PersonFilter argument, absent argument disables all filters.
+func toFilter(arg any) repository.PersonFilter {
+	values, _ := arg.(map[string]any)
+
+	var filter repository.PersonFilter
+	filter.Login, _ = values["login"].(string)
+	filter.FirstName, _ = values["firstName"].(string)
+	filter.LastName, _ = values["lastName"].(string)
+	if minAge, ok := values["minAge"].(int); ok {
+		filter.MinAge = &minAge
+	}
+	if maxAge, ok := values["maxAge"].(int); ok {
+		filter.MaxAge = &maxAge
+	}
+	country, _ := values["country"].(string)
+	filter.Country = strings.ToUpper(country)
+	filter.City, _ = values["city"].(string)
+	return filter
+}
+
+// toSort converts PersonSort argument, persons are sorted by id without it.
+func toSort(arg any) repository.PersonSort {
+	values, _ := arg.(map[string]any)
+
+	sort := repository.PersonSort{Field: repository.SortById}
+	if field, ok := values["field"].(string); ok {
+		sort.Field = repository.PersonSortField(field)
+	}
+	sort.Desc = values["direction"] == "DESC"
+	return sort
+}
+
+// toPersonRequest converts PersonInput argument.
+func toPersonRequest(arg any) model.PersonRequest {
+	values, _ := arg.(map[string]any)
+
+	var request model.PersonRequest
+	request.FirstName, _ = values["firstName"].(string)
+	request.LastName, _ = values["lastName"].(string)
+	request.Age, _ = values["age"].(int)
+	request.BirthDate, _ = values["birthDate"].(string)
+	request.Login, _ = values["login"].(string)
+	return request
+}
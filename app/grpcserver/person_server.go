@@ -0,0 +1,267 @@
+package grpcserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"golang.org/x/exp/slog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"person-service/db/entity"
+	"person-service/db/repository"
+	"person-service/events"
+	"person-service/mappers"
+	"person-service/model"
+	personv1 "person-service/proto/person/v1"
+	"person-service/services"
+	"person-service/utils"
+	"time"
+)
+
+const uniqueViolation = "23505"
+
+const (
+	/* rows fetched from cursor of ListPersons at once */
+	listFetchSize = 100
+	/* events loaded by one poll of WatchPersons */
+	watchBatchSize = 100
+)
+
+var eventTypes = map[events.Type]personv1.PersonEvent_Type{
+	events.PersonCreated: personv1.PersonEvent_TYPE_CREATED,
+	events.PersonUpdated: personv1.PersonEvent_TYPE_UPDATED,
+	events.PersonDeleted: personv1.PersonEvent_TYPE_DELETED,
+}
+
+// PersonServer grpc adapter of person service, it shares validation and events with http handlers.
+type PersonServer struct {
+	personv1.UnimplementedPersonServiceServer
+	logger            *slog.Logger
+	service           *services.PersonService
+	watchPollInterval time.Duration
+}
+
+func NewPersonServer(logger *slog.Logger, service *services.PersonService, watchPollInterval time.Duration) *PersonServer {
+	return &PersonServer{logger: logger, service: service, watchPollInterval: watchPollInterval}
+}
+
+func (s *PersonServer) CreatePerson(ctx context.Context, req *personv1.CreatePersonRequest) (*personv1.Person, error) {
+	const op = "grpc.createPerson"
+	logger := s.logger.With(slog.String("op", op))
+
+	if req.GetPerson() == nil {
+		return nil, status.Error(codes.InvalidArgument, "Field person is required")
+	}
+	id := uuid.Nil
+	if req.GetPerson().GetId() != "" {
+		var err error
+		if id, err = parseId(req.GetPerson().GetId()); err != nil {
+			return nil, err
+		}
+	}
+
+	saved, err := s.service.CreatePerson(ctx, mappers.FromPersonProto(id, req.GetPerson()))
+	if err != nil {
+		return nil, toStatus(logger, err, "Error while save new entity")
+	}
+
+	logger.Info("Successfully save new person", slog.String("id", saved.Id.String()))
+	return mappers.ToPersonProto(saved), nil
+}
+
+func (s *PersonServer) GetPerson(ctx context.Context, req *personv1.GetPersonRequest) (*personv1.Person, error) {
+	const op = "grpc.getPerson"
+	logger := s.logger.With(slog.String("op", op))
+
+	id, err := parseId(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	person, err := s.service.FindPersonById(ctx, &id)
+	if err != nil {
+		return nil, toStatus(logger, err, fmt.Sprintf("Error while find entity with id %s", id))
+	}
+	return mappers.ToPersonProto(person), nil
+}
+
+func (s *PersonServer) GetPersonByLogin(ctx context.Context, req *personv1.GetPersonByLoginRequest) (*personv1.Person, error) {
+	const op = "grpc.getPersonByLogin"
+	logger := s.logger.With(slog.String("op", op))
+
+	if req.GetLogin() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Field login is required")
+	}
+
+	person, err := s.service.FindPersonByLogin(ctx, req.GetLogin())
+	if err != nil {
+		return nil, toStatus(logger, err, fmt.Sprintf("Error while find person by login, with %s", req.GetLogin()))
+	}
+	return mappers.ToPersonProto(person), nil
+}
+
+func (s *PersonServer) UpdatePerson(ctx context.Context, req *personv1.UpdatePersonRequest) (*personv1.Person, error) {
+	const op = "grpc.updatePerson"
+	logger := s.logger.With(slog.String("op", op))
+
+	if req.GetPerson() == nil {
+		return nil, status.Error(codes.InvalidArgument, "Field person is required")
+	}
+	id, err := parseId(req.GetPerson().GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.service.UpdatePerson(ctx, mappers.FromPersonProto(id, req.GetPerson()))
+	if err != nil {
+		return nil, toStatus(logger, err, "Error while update entity")
+	}
+
+	logger.Info("Successfully update person", slog.String("id", id.String()))
+	return mappers.ToPersonProto(updated), nil
+}
+
+func (s *PersonServer) DeletePerson(ctx context.Context, req *personv1.DeletePersonRequest) (*emptypb.Empty, error) {
+	const op = "grpc.deletePerson"
+	logger := s.logger.With(slog.String("op", op))
+
+	id, err := parseId(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.service.DeletePerson(ctx, id); err != nil {
+		return nil, toStatus(logger, err, fmt.Sprintf("Error while delete entity with id %s", id))
+	}
+
+	logger.Info("Person with id was successfully deleted", slog.String("id", id.String()))
+	return &emptypb.Empty{}, nil
+}
+
+// ListPersons streams persons from server-side cursor like export does.
+func (s *PersonServer) ListPersons(req *personv1.ListPersonsRequest, stream personv1.PersonService_ListPersonsServer) error {
+	const op = "grpc.listPersons"
+	logger := s.logger.With(slog.String("op", op))
+
+	filter := repository.PersonFilter{Login: req.GetLogin()}
+	err := s.service.ExportPersons(stream.Context(), filter, listFetchSize, func(person entity.Person) error {
+		return stream.Send(mappers.ToPersonProto(person))
+	})
+	if err != nil {
+		return toStatus(logger, err, "Error while loading persons")
+	}
+	return nil
+}
+
+// WatchPersons polls outbox for person events and streams them until client cancels the call.
+// Events of concurrent transactions may be committed out of id order, so watcher relies on
+// events being written in short transactions.
+func (s *PersonServer) WatchPersons(req *personv1.WatchPersonsRequest, stream personv1.PersonService_WatchPersonsServer) error {
+	const op = "grpc.watchPersons"
+	logger := s.logger.With(slog.String("op", op))
+	ctx := stream.Context()
+
+	var personId *uuid.UUID
+	if req.GetPersonId() != "" {
+		id, err := parseId(req.GetPersonId())
+		if err != nil {
+			return err
+		}
+		personId = &id
+	}
+
+	afterId := req.GetAfterEventId()
+	if afterId <= 0 {
+		lastId, err := s.service.LastEventId(ctx)
+		if err != nil {
+			return toStatus(logger, err, "Error while start watch")
+		}
+		afterId = lastId
+	}
+
+	ticker := time.NewTicker(s.watchPollInterval)
+	defer ticker.Stop()
+
+	for {
+		loaded, err := s.service.LoadEvents(ctx, afterId, personId, watchBatchSize)
+		if err != nil {
+			return toStatus(logger, err, "Error while load events")
+		}
+
+		for _, event := range loaded {
+			message, err := toPersonEvent(event)
+			if err != nil {
+				return toStatus(logger, err, fmt.Sprintf("Error while decode event %d", event.Id))
+			}
+			if err := stream.Send(message); err != nil {
+				return err
+			}
+			afterId = event.Id
+		}
+
+		/* full batch means more events are waiting */
+		if len(loaded) == watchBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func toPersonEvent(event events.Event) (*personv1.PersonEvent, error) {
+	var person model.PersonResponse
+	if err := json.Unmarshal(event.Payload, &person); err != nil {
+		return nil, err
+	}
+
+	return &personv1.PersonEvent{
+		Id:        event.Id,
+		Type:      eventTypes[event.Type],
+		OccurTime: timestamppb.New(event.OccurredAt),
+		Person:    mappers.PersonResponseToProto(person),
+	}, nil
+}
+
+func parseId(value string) (uuid.UUID, error) {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, status.Error(codes.InvalidArgument, fmt.Sprintf("Field id is not valid uuid: %s", value))
+	}
+	return id, nil
+}
+
+// toStatus maps service errors to grpc codes, the same way http handlers map them to statuses.
+func toStatus(logger *slog.Logger, err error, msg string) error {
+	var validationErr *services.ValidationError
+	var pgErr *pgconn.PgError
+
+	switch {
+	case errors.As(err, &validationErr):
+		logger.Error("Person request is not valid", utils.Err(err))
+		return status.Error(codes.InvalidArgument, validationErr.Message)
+	case errors.Is(err, pgx.ErrNoRows):
+		logger.Error("Person not found", utils.Err(err))
+		return status.Error(codes.NotFound, "Person not found")
+	case errors.As(err, &pgErr) && pgErr.Code == uniqueViolation:
+		logger.Error("Person conflicts with existing one", utils.Err(err))
+		return status.Error(codes.AlreadyExists, "Person with the same login already exists")
+	case errors.Is(err, services.ErrQuotaExceeded):
+		logger.Error("Quota of tenant is exceeded", utils.Err(err))
+		return status.Error(codes.ResourceExhausted, "Quota of persons is exceeded")
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	default:
+		logger.Error(msg, utils.Err(err))
+		return status.Error(codes.Internal, msg)
+	}
+}
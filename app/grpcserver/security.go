@@ -0,0 +1,62 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/rsa"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"person-service/utils"
+)
+
+// authorizationKey metadata key of bearer token, grpc metadata keys are lower case.
+const authorizationKey = "authorization"
+
+// UnaryJwtValidation rejects calls without valid bearer token in authorization metadata.
+func UnaryJwtValidation(key *rsa.PublicKey) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		token, err := authenticate(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		return handler(context.WithValue(ctx, tokenKey{}, token), req)
+	}
+}
+
+// StreamJwtValidation rejects streams without valid bearer token in authorization metadata.
+func StreamJwtValidation(key *rsa.PublicKey) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		token, err := authenticate(ss.Context(), key)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: context.WithValue(ss.Context(), tokenKey{}, token)})
+	}
+}
+
+// tokenKey ctx key of validated bearer token, tenancy interceptors read claims of it.
+type tokenKey struct{}
+
+func authenticate(ctx context.Context, key *rsa.PublicKey) (utils.BearerToken, error) {
+	values := metadata.ValueFromIncomingContext(ctx, authorizationKey)
+	if len(values) == 0 || values[0] == "" {
+		return utils.BearerToken{}, status.Error(codes.Unauthenticated, "Unauthorized")
+	}
+
+	token, err := utils.ValidateBearerToken(key, values[0])
+	if err != nil {
+		return utils.BearerToken{}, status.Error(codes.Unauthenticated, "Unauthorized")
+	}
+	return token, nil
+}
+
+// contextStream server stream with ctx replaced by interceptor.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
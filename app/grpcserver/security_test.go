@@ -0,0 +1,87 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/exp/slog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"io"
+	"net"
+	"person-service/config"
+	personv1 "person-service/proto/person/v1"
+	"testing"
+	"time"
+)
+
+func Test_JwtValidation(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	/* service is not reached, id is validated before it */
+	client := newClient(t, NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, &key.PublicKey, config.Grpc{WatchPollInterval: time.Second}, config.Tenancy{}))
+	request := &personv1.GetPersonRequest{Id: "not-uuid"}
+
+	t.Run("must reject call without token", func(t *testing.T) {
+		_, err := client.GetPerson(context.Background(), request)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("must reject token signed by other key", func(t *testing.T) {
+		other, err := rsa.GenerateKey(rand.Reader, 2048)
+		assert.NoError(t, err)
+
+		_, err = client.GetPerson(withToken(t, other, "user"), request)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("must pass call with valid token", func(t *testing.T) {
+		_, err := client.GetPerson(withToken(t, key, "user"), request)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("must reject stream without token", func(t *testing.T) {
+		stream, err := client.ListPersons(context.Background(), &personv1.ListPersonsRequest{})
+		assert.NoError(t, err)
+		_, err = stream.Recv()
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("must pass stream with valid token", func(t *testing.T) {
+		stream, err := client.WatchPersons(withToken(t, key, "user"), &personv1.WatchPersonsRequest{PersonId: "not-uuid"})
+		assert.NoError(t, err)
+		_, err = stream.Recv()
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func newClient(t *testing.T, server *grpc.Server) personv1.PersonServiceClient {
+	listener := bufconn.Listen(1024 * 1024)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return personv1.NewPersonServiceClient(conn)
+}
+
+func withToken(t *testing.T, key *rsa.PrivateKey, subject string) context.Context {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.RegisteredClaims{Subject: subject}).SignedString(key)
+	assert.NoError(t, err)
+
+	return metadata.AppendToOutgoingContext(context.Background(), authorizationKey, "Bearer "+token)
+}
@@ -0,0 +1,64 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/rsa"
+	"golang.org/x/exp/slog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"person-service/config"
+	personv1 "person-service/proto/person/v1"
+	"person-service/services"
+	"time"
+)
+
+// NewServer grpc server of person api with reflection, nil key disables JWT authentication like for http api.
+// Calls are served without tenant for nil tenants or disabled tenancy.
+func NewServer(logger *slog.Logger, service *services.PersonService, tenants *services.TenantService, rsaPubKey *rsa.PublicKey, options config.Grpc, tenancy config.Tenancy) *grpc.Server {
+	unary := []grpc.UnaryServerInterceptor{UnaryLogger(logger)}
+	stream := []grpc.StreamServerInterceptor{StreamLogger(logger)}
+	if rsaPubKey != nil {
+		unary = append(unary, UnaryJwtValidation(rsaPubKey))
+		stream = append(stream, StreamJwtValidation(rsaPubKey))
+	}
+	if tenants != nil && tenancy.Enabled {
+		unary = append(unary, UnaryTenancy(tenants, tenancy, rsaPubKey != nil))
+		stream = append(stream, StreamTenancy(tenants, tenancy, rsaPubKey != nil))
+	}
+
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(unary...), grpc.ChainStreamInterceptor(stream...))
+	personv1.RegisterPersonServiceServer(server, NewPersonServer(logger, service, options.WatchPollInterval))
+	reflection.Register(server)
+
+	return server
+}
+
+// UnaryLogger logs completed calls, the same as http middleware logger does for requests.
+func UnaryLogger(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		timestamp := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(logger, info.FullMethod, timestamp, err)
+		return resp, err
+	}
+}
+
+// StreamLogger logs completed streams.
+func StreamLogger(logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		timestamp := time.Now()
+		err := handler(srv, ss)
+		logCall(logger, info.FullMethod, timestamp, err)
+		return err
+	}
+}
+
+func logCall(logger *slog.Logger, method string, timestamp time.Time, err error) {
+	logger.Info(
+		"call completed",
+		slog.String("method", method),
+		slog.String("code", status.Code(err).String()),
+		slog.String("duration", time.Since(timestamp).String()),
+	)
+}
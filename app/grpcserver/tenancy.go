@@ -0,0 +1,61 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"person-service/config"
+	"person-service/db/repository"
+	"person-service/services"
+	"person-service/utils"
+	"strings"
+)
+
+// UnaryTenancy binds calls to tenant claimed by token or requested by metadata, the same way http middleware does.
+// Metadata alone picks tenant only for unsecured server.
+func UnaryTenancy(service *services.TenantService, tenancy config.Tenancy, secured bool) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := withTenant(ctx, service, tenancy, secured)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamTenancy binds streams to tenant claimed by token or requested by metadata.
+func StreamTenancy(service *services.TenantService, tenancy config.Tenancy, secured bool) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := withTenant(ss.Context(), service, tenancy, secured)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func withTenant(ctx context.Context, service *services.TenantService, tenancy config.Tenancy, secured bool) (context.Context, error) {
+	token, _ := ctx.Value(tokenKey{}).(utils.BearerToken)
+	var requested string
+	if values := metadata.ValueFromIncomingContext(ctx, strings.ToLower(tenancy.Header)); len(values) > 0 {
+		requested = values[0]
+	}
+
+	tenantId, err := service.ResolveTenant(ctx, token.Claim(tenancy.Claim), requested, secured)
+	var validationErr *services.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return nil, status.Error(codes.InvalidArgument, validationErr.Message)
+	case errors.Is(err, services.ErrTenantRequired):
+		return nil, status.Error(codes.InvalidArgument, "Tenant is required")
+	case errors.Is(err, services.ErrTenantMismatch), errors.Is(err, services.ErrTenantNotClaimed),
+		errors.Is(err, services.ErrTenantNotFound), errors.Is(err, services.ErrTenantSuspended):
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Internal, "Error while resolve tenant")
+	}
+	return repository.WithTenant(ctx, tenantId), nil
+}
@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/exp/slog"
+	"net/http"
+	"person-service/db/entity"
+	"person-service/mappers"
+	"person-service/model"
+	"person-service/services"
+	"person-service/utils"
+)
+
+// CreateAddress godoc
+// @Summary      Add postal address of person
+// @Description  Add postal address of person, postal code is validated by rules of country. Change of address updates
+// @Description  updatedAt of person and writes PersonUpdated event with all addresses of person.
+// @Tags         addresses
+// @Accept       json
+// @Accept       xml
+// @Accept       application/msgpack
+// @Accept       application/cbor
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    		path    	string  				true  	"ID of person entity."
+// @Param  		 request	body    	model.AddressRequest  	true  	"Model for create address."
+// @Success      201  		{object}   	model.AddressResponse
+// @Failure      400  		{object}   	model.ErrorResponse
+// @Failure      404  		{object}   	model.ErrorResponse
+// @Failure      415  		{object}   	model.ErrorResponse
+// @Router       /v2/persons/{id}/addresses [post]
+func CreateAddress(logger *slog.Logger, service *services.AddressService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.createAddress"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		personId, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+		address, ok := decodeAddressRequest(w, r, logger)
+		if !ok {
+			return
+		}
+
+		address.PersonId = personId
+		saved, err := service.CreateAddress(r.Context(), address)
+		if err != nil {
+			renderAddressError(w, r, logger, err, fmt.Sprintf("Error while save address of person %s", personId))
+			return
+		}
+
+		logger.Info("Successfully save address", slog.String("id", saved.Id.String()))
+		w.Header().Set("Location", fmt.Sprintf("%s/%s/addresses/%s", PersonsPath, personId, saved.Id))
+		render.Status(r, http.StatusCreated)
+		respond(w, r, mappers.ToAddressResponse(saved))
+	}
+}
+
+// LoadAddresses godoc
+// @Summary      Load postal addresses of person
+// @Description  Load postal addresses of person in order of creation
+// @Tags         addresses
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    path    string  	true  	"ID of person entity."
+// @Success      200  {array}    model.AddressResponse
+// @Failure      404  {object}   model.ErrorResponse
+// @Router       /v2/persons/{id}/addresses [get]
+func LoadAddresses(logger *slog.Logger, service *services.AddressService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.loadAddresses"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		personId, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+
+		addresses, err := service.LoadPersonAddresses(r.Context(), personId)
+		if err != nil {
+			renderAddressError(w, r, logger, err, fmt.Sprintf("Error while loading addresses of person %s", personId))
+			return
+		}
+
+		respond(w, r, mappers.ToAddressesResponse(addresses))
+	}
+}
+
+// FindAddress godoc
+// @Summary      Find postal address of person
+// @Description  Find postal address of person by id
+// @Tags         addresses
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    		path    string  	true  	"ID of person entity."
+// @Param		 addressId  path    string  	true  	"ID of address."
+// @Success      200  {object}   model.AddressResponse
+// @Failure      404  {object}   model.ErrorResponse
+// @Router       /v2/persons/{id}/addresses/{addressId} [get]
+func FindAddress(logger *slog.Logger, service *services.AddressService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.findAddress"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		personId, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+		id, ok := parseUuidParam(w, r, "addressId")
+		if !ok {
+			return
+		}
+
+		address, err := service.FindAddress(r.Context(), personId, id)
+		if err != nil {
+			renderAddressError(w, r, logger, err, fmt.Sprintf("Error while find address %s", id))
+			return
+		}
+
+		respond(w, r, mappers.ToAddressResponse(address))
+	}
+}
+
+// UpdateAddress godoc
+// @Summary      Update postal address of person
+// @Description  Replace postal address of person, validation rules are the same as on create
+// @Tags         addresses
+// @Accept       json
+// @Accept       xml
+// @Accept       application/msgpack
+// @Accept       application/cbor
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    		path    	string  				true  	"ID of person entity."
+// @Param		 addressId  path    	string  				true  	"ID of address."
+// @Param  		 request	body    	model.AddressRequest  	true  	"Model for update address."
+// @Success      200  		{object}   	model.AddressResponse
+// @Failure      400  		{object}   	model.ErrorResponse
+// @Failure      404  		{object}   	model.ErrorResponse
+// @Failure      415  		{object}   	model.ErrorResponse
+// @Router       /v2/persons/{id}/addresses/{addressId} [put]
+func UpdateAddress(logger *slog.Logger, service *services.AddressService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.updateAddress"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		personId, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+		id, ok := parseUuidParam(w, r, "addressId")
+		if !ok {
+			return
+		}
+		address, ok := decodeAddressRequest(w, r, logger)
+		if !ok {
+			return
+		}
+
+		address.Id = id
+		address.PersonId = personId
+		updated, err := service.UpdateAddress(r.Context(), address)
+		if err != nil {
+			renderAddressError(w, r, logger, err, fmt.Sprintf("Error while update address %s", id))
+			return
+		}
+
+		logger.Info("Successfully update address", slog.String("id", id.String()))
+		respond(w, r, mappers.ToAddressResponse(updated))
+	}
+}
+
+// DeleteAddress godoc
+// @Summary      Delete postal address of person
+// @Description  Delete postal address of person
+// @Tags         addresses
+// @Param		 id    		path    string  	true  	"ID of person entity."
+// @Param		 addressId  path    string  	true  	"ID of address."
+// @Success      204
+// @Failure      404  {object}   model.ErrorResponse
+// @Router       /v2/persons/{id}/addresses/{addressId} [delete]
+func DeleteAddress(logger *slog.Logger, service *services.AddressService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.deleteAddress"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		personId, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+		id, ok := parseUuidParam(w, r, "addressId")
+		if !ok {
+			return
+		}
+
+		deleted, err := service.DeleteAddress(r.Context(), personId, id)
+		if err != nil {
+			renderAddressError(w, r, logger, err, fmt.Sprintf("Error while delete address %s", id))
+			return
+		}
+		if !deleted {
+			renderError(w, r, http.StatusNotFound, fmt.Sprintf("Address not found by id, with %s", id))
+			return
+		}
+
+		logger.Info("Address was successfully deleted", slog.String("id", id.String()))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// decodeAddressRequest decode body of create/update request, writes 400 or 415 response when it is not valid.
+func decodeAddressRequest(w http.ResponseWriter, r *http.Request, logger *slog.Logger) (entity.Address, bool) {
+	var req model.AddressRequest
+	if !decodeRequest(w, r, logger, &req) {
+		return entity.Address{}, false
+	}
+
+	address, err := mappers.ToAddress(req)
+	if err != nil {
+		logger.Error("Address request is not valid", utils.Err(err))
+		renderError(w, r, http.StatusBadRequest, err.Error())
+		return entity.Address{}, false
+	}
+	return address, true
+}
+
+func renderAddressError(w http.ResponseWriter, r *http.Request, logger *slog.Logger, err error, msg string) {
+	var validationErr *services.ValidationError
+
+	switch {
+	case errors.As(err, &validationErr):
+		logger.Error("Address request is not valid", utils.Err(err))
+		renderError(w, r, http.StatusBadRequest, validationErr.Message)
+	case errors.Is(err, pgx.ErrNoRows):
+		logger.Error("Person or address not found", utils.Err(err))
+		renderError(w, r, http.StatusNotFound, "Person or address not found")
+	default:
+		logger.Error(msg, utils.Err(err))
+		renderError(w, r, http.StatusInternalServerError, msg)
+	}
+}
@@ -0,0 +1,375 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/exp/slog"
+	"io"
+	"mime"
+	"net/http"
+	"person-service/blob"
+	"person-service/db/entity"
+	"person-service/mappers"
+	"person-service/model"
+	"person-service/services"
+	"person-service/utils"
+	"strconv"
+)
+
+// checksumHeader expected SHA-256 (hex) of uploaded content, SHA-256 of downloaded content.
+const checksumHeader = "X-Checksum-Sha256"
+
+// UploadAttachment godoc
+// @Summary      Upload attachment of person
+// @Description  Stream document as multipart/form-data field file or as raw body with file name in fileName param or
+// @Description  Content-Disposition header. Content is scanned before it is stored, SHA-256 is verified against
+// @Description  X-Checksum-Sha256 header when it is sent. Generic content type is sniffed from content.
+// @Tags         attachments
+// @Accept       multipart/form-data
+// @Accept       application/octet-stream
+// @Produce      json
+// @Param		 id    			path    	string  	true  	"ID of person entity."
+// @Param		 file   		formData    file  		false  	"Document of multipart upload."
+// @Param		 fileName   	query    	string  	false  	"File name of raw upload."
+// @Param		 retainUntil   	query    	string  	false  	"Date (YYYY-MM-DD) after which attachment is purged."
+// @Param		 X-Checksum-Sha256 header   string  	false  	"Expected SHA-256 of content in hex."
+// @Success      201  	{object}   	model.AttachmentResponse
+// @Failure      400  	{object}   	model.ErrorResponse
+// @Failure      404  	{object}   	model.ErrorResponse
+// @Failure      413  	{object}   	model.ErrorResponse
+// @Failure      422  	{object}   	model.ErrorResponse
+// @Router       /v2/persons/{id}/attachments [post]
+func UploadAttachment(logger *slog.Logger, service *services.AttachmentService, maxSize int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.uploadAttachment"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		personId, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+		retainUntil, err := mappers.ParseRetention(r.URL.Query().Get("retainUntil"))
+		if err != nil {
+			renderError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		attachment := entity.Attachment{PersonId: personId, RetainUntil: retainUntil, Sha256: r.Header.Get(checksumHeader)}
+		r.Body = http.MaxBytesReader(w, r.Body, maxSize+multipartOverhead)
+		content, err := attachmentContent(r, &attachment)
+		if err != nil {
+			renderAttachmentError(w, r, logger, err, "Error while read attachment upload")
+			return
+		}
+
+		saved, err := service.CreateAttachment(r.Context(), attachment, content)
+		if err != nil {
+			renderAttachmentError(w, r, logger, err, fmt.Sprintf("Error while save attachment of person %s", personId))
+			return
+		}
+
+		logger.Info("Successfully save attachment", slog.String("id", saved.Id.String()), slog.Int64("size", saved.Size))
+		w.Header().Set("Location", fmt.Sprintf("%s/%s/attachments/%s", PersonsPath, personId, saved.Id))
+		render.Status(r, http.StatusCreated)
+		respond(w, r, mappers.ToAttachmentResponse(saved))
+	}
+}
+
+// LoadAttachments godoc
+// @Summary      Load attachments of person
+// @Description  Load metadata of person attachments in order of upload
+// @Tags         attachments
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    path    string  	true  	"ID of person entity."
+// @Success      200  {array}    model.AttachmentResponse
+// @Failure      404  {object}   model.ErrorResponse
+// @Router       /v2/persons/{id}/attachments [get]
+func LoadAttachments(logger *slog.Logger, service *services.AttachmentService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.loadAttachments"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		personId, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+
+		attachments, err := service.LoadAttachments(r.Context(), personId)
+		if err != nil {
+			renderAttachmentError(w, r, logger, err, fmt.Sprintf("Error while loading attachments of person %s", personId))
+			return
+		}
+
+		respond(w, r, mappers.ToAttachmentsResponse(attachments))
+	}
+}
+
+// FindAttachment godoc
+// @Summary      Find attachment of person
+// @Description  Find metadata of person attachment by id
+// @Tags         attachments
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    			path    string  	true  	"ID of person entity."
+// @Param		 attachmentId   path    string  	true  	"ID of attachment."
+// @Success      200  {object}   model.AttachmentResponse
+// @Failure      404  {object}   model.ErrorResponse
+// @Router       /v2/persons/{id}/attachments/{attachmentId} [get]
+func FindAttachment(logger *slog.Logger, service *services.AttachmentService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.findAttachment"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		personId, attachmentId, ok := parseAttachmentParams(w, r)
+		if !ok {
+			return
+		}
+
+		attachment, err := service.FindAttachment(r.Context(), personId, attachmentId)
+		if err != nil {
+			renderAttachmentError(w, r, logger, err, fmt.Sprintf("Error while find attachment %s", attachmentId))
+			return
+		}
+
+		respond(w, r, mappers.ToAttachmentResponse(attachment))
+	}
+}
+
+// DownloadAttachment godoc
+// @Summary      Download attachment of person
+// @Description  Stream content of attachment, X-Checksum-Sha256 and Digest headers carry its SHA-256 which is ETag too.
+// @Tags         attachments
+// @Produce      application/octet-stream
+// @Param		 id    			path    string  	true  	"ID of person entity."
+// @Param		 attachmentId   path    string  	true  	"ID of attachment."
+// @Success      200  {file}     binary
+// @Success      304
+// @Failure      404  {object}   model.ErrorResponse
+// @Router       /v2/persons/{id}/attachments/{attachmentId}/content [get]
+func DownloadAttachment(logger *slog.Logger, service *services.AttachmentService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.downloadAttachment"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		personId, attachmentId, ok := parseAttachmentParams(w, r)
+		if !ok {
+			return
+		}
+
+		attachment, err := service.FindAttachment(r.Context(), personId, attachmentId)
+		if err != nil {
+			renderAttachmentError(w, r, logger, err, fmt.Sprintf("Error while find attachment %s", attachmentId))
+			return
+		}
+
+		etag := fmt.Sprintf(`"%s"`, attachment.Sha256)
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", "private, no-cache")
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		_, content, err := service.OpenAttachment(r.Context(), personId, attachmentId)
+		if err != nil {
+			renderAttachmentError(w, r, logger, err, fmt.Sprintf("Error while read attachment %s", attachmentId))
+			return
+		}
+		defer content.Close()
+
+		if checksum, err := hex.DecodeString(attachment.Sha256); err == nil {
+			w.Header().Set("Digest", "sha-256="+base64.StdEncoding.EncodeToString(checksum))
+		}
+		w.Header().Set(checksumHeader, attachment.Sha256)
+		w.Header().Set("Content-Type", attachment.ContentType)
+		w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+
+		if _, err := io.Copy(w, content); err != nil {
+			/* status is already sent, client sees truncated body */
+			logger.Error("Failed to stream attachment", utils.Err(err))
+		}
+	}
+}
+
+// UpdateAttachmentRetention godoc
+// @Summary      Change retention date of attachment
+// @Description  Set date after which attachment is purged, empty retainUntil keeps attachment until it is deleted
+// @Tags         attachments
+// @Accept       json
+// @Accept       xml
+// @Accept       application/msgpack
+// @Accept       application/cbor
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    			path    string  							true  	"ID of person entity."
+// @Param		 attachmentId   path    string  							true  	"ID of attachment."
+// @Param  		 request		body    model.AttachmentRetentionRequest  	true  	"Retention date."
+// @Success      200  {object}   model.AttachmentResponse
+// @Failure      400  {object}   model.ErrorResponse
+// @Failure      404  {object}   model.ErrorResponse
+// @Failure      415  {object}   model.ErrorResponse
+// @Router       /v2/persons/{id}/attachments/{attachmentId}/retention [put]
+func UpdateAttachmentRetention(logger *slog.Logger, service *services.AttachmentService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.updateAttachmentRetention"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		personId, attachmentId, ok := parseAttachmentParams(w, r)
+		if !ok {
+			return
+		}
+		var req model.AttachmentRetentionRequest
+		if !decodeRequest(w, r, logger, &req) {
+			return
+		}
+		retainUntil, err := mappers.ParseRetention(req.RetainUntil)
+		if err != nil {
+			renderError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		updated, err := service.UpdateRetention(r.Context(), personId, attachmentId, retainUntil)
+		if err != nil {
+			renderAttachmentError(w, r, logger, err, fmt.Sprintf("Error while update retention of attachment %s", attachmentId))
+			return
+		}
+
+		logger.Info("Successfully update retention of attachment", slog.String("id", attachmentId.String()))
+		respond(w, r, mappers.ToAttachmentResponse(updated))
+	}
+}
+
+// DeleteAttachment godoc
+// @Summary      Delete attachment of person
+// @Description  Delete attachment of person, its content is purged from blob store in background
+// @Tags         attachments
+// @Param		 id    			path    string  	true  	"ID of person entity."
+// @Param		 attachmentId   path    string  	true  	"ID of attachment."
+// @Success      204
+// @Failure      404  {object}   model.ErrorResponse
+// @Router       /v2/persons/{id}/attachments/{attachmentId} [delete]
+func DeleteAttachment(logger *slog.Logger, service *services.AttachmentService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.deleteAttachment"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		personId, attachmentId, ok := parseAttachmentParams(w, r)
+		if !ok {
+			return
+		}
+
+		deleted, err := service.DeleteAttachment(r.Context(), personId, attachmentId)
+		if err != nil {
+			renderAttachmentError(w, r, logger, err, fmt.Sprintf("Error while delete attachment %s", attachmentId))
+			return
+		}
+		if !deleted {
+			renderError(w, r, http.StatusNotFound, fmt.Sprintf("Attachment not found by id, with %s", attachmentId))
+			return
+		}
+
+		logger.Info("Attachment was successfully deleted", slog.String("id", attachmentId.String()))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// attachmentContent content of multipart field file or raw body, file name and content type are taken from part
+// or from request.
+func attachmentContent(r *http.Request, attachment *entity.Attachment) (io.Reader, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		attachment.FileName = r.URL.Query().Get("fileName")
+		if attachment.FileName == "" {
+			if _, params, err := mime.ParseMediaType(r.Header.Get("Content-Disposition")); err == nil {
+				attachment.FileName = params["filename"]
+			}
+		}
+		attachment.ContentType = r.Header.Get("Content-Type")
+		return r.Body, nil
+	}
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, &services.ValidationError{Message: "Multipart body is not valid"}
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, &services.ValidationError{Message: "Multipart field file is required"}
+		}
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() == uploadField {
+			attachment.FileName = part.FileName()
+			attachment.ContentType = part.Header.Get("Content-Type")
+			return part, nil
+		}
+	}
+}
+
+func parseAttachmentParams(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	personId, ok := parseUuidParam(w, r, "id")
+	if !ok {
+		return uuid.UUID{}, uuid.UUID{}, false
+	}
+	attachmentId, ok := parseUuidParam(w, r, "attachmentId")
+	return personId, attachmentId, ok
+}
+
+func renderAttachmentError(w http.ResponseWriter, r *http.Request, logger *slog.Logger, err error, msg string) {
+	var validationErr *services.ValidationError
+	var infectedErr *services.InfectedError
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &validationErr):
+		logger.Error("Attachment is not valid", utils.Err(err))
+		renderError(w, r, http.StatusBadRequest, validationErr.Message)
+	case errors.As(err, &infectedErr):
+		logger.Warn("Attachment is rejected by scanner", slog.String("threat", infectedErr.Threat))
+		renderError(w, r, http.StatusUnprocessableEntity, fmt.Sprintf("Attachment is rejected by virus scan: %s", infectedErr.Threat))
+	case errors.Is(err, services.ErrAttachmentTooLarge), errors.As(err, &maxBytesErr):
+		logger.Error("Attachment is too large", utils.Err(err))
+		renderError(w, r, http.StatusRequestEntityTooLarge, "Attachment is too large")
+	case errors.Is(err, pgx.ErrNoRows), errors.Is(err, blob.ErrNotFound):
+		logger.Error("Person or attachment not found", utils.Err(err))
+		renderError(w, r, http.StatusNotFound, "Person or attachment not found")
+	default:
+		logger.Error(msg, utils.Err(err))
+		renderError(w, r, http.StatusInternalServerError, msg)
+	}
+}
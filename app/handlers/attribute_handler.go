@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"golang.org/x/exp/slog"
+	"net/http"
+	"person-service/mappers"
+	"person-service/model"
+	"person-service/services"
+	"person-service/utils"
+	"strings"
+)
+
+// AttributesPath admin resource of custom person attribute definitions.
+const AttributesPath = "/api/v2/admin/attributes"
+
+// attributeFilterPrefix prefix of listing parameters filtering persons by attribute value (attr.department=sales).
+const attributeFilterPrefix = "attr."
+
+// CreateAttributeDefinition godoc
+// @Summary      Create attribute definition
+// @Description  Register custom person attribute, values of persons are validated against it on create and update.
+// @Tags         attributes
+// @Accept       json
+// @Accept       xml
+// @Accept       application/msgpack
+// @Accept       application/cbor
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param  		 request	body    	model.AttributeDefinitionRequest  	true  "Model of attribute definition."
+// @Success      201  		{object}   	model.AttributeDefinitionResponse
+// @Failure      400  		{object}   	model.ErrorResponse
+// @Failure      409  		{object}   	model.ErrorResponse
+// @Failure      415  		{object}   	model.ErrorResponse
+// @Router       /v2/admin/attributes [post]
+func CreateAttributeDefinition(logger *slog.Logger, service *services.AttributeService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.createAttributeDefinition"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req model.AttributeDefinitionRequest
+		if !decodeRequest(w, r, logger, &req) {
+			return
+		}
+
+		saved, err := service.CreateDefinition(r.Context(), mappers.ToAttributeDefinition(req))
+		if err != nil {
+			renderAttributeError(w, r, logger, err, "Error while save attribute definition")
+			return
+		}
+
+		logger.Info("Successfully save attribute definition", slog.String("name", saved.Name))
+		w.Header().Set("Location", fmt.Sprintf("%s/%s", AttributesPath, saved.Name))
+		render.Status(r, http.StatusCreated)
+		respond(w, r, mappers.ToAttributeDefinitionResponse(saved))
+	}
+}
+
+// UpdateAttributeDefinition godoc
+// @Summary      Update attribute definition
+// @Description  Update required flag, pattern or enum values of attribute, type must not be changed.
+// @Description  Stored values are validated against changed definition when person is written next time.
+// @Tags         attributes
+// @Accept       json
+// @Accept       xml
+// @Accept       application/msgpack
+// @Accept       application/cbor
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 name    	path    	string  							true  	"Name of attribute."
+// @Param  		 request	body    	model.AttributeDefinitionRequest  	true  	"Model of attribute definition."
+// @Success      200  		{object}   	model.AttributeDefinitionResponse
+// @Failure      400  		{object}   	model.ErrorResponse
+// @Failure      404  		{object}   	model.ErrorResponse
+// @Failure      415  		{object}   	model.ErrorResponse
+// @Router       /v2/admin/attributes/{name} [put]
+func UpdateAttributeDefinition(logger *slog.Logger, service *services.AttributeService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.updateAttributeDefinition"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req model.AttributeDefinitionRequest
+		if !decodeRequest(w, r, logger, &req) {
+			return
+		}
+
+		definition := mappers.ToAttributeDefinition(req)
+		definition.Name = chi.URLParam(r, "name")
+		updated, err := service.UpdateDefinition(r.Context(), definition)
+		if err != nil {
+			renderAttributeError(w, r, logger, err, fmt.Sprintf("Error while update attribute definition %s", definition.Name))
+			return
+		}
+
+		logger.Info("Successfully update attribute definition", slog.String("name", updated.Name))
+		respond(w, r, mappers.ToAttributeDefinitionResponse(updated))
+	}
+}
+
+// DeleteAttributeDefinition godoc
+// @Summary      Delete attribute definition
+// @Description  Delete attribute definition together with values of all persons.
+// @Tags         attributes
+// @Param		 name    path    string  	true  	"Name of attribute."
+// @Success      204
+// @Failure      404  {object}   model.ErrorResponse
+// @Router       /v2/admin/attributes/{name} [delete]
+func DeleteAttributeDefinition(logger *slog.Logger, service *services.AttributeService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.deleteAttributeDefinition"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		name := chi.URLParam(r, "name")
+		deleted, err := service.DeleteDefinition(r.Context(), name)
+		if err != nil {
+			renderAttributeError(w, r, logger, err, fmt.Sprintf("Error while delete attribute definition %s", name))
+			return
+		}
+		if !deleted {
+			renderError(w, r, http.StatusNotFound, fmt.Sprintf("Attribute definition not found by name, with %s", name))
+			return
+		}
+
+		logger.Info("Attribute definition was successfully deleted", slog.String("name", name))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// FindAttributeDefinition godoc
+// @Summary      Find attribute definition
+// @Description  Find attribute definition by name
+// @Tags         attributes
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 name    path    string  	true  	"Name of attribute."
+// @Success      200  {object}   model.AttributeDefinitionResponse
+// @Failure      404  {object}   model.ErrorResponse
+// @Router       /v2/admin/attributes/{name} [get]
+func FindAttributeDefinition(logger *slog.Logger, service *services.AttributeService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.findAttributeDefinition"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		name := chi.URLParam(r, "name")
+		definition, err := service.FindDefinition(r.Context(), name)
+		if err != nil {
+			renderAttributeError(w, r, logger, err, fmt.Sprintf("Error while find attribute definition %s", name))
+			return
+		}
+
+		respond(w, r, mappers.ToAttributeDefinitionResponse(definition))
+	}
+}
+
+// LoadAttributeDefinitions godoc
+// @Summary      Load attribute definitions
+// @Description  Load all attribute definitions ordered by name
+// @Tags         attributes
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Success      200  {array}   model.AttributeDefinitionResponse
+// @Router       /v2/admin/attributes [get]
+func LoadAttributeDefinitions(logger *slog.Logger, service *services.AttributeService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.loadAttributeDefinitions"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		definitions, err := service.LoadDefinitions(r.Context())
+		if err != nil {
+			renderAttributeError(w, r, logger, err, "Error while loading attribute definitions")
+			return
+		}
+
+		respond(w, r, mappers.ToAttributeDefinitionsResponse(definitions))
+	}
+}
+
+// parseAttributeFilter raw values of attr.<name> parameters, they are converted by attribute definitions in service.
+func parseAttributeFilter(r *http.Request) map[string]string {
+	var filter map[string]string
+	for parameter, values := range r.URL.Query() {
+		if name, ok := strings.CutPrefix(parameter, attributeFilterPrefix); ok && len(values) > 0 {
+			if filter == nil {
+				filter = make(map[string]string)
+			}
+			filter[name] = values[0]
+		}
+	}
+	return filter
+}
+
+func renderAttributeError(w http.ResponseWriter, r *http.Request, logger *slog.Logger, err error, msg string) {
+	var validationErr *services.ValidationError
+	var pgErr *pgconn.PgError
+
+	switch {
+	case errors.As(err, &validationErr):
+		logger.Error("Attribute definition is not valid", utils.Err(err))
+		renderError(w, r, http.StatusBadRequest, validationErr.Message)
+	case errors.Is(err, pgx.ErrNoRows):
+		logger.Error("Attribute definition not found", utils.Err(err))
+		renderError(w, r, http.StatusNotFound, "Attribute definition not found")
+	case errors.As(err, &pgErr) && pgErr.Code == uniqueViolation:
+		logger.Error("Attribute definition already exists", utils.Err(err))
+		renderError(w, r, http.StatusConflict, "Attribute definition with the same name already exists")
+	default:
+		logger.Error(msg, utils.Err(err))
+		renderError(w, r, http.StatusInternalServerError, msg)
+	}
+}
@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/exp/slog"
+	"io"
+	"mime"
+	"net/http"
+	"person-service/blob"
+	"person-service/mappers"
+	"person-service/services"
+	"person-service/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	/* form field of multipart upload of avatar and attachment */
+	uploadField = "file"
+	/* allowance for multipart boundaries and part headers over max size of image */
+	multipartOverhead = 64 << 10
+)
+
+var errAvatarTooLarge = errors.New("avatar is too large")
+
+// SaveAvatar godoc
+// @Summary      Upload avatar of person
+// @Description  Upload image as multipart/form-data field file or as raw body. Format is sniffed from content (JPEG, PNG, GIF),
+// @Description  image is scaled down, thumbnails are made and metadata as EXIF is stripped. Existing avatar is replaced.
+// @Tags         avatars
+// @Accept       multipart/form-data
+// @Accept       image/jpeg
+// @Accept       image/png
+// @Accept       image/gif
+// @Produce      json
+// @Param		 id    	path    	string  	true  	"ID of person entity."
+// @Param		 file   formData    file  		false  	"Image of multipart upload."
+// @Success      200  	{object}   	model.AvatarResponse
+// @Failure      400  	{object}   	model.ErrorResponse
+// @Failure      404  	{object}   	model.ErrorResponse
+// @Failure      413  	{object}   	model.ErrorResponse
+// @Router       /v2/persons/{id}/avatar [put]
+func SaveAvatar(logger *slog.Logger, service *services.AvatarService, maxSize int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.saveAvatar"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		personId, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+
+		data, err := readAvatarUpload(w, r, maxSize)
+		switch {
+		case errors.Is(err, errAvatarTooLarge):
+			renderError(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("Avatar must not be larger than %d bytes", maxSize))
+			return
+		case err != nil:
+			logger.Error("Failed to read avatar upload", utils.Err(err))
+			renderError(w, r, http.StatusBadRequest, "Error while read avatar upload")
+			return
+		case len(data) == 0:
+			renderError(w, r, http.StatusBadRequest, "Avatar image is required")
+			return
+		}
+
+		saved, err := service.SaveAvatar(r.Context(), personId, data)
+		if err != nil {
+			renderAvatarError(w, r, logger, err, fmt.Sprintf("Error while save avatar of person %s", personId))
+			return
+		}
+
+		logger.Info("Successfully save avatar", slog.String("person_id", personId.String()), slog.String("etag", saved.ETag))
+		respond(w, r, mappers.ToAvatarResponse(saved))
+	}
+}
+
+// FindAvatar godoc
+// @Summary      Download avatar of person
+// @Description  Download original image or square thumbnail of size. Response is cacheable by ETag and Last-Modified,
+// @Description  If-None-Match and If-Modified-Since are answered with 304.
+// @Tags         avatars
+// @Produce      image/jpeg
+// @Produce      image/png
+// @Param		 id    	path    	string  	true  	"ID of person entity."
+// @Param		 size   query    	int  		false  	"Size of thumbnail, original when absent."
+// @Success      200  	{file}   	binary
+// @Success      304
+// @Failure      400  	{object}   	model.ErrorResponse
+// @Failure      404  	{object}   	model.ErrorResponse
+// @Router       /v2/persons/{id}/avatar [get]
+func FindAvatar(logger *slog.Logger, service *services.AvatarService, cacheMaxAge time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.findAvatar"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		personId, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+		size := 0
+		if value := r.URL.Query().Get("size"); value != "" {
+			var err error
+			if size, err = strconv.Atoi(value); err != nil {
+				renderError(w, r, http.StatusBadRequest, "Parameter size must be integer")
+				return
+			}
+		}
+
+		avatar, err := service.FindAvatar(r.Context(), personId)
+		if err != nil {
+			renderAvatarError(w, r, logger, err, fmt.Sprintf("Error while find avatar of person %s", personId))
+			return
+		}
+		variant, err := service.Variant(avatar, size)
+		if err != nil {
+			renderAvatarError(w, r, logger, err, fmt.Sprintf("Error while find avatar of person %s", personId))
+			return
+		}
+
+		etag := fmt.Sprintf(`"%s-%s"`, avatar.ETag, variant)
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(cacheMaxAge.Seconds())))
+		/* revalidation does not need file */
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		file, err := service.OpenVariant(r.Context(), avatar, variant)
+		if err != nil {
+			renderAvatarError(w, r, logger, err, fmt.Sprintf("Error while read avatar of person %s", personId))
+			return
+		}
+		defer file.Close()
+		data, err := io.ReadAll(file)
+		if err != nil {
+			renderAvatarError(w, r, logger, err, fmt.Sprintf("Error while read avatar of person %s", personId))
+			return
+		}
+
+		w.Header().Set("Content-Type", avatar.ContentType)
+		http.ServeContent(w, r, "", avatar.UpdatedAt, bytes.NewReader(data))
+	}
+}
+
+// DeleteAvatar godoc
+// @Summary      Delete avatar of person
+// @Description  Delete avatar of person together with its thumbnails
+// @Tags         avatars
+// @Param		 id    path    string  	true  	"ID of person entity."
+// @Success      204
+// @Failure      404  {object}   model.ErrorResponse
+// @Router       /v2/persons/{id}/avatar [delete]
+func DeleteAvatar(logger *slog.Logger, service *services.AvatarService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.deleteAvatar"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		personId, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+
+		deleted, err := service.DeleteAvatar(r.Context(), personId)
+		if err != nil {
+			renderAvatarError(w, r, logger, err, fmt.Sprintf("Error while delete avatar of person %s", personId))
+			return
+		}
+		if !deleted {
+			renderError(w, r, http.StatusNotFound, fmt.Sprintf("Avatar not found by person id, with %s", personId))
+			return
+		}
+
+		logger.Info("Avatar was successfully deleted", slog.String("person_id", personId.String()))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// readAvatarUpload image of multipart field file or of raw body, errAvatarTooLarge when it is over max size.
+func readAvatarUpload(w http.ResponseWriter, r *http.Request, maxSize int64) ([]byte, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+multipartOverhead)
+
+	var source io.Reader = r.Body
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		reader, err := r.MultipartReader()
+		if err != nil {
+			return nil, err
+		}
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				return nil, nil
+			}
+			if err != nil {
+				return nil, uploadError(err)
+			}
+			if part.FormName() == uploadField {
+				source = part
+				break
+			}
+		}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(source, maxSize+1))
+	if err != nil {
+		return nil, uploadError(err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, errAvatarTooLarge
+	}
+	return data, nil
+}
+
+func uploadError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return errAvatarTooLarge
+	}
+	return err
+}
+
+// etagMatches weak comparison of If-None-Match list with etag.
+func etagMatches(header string, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func renderAvatarError(w http.ResponseWriter, r *http.Request, logger *slog.Logger, err error, msg string) {
+	var validationErr *services.ValidationError
+
+	switch {
+	case errors.As(err, &validationErr):
+		logger.Error("Avatar is not valid", utils.Err(err))
+		renderError(w, r, http.StatusBadRequest, validationErr.Message)
+	case errors.Is(err, pgx.ErrNoRows), errors.Is(err, blob.ErrNotFound):
+		logger.Error("Person or avatar not found", utils.Err(err))
+		renderError(w, r, http.StatusNotFound, "Person or avatar not found")
+	default:
+		logger.Error(msg, utils.Err(err))
+		renderError(w, r, http.StatusInternalServerError, msg)
+	}
+}
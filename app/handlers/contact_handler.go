@@ -0,0 +1,429 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"golang.org/x/exp/slog"
+	"net/http"
+	"person-service/db/entity"
+	"person-service/mappers"
+	"person-service/model"
+	"person-service/services"
+	"person-service/utils"
+)
+
+/* unique indexes of contact tables, see migration 0007 */
+const (
+	emailPrimaryUniqueIdx = "person_email_primary_unique_idx"
+	emailUniqueIdx        = "person_email_unique_idx"
+	phoneUniqueIdx        = "person_phone_unique_idx"
+)
+
+// contactResource binds generic contact handlers to emails or phones.
+type contactResource struct {
+	kind       entity.ContactKind
+	idParam    string
+	decode     func(w http.ResponseWriter, r *http.Request, logger *slog.Logger) (entity.Contact, bool)
+	toResponse func(contact entity.Contact) any
+	toList     func(contacts []entity.Contact) any
+}
+
+var emailResource = contactResource{
+	kind:    entity.ContactEmail,
+	idParam: "emailId",
+	decode: func(w http.ResponseWriter, r *http.Request, logger *slog.Logger) (entity.Contact, bool) {
+		var req model.EmailRequest
+		ok := decodeRequest(w, r, logger, &req)
+		return mappers.EmailToContact(req), ok
+	},
+	toResponse: func(contact entity.Contact) any { return mappers.ToEmailResponse(contact) },
+	toList:     func(contacts []entity.Contact) any { return mappers.ToEmailsResponse(contacts) },
+}
+
+var phoneResource = contactResource{
+	kind:    entity.ContactPhone,
+	idParam: "phoneId",
+	decode: func(w http.ResponseWriter, r *http.Request, logger *slog.Logger) (entity.Contact, bool) {
+		var req model.PhoneRequest
+		ok := decodeRequest(w, r, logger, &req)
+		return mappers.PhoneToContact(req), ok
+	},
+	toResponse: func(contact entity.Contact) any { return mappers.ToPhoneResponse(contact) },
+	toList:     func(contacts []entity.Contact) any { return mappers.ToPhonesResponse(contacts) },
+}
+
+// CreateEmail godoc
+// @Summary      Add email of person
+// @Description  Add email of person, address must be bare RFC 5322 address, its domain is stored in lower case.
+// @Description  Primary email replaces current primary one of person and must not be primary email of another person.
+// @Tags         contacts
+// @Accept       json
+// @Accept       xml
+// @Accept       application/msgpack
+// @Accept       application/cbor
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    		path    	string  			true  	"ID of person entity."
+// @Param  		 request	body    	model.EmailRequest  true  	"Model for create email."
+// @Success      201  		{object}   	model.EmailResponse
+// @Failure      400  		{object}   	model.ErrorResponse
+// @Failure      404  		{object}   	model.ErrorResponse
+// @Failure      409  		{object}   	model.ErrorResponse
+// @Failure      415  		{object}   	model.ErrorResponse
+// @Router       /v2/persons/{id}/emails [post]
+func CreateEmail(logger *slog.Logger, service *services.ContactService) http.HandlerFunc {
+	return createContact(logger, service, emailResource)
+}
+
+// LoadEmails godoc
+// @Summary      Load emails of person
+// @Description  Load emails of person, primary email goes first
+// @Tags         contacts
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    path    string  	true  	"ID of person entity."
+// @Success      200  {array}    model.EmailResponse
+// @Failure      404  {object}   model.ErrorResponse
+// @Router       /v2/persons/{id}/emails [get]
+func LoadEmails(logger *slog.Logger, service *services.ContactService) http.HandlerFunc {
+	return loadContacts(logger, service, emailResource)
+}
+
+// FindEmail godoc
+// @Summary      Find email of person
+// @Description  Find email of person by id
+// @Tags         contacts
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    		path    string  	true  	"ID of person entity."
+// @Param		 emailId    path    string  	true  	"ID of email."
+// @Success      200  {object}   model.EmailResponse
+// @Failure      404  {object}   model.ErrorResponse
+// @Router       /v2/persons/{id}/emails/{emailId} [get]
+func FindEmail(logger *slog.Logger, service *services.ContactService) http.HandlerFunc {
+	return findContact(logger, service, emailResource)
+}
+
+// UpdateEmail godoc
+// @Summary      Update email of person
+// @Description  Replace email of person, validation and primary rules are the same as on create
+// @Tags         contacts
+// @Accept       json
+// @Accept       xml
+// @Accept       application/msgpack
+// @Accept       application/cbor
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    		path    	string  			true  	"ID of person entity."
+// @Param		 emailId    path    	string  			true  	"ID of email."
+// @Param  		 request	body    	model.EmailRequest  true  	"Model for update email."
+// @Success      200  		{object}   	model.EmailResponse
+// @Failure      400  		{object}   	model.ErrorResponse
+// @Failure      404  		{object}   	model.ErrorResponse
+// @Failure      409  		{object}   	model.ErrorResponse
+// @Failure      415  		{object}   	model.ErrorResponse
+// @Router       /v2/persons/{id}/emails/{emailId} [put]
+func UpdateEmail(logger *slog.Logger, service *services.ContactService) http.HandlerFunc {
+	return updateContact(logger, service, emailResource)
+}
+
+// DeleteEmail godoc
+// @Summary      Delete email of person
+// @Description  Delete email of person
+// @Tags         contacts
+// @Param		 id    		path    string  	true  	"ID of person entity."
+// @Param		 emailId    path    string  	true  	"ID of email."
+// @Success      204
+// @Failure      404  {object}   model.ErrorResponse
+// @Router       /v2/persons/{id}/emails/{emailId} [delete]
+func DeleteEmail(logger *slog.Logger, service *services.ContactService) http.HandlerFunc {
+	return deleteContact(logger, service, emailResource)
+}
+
+// CreatePhone godoc
+// @Summary      Add phone of person
+// @Description  Add phone of person, number in international format is normalized to E.164 (+ and up to 15 digits).
+// @Description  Primary phone replaces current primary one of person.
+// @Tags         contacts
+// @Accept       json
+// @Accept       xml
+// @Accept       application/msgpack
+// @Accept       application/cbor
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    		path    	string  			true  	"ID of person entity."
+// @Param  		 request	body    	model.PhoneRequest  true  	"Model for create phone."
+// @Success      201  		{object}   	model.PhoneResponse
+// @Failure      400  		{object}   	model.ErrorResponse
+// @Failure      404  		{object}   	model.ErrorResponse
+// @Failure      409  		{object}   	model.ErrorResponse
+// @Failure      415  		{object}   	model.ErrorResponse
+// @Router       /v2/persons/{id}/phones [post]
+func CreatePhone(logger *slog.Logger, service *services.ContactService) http.HandlerFunc {
+	return createContact(logger, service, phoneResource)
+}
+
+// LoadPhones godoc
+// @Summary      Load phones of person
+// @Description  Load phones of person, primary phone goes first
+// @Tags         contacts
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    path    string  	true  	"ID of person entity."
+// @Success      200  {array}    model.PhoneResponse
+// @Failure      404  {object}   model.ErrorResponse
+// @Router       /v2/persons/{id}/phones [get]
+func LoadPhones(logger *slog.Logger, service *services.ContactService) http.HandlerFunc {
+	return loadContacts(logger, service, phoneResource)
+}
+
+// FindPhone godoc
+// @Summary      Find phone of person
+// @Description  Find phone of person by id
+// @Tags         contacts
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    		path    string  	true  	"ID of person entity."
+// @Param		 phoneId    path    string  	true  	"ID of phone."
+// @Success      200  {object}   model.PhoneResponse
+// @Failure      404  {object}   model.ErrorResponse
+// @Router       /v2/persons/{id}/phones/{phoneId} [get]
+func FindPhone(logger *slog.Logger, service *services.ContactService) http.HandlerFunc {
+	return findContact(logger, service, phoneResource)
+}
+
+// UpdatePhone godoc
+// @Summary      Update phone of person
+// @Description  Replace phone of person, validation and primary rules are the same as on create
+// @Tags         contacts
+// @Accept       json
+// @Accept       xml
+// @Accept       application/msgpack
+// @Accept       application/cbor
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    		path    	string  			true  	"ID of person entity."
+// @Param		 phoneId    path    	string  			true  	"ID of phone."
+// @Param  		 request	body    	model.PhoneRequest  true  	"Model for update phone."
+// @Success      200  		{object}   	model.PhoneResponse
+// @Failure      400  		{object}   	model.ErrorResponse
+// @Failure      404  		{object}   	model.ErrorResponse
+// @Failure      409  		{object}   	model.ErrorResponse
+// @Failure      415  		{object}   	model.ErrorResponse
+// @Router       /v2/persons/{id}/phones/{phoneId} [put]
+func UpdatePhone(logger *slog.Logger, service *services.ContactService) http.HandlerFunc {
+	return updateContact(logger, service, phoneResource)
+}
+
+// DeletePhone godoc
+// @Summary      Delete phone of person
+// @Description  Delete phone of person
+// @Tags         contacts
+// @Param		 id    		path    string  	true  	"ID of person entity."
+// @Param		 phoneId    path    string  	true  	"ID of phone."
+// @Success      204
+// @Failure      404  {object}   model.ErrorResponse
+// @Router       /v2/persons/{id}/phones/{phoneId} [delete]
+func DeletePhone(logger *slog.Logger, service *services.ContactService) http.HandlerFunc {
+	return deleteContact(logger, service, phoneResource)
+}
+
+func createContact(logger *slog.Logger, service *services.ContactService, resource contactResource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.createContact"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("kind", string(resource.kind)),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		personId, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+		contact, ok := resource.decode(w, r, logger)
+		if !ok {
+			return
+		}
+
+		contact.PersonId = personId
+		saved, err := service.CreateContact(r.Context(), contact)
+		if err != nil {
+			renderContactError(w, r, logger, err, fmt.Sprintf("Error while save %s of person %s", resource.kind, personId))
+			return
+		}
+
+		logger.Info("Successfully save contact", slog.String("id", saved.Id.String()))
+		w.Header().Set("Location", fmt.Sprintf("%s/%s/%ss/%s", PersonsPath, personId, resource.kind, saved.Id))
+		render.Status(r, http.StatusCreated)
+		respond(w, r, resource.toResponse(saved))
+	}
+}
+
+func loadContacts(logger *slog.Logger, service *services.ContactService, resource contactResource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.loadContacts"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("kind", string(resource.kind)),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		personId, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+
+		contacts, err := service.LoadPersonContacts(r.Context(), resource.kind, personId)
+		if err != nil {
+			renderContactError(w, r, logger, err, fmt.Sprintf("Error while loading %ss of person %s", resource.kind, personId))
+			return
+		}
+
+		respond(w, r, resource.toList(contacts))
+	}
+}
+
+func findContact(logger *slog.Logger, service *services.ContactService, resource contactResource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.findContact"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("kind", string(resource.kind)),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		personId, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+		id, ok := parseUuidParam(w, r, resource.idParam)
+		if !ok {
+			return
+		}
+
+		contact, err := service.FindContact(r.Context(), resource.kind, personId, id)
+		if err != nil {
+			renderContactError(w, r, logger, err, fmt.Sprintf("Error while find %s %s", resource.kind, id))
+			return
+		}
+
+		respond(w, r, resource.toResponse(contact))
+	}
+}
+
+func updateContact(logger *slog.Logger, service *services.ContactService, resource contactResource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.updateContact"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("kind", string(resource.kind)),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		personId, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+		id, ok := parseUuidParam(w, r, resource.idParam)
+		if !ok {
+			return
+		}
+		contact, ok := resource.decode(w, r, logger)
+		if !ok {
+			return
+		}
+
+		contact.Id = id
+		contact.PersonId = personId
+		updated, err := service.UpdateContact(r.Context(), contact)
+		if err != nil {
+			renderContactError(w, r, logger, err, fmt.Sprintf("Error while update %s %s", resource.kind, id))
+			return
+		}
+
+		logger.Info("Successfully update contact", slog.String("id", id.String()))
+		respond(w, r, resource.toResponse(updated))
+	}
+}
+
+func deleteContact(logger *slog.Logger, service *services.ContactService, resource contactResource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.deleteContact"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("kind", string(resource.kind)),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		personId, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+		id, ok := parseUuidParam(w, r, resource.idParam)
+		if !ok {
+			return
+		}
+
+		deleted, err := service.DeleteContact(r.Context(), resource.kind, personId, id)
+		if err != nil {
+			renderContactError(w, r, logger, err, fmt.Sprintf("Error while delete %s %s", resource.kind, id))
+			return
+		}
+		if !deleted {
+			renderError(w, r, http.StatusNotFound, fmt.Sprintf("Contact not found by id, with %s", id))
+			return
+		}
+
+		logger.Info("Contact was successfully deleted", slog.String("id", id.String()))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func renderContactError(w http.ResponseWriter, r *http.Request, logger *slog.Logger, err error, msg string) {
+	var validationErr *services.ValidationError
+	var pgErr *pgconn.PgError
+
+	switch {
+	case errors.As(err, &validationErr):
+		logger.Error("Contact request is not valid", utils.Err(err))
+		renderError(w, r, http.StatusBadRequest, validationErr.Message)
+	case errors.Is(err, pgx.ErrNoRows):
+		logger.Error("Person or contact not found", utils.Err(err))
+		renderError(w, r, http.StatusNotFound, "Person or contact not found")
+	case errors.As(err, &pgErr) && pgErr.Code == uniqueViolation:
+		logger.Error("Contact conflicts with existing one", utils.Err(err))
+		switch pgErr.ConstraintName {
+		case emailPrimaryUniqueIdx:
+			renderError(w, r, http.StatusConflict, "Email is primary email of another person")
+		case emailUniqueIdx, phoneUniqueIdx:
+			renderError(w, r, http.StatusConflict, "Person already has the same contact")
+		default:
+			/* concurrent change of primary contact */
+			renderError(w, r, http.StatusConflict, "Primary contact of person was changed concurrently")
+		}
+	default:
+		logger.Error(msg, utils.Err(err))
+		renderError(w, r, http.StatusInternalServerError, msg)
+	}
+}
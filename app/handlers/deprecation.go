@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"person-service/model"
+	"time"
+)
+
+// Deprecation middleware marks responses of deprecated api, Sunset header is omitted for zero sunset.
+func Deprecation(sunset time.Time, successor string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", "true")
+			if !sunset.IsZero() {
+				w.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+			}
+			w.Header().Add("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// LegacyV1 adapts resource handler to v1 contract: id is taken from query param,
+// 201 is answered with 200 and 204 with 200 and delete message.
+func LegacyV1(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Query().Get("id")
+		if id != "" {
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				rctx.URLParams.Add("id", id)
+			}
+		}
+
+		legacy := &legacyResponseWriter{ResponseWriter: w}
+		next(legacy, r)
+
+		if legacy.deleted {
+			respond(w, r, model.CreateSuccessDeleteResponse(id))
+		}
+	}
+}
+
+type legacyResponseWriter struct {
+	http.ResponseWriter
+	deleted bool
+}
+
+func (w *legacyResponseWriter) WriteHeader(status int) {
+	switch status {
+	case http.StatusCreated:
+		w.ResponseWriter.WriteHeader(http.StatusOK)
+	case http.StatusNoContent:
+		/* body is written after handler returns */
+		w.deleted = true
+	default:
+		w.ResponseWriter.WriteHeader(status)
+	}
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"golang.org/x/exp/slog"
+	"net/http"
+	"person-service/codec"
+	"person-service/model"
+	"person-service/utils"
+	"strconv"
+	"strings"
+)
+
+type codecCtxKey struct{}
+
+// Negotiation middleware picks response codec before handler runs, so unacceptable request is answered
+// with 406 without side effects. URL format extension (.json, .xml, .msgpack, .cbor) has priority over Accept.
+func Negotiation(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		selected, err := responseCodec(r)
+		if err != nil {
+			w.Header().Set("Vary", "Accept")
+			writeBody(w, codec.JSON, http.StatusNotAcceptable, model.Error(
+				fmt.Sprintf("Response can be encoded only as %s", strings.Join(codec.MediaTypes(), ", ")),
+				strconv.Itoa(http.StatusNotAcceptable)))
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), codecCtxKey{}, selected)))
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// respond encodes value with negotiated codec, status is taken from render.Status like render.JSON does.
+// Handlers outside of Negotiation fall back to JSON when Accept is not supported.
+func respond(w http.ResponseWriter, r *http.Request, v any) {
+	selected, ok := r.Context().Value(codecCtxKey{}).(codec.Codec)
+	if !ok {
+		var err error
+		if selected, err = responseCodec(r); err != nil {
+			selected = codec.JSON
+		}
+	}
+
+	status := http.StatusOK
+	if s, ok := r.Context().Value(render.StatusCtxKey).(int); ok {
+		status = s
+	}
+
+	w.Header().Add("Vary", "Accept")
+	writeBody(w, selected, status, v)
+}
+
+// decodeRequest decodes body with codec of Content-Type, writes 415 or 400 response when it is not possible.
+func decodeRequest(w http.ResponseWriter, r *http.Request, logger *slog.Logger, v any) bool {
+	selected, err := codec.ForContentType(r.Header.Get("Content-Type"))
+	if err != nil {
+		logger.Error("Unsupported content type", slog.String("content_type", r.Header.Get("Content-Type")))
+		renderError(w, r, http.StatusUnsupportedMediaType,
+			fmt.Sprintf("Content-Type must be one of %s", strings.Join(codec.MediaTypes(), ", ")))
+		return false
+	}
+
+	if err := selected.Decode(r.Body, v); err != nil {
+		logger.Error("Failed to decode message", utils.Err(err))
+		renderError(w, r, http.StatusBadRequest, "Error while parse request")
+		return false
+	}
+	return true
+}
+
+func responseCodec(r *http.Request) (codec.Codec, error) {
+	if format, _ := r.Context().Value(middleware.URLFormatCtxKey).(string); format != "" {
+		if selected, ok := codec.ByName(format); ok {
+			return selected, nil
+		}
+		return nil, codec.ErrNotAcceptable
+	}
+	return codec.Negotiate(r.Header.Get("Accept"))
+}
+
+/* body is encoded before status is written, so encoding failure is still answered with 500 */
+func writeBody(w http.ResponseWriter, selected codec.Codec, status int, v any) {
+	var buf bytes.Buffer
+	if err := selected.Encode(&buf, v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", selected.ContentType())
+	w.WriteHeader(status)
+	_, _ = w.Write(buf.Bytes())
+}
@@ -0,0 +1,14 @@
+package handlers
+
+import (
+	"github.com/go-chi/render"
+	"net/http"
+	"person-service/model"
+	"strconv"
+)
+
+// renderError writes error body together with http status.
+func renderError(w http.ResponseWriter, r *http.Request, status int, msg string) {
+	render.Status(r, status)
+	respond(w, r, model.Error(msg, strconv.Itoa(status)))
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"net/http"
+	"person-service/mappers"
+	"person-service/model"
+	"person-service/services"
+	"strings"
+)
+
+const (
+	expandContacts  = "contacts"
+	expandAddresses = "addresses"
+)
+
+// PersonExpansion services of sub-resources which can be embedded into person responses by expand parameter.
+type PersonExpansion struct {
+	Contacts  *services.ContactService
+	Addresses *services.AddressService
+}
+
+func (e PersonExpansion) names() []string {
+	return []string{expandContacts, expandAddresses}
+}
+
+// expand embed sub-resources requested by expand parameter into person responses.
+func (e PersonExpansion) expand(r *http.Request, expand map[string]bool, persons []model.PersonResponse) error {
+	if len(expand) == 0 || len(persons) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(persons))
+	for index, person := range persons {
+		ids[index] = person.Id
+	}
+
+	if expand[expandContacts] {
+		emails, phones, err := e.Contacts.LoadContacts(r.Context(), ids)
+		if err != nil {
+			return err
+		}
+		mappers.WithContacts(persons, emails, phones)
+	}
+	if expand[expandAddresses] {
+		addresses, err := e.Addresses.LoadAddresses(r.Context(), ids)
+		if err != nil {
+			return err
+		}
+		mappers.WithAddresses(persons, addresses)
+	}
+	return nil
+}
+
+// parseExpand parse comma separated expand parameter (repeated parameter is allowed),
+// writes 400 response for unknown value.
+func parseExpand(w http.ResponseWriter, r *http.Request, allowed ...string) (map[string]bool, bool) {
+	known := make(map[string]bool, len(allowed))
+	for _, value := range allowed {
+		known[value] = true
+	}
+
+	expand := make(map[string]bool)
+	for _, parameter := range r.URL.Query()["expand"] {
+		for _, value := range strings.Split(parameter, ",") {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				continue
+			}
+			if !known[value] {
+				renderError(w, r, http.StatusBadRequest,
+					fmt.Sprintf("Parameter expand must be one of %s", strings.Join(allowed, ", ")))
+				return nil, false
+			}
+			expand[value] = true
+		}
+	}
+	return expand, true
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_ParseExpand(t *testing.T) {
+	t.Run("comma separated and repeated values are merged", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		expand, ok := parseExpand(w, httptest.NewRequest(http.MethodGet, "/?expand=contacts,+&expand=notes", nil), "contacts", "notes")
+
+		assert.True(t, ok)
+		assert.Equal(t, map[string]bool{"contacts": true, "notes": true}, expand)
+	})
+
+	t.Run("unknown value is rejected", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		_, ok := parseExpand(w, httptest.NewRequest(http.MethodGet, "/?expand=friends", nil), "contacts")
+
+		assert.False(t, ok)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
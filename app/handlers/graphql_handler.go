@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5/middleware"
+	"golang.org/x/exp/slog"
+	"net/http"
+	"person-service/codec"
+	"person-service/graph"
+	"person-service/utils"
+)
+
+// Graphql executes graphql request, query is read from JSON body of POST or from parameters of GET request.
+// Mutations are allowed only with POST. Response with errors and without data is answered with 400,
+// partial results are returned with 200 together with errors.
+func Graphql(logger *slog.Logger, schema *graph.Schema) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.graphql"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req graph.Request
+		switch r.Method {
+		case http.MethodPost:
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				logger.Error("Failed to decode graphql request", utils.Err(err))
+				writeBody(w, codec.JSON, http.StatusBadRequest, graphqlError("Request body is not valid graphql request"))
+				return
+			}
+		case http.MethodGet:
+			query := r.URL.Query()
+			req.Query = query.Get("query")
+			req.OperationName = query.Get("operationName")
+			if variables := query.Get("variables"); variables != "" {
+				if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+					writeBody(w, codec.JSON, http.StatusBadRequest, graphqlError("Parameter variables is not valid JSON object"))
+					return
+				}
+			}
+		}
+
+		if req.Query == "" {
+			writeBody(w, codec.JSON, http.StatusBadRequest, graphqlError("Query is required"))
+			return
+		}
+
+		result := schema.Execute(r.Context(), req, r.Method == http.MethodPost)
+		status := http.StatusOK
+		if result.Data == nil && result.HasErrors() {
+			status = http.StatusBadRequest
+		}
+		writeBody(w, codec.JSON, status, result)
+	}
+}
+
+/* error of request which was not executed in graphql response format */
+func graphqlError(msg string) map[string]any {
+	return map[string]any{"errors": []map[string]string{{"message": msg}}}
+}
@@ -0,0 +1,423 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"golang.org/x/exp/slog"
+	"net/http"
+	"person-service/db/repository"
+	"person-service/mappers"
+	"person-service/model"
+	"person-service/services"
+	"person-service/utils"
+	"strconv"
+)
+
+// GroupsPath resource of person groups.
+const GroupsPath = "/api/v2/groups"
+
+// CreateGroup godoc
+// @Summary      Create group
+// @Description  Create group of persons, group with parentId is child of existing group. Names are unique among siblings.
+// @Tags         groups
+// @Accept       json
+// @Accept       xml
+// @Accept       application/msgpack
+// @Accept       application/cbor
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param  		 request	body    	model.GroupRequest  	true  "Model of group."
+// @Success      201  		{object}   	model.GroupResponse
+// @Failure      400  		{object}   	model.ErrorResponse
+// @Failure      409  		{object}   	model.ErrorResponse
+// @Failure      415  		{object}   	model.ErrorResponse
+// @Router       /v2/groups [post]
+func CreateGroup(logger *slog.Logger, service *services.GroupService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.createGroup"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req model.GroupRequest
+		if !decodeRequest(w, r, logger, &req) {
+			return
+		}
+
+		saved, err := service.CreateGroup(r.Context(), mappers.ToGroup(req))
+		if err != nil {
+			renderGroupError(w, r, logger, err, "Error while save group")
+			return
+		}
+
+		logger.Info("Successfully save group", slog.String("id", saved.Id.String()))
+		w.Header().Set("Location", fmt.Sprintf("%s/%s", GroupsPath, saved.Id))
+		render.Status(r, http.StatusCreated)
+		respond(w, r, mappers.ToGroupResponse(saved))
+	}
+}
+
+// UpdateGroup godoc
+// @Summary      Update group
+// @Description  Rename group or move it under another parent, group without parentId becomes root group.
+// @Description  Group must not be moved under itself or its descendant.
+// @Tags         groups
+// @Accept       json
+// @Accept       xml
+// @Accept       application/msgpack
+// @Accept       application/cbor
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    		path    	string  			true  	"ID of group."
+// @Param  		 request	body    	model.GroupRequest  true  	"Model of group."
+// @Success      200  		{object}   	model.GroupResponse
+// @Failure      400  		{object}   	model.ErrorResponse
+// @Failure      404  		{object}   	model.ErrorResponse
+// @Failure      409  		{object}   	model.ErrorResponse
+// @Failure      415  		{object}   	model.ErrorResponse
+// @Router       /v2/groups/{id} [put]
+func UpdateGroup(logger *slog.Logger, service *services.GroupService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.updateGroup"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+		var req model.GroupRequest
+		if !decodeRequest(w, r, logger, &req) {
+			return
+		}
+
+		group := mappers.ToGroup(req)
+		group.Id = id
+		updated, err := service.UpdateGroup(r.Context(), group)
+		if err != nil {
+			renderGroupError(w, r, logger, err, fmt.Sprintf("Error while update group %s", id))
+			return
+		}
+
+		logger.Info("Successfully update group", slog.String("id", id.String()))
+		respond(w, r, mappers.ToGroupResponse(updated))
+	}
+}
+
+// DeleteGroup godoc
+// @Summary      Delete group
+// @Description  Delete group by groups.delete-mode: restrict responds 409 for group with children or members,
+// @Description  cascade deletes descendants and memberships together with group. Persons are never deleted.
+// @Tags         groups
+// @Param		 id    path    string  	true  	"ID of group."
+// @Success      204
+// @Failure      404  {object}   model.ErrorResponse
+// @Failure      409  {object}   model.ErrorResponse
+// @Router       /v2/groups/{id} [delete]
+func DeleteGroup(logger *slog.Logger, service *services.GroupService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.deleteGroup"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+
+		deleted, err := service.DeleteGroup(r.Context(), id)
+		if err != nil {
+			renderGroupError(w, r, logger, err, fmt.Sprintf("Error while delete group %s", id))
+			return
+		}
+		if !deleted {
+			renderError(w, r, http.StatusNotFound, fmt.Sprintf("Group not found by id, with %s", id))
+			return
+		}
+
+		logger.Info("Group was successfully deleted", slog.String("id", id.String()))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// FindGroup godoc
+// @Summary      Find group
+// @Description  Find group by id
+// @Tags         groups
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    path    string  	true  	"ID of group."
+// @Success      200  {object}   model.GroupResponse
+// @Failure      404  {object}   model.ErrorResponse
+// @Router       /v2/groups/{id} [get]
+func FindGroup(logger *slog.Logger, service *services.GroupService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.findGroup"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+
+		group, err := service.FindGroup(r.Context(), id)
+		if err != nil {
+			renderGroupError(w, r, logger, err, fmt.Sprintf("Error while find group %s", id))
+			return
+		}
+
+		respond(w, r, mappers.ToGroupResponse(group))
+	}
+}
+
+// LoadGroups godoc
+// @Summary      Load groups
+// @Description  Load all groups ordered by name, hierarchy is given by parentId
+// @Tags         groups
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Success      200  {array}   model.GroupResponse
+// @Router       /v2/groups [get]
+func LoadGroups(logger *slog.Logger, service *services.GroupService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.loadGroups"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		groups, err := service.LoadGroups(r.Context())
+		if err != nil {
+			renderGroupError(w, r, logger, err, "Error while loading groups")
+			return
+		}
+
+		respond(w, r, mappers.ToGroupsResponse(groups))
+	}
+}
+
+// AddGroupMembers godoc
+// @Summary      Add members of group
+// @Description  Add up to api.batch-limit existing persons to group, persons which already are members are skipped.
+// @Tags         groups
+// @Accept       json
+// @Accept       xml
+// @Accept       application/msgpack
+// @Accept       application/cbor
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    		path    	string  				true  	"ID of group."
+// @Param  		 request	body    	model.MembersRequest  	true  	"Ids of persons."
+// @Success      200  		{object}   	model.MembersResponse
+// @Failure      400  		{object}   	model.ErrorResponse
+// @Failure      404  		{object}   	model.ErrorResponse
+// @Failure      413  		{object}   	model.ErrorResponse
+// @Failure      415  		{object}   	model.ErrorResponse
+// @Router       /v2/groups/{id}/members [post]
+func AddGroupMembers(logger *slog.Logger, service *services.GroupService, limit int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.addGroupMembers"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+		personIds, ok := decodeMembersRequest(w, r, logger, limit)
+		if !ok {
+			return
+		}
+
+		added, err := service.AddMembers(r.Context(), id, personIds)
+		if err != nil {
+			renderGroupError(w, r, logger, err, fmt.Sprintf("Error while add members of group %s", id))
+			return
+		}
+
+		logger.Info("Successfully add group members", slog.String("id", id.String()), slog.Int64("added", added))
+		respond(w, r, model.MembersResponse{Changed: added})
+	}
+}
+
+// RemoveGroupMembers godoc
+// @Summary      Remove members of group
+// @Description  Remove up to api.batch-limit persons from group, persons which are not members are skipped.
+// @Tags         groups
+// @Accept       json
+// @Accept       xml
+// @Accept       application/msgpack
+// @Accept       application/cbor
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    		path    	string  				true  	"ID of group."
+// @Param  		 request	body    	model.MembersRequest  	true  	"Ids of persons."
+// @Success      200  		{object}   	model.MembersResponse
+// @Failure      400  		{object}   	model.ErrorResponse
+// @Failure      404  		{object}   	model.ErrorResponse
+// @Failure      413  		{object}   	model.ErrorResponse
+// @Failure      415  		{object}   	model.ErrorResponse
+// @Router       /v2/groups/{id}/members [delete]
+func RemoveGroupMembers(logger *slog.Logger, service *services.GroupService, limit int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.removeGroupMembers"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+		personIds, ok := decodeMembersRequest(w, r, logger, limit)
+		if !ok {
+			return
+		}
+
+		removed, err := service.RemoveMembers(r.Context(), id, personIds)
+		if err != nil {
+			renderGroupError(w, r, logger, err, fmt.Sprintf("Error while remove members of group %s", id))
+			return
+		}
+
+		logger.Info("Successfully remove group members", slog.String("id", id.String()), slog.Int64("removed", removed))
+		respond(w, r, model.MembersResponse{Changed: removed})
+	}
+}
+
+// LoadGroupMembers godoc
+// @Summary      Load members of group
+// @Description  Load page of group members by 50 rows, members of descendant groups are included with descendants=true
+// @Tags         groups
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    		  path     string  	true  	"ID of group."
+// @Param		 page     	  query    string  	false  	"Page of members, when load by 50 rows."
+// @Param		 descendants  query    bool  	false  	"Include members of descendant groups."
+// @Success      200  {array}    model.PersonResponse
+// @Failure      400  {object}   model.ErrorResponse
+// @Failure      404  {object}   model.ErrorResponse
+// @Router       /v2/groups/{id}/members [get]
+func LoadGroupMembers(logger *slog.Logger, service *services.GroupService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.loadGroupMembers"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+		var descendants bool
+		if value := r.URL.Query().Get("descendants"); value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				renderError(w, r, http.StatusBadRequest, "Parameter descendants must be boolean")
+				return
+			}
+			descendants = parsed
+		}
+
+		page := r.URL.Query().Get("page")
+		persons, err := service.LoadMembers(r.Context(), id, descendants, &page)
+		if err != nil {
+			renderGroupError(w, r, logger, err, fmt.Sprintf("Error while loading members of group %s", id))
+			return
+		}
+
+		logger.Info("Successfully loaded group members", slog.Int("count", len(persons)))
+		respond(w, r, mappers.ToPersonsResponse(persons))
+	}
+}
+
+// decodeMembersRequest decode person ids of bulk membership change, writes 400, 413 or 415 response when it is not valid.
+func decodeMembersRequest(w http.ResponseWriter, r *http.Request, logger *slog.Logger, limit int) ([]uuid.UUID, bool) {
+	var req model.MembersRequest
+	if !decodeRequest(w, r, logger, &req) {
+		return nil, false
+	}
+
+	if len(req.PersonIds) > limit {
+		renderError(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("Request must contain at most %d persons", limit))
+		return nil, false
+	}
+	return req.PersonIds, true
+}
+
+// parseMembershipFilter set tags (all must match) and group (descendants are included) of listing filter,
+// writes 400 response when they are not valid.
+func parseMembershipFilter(w http.ResponseWriter, r *http.Request, filter *repository.PersonFilter) bool {
+	for _, value := range r.URL.Query()["tag"] {
+		tag, err := services.NormalizeTag(value)
+		if err != nil {
+			renderError(w, r, http.StatusBadRequest, err.Error())
+			return false
+		}
+		filter.Tags = append(filter.Tags, tag)
+	}
+
+	if value := r.URL.Query().Get("group"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			renderError(w, r, http.StatusBadRequest, "Parameter group must be uuid")
+			return false
+		}
+		filter.Group = &id
+	}
+	return true
+}
+
+func renderGroupError(w http.ResponseWriter, r *http.Request, logger *slog.Logger, err error, msg string) {
+	var validationErr *services.ValidationError
+	var pgErr *pgconn.PgError
+
+	switch {
+	case errors.As(err, &validationErr):
+		logger.Error("Group request is not valid", utils.Err(err))
+		renderError(w, r, http.StatusBadRequest, validationErr.Message)
+	case errors.Is(err, pgx.ErrNoRows):
+		logger.Error("Group not found", utils.Err(err))
+		renderError(w, r, http.StatusNotFound, "Group not found")
+	case errors.Is(err, services.ErrGroupNotEmpty):
+		logger.Error("Group is not empty", utils.Err(err))
+		renderError(w, r, http.StatusConflict, "Group has child groups or members")
+	case errors.As(err, &pgErr) && pgErr.Code == uniqueViolation:
+		logger.Error("Group conflicts with existing one", utils.Err(err))
+		renderError(w, r, http.StatusConflict, "Group with the same name already exists under parent")
+	default:
+		logger.Error(msg, utils.Err(err))
+		renderError(w, r, http.StatusInternalServerError, msg)
+	}
+}
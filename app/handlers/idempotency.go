@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5/middleware"
+	"golang.org/x/exp/slog"
+	"io"
+	"net/http"
+	"person-service/services"
+	"person-service/utils"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+// Idempotency middleware executes request with Idempotency-Key header once per key and principal,
+// retry with the same key replays stored response, the same key with different payload gets 422.
+// Requests without header are passed through.
+func Idempotency(logger *slog.Logger, service *services.IdempotencyService) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			const op = "handlers.idempotency"
+
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			logger := logger.With(
+				slog.String("op", op),
+				slog.String("request_id", middleware.GetReqID(r.Context())),
+				slog.String("idempotency_key", key),
+			)
+
+			if len(key) > maxIdempotencyKeyLength {
+				renderError(w, r, http.StatusBadRequest, fmt.Sprintf("Header %s must be at most %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLength))
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				logger.Error("Failed to read message", utils.Err(err))
+				renderError(w, r, http.StatusBadRequest, "Error while parse request")
+				return
+			}
+
+			hash := sha256.New()
+			_, _ = fmt.Fprintf(hash, "%s %s\n", r.Method, r.URL.Path)
+			hash.Write(body)
+
+			response, replayed, err := service.Execute(r.Context(), Principal(r.Context()), key, hash.Sum(nil), func(ctx context.Context) services.IdempotentResponse {
+				recorder := &responseRecorder{header: make(http.Header), status: http.StatusOK}
+				request := r.WithContext(ctx)
+				request.Body = io.NopCloser(bytes.NewReader(body))
+
+				next.ServeHTTP(recorder, request)
+				return recorder.response()
+			})
+
+			if errors.Is(err, services.ErrIdempotencyKeyReused) {
+				logger.Error("Idempotency key was reused with different payload")
+				renderError(w, r, http.StatusUnprocessableEntity, fmt.Sprintf("Header %s was already used for different request", IdempotencyKeyHeader))
+				return
+			} else if err != nil {
+				logger.Error("Failed to execute idempotent request", utils.Err(err))
+				renderError(w, r, http.StatusInternalServerError, "Error while execute request")
+				return
+			}
+
+			for name, value := range response.Headers {
+				w.Header().Set(name, value)
+			}
+			if replayed {
+				logger.Info("Replay stored response")
+				w.Header().Set(IdempotentReplayedHeader, "true")
+			}
+			w.WriteHeader(response.Status)
+			_, _ = w.Write(response.Body)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// responseRecorder buffer response of handler until it is stored.
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+	wrote  bool
+}
+
+func (rr *responseRecorder) Header() http.Header {
+	return rr.header
+}
+
+func (rr *responseRecorder) WriteHeader(status int) {
+	if !rr.wrote {
+		rr.status = status
+		rr.wrote = true
+	}
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	rr.wrote = true
+	return rr.body.Write(b)
+}
+
+func (rr *responseRecorder) response() services.IdempotentResponse {
+	headers := make(map[string]string, len(rr.header))
+	for name := range rr.header {
+		headers[name] = rr.header.Get(name)
+	}
+	return services.IdempotentResponse{Status: rr.status, Headers: headers, Body: rr.body.Bytes()}
+}
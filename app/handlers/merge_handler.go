@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"golang.org/x/exp/slog"
+	"net/http"
+	"person-service/mappers"
+	"person-service/model"
+	"person-service/services"
+	"person-service/utils"
+	"strings"
+)
+
+// FindDuplicates godoc
+// @Summary      Find duplicates of person
+// @Description  Find persons which may duplicate person ordered by score. Names are compared by trigram similarity of
+// @Description  transliterated names (Cyrillic and Latin spellings match, order of first and last name does not
+// @Description  matter), shared email or phone and the same birth date raise score. Candidates below
+// @Description  duplicates.min-score are skipped, at most duplicates.limit are returned.
+// @Tags         duplicates
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    	 path     string  	true  	"ID of person entity."
+// @Success      200  {array}    model.DuplicateResponse
+// @Failure      400  {object}   model.ErrorResponse
+// @Failure      404  {object}   model.ErrorResponse
+// @Router       /v2/persons/{id}/duplicates [get]
+// @Router       /v1/person/{id}/duplicates [get]
+func FindDuplicates(logger *slog.Logger, service *services.MergeService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.findDuplicates"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		personId, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+
+		duplicates, err := service.FindDuplicates(r.Context(), personId)
+		if err != nil {
+			renderMergeError(w, r, logger, err, fmt.Sprintf("Error while find duplicates of person %s", personId))
+			return
+		}
+
+		logger.Info("Duplicates were found", slog.String("id", personId.String()), slog.Int("count", len(duplicates)))
+		respond(w, r, mappers.ToDuplicatesResponse(duplicates))
+	}
+}
+
+// MergePersons godoc
+// @Summary      Merge duplicate into person
+// @Description  Merge duplicate into person in path (survivor). Fields choose source of person fields, contacts,
+// @Description  addresses, attachments, group and tag memberships and relationships of duplicate are moved to survivor
+// @Description  (conflicting ones are dropped, survivor keeps its primary contacts), avatar of duplicate is dropped.
+// @Description  Duplicate is deleted, merge is recorded in history and requests of duplicate id are redirected to
+// @Description  survivor with 308.
+// @Tags         duplicates
+// @Accept       json
+// @Accept       xml
+// @Accept       application/msgpack
+// @Accept       application/cbor
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    		path    	string  			true  	"ID of surviving person."
+// @Param  		 request	body    	model.MergeRequest  true  	"Duplicate and sources of fields."
+// @Success      200  		{object}   	model.MergeResponse
+// @Failure      400  		{object}   	model.ErrorResponse
+// @Failure      404  		{object}   	model.ErrorResponse
+// @Failure      409  		{object}   	model.ErrorResponse
+// @Failure      415  		{object}   	model.ErrorResponse
+// @Router       /v2/persons/{id}/merge [post]
+// @Router       /v1/person/{id}/merge [post]
+func MergePersons(logger *slog.Logger, service *services.MergeService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.mergePersons"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		survivorId, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+		var req model.MergeRequest
+		if !decodeRequest(w, r, logger, &req) {
+			return
+		}
+
+		person, merge, err := service.MergePersons(r.Context(), survivorId, req.DuplicateId, mappers.ToMergeFields(req.Fields))
+		if err != nil {
+			renderMergeError(w, r, logger, err, fmt.Sprintf("Error while merge person %s into %s", req.DuplicateId, survivorId))
+			return
+		}
+
+		logger.Info("Persons were merged", slog.String("survivor", survivorId.String()), slog.String("merged", req.DuplicateId.String()))
+		response := mappers.ToMergeResponse(merge)
+		survivor := mappers.ToPersonResponse(person)
+		response.Person = &survivor
+		respond(w, r, response)
+	}
+}
+
+// LoadMerges godoc
+// @Summary      Load merge history of person
+// @Description  Load merges into person in order of merge, merged persons are as they were before merge
+// @Tags         duplicates
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    	 path     string  	true  	"ID of person entity."
+// @Success      200  {array}    model.MergeResponse
+// @Failure      400  {object}   model.ErrorResponse
+// @Failure      404  {object}   model.ErrorResponse
+// @Router       /v2/persons/{id}/merges [get]
+func LoadMerges(logger *slog.Logger, service *services.MergeService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.loadMerges"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		personId, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+
+		merges, err := service.LoadMerges(r.Context(), personId)
+		if err != nil {
+			renderMergeError(w, r, logger, err, fmt.Sprintf("Error while load merges of person %s", personId))
+			return
+		}
+
+		respond(w, r, mappers.ToMergesResponse(merges))
+	}
+}
+
+// redirectMerged redirect request of merged person to person it was merged into, reports whether it was redirected.
+func redirectMerged(w http.ResponseWriter, r *http.Request, service *services.PersonService, personId uuid.UUID) bool {
+	survivorId, err := service.FindSurvivor(r.Context(), personId)
+	if err != nil {
+		return false
+	}
+
+	w.Header().Set("Location", strings.Replace(r.URL.RequestURI(), chi.URLParam(r, "id"), survivorId.String(), 1))
+	w.WriteHeader(http.StatusPermanentRedirect)
+	return true
+}
+
+func renderMergeError(w http.ResponseWriter, r *http.Request, logger *slog.Logger, err error, msg string) {
+	var validationErr *services.ValidationError
+	var pgErr *pgconn.PgError
+
+	switch {
+	case errors.As(err, &validationErr):
+		logger.Error("Merge request is not valid", utils.Err(err))
+		renderError(w, r, http.StatusBadRequest, validationErr.Message)
+	case errors.Is(err, pgx.ErrNoRows):
+		logger.Error("Person not found", utils.Err(err))
+		renderError(w, r, http.StatusNotFound, "Person not found")
+	case errors.As(err, &pgErr) && pgErr.Code == uniqueViolation:
+		logger.Error("Merged person conflicts with existing one", utils.Err(err))
+		renderError(w, r, http.StatusConflict, "Person with the same login already exists")
+	default:
+		logger.Error(msg, utils.Err(err))
+		renderError(w, r, http.StatusInternalServerError, msg)
+	}
+}
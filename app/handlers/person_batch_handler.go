@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"golang.org/x/exp/slog"
+	"net/http"
+	"person-service/db/entity"
+	"person-service/mappers"
+	"person-service/model"
+	"person-service/services"
+	"person-service/utils"
+)
+
+// BatchPersons godoc
+// @Summary      Create, upsert or delete persons in one request
+// @Description  Execute up to api.batch-limit operations, results are returned in request order.
+// @Description  Atomic mode responds 200 when all operations were committed, otherwise status of first failed operation.
+// @Description  Best-effort mode commits every successful operation and responds 200.
+// @Tags         persons
+// @Accept       json
+// @Accept       xml
+// @Accept       application/msgpack
+// @Accept       application/cbor
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param  		 request	body    	model.PersonBatchRequest  	true  "Operations of batch."
+// @Param  		 Idempotency-Key	header    	string  	false  "Key of request, retry with the same key replays first response."
+// @Success      200  		{object}   	model.PersonBatchResponse
+// @Failure      400  		{object}   	model.PersonBatchResponse
+// @Failure      404  		{object}   	model.PersonBatchResponse
+// @Failure      409  		{object}   	model.PersonBatchResponse
+// @Failure      413  		{object}   	model.ErrorResponse
+// @Failure      422  		{object}   	model.ErrorResponse
+// @Failure      415  		{object}   	model.ErrorResponse
+// @Router       /v2/persons/batch [post]
+func BatchPersons(logger *slog.Logger, service *services.PersonService, limit int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.batchPersons"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req model.PersonBatchRequest
+		if !decodeRequest(w, r, logger, &req) {
+			return
+		}
+
+		if req.Mode == "" {
+			req.Mode = model.BatchModeAtomic
+		}
+		if req.Mode != model.BatchModeAtomic && req.Mode != model.BatchModeBestEffort {
+			renderError(w, r, http.StatusBadRequest, fmt.Sprintf("Unknown batch mode: %s", req.Mode))
+			return
+		}
+		if len(req.Operations) == 0 {
+			renderError(w, r, http.StatusBadRequest, "Batch must contain at least one operation")
+			return
+		}
+		if len(req.Operations) > limit {
+			renderError(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("Batch must contain at most %d operations", limit))
+			return
+		}
+
+		operations := make([]services.PersonOperation, len(req.Operations))
+		for index, operation := range req.Operations {
+			var err error
+			if operations[index], err = toPersonOperation(operation); err != nil {
+				renderError(w, r, http.StatusBadRequest, fmt.Sprintf("Operation %d: %s", index, err))
+				return
+			}
+		}
+
+		results, err := service.BatchPersons(r.Context(), operations, req.Mode == model.BatchModeAtomic)
+
+		response := model.PersonBatchResponse{
+			Mode:      req.Mode,
+			Committed: err == nil,
+			Results:   make([]model.PersonBatchResult, len(results)),
+		}
+		for index, result := range results {
+			response.Results[index] = toPersonBatchResult(index, req.Operations[index].Op, operations[index], result)
+		}
+
+		if err != nil {
+			logger.Error("Batch was rolled back", utils.Err(err))
+			render.Status(r, batchErrorStatus(err))
+		} else {
+			logger.Info("Batch was successfully executed", slog.Int("count", len(results)))
+		}
+		respond(w, r, response)
+	}
+}
+
+func toPersonOperation(operation model.PersonBatchOperation) (services.PersonOperation, error) {
+	var person entity.Person
+	if operation.Person != nil {
+		var err error
+		if person, err = mappers.ToPerson(*operation.Person); err != nil {
+			return services.PersonOperation{}, err
+		}
+	}
+	/* id of operation has priority over id of person */
+	if operation.Id != uuid.Nil {
+		id := operation.Id
+		person.Id = &id
+	}
+
+	return services.PersonOperation{Type: services.OperationType(operation.Op), Person: person}, nil
+}
+
+func toPersonBatchResult(index int, op string, operation services.PersonOperation, result services.PersonOperationResult) model.PersonBatchResult {
+	batchResult := model.PersonBatchResult{Index: index, Op: op}
+
+	if result.Err != nil {
+		batchResult.Status = batchErrorStatus(result.Err)
+		batchResult.Error = batchErrorMessage(result.Err)
+		if operation.Person.Id != nil && !utils.IsNullableUUID(operation.Person.Id) {
+			batchResult.Id = operation.Person.Id
+		}
+		return batchResult
+	}
+
+	batchResult.Id = result.Person.Id
+	switch {
+	case operation.Type == services.OperationDelete:
+		batchResult.Status = http.StatusNoContent
+	case result.Created:
+		batchResult.Status = http.StatusCreated
+		person := mappers.ToPersonResponse(result.Person)
+		batchResult.Person = &person
+	default:
+		batchResult.Status = http.StatusOK
+		person := mappers.ToPersonResponse(result.Person)
+		batchResult.Person = &person
+	}
+	return batchResult
+}
+
+func batchErrorStatus(err error) int {
+	var validationErr *services.ValidationError
+	var pgErr *pgconn.PgError
+
+	switch {
+	case errors.As(err, &validationErr):
+		return http.StatusBadRequest
+	case errors.Is(err, pgx.ErrNoRows):
+		return http.StatusNotFound
+	case errors.As(err, &pgErr) && pgErr.Code == uniqueViolation:
+		return http.StatusConflict
+	case errors.Is(err, services.ErrBatchAborted):
+		return http.StatusFailedDependency
+	case errors.Is(err, services.ErrQuotaExceeded):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func batchErrorMessage(err error) string {
+	var validationErr *services.ValidationError
+
+	switch batchErrorStatus(err) {
+	case http.StatusBadRequest:
+		errors.As(err, &validationErr)
+		return validationErr.Message
+	case http.StatusNotFound:
+		return "Person not found"
+	case http.StatusConflict:
+		return "Person with the same login or id already exists"
+	case http.StatusFailedDependency:
+		return services.ErrBatchAborted.Error()
+	case http.StatusForbidden:
+		return "Quota of persons is exceeded"
+	default:
+		return "Error while execute operation"
+	}
+}
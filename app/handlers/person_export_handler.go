@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5/middleware"
+	"golang.org/x/exp/slog"
+	"net/http"
+	"person-service/db/entity"
+	"person-service/db/repository"
+	"person-service/exporter"
+	"person-service/services"
+	"person-service/utils"
+	"strings"
+	"time"
+)
+
+const (
+	/* rows fetched from cursor and written between flushes */
+	exportChunkSize = 1000
+	/* write deadline is extended by timeout after every flushed chunk */
+	exportWriteTimeout = 30 * time.Second
+)
+
+// ExportPersons godoc
+// @Summary      Export persons
+// @Description  Stream all persons ordered by id as file, rows are read from server-side cursor and flushed by chunks.
+// @Description  Accepts the same filters as listing, including attr.<name>=value of custom attributes.
+// @Tags         persons
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param		 format    query    string  	false  	"File format: csv (default), ndjson, xlsx."
+// @Param		 login     query    string  	false  	"Login of person entity."
+// @Param		 country   query    string  	false  	"ISO 3166-1 alpha-2 country of person address."
+// @Param		 city      query    string  	false  	"City of person address (case-insensitive)."
+// @Param		 tag       query    []string  	false  	"Tag of person, repeated parameter requires all tags." collectionFormat(multi)
+// @Param		 group     query    string  	false  	"ID of group, members of its descendant groups match too."
+// @Param		 minAge    query    int  		false  	"Minimal age of person in full years."
+// @Param		 maxAge    query    int  		false  	"Maximal age of person in full years."
+// @Success      200
+// @Failure      400  {object}   model.ErrorResponse
+// @Router       /v2/persons/export [get]
+// @Router       /v1/persons/export [get]
+func ExportPersons(logger *slog.Logger, service *services.PersonService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.exportPersons"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		name := r.URL.Query().Get("format")
+		if name == "" {
+			name = exporter.FormatCsv
+		}
+		format, err := exporter.Lookup(name)
+		if err != nil {
+			renderError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		filter := repository.PersonFilter{
+			Login:   r.URL.Query().Get("login"),
+			Country: strings.ToUpper(r.URL.Query().Get("country")),
+			City:    r.URL.Query().Get("city"),
+		}
+		if !parseMembershipFilter(w, r, &filter) || !parseAgeFilter(w, r, &filter) {
+			return
+		}
+		if filter.Attributes, err = service.AttributeFilter(r.Context(), parseAttributeFilter(r)); err != nil {
+			renderPersonError(w, r, logger, err, "Error while export persons")
+			return
+		}
+		controller := http.NewResponseController(w)
+
+		var writer exporter.Writer
+		count := 0
+		err = service.ExportPersons(r.Context(), filter, exportChunkSize, func(person entity.Person) error {
+			if writer == nil {
+				started, err := startExport(w, format, controller)
+				if err != nil {
+					return err
+				}
+				writer = started
+			}
+			if err := writer.Write(person); err != nil {
+				return err
+			}
+
+			count++
+			if count%exportChunkSize == 0 {
+				return flushExport(writer, controller)
+			}
+			return nil
+		})
+
+		if err != nil && writer == nil {
+			logger.Error("Failed to export persons", utils.Err(err))
+			renderError(w, r, http.StatusInternalServerError, "Error while export persons")
+			return
+		} else if err != nil {
+			/* response is already started, connection is aborted so client sees broken file */
+			logger.Error("Export was interrupted", utils.Err(err), slog.Int("count", count))
+			panic(http.ErrAbortHandler)
+		}
+
+		if writer == nil {
+			if writer, err = startExport(w, format, controller); err != nil {
+				logger.Error("Failed to export persons", utils.Err(err))
+				return
+			}
+		}
+		if err := writer.Close(); err != nil {
+			logger.Error("Failed to finish export", utils.Err(err))
+			return
+		}
+
+		logger.Info("Successfully exported persons", slog.String("format", name), slog.Int("count", count))
+	}
+}
+
+// startExport write headers of file response and create writer of format.
+func startExport(w http.ResponseWriter, format exporter.Format, controller *http.ResponseController) (exporter.Writer, error) {
+	if err := extendWriteDeadline(controller); err != nil {
+		return nil, err
+	}
+
+	filename := fmt.Sprintf("persons-%s.%s", time.Now().UTC().Format("20060102T150405Z"), format.Extension)
+	w.Header().Set("Content-Type", format.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	return format.NewWriter(w)
+}
+
+func flushExport(writer exporter.Writer, controller *http.ResponseController) error {
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	if err := controller.Flush(); err != nil {
+		return err
+	}
+	return extendWriteDeadline(controller)
+}
+
+// extendWriteDeadline server write timeout is meant for regular responses, export extends it by every chunk.
+func extendWriteDeadline(controller *http.ResponseController) error {
+	err := controller.SetWriteDeadline(time.Now().Add(exportWriteTimeout))
+	if errors.Is(err, http.ErrNotSupported) {
+		return nil
+	}
+	return err
+}
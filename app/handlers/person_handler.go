@@ -1,68 +1,91 @@
 package handlers
 
 import (
-	"database/sql"
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
-	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"golang.org/x/exp/slog"
+	"io"
+	"mime"
 	"net/http"
 	"person-service/db/entity"
 	"person-service/db/repository"
 	"person-service/mappers"
 	"person-service/model"
+	"person-service/services"
 	"person-service/utils"
+	"strconv"
+	"strings"
 )
 
-type PersonRepositoryImpl interface {
-	SavePerson(p entity.Person) (entity.Person, error)
-	DeletePerson(id uuid.UUID) (string, error)
-	UpdatePerson(p entity.Person) (entity.Person, error)
-	FindPersonById(id *uuid.UUID) (entity.Person, error)
-	FindPersonByLogin(login *string) (entity.Person, error)
-	LoadPersons(page *string) ([]entity.Person, error)
-}
+// PersonsPath path of persons resource, used for Location of created persons.
+const PersonsPath = "/api/v2/persons"
+
+const uniqueViolation = "23505"
+
+const (
+	mergePatchType = "application/merge-patch+json"
+	jsonPatchType  = "application/json-patch+json"
+)
 
 // CreatePerson godoc
 // @Summary      Create new person entity
-// @Description  Create new person entity
+// @Description  Create new person entity, Location header points to created resource
 // @Tags         persons
 // @Accept       json
+// @Accept       xml
+// @Accept       application/msgpack
+// @Accept       application/cbor
 // @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
 // @Param  		 request	body    	model.PersonRequest  	true  "Model for create new person entity."
-// @Success      200  		{array}   	model.PersonResponse
-// @Router       /person/create [post]
-func CreatePerson(logger *slog.Logger, impl *repository.PersonRepositoryImpl) http.HandlerFunc {
+// @Param  		 Idempotency-Key	header    	string  	false  "Key of request, retry with the same key replays first response."
+// @Success      201  		{object}   	model.PersonResponse
+// @Failure      400  		{object}   	model.ErrorResponse
+// @Failure      409  		{object}   	model.ErrorResponse
+// @Failure      422  		{object}   	model.ErrorResponse
+// @Failure      415  		{object}   	model.ErrorResponse
+// @Router       /v2/persons [post]
+// @Router       /v1/person/create [post]
+func CreatePerson(logger *slog.Logger, service *services.PersonService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.createPerson"
-		logger = logger.With(
+		logger := logger.With(
 			slog.String("op", op),
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
 
-		var req model.PersonRequest
-		err := render.DecodeJSON(r.Body, &req)
-
-		if err != nil {
-			logger.Error("Failed to decode message", utils.Err(err))
-			render.JSON(w, r, model.Error("Error while parse request", model.InternalError))
+		req, ok := decodePersonRequest(w, r, logger)
+		if !ok {
 			return
 		}
 
 		logger.Info("Request body decoded", slog.Any("request", req))
-		entityToSave := mappers.ToPerson(req)
-		savedPerson, err := impl.SavePerson(entityToSave)
+		entityToSave, err := mappers.ToPerson(req)
+		if err != nil {
+			renderError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		savedPerson, err := service.CreatePerson(r.Context(), entityToSave)
 
 		if err != nil {
-			logger.Error("Error while save new person to database", utils.Err(err))
-			render.JSON(w, r, model.Error("Error while save new entity", model.InternalError))
+			renderPersonError(w, r, logger, err, "Error while save new entity")
 			return
 		}
 
 		logger.Info("Successfully save new person", slog.Any("saved_person", savedPerson))
-		render.JSON(w, r, mappers.ToPersonResponse(savedPerson))
+		w.Header().Set("Location", fmt.Sprintf("%s/%s", PersonsPath, savedPerson.Id))
+		render.Status(r, http.StatusCreated)
+		respond(w, r, mappers.ToPersonResponse(savedPerson))
 	}
 }
 
@@ -70,118 +93,257 @@ func CreatePerson(logger *slog.Logger, impl *repository.PersonRepositoryImpl) ht
 // @Summary      Delete existing persons
 // @Description  Delete existing persons
 // @Tags         persons
-// @Accept       json
-// @Produce      json
-// @Param  		 id    		query    	string  					true  	"ID for remove person entity"
-// @Success      200  		{array} 	model.PersonDeleteResponse
-// @Router       /person/delete [delete]
-func DeletePerson(logger *slog.Logger, impl *repository.PersonRepositoryImpl) http.HandlerFunc {
+// @Param  		 id    		path    	string  					true  	"ID for remove person entity"
+// @Success      204
+// @Failure      400  		{object}   	model.ErrorResponse
+// @Router       /v2/persons/{id} [delete]
+// @Router       /v1/person/delete [delete]
+func DeletePerson(logger *slog.Logger, service *services.PersonService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.deletePerson"
-		logger = logger.With(
+		logger := logger.With(
 			slog.String("op", op),
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
 
-		var deleteId string
-		deleteId = r.URL.Query().Get("id")
-		logger.Info("Request body decoded", slog.Any("entity_id", deleteId))
-
-		id, err := impl.DeletePerson(uuid.MustParse(deleteId))
-		if err != nil {
-			logger.Error("Error while delete person by id", slog.String("entity_id", deleteId), utils.Err(err))
-			render.JSON(w, r, model.Error(fmt.Sprintf("Error while delete entity with id %s", deleteId), model.InternalError))
+		id, ok := parseUuidParam(w, r, "id")
+		if !ok {
 			return
 		}
+		logger.Info("Request body decoded", slog.Any("entity_id", id))
 
-		logger.Info("Person with id was successfully deleted", slog.String("id", deleteId))
+		if _, err := service.DeletePerson(r.Context(), id); err != nil {
+			renderPersonError(w, r, logger, err, fmt.Sprintf("Error while delete entity with id %s", id))
+			return
+		}
 
-		render.JSON(w, r, model.CreateSuccessDeleteResponse(id))
+		logger.Info("Person with id was successfully deleted", slog.String("id", id.String()))
+		w.WriteHeader(http.StatusNoContent)
 	}
 }
 
 // UpdatePerson godoc
 // @Summary      Update existing persons
-// @Description  Update existing persons
+// @Description  Replace existing person, v1 route takes id from body and creates person when id is empty
 // @Tags         persons
 // @Accept       json
+// @Accept       xml
+// @Accept       application/msgpack
+// @Accept       application/cbor
 // @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    		path    	string  				true  	"ID of person entity."
 // @Param  		 request    body    	model.PersonRequest  	true  	"Model for update person entity"
-// @Success      200  		{array}   	model.PersonResponse
-// @Router       /person/update [put]
-func UpdatePerson(logger *slog.Logger, impl *repository.PersonRepositoryImpl) http.HandlerFunc {
+// @Success      200  		{object}   	model.PersonResponse
+// @Failure      400  		{object}   	model.ErrorResponse
+// @Failure      404  		{object}   	model.ErrorResponse
+// @Failure      415  		{object}   	model.ErrorResponse
+// @Router       /v2/persons/{id} [put]
+// @Router       /v1/person/update [put]
+func UpdatePerson(logger *slog.Logger, service *services.PersonService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.updatePerson"
-		logger = logger.With(
+		logger := logger.With(
 			slog.String("op", op),
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
 
-		var req model.PersonRequest
-		err := render.DecodeJSON(r.Body, &req)
-
-		if err != nil {
-			logger.Error("Failed to decode message", utils.Err(err))
-			render.JSON(w, r, model.Error("Error while parse request", model.InternalError))
+		req, ok := decodePersonRequest(w, r, logger)
+		if !ok {
 			return
 		}
 
+		/* resource id has priority over id of body */
+		if chi.URLParam(r, "id") != "" {
+			id, ok := parseUuidParam(w, r, "id")
+			if !ok {
+				return
+			}
+			req.Id = id
+		}
+
 		logger.Info("Request body decoded", slog.Any("request", req))
-		entityToSave := mappers.ToPerson(req)
-		updatePerson, err := impl.UpdatePerson(entityToSave)
+		entityToSave, err := mappers.ToPerson(req)
+		if err != nil {
+			renderError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		updatePerson, err := service.UpdatePerson(r.Context(), entityToSave)
 
 		if err != nil {
-			logger.Error("Error while save new person to database", utils.Err(err))
-			render.JSON(w, r, model.Error("Error while update entity", model.InternalError))
+			renderPersonError(w, r, logger, err, "Error while update entity")
 			return
 		}
 
 		logger.Info("Successfully save new person", slog.Any("updated_person", updatePerson))
-		render.JSON(w, r, mappers.ToPersonResponse(updatePerson))
+		respond(w, r, mappers.ToPersonResponse(updatePerson))
+	}
+}
+
+// PatchPerson godoc
+// @Summary      Partially update existing person
+// @Description  Apply JSON Merge Patch (RFC 7396, also used for application/json) or JSON Patch (RFC 6902) to stored person,
+// @Description  only changed columns are updated. Failed JSON Patch test operation responds with 409.
+// @Tags         persons
+// @Accept       json
+// @Accept       application/merge-patch+json
+// @Accept       application/json-patch+json
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    		path    	string  				true  	"ID of person entity."
+// @Param  		 request    body    	model.PersonRequest  	true  	"Merge patch document or array of JSON Patch operations"
+// @Success      200  		{object}   	model.PersonResponse
+// @Failure      400  		{object}   	model.ErrorResponse
+// @Failure      404  		{object}   	model.ErrorResponse
+// @Failure      409  		{object}   	model.ErrorResponse
+// @Failure      415  		{object}   	model.ErrorResponse
+// @Router       /v2/persons/{id} [patch]
+func PatchPerson(logger *slog.Logger, service *services.PersonService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.patchPerson"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || (mediaType != mergePatchType && mediaType != jsonPatchType && mediaType != "application/json") {
+			renderError(w, r, http.StatusUnsupportedMediaType,
+				fmt.Sprintf("Content-Type must be %s or %s", mergePatchType, jsonPatchType))
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			logger.Error("Failed to read message", utils.Err(err))
+			renderError(w, r, http.StatusBadRequest, "Error while parse request")
+			return
+		}
+
+		patched, err := service.PatchPerson(r.Context(), id, func(person *entity.Person) error {
+			return applyPersonPatch(mediaType, body, person)
+		})
+		if err != nil {
+			renderPersonError(w, r, logger, err, fmt.Sprintf("Error while patch entity with id %s", id))
+			return
+		}
+
+		logger.Info("Successfully patch person", slog.Any("updated_person", patched))
+		respond(w, r, mappers.ToPersonResponse(patched))
 	}
 }
 
+// applyPersonPatch apply patch document to request representation of person.
+// Patch changing age but not birth date drops stored birth date, so patched age gives approximate one.
+func applyPersonPatch(mediaType string, body []byte, person *entity.Person) error {
+	base := mappers.ToPersonRequest(*person)
+	document, err := json.Marshal(base)
+	if err != nil {
+		return err
+	}
+
+	if mediaType == jsonPatchType {
+		var patch jsonpatch.Patch
+		if patch, err = jsonpatch.DecodePatch(body); err == nil {
+			document, err = patch.Apply(document)
+		}
+	} else {
+		document, err = jsonpatch.MergePatch(document, body)
+	}
+	if errors.Is(err, jsonpatch.ErrTestFailed) {
+		return err
+	} else if err != nil {
+		return &services.ValidationError{Message: fmt.Sprintf("Error while apply patch: %s", err)}
+	}
+
+	var req model.PersonRequest
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(&req); err != nil {
+		return &services.ValidationError{Message: fmt.Sprintf("Patched person is not valid: %s", err)}
+	}
+	if req.Timestamp != nil {
+		return &services.ValidationError{Message: "Field timestamp is managed by server and must not be sent"}
+	}
+	if req.Id != *person.Id {
+		return &services.ValidationError{Message: "Field id must not be changed"}
+	}
+	if req.Age != base.Age && req.BirthDate == base.BirthDate {
+		req.BirthDate = ""
+	}
+
+	patched, err := mappers.ToPerson(req)
+	if err != nil {
+		return &services.ValidationError{Message: err.Error()}
+	}
+	*person = patched
+	return nil
+}
+
 // FindPersonById godoc
 // @Summary      Find existing persons
 // @Description  Find existing persons
 // @Tags         persons
 // @Accept       json
 // @Produce      json
-// @Param		 id    query    string  				true  	"ID of person entity."
-// @Success      200  {array}   model.PersonResponse
-// @Router       /person/get [get]
-func FindPersonById(logger *slog.Logger, impl *repository.PersonRepositoryImpl) http.HandlerFunc {
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    	 path    string  				true  	"ID of person entity."
+// @Param		 expand  query   string  				false  	"Embedded sub-resources, comma separated: contacts, addresses."
+// @Success      200  {object}   model.PersonResponse
+// @Failure      400  {object}   model.ErrorResponse
+// @Failure      308  {string}   string  "Person was merged, Location is URL of person it was merged into."
+// @Failure      404  {object}   model.ErrorResponse
+// @Router       /v2/persons/{id} [get]
+// @Router       /v1/person/get/id [get]
+func FindPersonById(logger *slog.Logger, service *services.PersonService, expansion PersonExpansion) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.findPersonById"
 
-		logger = logger.With(
+		logger := logger.With(
 			slog.String("op", op),
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
 
-		var personId string
-		personId = r.URL.Query().Get("id")
+		personId, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+		expand, ok := parseExpand(w, r, expansion.names()...)
+		if !ok {
+			return
+		}
 		logger.Info("Request body decoded", slog.Any("entity_id", personId))
 
-		parsedUuid := uuid.MustParse(personId)
-		person, err := impl.FindPersonById(&parsedUuid)
+		person, err := service.FindPersonById(r.Context(), &personId)
 
-		if err != nil && errors.Is(err, sql.ErrNoRows) {
-			logger.Error("Error while find person by login, person not found", slog.String("personId", personId), utils.Err(err))
-			render.JSON(w, r,
-				model.Error(fmt.Sprintf("Person not found by id, with %s", personId), model.NotFoundError),
-			)
+		if errors.Is(err, pgx.ErrNoRows) && redirectMerged(w, r, service, personId) {
+			logger.Info("Merged person was redirected", slog.String("id", personId.String()))
+			return
+		}
+		if err != nil {
+			renderPersonError(w, r, logger, err, fmt.Sprintf("Error while find entity with id %s", personId))
 			return
-		} else if err != nil {
-			logger.Error("Error while find person by id", slog.String("entity_id", personId), utils.Err(err))
-			render.JSON(w, r,
-				model.Error(fmt.Sprintf("Error while find entity with id %s", personId), model.InternalError),
-			)
+		}
+
+		response := []model.PersonResponse{mappers.ToPersonResponse(person)}
+		if err = expansion.expand(r, expand, response); err != nil {
+			renderPersonError(w, r, logger, err, fmt.Sprintf("Error while expand entity with id %s", personId))
 			return
 		}
 
-		logger.Info("Person with id was successfully found", slog.String("id", personId))
-		render.JSON(w, r, mappers.ToPersonResponse(person))
+		logger.Info("Person with id was successfully found", slog.String("id", personId.String()))
+		respond(w, r, response[0])
 	}
 }
 
@@ -191,14 +353,19 @@ func FindPersonById(logger *slog.Logger, impl *repository.PersonRepositoryImpl)
 // @Tags         persons
 // @Accept       json
 // @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
 // @Param		 login    query    string  				true  	"Login of person entity."
-// @Success      200  {array}   model.PersonResponse
-// @Router       /person/get [get]
-func FindPersonByLogin(logger *slog.Logger, impl *repository.PersonRepositoryImpl) http.HandlerFunc {
+// @Success      200  {object}   model.PersonResponse
+// @Failure      404  {object}   model.ErrorResponse
+// @Deprecated
+// @Router       /v1/person/get/login [get]
+func FindPersonByLogin(logger *slog.Logger, service *services.PersonService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.findPersonByLogin"
 
-		logger = logger.With(
+		logger := logger.With(
 			slog.String("op", op),
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
@@ -206,55 +373,150 @@ func FindPersonByLogin(logger *slog.Logger, impl *repository.PersonRepositoryImp
 		var login string
 		login = r.URL.Query().Get("login")
 		logger.Info("Request body decoded", slog.Any("login", login))
-		person, err := impl.FindPersonByLogin(login)
+		person, err := service.FindPersonByLogin(r.Context(), login)
 
-		if err != nil && errors.Is(err, sql.ErrNoRows) {
-			logger.Error("Error while find person by login, person not found", slog.String("login", login), utils.Err(err))
-			render.JSON(w, r,
-				model.Error(fmt.Sprintf("Person not found by login, with %s", login), model.NotFoundError),
-			)
-			return
-		} else if err != nil {
-			logger.Error("Error while find person by login", slog.String("login", login), utils.Err(err))
-			render.JSON(w, r,
-				model.Error(fmt.Sprintf("Error while find person by login, with %s", login), model.InternalError),
-			)
+		if err != nil {
+			renderPersonError(w, r, logger, err, fmt.Sprintf("Error while find person by login, with %s", login))
 			return
 		}
 
 		logger.Info("Person with login was successfully found", slog.String("login", login))
-		render.JSON(w, r, mappers.ToPersonResponse(person))
+		respond(w, r, mappers.ToPersonResponse(person))
 	}
 }
 
 // LoadPersons godoc
-// @Summary      Load first 50 persons
-// @Description  Load persons
+// @Summary      Load persons
+// @Description  Load page of persons by 50 rows, login filters persons by login, country and city by address
+// @Description  Custom attributes are filtered by attr.<name>=value parameters (attr.department=sales), all of them must match.
 // @Tags         persons
 // @Accept       json
 // @Produce      json
-// @Param		 page    query    string  				true  	"Page of person table, when load by 50 rows."
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 page     query    string  				false  	"Page of person table, when load by 50 rows."
+// @Param		 login    query    string  				false  	"Login of person entity."
+// @Param		 country  query    string  				false  	"ISO 3166-1 alpha-2 country of person address."
+// @Param		 city     query    string  				false  	"City of person address (case-insensitive), matched with country on the same address."
+// @Param		 tag      query    []string  			false  	"Tag of person, repeated parameter requires all tags." collectionFormat(multi)
+// @Param		 group    query    string  				false  	"ID of group, members of its descendant groups match too."
+// @Param		 minAge   query    int  					false  	"Minimal age of person in full years."
+// @Param		 maxAge   query    int  					false  	"Maximal age of person in full years."
+// @Param		 expand   query    string  				false  	"Embedded sub-resources, comma separated: contacts, addresses."
 // @Success      200  {array}   model.PersonResponse
-// @Router       /persons [get]
-func LoadPersons(logger *slog.Logger, impl *repository.PersonRepositoryImpl) http.HandlerFunc {
+// @Failure      400  {object}  model.ErrorResponse
+// @Router       /v2/persons [get]
+// @Router       /v1/persons [get]
+func LoadPersons(logger *slog.Logger, service *services.PersonService, expansion PersonExpansion) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.loadPersons"
-		logger = logger.With(
+		logger := logger.With(
 			slog.String("op", op),
 			slog.String("request_id", middleware.GetReqID(r.Context())))
 
-		var page string
-		page = r.URL.Query().Get("page")
-		logger.Info("Request body decoded", slog.Any("page", page))
-		persons, err := impl.LoadPersons(&page)
+		expand, ok := parseExpand(w, r, expansion.names()...)
+		if !ok {
+			return
+		}
 
-		if err != nil {
-			logger.Error("Error while loading persons", utils.Err(err))
-			render.JSON(w, r, model.Error("Error while loading persons", model.InternalError))
+		var persons []entity.Person
+		if login := r.URL.Query().Get("login"); login != "" {
+			person, err := service.FindPersonByLogin(r.Context(), login)
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				renderPersonError(w, r, logger, err, "Error while loading persons")
+				return
+			} else if err == nil {
+				persons = append(persons, person)
+			}
+		} else {
+			var page string
+			page = r.URL.Query().Get("page")
+			logger.Info("Request body decoded", slog.Any("page", page))
+
+			filter := repository.PersonFilter{Country: strings.ToUpper(r.URL.Query().Get("country")), City: r.URL.Query().Get("city")}
+			if !parseMembershipFilter(w, r, &filter) || !parseAgeFilter(w, r, &filter) {
+				return
+			}
+
+			var err error
+			if filter.Attributes, err = service.AttributeFilter(r.Context(), parseAttributeFilter(r)); err != nil {
+				renderPersonError(w, r, logger, err, "Error while loading persons")
+				return
+			}
+			if persons, err = service.LoadPersons(r.Context(), filter, &page); err != nil {
+				renderPersonError(w, r, logger, err, "Error while loading persons")
+				return
+			}
+		}
+
+		response := mappers.ToPersonsResponse(persons)
+		if err := expansion.expand(r, expand, response); err != nil {
+			renderPersonError(w, r, logger, err, "Error while expand persons")
 			return
 		}
 
-		logger.Info("Successfully loaded persons", slog.Any("persons", persons))
-		render.JSON(w, r, mappers.ToPersonsResponse(persons))
+		logger.Info("Successfully loaded persons", slog.Int("count", len(persons)))
+		respond(w, r, response)
+	}
+}
+
+// parseAgeFilter minAge and maxAge listing parameters, writes 400 response when they are not valid.
+func parseAgeFilter(w http.ResponseWriter, r *http.Request, filter *repository.PersonFilter) bool {
+	for name, target := range map[string]**int{"minAge": &filter.MinAge, "maxAge": &filter.MaxAge} {
+		value := r.URL.Query().Get(name)
+		if value == "" {
+			continue
+		}
+		age, err := strconv.Atoi(value)
+		if err != nil || age < 0 {
+			renderError(w, r, http.StatusBadRequest, fmt.Sprintf("Parameter %s must be non-negative integer", name))
+			return false
+		}
+		*target = &age
+	}
+	return true
+}
+
+// decodePersonRequest decode body of create/update request, writes 400 or 415 response when it is not valid.
+func decodePersonRequest(w http.ResponseWriter, r *http.Request, logger *slog.Logger) (model.PersonRequest, bool) {
+	var req model.PersonRequest
+
+	if !decodeRequest(w, r, logger, &req) {
+		return req, false
+	}
+
+	if req.Timestamp != nil {
+		logger.Error("Request contains timestamp managed by database")
+		renderError(w, r, http.StatusBadRequest, "Field timestamp is managed by server and must not be sent")
+		return req, false
+	}
+
+	return req, true
+}
+
+func renderPersonError(w http.ResponseWriter, r *http.Request, logger *slog.Logger, err error, msg string) {
+	var validationErr *services.ValidationError
+	var pgErr *pgconn.PgError
+
+	switch {
+	case errors.As(err, &validationErr):
+		logger.Error("Person request is not valid", utils.Err(err))
+		renderError(w, r, http.StatusBadRequest, validationErr.Message)
+	case errors.Is(err, pgx.ErrNoRows):
+		logger.Error("Person not found", utils.Err(err))
+		renderError(w, r, http.StatusNotFound, "Person not found")
+	case errors.Is(err, jsonpatch.ErrTestFailed):
+		logger.Error("Person does not match patch test operation", utils.Err(err))
+		renderError(w, r, http.StatusConflict, "Person does not match test operation of patch")
+	case errors.As(err, &pgErr) && pgErr.Code == uniqueViolation:
+		logger.Error("Person conflicts with existing one", utils.Err(err))
+		renderError(w, r, http.StatusConflict, "Person with the same login already exists")
+	case errors.Is(err, services.ErrQuotaExceeded):
+		logger.Error("Quota of tenant is exceeded", utils.Err(err))
+		renderError(w, r, http.StatusForbidden, "Quota of persons is exceeded")
+	default:
+		logger.Error(msg, utils.Err(err))
+		renderError(w, r, http.StatusInternalServerError, msg)
 	}
 }
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"errors"
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"person-service/db/entity"
+	"person-service/services"
+	"testing"
+	"time"
+)
+
+func Test_ApplyPersonPatch(t *testing.T) {
+	newPerson := func() entity.Person {
+		id := uuid.New()
+		return entity.Person{Id: &id, FirstName: "Алексей", LastName: "Сидоров", Age: 18, Login: "a.sidorov"}
+	}
+
+	t.Run("merge patch keeps absent fields and removes null ones", func(t *testing.T) {
+		person := newPerson()
+		err := applyPersonPatch(mergePatchType, []byte(`{"age": 19, "login": null}`), &person)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 19, person.Age)
+		assert.Equal(t, "Алексей", person.FirstName)
+		assert.Equal(t, "", person.Login)
+	})
+
+	t.Run("patched age drops exact birth date", func(t *testing.T) {
+		person := newPerson()
+		born := time.Date(2006, time.March, 1, 0, 0, 0, 0, time.UTC)
+		person.BirthDate = &born
+		err := applyPersonPatch(mergePatchType, []byte(`{"age": 40}`), &person)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 40, person.Age)
+		assert.Nil(t, person.BirthDate)
+
+		person.BirthDate = &born
+		err = applyPersonPatch(mergePatchType, []byte(`{"lastName": "Петров"}`), &person)
+		assert.NoError(t, err)
+		assert.Equal(t, &born, person.BirthDate)
+	})
+
+	t.Run("json patch applies operations after successful test", func(t *testing.T) {
+		person := newPerson()
+		err := applyPersonPatch(jsonPatchType, []byte(
+			`[{"op": "test", "path": "/login", "value": "a.sidorov"}, {"op": "replace", "path": "/lastName", "value": "Петров"}]`,
+		), &person)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Петров", person.LastName)
+	})
+
+	t.Run("json patch fails on test operation", func(t *testing.T) {
+		person := newPerson()
+		err := applyPersonPatch(jsonPatchType, []byte(`[{"op": "test", "path": "/age", "value": 20}]`), &person)
+
+		assert.True(t, errors.Is(err, jsonpatch.ErrTestFailed))
+	})
+
+	t.Run("patch must not change id or add unknown fields", func(t *testing.T) {
+		var validationErr *services.ValidationError
+
+		person := newPerson()
+		err := applyPersonPatch(mergePatchType, []byte(`{"id": "`+uuid.NewString()+`"}`), &person)
+		assert.True(t, errors.As(err, &validationErr))
+
+		err = applyPersonPatch(jsonPatchType, []byte(`[{"op": "add", "path": "/nickname", "value": "x"}]`), &person)
+		assert.True(t, errors.As(err, &validationErr))
+	})
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"github.com/go-chi/chi/v5/middleware"
+	"golang.org/x/exp/slog"
+	"mime"
+	"net/http"
+	"person-service/importer"
+	"person-service/model"
+	"person-service/services"
+	"person-service/utils"
+	"strconv"
+)
+
+const (
+	formatCsv    = "csv"
+	formatNdjson = "ndjson"
+)
+
+// ImportPersons godoc
+// @Summary      Import persons from CSV or NDJSON
+// @Description  Stream import file, every row is validated like created person and upserted by id or login.
+// @Description  Format is taken from format param or Content-Type (text/csv, application/x-ndjson).
+// @Description  report=csv responds with downloadable CSV of rejected rows instead of JSON summary.
+// @Tags         persons
+// @Accept       text/csv
+// @Accept       application/x-ndjson
+// @Produce      json
+// @Produce      text/csv
+// @Param		 format    	query   string  	false  	"File format: csv, ndjson."
+// @Param		 dryRun    	query   bool  		false  	"Validate and roll back import."
+// @Param		 delimiter  query   string  	false  	"CSV delimiter, default is comma, tab for tab."
+// @Param		 columns    query   string  	false  	"CSV column mapping field:column,... (fields: id, login, firstName, lastName, age)."
+// @Param		 report    	query   string  	false  	"csv for downloadable report of rejected rows."
+// @Success      200  		{object}   	model.PersonImportResponse
+// @Failure      400  		{object}   	model.ErrorResponse
+// @Failure      415  		{object}   	model.ErrorResponse
+// @Router       /v2/persons/import [post]
+func ImportPersons(logger *slog.Logger, service *services.PersonService, chunkSize int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.importPersons"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		query := r.URL.Query()
+		format := query.Get("format")
+		if format == "" {
+			mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			switch mediaType {
+			case "text/csv":
+				format = formatCsv
+			case "application/x-ndjson", "application/ndjson":
+				format = formatNdjson
+			}
+		}
+
+		var reader importer.Reader
+		switch format {
+		case formatCsv:
+			options, err := csvOptions(query.Get("delimiter"), query.Get("columns"))
+			if err != nil {
+				renderError(w, r, http.StatusBadRequest, err.Error())
+				return
+			}
+			if reader, err = importer.NewCSVReader(r.Body, options); err != nil {
+				logger.Error("Failed to read csv header", utils.Err(err))
+				renderError(w, r, http.StatusBadRequest, err.Error())
+				return
+			}
+		case formatNdjson:
+			reader = importer.NewNDJSONReader(r.Body)
+		default:
+			renderError(w, r, http.StatusUnsupportedMediaType, "Import file must be csv or ndjson")
+			return
+		}
+
+		dryRun, _ := strconv.ParseBool(query.Get("dryRun"))
+		report, err := importer.Import(r.Context(), service, reader, importer.Options{DryRun: dryRun, ChunkSize: chunkSize})
+		if err != nil {
+			logger.Error("Import was interrupted", utils.Err(err))
+			renderError(w, r, http.StatusInternalServerError, "Import was interrupted, rows of processed chunks are saved")
+			return
+		}
+
+		logger.Info("Import is finished",
+			slog.Bool("dry_run", dryRun),
+			slog.Int("total", report.Total),
+			slog.Int("rejected", len(report.Rejected)))
+
+		if query.Get("report") == formatCsv {
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+			w.Header().Set("Content-Disposition", `attachment; filename="import-report.csv"`)
+			if err := importer.WriteReport(w, report); err != nil {
+				logger.Error("Failed to write import report", utils.Err(err))
+			}
+			return
+		}
+
+		respond(w, r, toPersonImportResponse(report))
+	}
+}
+
+func csvOptions(delimiter string, columns string) (importer.CSVOptions, error) {
+	var options importer.CSVOptions
+	var err error
+
+	if options.Delimiter, err = importer.ParseDelimiter(delimiter); err != nil {
+		return options, err
+	}
+	if options.Columns, err = importer.ParseColumns(columns); err != nil {
+		return options, err
+	}
+
+	return options, nil
+}
+
+func toPersonImportResponse(report importer.Report) model.PersonImportResponse {
+	response := model.PersonImportResponse{
+		DryRun:   report.DryRun,
+		Total:    report.Total,
+		Created:  report.Created,
+		Updated:  report.Updated,
+		Rejected: make([]model.PersonImportError, len(report.Rejected)),
+	}
+	for index, rejected := range report.Rejected {
+		response.Rejected[index] = model.PersonImportError{Line: rejected.Line, Reason: rejected.Reason, Row: rejected.Raw}
+	}
+	return response
+}
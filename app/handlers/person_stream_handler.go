@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5/middleware"
+	"golang.org/x/exp/slog"
+	"golang.org/x/net/websocket"
+	"net/http"
+	"person-service/config"
+	"person-service/events"
+	"person-service/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/* write deadline is extended by timeout before every event and heartbeat */
+const streamWriteTimeout = 30 * time.Second
+
+const (
+	resetEvent     = "reset"
+	heartbeatEvent = "heartbeat"
+)
+
+/* close code of WebSocket connection failed by server */
+const wsCloseInternalError = 1011
+
+// PersonEventsProtocol WebSocket subprotocol of change feed, it is selected when client offers it, so browser
+// offering bearer subprotocol gets protocol other than token back.
+const PersonEventsProtocol = "person-events"
+
+var errTokenExpired = errors.New("bearer token expired")
+
+// eventSink transport of change feed connection.
+type eventSink interface {
+	Event(event events.Event) error
+	Reset() error
+	Heartbeat() error
+}
+
+// StreamPersonEvents godoc
+// @Summary      Stream person events
+// @Description  Server-Sent Events of person changes, event id is id of domain event. Events after Last-Event-ID
+// @Description  are replayed when client is behind by no more than stream.replay-limit events, otherwise `reset` event
+// @Description  is sent and client must reload persons. Heartbeat comment is sent every stream.heartbeat-interval,
+// @Description  stream is closed when bearer token expires. WebSocket variant is served on /v1/persons/stream/ws.
+// @Tags         persons
+// @Produce      text/event-stream
+// @Param		 Last-Event-ID  header  string  false  "Id of the last received event."
+// @Param		 lastEventId    query   string  false  "Id of the last received event, used when header is absent."
+// @Success      200
+// @Failure      400  {object}   model.ErrorResponse
+// @Router       /v1/persons/stream [get]
+func StreamPersonEvents(logger *slog.Logger, feed *events.Feed, stream config.Stream) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.streamPersonEvents"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		lastEventId, err := parseLastEventId(r)
+		if err != nil {
+			renderError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		subscription, err := feed.Subscribe(r.Context(), lastEventId)
+		if err != nil {
+			logger.Error("Failed to subscribe person events", utils.Err(err))
+			renderError(w, r, http.StatusInternalServerError, "Error while subscribe person events")
+			return
+		}
+		defer subscription.Close()
+
+		/* server read timeout would cancel request context of long-lived connection */
+		controller := http.NewResponseController(w)
+		if err = controller.SetReadDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			logger.Error("Failed to reset read deadline", utils.Err(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		sink := &sseSink{w: w, controller: controller}
+		if err = pumpEvents(r.Context(), subscription, sink, stream.HeartbeatInterval); err != nil {
+			logger.Info("Person events stream closed", utils.Err(err))
+		}
+	}
+}
+
+// StreamPersonEventsWs the same feed as StreamPersonEvents over WebSocket, every event is JSON text message,
+// reset and heartbeat are messages with type field only. Browser passes bearer token as subprotocol
+// "bearer.<token>" next to "person-events", handshake of origin outside of allowed ones is rejected.
+func StreamPersonEventsWs(logger *slog.Logger, feed *events.Feed, stream config.Stream, allowedOrigins []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.streamPersonEventsWs"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		lastEventId, err := parseLastEventId(r)
+		if err != nil {
+			renderError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		server := websocket.Server{
+			Handshake: func(ws *websocket.Config, r *http.Request) error {
+				/* clients other than browsers send no origin, they are authorized by bearer token only */
+				if origin := r.Header.Get("Origin"); origin != "" && !originAllowed(origin, allowedOrigins) {
+					return fmt.Errorf("origin %s is not allowed", origin)
+				}
+				ws.Protocol = nil
+				for _, protocol := range websocketProtocols(r) {
+					if protocol == PersonEventsProtocol {
+						ws.Protocol = []string{PersonEventsProtocol}
+					}
+				}
+				return nil
+			},
+			Handler: func(ws *websocket.Conn) {
+				subscription, err := feed.Subscribe(r.Context(), lastEventId)
+				if err != nil {
+					logger.Error("Failed to subscribe person events", utils.Err(err))
+					_ = ws.WriteClose(wsCloseInternalError)
+					return
+				}
+				defer subscription.Close()
+
+				/* client messages are not expected, reading detects closed connection */
+				if err := ws.SetReadDeadline(time.Time{}); err != nil {
+					return
+				}
+				ctx, cancel := context.WithCancel(r.Context())
+				defer cancel()
+				go func() {
+					defer cancel()
+					var discard []byte
+					for websocket.Message.Receive(ws, &discard) == nil {
+					}
+				}()
+
+				if err = pumpEvents(ctx, subscription, &wsSink{ws: ws}, stream.HeartbeatInterval); err != nil {
+					logger.Info("Person events stream closed", utils.Err(err))
+				}
+			},
+		}
+		server.ServeHTTP(w, r)
+	}
+}
+
+// originAllowed origin matches one of allowed origins, "*" matches any and may stand for part of origin
+// like "https://*.example.com", the same way CORS middleware matches them.
+func originAllowed(origin string, allowed []string) bool {
+	origin = strings.ToLower(origin)
+	for _, pattern := range allowed {
+		pattern = strings.ToLower(pattern)
+		if pattern == "*" || pattern == origin {
+			return true
+		}
+		if prefix, suffix, found := strings.Cut(pattern, "*"); found &&
+			len(origin) >= len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// pumpEvents writes replayed and live events to sink until connection, subscription or bearer token ends,
+// nil is returned when client disconnects.
+func pumpEvents(ctx context.Context, subscription *events.Subscription, sink eventSink, heartbeatInterval time.Duration) error {
+	if expiresAt, ok := TokenExpiresAt(ctx); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, expiresAt)
+		defer cancel()
+	}
+
+	if subscription.Reset {
+		if err := sink.Reset(); err != nil {
+			return err
+		}
+	}
+	for _, event := range subscription.Replay {
+		if err := sink.Event(event); err != nil {
+			return err
+		}
+	}
+	/* headers are flushed at once, client knows subscription is active */
+	if err := sink.Heartbeat(); err != nil {
+		return err
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return errTokenExpired
+			}
+			return nil
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return errors.New("subscription closed by feed")
+			}
+			if err := sink.Event(event); err != nil {
+				return err
+			}
+		case <-heartbeat.C:
+			if err := sink.Heartbeat(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// parseLastEventId id from Last-Event-ID header of reconnecting EventSource or from lastEventId parameter.
+func parseLastEventId(r *http.Request) (int64, error) {
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = r.URL.Query().Get("lastEventId")
+	}
+	if value == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return 0, fmt.Errorf("Last event id is not valid: %s", value)
+	}
+	return id, nil
+}
+
+type sseSink struct {
+	w          http.ResponseWriter
+	controller *http.ResponseController
+}
+
+func (s *sseSink) Event(event events.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return s.write(fmt.Sprintf("id: %d\nevent: %s\ndata: %s\n\n", event.Id, event.Type, data))
+}
+
+func (s *sseSink) Reset() error {
+	return s.write(fmt.Sprintf("event: %s\ndata: {}\n\n", resetEvent))
+}
+
+func (s *sseSink) Heartbeat() error {
+	return s.write(fmt.Sprintf(": %s\n\n", heartbeatEvent))
+}
+
+func (s *sseSink) write(frame string) error {
+	if err := s.controller.SetWriteDeadline(time.Now().Add(streamWriteTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	if _, err := s.w.Write([]byte(frame)); err != nil {
+		return err
+	}
+	return s.controller.Flush()
+}
+
+type wsSink struct {
+	ws *websocket.Conn
+}
+
+func (s *wsSink) Event(event events.Event) error {
+	return s.send(event)
+}
+
+func (s *wsSink) Reset() error {
+	return s.send(map[string]string{"type": resetEvent})
+}
+
+func (s *wsSink) Heartbeat() error {
+	return s.send(map[string]string{"type": heartbeatEvent})
+}
+
+func (s *wsSink) send(v any) error {
+	if err := s.ws.SetWriteDeadline(time.Now().Add(streamWriteTimeout)); err != nil {
+		return err
+	}
+	return websocket.JSON.Send(s.ws, v)
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"person-service/events"
+	"testing"
+	"time"
+)
+
+func Test_ParseLastEventId(t *testing.T) {
+	t.Run("header wins over parameter", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/persons/stream?lastEventId=5", nil)
+		r.Header.Set("Last-Event-ID", "42")
+
+		id, err := parseLastEventId(r)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(42), id)
+	})
+
+	t.Run("absent id means live events only", func(t *testing.T) {
+		id, err := parseLastEventId(httptest.NewRequest(http.MethodGet, "/api/v1/persons/stream", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), id)
+	})
+
+	t.Run("malformed id is rejected", func(t *testing.T) {
+		_, err := parseLastEventId(httptest.NewRequest(http.MethodGet, "/api/v1/persons/stream?lastEventId=-1", nil))
+		assert.Error(t, err)
+	})
+}
+
+func Test_WebsocketHandshake(t *testing.T) {
+	t.Run("origin must match allowed origins", func(t *testing.T) {
+		allowed := []string{"https://app.example.com", "https://*.example.org"}
+
+		assert.True(t, originAllowed("https://APP.example.com", allowed))
+		assert.True(t, originAllowed("https://admin.example.org", allowed))
+		assert.False(t, originAllowed("https://evil.example.net", allowed))
+		assert.True(t, originAllowed("https://evil.example.net", []string{"*"}))
+	})
+
+	t.Run("bearer token is taken from subprotocol without Authorization header", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/persons/stream/ws", nil)
+		r.Header.Set("Sec-WebSocket-Protocol", PersonEventsProtocol+", "+BearerProtocol+"token")
+		assert.Equal(t, "Bearer token", bearerToken(r))
+
+		r.Header.Set("Authorization", "Bearer header")
+		assert.Equal(t, "Bearer header", bearerToken(r))
+	})
+}
+
+func Test_SseSink(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	sink := &sseSink{w: recorder, controller: http.NewResponseController(recorder)}
+
+	event := events.Event{
+		Id:         7,
+		Type:       events.PersonUpdated,
+		PersonId:   uuid.New(),
+		OccurredAt: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		Payload:    json.RawMessage(`{"age":30}`),
+	}
+	data, _ := json.Marshal(event)
+
+	assert.NoError(t, sink.Reset())
+	assert.NoError(t, sink.Event(event))
+	assert.NoError(t, sink.Heartbeat())
+
+	assert.Equal(t, "event: reset\ndata: {}\n\n"+
+		"id: 7\nevent: PersonUpdated\ndata: "+string(data)+"\n\n"+
+		": heartbeat\n\n", recorder.Body.String())
+	assert.True(t, recorder.Flushed)
+}
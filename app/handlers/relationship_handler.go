@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"golang.org/x/exp/slog"
+	"net/http"
+	"person-service/db/entity"
+	"person-service/mappers"
+	"person-service/model"
+	"person-service/services"
+	"person-service/utils"
+	"strconv"
+)
+
+// CreateRelationship godoc
+// @Summary      Create relationship of person
+// @Description  Relate person of personId to person in path as manager, report, parent, child, spouse, sibling or
+// @Description  emergency_contact. Inverse types are stored as their type in opposite direction. Person has at most
+// @Description  one manager, manager and parent relationships must not make cycle.
+// @Tags         relationships
+// @Accept       json
+// @Accept       xml
+// @Accept       application/msgpack
+// @Accept       application/cbor
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    		path    	string  					true  	"ID of person entity."
+// @Param  		 request	body    	model.RelationshipRequest  	true  	"Model of relationship."
+// @Success      201  		{object}   	model.RelationshipResponse
+// @Failure      400  		{object}   	model.ErrorResponse
+// @Failure      404  		{object}   	model.ErrorResponse
+// @Failure      409  		{object}   	model.ErrorResponse
+// @Failure      415  		{object}   	model.ErrorResponse
+// @Router       /v2/persons/{id}/relationships [post]
+func CreateRelationship(logger *slog.Logger, service *services.RelationshipService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.createRelationship"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		personId, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+		var req model.RelationshipRequest
+		if !decodeRequest(w, r, logger, &req) {
+			return
+		}
+
+		saved, err := service.CreateRelationship(r.Context(), entity.Relationship{PersonId: personId, RelatedId: req.PersonId, Type: req.Type})
+		if err != nil {
+			renderRelationshipError(w, r, logger, err, fmt.Sprintf("Error while save relationship of person %s", personId))
+			return
+		}
+
+		logger.Info("Successfully save relationship", slog.String("id", saved.Id.String()))
+		w.Header().Set("Location", fmt.Sprintf("%s/%s/relationships/%s", PersonsPath, personId, saved.Id))
+		render.Status(r, http.StatusCreated)
+		respond(w, r, mappers.ToRelationshipResponse(saved))
+	}
+}
+
+// LoadRelationships godoc
+// @Summary      Load relationships of person
+// @Description  Load relationships of person in both directions in order of creation, type filters by type seen from person
+// @Tags         relationships
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    	 path     string  	true  	"ID of person entity."
+// @Param		 type    query    string  	false  	"Type of relationship seen from person."
+// @Success      200  {array}    model.RelationshipResponse
+// @Failure      404  {object}   model.ErrorResponse
+// @Router       /v2/persons/{id}/relationships [get]
+func LoadRelationships(logger *slog.Logger, service *services.RelationshipService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.loadRelationships"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		personId, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+
+		relationships, err := service.LoadRelationships(r.Context(), personId, r.URL.Query().Get("type"))
+		if err != nil {
+			renderRelationshipError(w, r, logger, err, fmt.Sprintf("Error while loading relationships of person %s", personId))
+			return
+		}
+
+		respond(w, r, mappers.ToRelationshipsResponse(relationships))
+	}
+}
+
+// DeleteRelationship godoc
+// @Summary      Delete relationship of person
+// @Description  Delete relationship of person, relationship created from the other person is deleted too
+// @Tags         relationships
+// @Param		 id    			  path    string  	true  	"ID of person entity."
+// @Param		 relationshipId   path    string  	true  	"ID of relationship."
+// @Success      204
+// @Failure      404  {object}   model.ErrorResponse
+// @Router       /v2/persons/{id}/relationships/{relationshipId} [delete]
+func DeleteRelationship(logger *slog.Logger, service *services.RelationshipService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.deleteRelationship"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		personId, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+		id, ok := parseUuidParam(w, r, "relationshipId")
+		if !ok {
+			return
+		}
+
+		deleted, err := service.DeleteRelationship(r.Context(), personId, id)
+		if err != nil {
+			renderRelationshipError(w, r, logger, err, fmt.Sprintf("Error while delete relationship %s", id))
+			return
+		}
+		if !deleted {
+			renderError(w, r, http.StatusNotFound, fmt.Sprintf("Relationship not found by id, with %s", id))
+			return
+		}
+
+		logger.Info("Relationship was successfully deleted", slog.String("id", id.String()))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// LoadReports godoc
+// @Summary      Load reports of person
+// @Description  Load tree of all reports of person (children for type parent) up to depth levels,
+// @Description  depth is limited by relationships.max-depth which is also used when depth is absent
+// @Tags         relationships
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    	 path     string  	true  	"ID of person entity."
+// @Param		 type    query    string  	false  	"Hierarchical relationship type: manager (default), parent."
+// @Param		 depth   query    int  		false  	"Levels of tree."
+// @Success      200  {array}    model.HierarchyNodeResponse
+// @Failure      400  {object}   model.ErrorResponse
+// @Failure      404  {object}   model.ErrorResponse
+// @Router       /v2/persons/{id}/reports [get]
+func LoadReports(logger *slog.Logger, service *services.RelationshipService) http.HandlerFunc {
+	return loadHierarchy(logger, "handlers.loadReports", service.LoadReports)
+}
+
+// LoadChain godoc
+// @Summary      Load chain of command of person
+// @Description  Load managers of person from direct one upwards (parents, grandparents for type parent) up to depth levels,
+// @Description  depth is limited by relationships.max-depth which is also used when depth is absent
+// @Tags         relationships
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    	 path     string  	true  	"ID of person entity."
+// @Param		 type    query    string  	false  	"Hierarchical relationship type: manager (default), parent."
+// @Param		 depth   query    int  		false  	"Levels of tree."
+// @Success      200  {array}    model.HierarchyNodeResponse
+// @Failure      400  {object}   model.ErrorResponse
+// @Failure      404  {object}   model.ErrorResponse
+// @Router       /v2/persons/{id}/chain [get]
+func LoadChain(logger *slog.Logger, service *services.RelationshipService) http.HandlerFunc {
+	return loadHierarchy(logger, "handlers.loadChain", service.LoadChain)
+}
+
+func loadHierarchy(
+	logger *slog.Logger,
+	op string,
+	load func(ctx context.Context, personId uuid.UUID, relationshipType string, depth int) ([]entity.HierarchyNode, error),
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		personId, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+		var depth int
+		if value := r.URL.Query().Get("depth"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 {
+				renderError(w, r, http.StatusBadRequest, "Parameter depth must be positive integer")
+				return
+			}
+			depth = parsed
+		}
+
+		nodes, err := load(r.Context(), personId, r.URL.Query().Get("type"), depth)
+		if err != nil {
+			renderRelationshipError(w, r, logger, err, fmt.Sprintf("Error while loading hierarchy of person %s", personId))
+			return
+		}
+
+		respond(w, r, mappers.ToHierarchyResponse(nodes))
+	}
+}
+
+func renderRelationshipError(w http.ResponseWriter, r *http.Request, logger *slog.Logger, err error, msg string) {
+	var validationErr *services.ValidationError
+	var pgErr *pgconn.PgError
+
+	switch {
+	case errors.As(err, &validationErr):
+		logger.Error("Relationship request is not valid", utils.Err(err))
+		renderError(w, r, http.StatusBadRequest, validationErr.Message)
+	case errors.Is(err, pgx.ErrNoRows):
+		logger.Error("Person not found", utils.Err(err))
+		renderError(w, r, http.StatusNotFound, "Person not found")
+	case errors.Is(err, services.ErrSingleRelationship):
+		logger.Error("Person already has relationship of type", utils.Err(err))
+		renderError(w, r, http.StatusConflict, "Person already has relationship of this type")
+	case errors.As(err, &pgErr) && pgErr.Code == uniqueViolation:
+		logger.Error("Relationship already exists", utils.Err(err))
+		renderError(w, r, http.StatusConflict, "Relationship already exists")
+	default:
+		logger.Error(msg, utils.Err(err))
+		renderError(w, r, http.StatusInternalServerError, msg)
+	}
+}
@@ -1,14 +1,32 @@
 package handlers
 
 import (
+	"context"
 	"crypto/rsa"
-	"fmt"
 	"github.com/golang-jwt/jwt/v5"
 	"net/http"
+	"person-service/utils"
 	"strings"
+	"time"
 )
 
-const ProtectedPattern = "/api/v1"
+const ProtectedPattern = "/api/"
+
+// GraphqlPath graphql endpoint, it is protected like REST routes.
+const GraphqlPath = "/graphql"
+
+// anonymousPrincipal principal of requests when security is disabled.
+const anonymousPrincipal = "anonymous"
+
+// BearerProtocol prefix of WebSocket subprotocol carrying bearer token, browsers can not set Authorization header
+// of WebSocket handshake.
+const BearerProtocol = "bearer."
+
+type principalKey struct{}
+
+type expiresAtKey struct{}
+
+type claimsKey struct{}
 
 var rsaKey *rsa.PublicKey
 
@@ -18,26 +36,24 @@ func Init(key *rsa.PublicKey) {
 
 func JwtBearerValidation(next http.Handler) http.Handler {
 	fn := func(w http.ResponseWriter, r *http.Request) {
-		if strings.Contains(r.URL.Path, ProtectedPattern) {
-			token := r.Header.Get("Authorization")
+		if strings.Contains(r.URL.Path, ProtectedPattern) || r.URL.Path == GraphqlPath {
+			token := bearerToken(r)
 			if token == "" {
 				http.Error(w, "Unauthorized", http.StatusUnauthorized)
 				return
 			} else {
-				token := strings.TrimPrefix(token, "Bearer ")
-				claims := &jwt.MapClaims{}
-
-				_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
-					if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
-						return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-					}
-					return rsaKey, nil
-				})
-
+				validated, err := utils.ValidateBearerToken(rsaKey, token)
 				if err != nil {
 					http.Error(w, "Unauthorized", http.StatusUnauthorized)
 					return
 				}
+				if validated.Subject != "" {
+					r = r.WithContext(context.WithValue(r.Context(), principalKey{}, validated.Subject))
+				}
+				if !validated.ExpiresAt.IsZero() {
+					r = r.WithContext(context.WithValue(r.Context(), expiresAtKey{}, validated.ExpiresAt))
+				}
+				r = r.WithContext(context.WithValue(r.Context(), claimsKey{}, validated))
 			}
 		}
 
@@ -47,6 +63,52 @@ func JwtBearerValidation(next http.Handler) http.Handler {
 	return http.HandlerFunc(fn)
 }
 
+// bearerToken Authorization header or bearer subprotocol of WebSocket handshake, empty when request has none.
+func bearerToken(r *http.Request) string {
+	if authorization := r.Header.Get("Authorization"); authorization != "" {
+		return authorization
+	}
+	for _, protocol := range websocketProtocols(r) {
+		if strings.HasPrefix(protocol, BearerProtocol) {
+			return "Bearer " + strings.TrimPrefix(protocol, BearerProtocol)
+		}
+	}
+	return ""
+}
+
+// websocketProtocols subprotocols offered by WebSocket handshake.
+func websocketProtocols(r *http.Request) []string {
+	var protocols []string
+	for _, header := range r.Header.Values("Sec-WebSocket-Protocol") {
+		for _, protocol := range strings.Split(header, ",") {
+			if protocol = strings.TrimSpace(protocol); protocol != "" {
+				protocols = append(protocols, protocol)
+			}
+		}
+	}
+	return protocols
+}
+
+// Principal subject of validated bearer token or anonymous.
+func Principal(ctx context.Context) string {
+	if principal, ok := ctx.Value(principalKey{}).(string); ok {
+		return principal
+	}
+	return anonymousPrincipal
+}
+
+// TokenExpiresAt expiration of validated bearer token, long-lived connections are closed when it is reached.
+func TokenExpiresAt(ctx context.Context) (time.Time, bool) {
+	expiresAt, ok := ctx.Value(expiresAtKey{}).(time.Time)
+	return expiresAt, ok
+}
+
+// Claim string claim of validated bearer token, empty without token or claim.
+func Claim(ctx context.Context, name string) string {
+	token, _ := ctx.Value(claimsKey{}).(utils.BearerToken)
+	return token.Claim(name)
+}
+
 type JwtClaims struct {
 	jwt.Claims
 }
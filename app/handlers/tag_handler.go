@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"golang.org/x/exp/slog"
+	"net/http"
+	"person-service/mappers"
+	"person-service/model"
+	"person-service/services"
+	"person-service/utils"
+)
+
+// TagsPath resource of person tags.
+const TagsPath = "/api/v2/tags"
+
+// LoadTags godoc
+// @Summary      Load tags
+// @Description  Load all tags ordered by name with count of tagged persons, tag exists while some person has it
+// @Tags         tags
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Success      200  {array}   model.TagResponse
+// @Router       /v2/tags [get]
+func LoadTags(logger *slog.Logger, service *services.TagService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.loadTags"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		tags, err := service.LoadTags(r.Context())
+		if err != nil {
+			renderTagError(w, r, logger, err, "Error while loading tags")
+			return
+		}
+
+		respond(w, r, mappers.ToTagsResponse(tags))
+	}
+}
+
+// AddTagMembers godoc
+// @Summary      Tag persons
+// @Description  Tag up to api.batch-limit existing persons, tag is trimmed and lower-cased. Tagged persons are skipped.
+// @Tags         tags
+// @Accept       json
+// @Accept       xml
+// @Accept       application/msgpack
+// @Accept       application/cbor
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 tag    	path    	string  				true  	"Tag."
+// @Param  		 request	body    	model.MembersRequest  	true  	"Ids of persons."
+// @Success      200  		{object}   	model.MembersResponse
+// @Failure      400  		{object}   	model.ErrorResponse
+// @Failure      413  		{object}   	model.ErrorResponse
+// @Failure      415  		{object}   	model.ErrorResponse
+// @Router       /v2/tags/{tag}/members [post]
+func AddTagMembers(logger *slog.Logger, service *services.TagService, limit int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.addTagMembers"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		tag := chi.URLParam(r, "tag")
+		personIds, ok := decodeMembersRequest(w, r, logger, limit)
+		if !ok {
+			return
+		}
+
+		added, err := service.AddTag(r.Context(), tag, personIds)
+		if err != nil {
+			renderTagError(w, r, logger, err, fmt.Sprintf("Error while add tag %s", tag))
+			return
+		}
+
+		logger.Info("Successfully tag persons", slog.String("tag", tag), slog.Int64("added", added))
+		respond(w, r, model.MembersResponse{Changed: added})
+	}
+}
+
+// RemoveTagMembers godoc
+// @Summary      Untag persons
+// @Description  Remove tag of up to api.batch-limit persons, persons without tag are skipped.
+// @Tags         tags
+// @Accept       json
+// @Accept       xml
+// @Accept       application/msgpack
+// @Accept       application/cbor
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 tag    	path    	string  				true  	"Tag."
+// @Param  		 request	body    	model.MembersRequest  	true  	"Ids of persons."
+// @Success      200  		{object}   	model.MembersResponse
+// @Failure      400  		{object}   	model.ErrorResponse
+// @Failure      413  		{object}   	model.ErrorResponse
+// @Failure      415  		{object}   	model.ErrorResponse
+// @Router       /v2/tags/{tag}/members [delete]
+func RemoveTagMembers(logger *slog.Logger, service *services.TagService, limit int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.removeTagMembers"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		tag := chi.URLParam(r, "tag")
+		personIds, ok := decodeMembersRequest(w, r, logger, limit)
+		if !ok {
+			return
+		}
+
+		removed, err := service.RemoveTag(r.Context(), tag, personIds)
+		if err != nil {
+			renderTagError(w, r, logger, err, fmt.Sprintf("Error while remove tag %s", tag))
+			return
+		}
+
+		logger.Info("Successfully untag persons", slog.String("tag", tag), slog.Int64("removed", removed))
+		respond(w, r, model.MembersResponse{Changed: removed})
+	}
+}
+
+// LoadTagMembers godoc
+// @Summary      Load tagged persons
+// @Description  Load page of persons with tag by 50 rows
+// @Tags         tags
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 tag    path     string  	true  	"Tag."
+// @Param		 page   query    string  	false  	"Page of persons, when load by 50 rows."
+// @Success      200  {array}    model.PersonResponse
+// @Failure      400  {object}   model.ErrorResponse
+// @Router       /v2/tags/{tag}/members [get]
+func LoadTagMembers(logger *slog.Logger, service *services.TagService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.loadTagMembers"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		tag := chi.URLParam(r, "tag")
+		page := r.URL.Query().Get("page")
+		persons, err := service.LoadTagged(r.Context(), tag, &page)
+		if err != nil {
+			renderTagError(w, r, logger, err, fmt.Sprintf("Error while loading persons with tag %s", tag))
+			return
+		}
+
+		logger.Info("Successfully loaded tagged persons", slog.Int("count", len(persons)))
+		respond(w, r, mappers.ToPersonsResponse(persons))
+	}
+}
+
+func renderTagError(w http.ResponseWriter, r *http.Request, logger *slog.Logger, err error, msg string) {
+	var validationErr *services.ValidationError
+
+	switch {
+	case errors.As(err, &validationErr):
+		logger.Error("Tag request is not valid", utils.Err(err))
+		renderError(w, r, http.StatusBadRequest, validationErr.Message)
+	default:
+		logger.Error(msg, utils.Err(err))
+		renderError(w, r, http.StatusInternalServerError, msg)
+	}
+}
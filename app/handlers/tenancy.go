@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5/middleware"
+	"golang.org/x/exp/slog"
+	"net/http"
+	"person-service/config"
+	"person-service/db/repository"
+	"person-service/services"
+	"person-service/utils"
+	"strings"
+)
+
+// Tenancy middleware binds protected requests to tenant claimed by token or requested by header, header alone picks
+// tenant only when security is disabled. Statements of request see rows of this tenant only. Tenant admin api and
+// every request of disabled tenancy are served without tenant, rows written without tenant belong to default tenant.
+func Tenancy(logger *slog.Logger, service *services.TenantService, tenancy config.Tenancy) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			const op = "handlers.tenancy"
+
+			protected := strings.Contains(r.URL.Path, ProtectedPattern) || r.URL.Path == GraphqlPath
+			if !tenancy.Enabled || !protected || strings.HasPrefix(r.URL.Path, TenantsPath) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			tenantId, err := service.ResolveTenant(r.Context(), Claim(r.Context(), tenancy.Claim), r.Header.Get(tenancy.Header), rsaKey != nil)
+			if err != nil {
+				logger := logger.With(
+					slog.String("op", op),
+					slog.String("request_id", middleware.GetReqID(r.Context())),
+				)
+				renderTenancyError(w, r, logger, err)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(repository.WithTenant(r.Context(), tenantId)))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// RequireTenantAdmin middleware lets only principals of admins manage tenants, every request is rejected when
+// security is disabled, since principal is not known then.
+func RequireTenantAdmin(admins []string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if rsaKey == nil || !isTenantAdmin(admins, Principal(r.Context())) {
+				renderError(w, r, http.StatusForbidden, "Principal is not tenant admin")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+func isTenantAdmin(admins []string, principal string) bool {
+	for _, admin := range admins {
+		if admin == principal {
+			return true
+		}
+	}
+	return false
+}
+
+func renderTenancyError(w http.ResponseWriter, r *http.Request, logger *slog.Logger, err error) {
+	var validationErr *services.ValidationError
+
+	switch {
+	case errors.As(err, &validationErr):
+		logger.Error("Tenant of request is not valid", utils.Err(err))
+		renderError(w, r, http.StatusBadRequest, validationErr.Message)
+	case errors.Is(err, services.ErrTenantRequired):
+		logger.Error("Request has no tenant", utils.Err(err))
+		renderError(w, r, http.StatusBadRequest, "Tenant is required")
+	case errors.Is(err, services.ErrTenantMismatch):
+		logger.Error("Tenant of header does not match token", utils.Err(err))
+		renderError(w, r, http.StatusForbidden, "Tenant of header does not match tenant of token")
+	case errors.Is(err, services.ErrTenantNotClaimed):
+		logger.Error("Tenant of header is not claimed by token", utils.Err(err))
+		renderError(w, r, http.StatusForbidden, "Tenant of header is not claimed by token")
+	case errors.Is(err, services.ErrTenantNotFound):
+		logger.Error("Tenant not found", utils.Err(err))
+		renderError(w, r, http.StatusForbidden, "Tenant not found")
+	case errors.Is(err, services.ErrTenantSuspended):
+		logger.Error("Tenant is suspended", utils.Err(err))
+		renderError(w, r, http.StatusForbidden, "Tenant is suspended")
+	default:
+		logger.Error("Error while resolve tenant", utils.Err(err))
+		renderError(w, r, http.StatusInternalServerError, "Error while resolve tenant")
+	}
+}
@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"golang.org/x/exp/slog"
+	"net/http"
+	"person-service/db/entity"
+	"person-service/mappers"
+	"person-service/model"
+	"person-service/services"
+	"person-service/utils"
+)
+
+// TenantsPath admin resource of tenants, it is served without tenant of request.
+const TenantsPath = "/api/v2/admin/tenants"
+
+// CreateTenant godoc
+// @Summary      Create tenant
+// @Description  Provision active tenant, its persons are isolated from persons of other tenants.
+// @Tags         tenants
+// @Accept       json
+// @Accept       xml
+// @Accept       application/msgpack
+// @Accept       application/cbor
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param  		 request	body    	model.TenantRequest  	true  "Model of tenant."
+// @Success      201  		{object}   	model.TenantResponse
+// @Failure      400  		{object}   	model.ErrorResponse
+// @Failure      403  		{object}   	model.ErrorResponse
+// @Failure      409  		{object}   	model.ErrorResponse
+// @Failure      415  		{object}   	model.ErrorResponse
+// @Router       /v2/admin/tenants [post]
+func CreateTenant(logger *slog.Logger, service *services.TenantService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.createTenant"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req model.TenantRequest
+		if !decodeRequest(w, r, logger, &req) {
+			return
+		}
+
+		saved, err := service.CreateTenant(r.Context(), mappers.ToTenant(req))
+		if err != nil {
+			renderTenantError(w, r, logger, err, "Error while save tenant")
+			return
+		}
+
+		logger.Info("Successfully save tenant", slog.String("id", saved.Id.String()))
+		w.Header().Set("Location", fmt.Sprintf("%s/%s", TenantsPath, saved.Id))
+		render.Status(r, http.StatusCreated)
+		respond(w, r, mappers.ToTenantResponse(saved))
+	}
+}
+
+// UpdateTenant godoc
+// @Summary      Update tenant
+// @Description  Update name, quota and person validation settings of tenant.
+// @Description  Lowered quota keeps existing persons, new ones are rejected until count is below it.
+// @Tags         tenants
+// @Accept       json
+// @Accept       xml
+// @Accept       application/msgpack
+// @Accept       application/cbor
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    		path    	string  				true  	"Id of tenant."
+// @Param  		 request	body    	model.TenantRequest  	true  	"Model of tenant."
+// @Success      200  		{object}   	model.TenantResponse
+// @Failure      400  		{object}   	model.ErrorResponse
+// @Failure      403  		{object}   	model.ErrorResponse
+// @Failure      404  		{object}   	model.ErrorResponse
+// @Failure      409  		{object}   	model.ErrorResponse
+// @Failure      415  		{object}   	model.ErrorResponse
+// @Router       /v2/admin/tenants/{id} [put]
+func UpdateTenant(logger *slog.Logger, service *services.TenantService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.updateTenant"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+		var req model.TenantRequest
+		if !decodeRequest(w, r, logger, &req) {
+			return
+		}
+
+		tenant := mappers.ToTenant(req)
+		tenant.Id = id
+		updated, err := service.UpdateTenant(r.Context(), tenant)
+		if err != nil {
+			renderTenantError(w, r, logger, err, fmt.Sprintf("Error while update tenant %s", id))
+			return
+		}
+
+		logger.Info("Successfully update tenant", slog.String("id", updated.Id.String()))
+		respond(w, r, mappers.ToTenantResponse(updated))
+	}
+}
+
+// SuspendTenant godoc
+// @Summary      Suspend tenant
+// @Description  Reject requests of tenant with 403 until it is resumed, data of tenant is kept.
+// @Tags         tenants
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    path    string  	true  	"Id of tenant."
+// @Success      200  {object}   model.TenantResponse
+// @Failure      400  {object}   model.ErrorResponse
+// @Failure      403  {object}   model.ErrorResponse
+// @Failure      404  {object}   model.ErrorResponse
+// @Router       /v2/admin/tenants/{id}/suspend [post]
+func SuspendTenant(logger *slog.Logger, service *services.TenantService) http.HandlerFunc {
+	return updateTenantStatus(logger, service, "handlers.suspendTenant", entity.TenantSuspended)
+}
+
+// ResumeTenant godoc
+// @Summary      Resume tenant
+// @Description  Serve requests of suspended tenant again.
+// @Tags         tenants
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    path    string  	true  	"Id of tenant."
+// @Success      200  {object}   model.TenantResponse
+// @Failure      400  {object}   model.ErrorResponse
+// @Failure      403  {object}   model.ErrorResponse
+// @Failure      404  {object}   model.ErrorResponse
+// @Router       /v2/admin/tenants/{id}/resume [post]
+func ResumeTenant(logger *slog.Logger, service *services.TenantService) http.HandlerFunc {
+	return updateTenantStatus(logger, service, "handlers.resumeTenant", entity.TenantActive)
+}
+
+func updateTenantStatus(logger *slog.Logger, service *services.TenantService, op string, status string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+
+		var updated entity.Tenant
+		var err error
+		if status == entity.TenantSuspended {
+			updated, err = service.SuspendTenant(r.Context(), id)
+		} else {
+			updated, err = service.ResumeTenant(r.Context(), id)
+		}
+		if err != nil {
+			renderTenantError(w, r, logger, err, fmt.Sprintf("Error while update status of tenant %s", id))
+			return
+		}
+
+		logger.Info("Successfully update status of tenant", slog.String("id", id.String()), slog.String("status", updated.Status))
+		respond(w, r, mappers.ToTenantResponse(updated))
+	}
+}
+
+// FindTenant godoc
+// @Summary      Find tenant
+// @Description  Find tenant by id together with count of its persons
+// @Tags         tenants
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    path    string  	true  	"Id of tenant."
+// @Success      200  {object}   model.TenantResponse
+// @Failure      400  {object}   model.ErrorResponse
+// @Failure      403  {object}   model.ErrorResponse
+// @Failure      404  {object}   model.ErrorResponse
+// @Router       /v2/admin/tenants/{id} [get]
+func FindTenant(logger *slog.Logger, service *services.TenantService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.findTenant"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+
+		tenant, err := service.FindTenant(r.Context(), id)
+		if err != nil {
+			renderTenantError(w, r, logger, err, fmt.Sprintf("Error while find tenant %s", id))
+			return
+		}
+
+		respond(w, r, mappers.ToTenantResponse(tenant))
+	}
+}
+
+// LoadTenants godoc
+// @Summary      Load tenants
+// @Description  Load all tenants ordered by name together with counts of their persons
+// @Tags         tenants
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Success      200  {array}   model.TenantResponse
+// @Failure      403  {object}  model.ErrorResponse
+// @Router       /v2/admin/tenants [get]
+func LoadTenants(logger *slog.Logger, service *services.TenantService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.loadTenants"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		tenants, err := service.LoadTenants(r.Context())
+		if err != nil {
+			renderTenantError(w, r, logger, err, "Error while loading tenants")
+			return
+		}
+
+		respond(w, r, mappers.ToTenantsResponse(tenants))
+	}
+}
+
+func renderTenantError(w http.ResponseWriter, r *http.Request, logger *slog.Logger, err error, msg string) {
+	var validationErr *services.ValidationError
+	var pgErr *pgconn.PgError
+
+	switch {
+	case errors.As(err, &validationErr):
+		logger.Error("Tenant is not valid", utils.Err(err))
+		renderError(w, r, http.StatusBadRequest, validationErr.Message)
+	case errors.Is(err, pgx.ErrNoRows):
+		logger.Error("Tenant not found", utils.Err(err))
+		renderError(w, r, http.StatusNotFound, "Tenant not found")
+	case errors.As(err, &pgErr) && pgErr.Code == uniqueViolation:
+		logger.Error("Tenant already exists", utils.Err(err))
+		renderError(w, r, http.StatusConflict, "Tenant with the same name already exists")
+	default:
+		logger.Error(msg, utils.Err(err))
+		renderError(w, r, http.StatusInternalServerError, msg)
+	}
+}
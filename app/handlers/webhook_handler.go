@@ -0,0 +1,315 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/exp/slog"
+	"net/http"
+	"person-service/mappers"
+	"person-service/model"
+	"person-service/services"
+	"person-service/utils"
+	"strconv"
+)
+
+const deliveriesLimit = 100
+
+// CreateWebhook godoc
+// @Summary      Create webhook subscription
+// @Description  Create webhook subscription, generated secret is returned only in this response
+// @Tags         webhooks
+// @Accept       json
+// @Accept       xml
+// @Accept       application/msgpack
+// @Accept       application/cbor
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param  		 request	body    	model.WebhookRequest  	true  "Model for create webhook subscription."
+// @Success      201  		{object}   	model.WebhookResponse
+// @Failure      400  		{object}   	model.ErrorResponse
+// @Failure      415  		{object}   	model.ErrorResponse
+// @Router       /v1/webhooks [post]
+func CreateWebhook(logger *slog.Logger, service *services.WebhookService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.createWebhook"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req model.WebhookRequest
+		if !decodeRequest(w, r, logger, &req) {
+			return
+		}
+
+		saved, err := service.CreateSubscription(r.Context(), mappers.ToWebhookSubscription(req))
+		if err != nil {
+			renderWebhookError(w, r, logger, err, "Error while save webhook subscription")
+			return
+		}
+
+		logger.Info("Successfully save webhook subscription", slog.String("id", saved.Id.String()))
+		render.Status(r, http.StatusCreated)
+		respond(w, r, mappers.ToWebhookResponse(saved, true))
+	}
+}
+
+// UpdateWebhook godoc
+// @Summary      Update webhook subscription
+// @Description  Update webhook subscription, empty secret keeps current one
+// @Tags         webhooks
+// @Accept       json
+// @Accept       xml
+// @Accept       application/msgpack
+// @Accept       application/cbor
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    		path    	string  				true  	"ID of webhook subscription."
+// @Param  		 request	body    	model.WebhookRequest  	true  	"Model for update webhook subscription."
+// @Success      200  		{object}   	model.WebhookResponse
+// @Failure      400  		{object}   	model.ErrorResponse
+// @Failure      404  		{object}   	model.ErrorResponse
+// @Failure      415  		{object}   	model.ErrorResponse
+// @Router       /v1/webhooks/{id} [put]
+func UpdateWebhook(logger *slog.Logger, service *services.WebhookService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.updateWebhook"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+
+		var req model.WebhookRequest
+		if !decodeRequest(w, r, logger, &req) {
+			return
+		}
+
+		subscription := mappers.ToWebhookSubscription(req)
+		subscription.Id = id
+		updated, err := service.UpdateSubscription(r.Context(), subscription)
+		if err != nil {
+			renderWebhookError(w, r, logger, err, fmt.Sprintf("Error while update webhook subscription %s", id))
+			return
+		}
+
+		logger.Info("Successfully update webhook subscription", slog.String("id", id.String()))
+		respond(w, r, mappers.ToWebhookResponse(updated, req.Secret != ""))
+	}
+}
+
+// DeleteWebhook godoc
+// @Summary      Delete webhook subscription
+// @Description  Delete webhook subscription with its delivery log
+// @Tags         webhooks
+// @Param		 id    path    string  	true  	"ID of webhook subscription."
+// @Success      204
+// @Failure      404  {object}   model.ErrorResponse
+// @Router       /v1/webhooks/{id} [delete]
+func DeleteWebhook(logger *slog.Logger, service *services.WebhookService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.deleteWebhook"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+
+		deleted, err := service.DeleteSubscription(r.Context(), id)
+		if err != nil {
+			renderWebhookError(w, r, logger, err, fmt.Sprintf("Error while delete webhook subscription %s", id))
+			return
+		}
+		if !deleted {
+			renderError(w, r, http.StatusNotFound, fmt.Sprintf("Webhook subscription not found by id, with %s", id))
+			return
+		}
+
+		logger.Info("Webhook subscription was successfully deleted", slog.String("id", id.String()))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// FindWebhook godoc
+// @Summary      Find webhook subscription
+// @Description  Find webhook subscription by id
+// @Tags         webhooks
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    path    string  	true  	"ID of webhook subscription."
+// @Success      200  {object}   model.WebhookResponse
+// @Failure      404  {object}   model.ErrorResponse
+// @Router       /v1/webhooks/{id} [get]
+func FindWebhook(logger *slog.Logger, service *services.WebhookService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.findWebhook"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+
+		subscription, err := service.FindSubscriptionById(r.Context(), id)
+		if err != nil {
+			renderWebhookError(w, r, logger, err, fmt.Sprintf("Error while find webhook subscription %s", id))
+			return
+		}
+
+		respond(w, r, mappers.ToWebhookResponse(subscription, false))
+	}
+}
+
+// LoadWebhooks godoc
+// @Summary      Load webhook subscriptions
+// @Description  Load all webhook subscriptions
+// @Tags         webhooks
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Success      200  {array}   model.WebhookResponse
+// @Router       /v1/webhooks [get]
+func LoadWebhooks(logger *slog.Logger, service *services.WebhookService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.loadWebhooks"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		subscriptions, err := service.LoadSubscriptions(r.Context())
+		if err != nil {
+			renderWebhookError(w, r, logger, err, "Error while loading webhook subscriptions")
+			return
+		}
+
+		respond(w, r, mappers.ToWebhooksResponse(subscriptions))
+	}
+}
+
+// LoadWebhookDeliveries godoc
+// @Summary      Load delivery log of webhook subscription
+// @Description  Load latest 100 deliveries with attempts, status=dead returns dead-letter store
+// @Tags         webhooks
+// @Produce      json
+// @Produce      xml
+// @Produce      application/msgpack
+// @Produce      application/cbor
+// @Param		 id    		path    string  	true  	"ID of webhook subscription."
+// @Param		 status    	query   string  	false  	"Delivery status: pending, delivered, dead."
+// @Success      200  {array}   model.WebhookDeliveryResponse
+// @Failure      404  {object}  model.ErrorResponse
+// @Router       /v1/webhooks/{id}/deliveries [get]
+func LoadWebhookDeliveries(logger *slog.Logger, service *services.WebhookService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.loadWebhookDeliveries"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+
+		deliveries, attempts, err := service.LoadDeliveries(r.Context(), id, r.URL.Query().Get("status"), deliveriesLimit)
+		if err != nil {
+			renderWebhookError(w, r, logger, err, fmt.Sprintf("Error while loading deliveries of webhook subscription %s", id))
+			return
+		}
+
+		respond(w, r, mappers.ToWebhookDeliveriesResponse(deliveries, attempts))
+	}
+}
+
+// RetryWebhookDelivery godoc
+// @Summary      Retry dead webhook delivery
+// @Description  Move delivery from dead-letter store back to queue
+// @Tags         webhooks
+// @Param		 id    			path    string  	true  	"ID of webhook subscription."
+// @Param		 deliveryId    	path    int  		true  	"ID of delivery."
+// @Success      202
+// @Failure      404  {object}  model.ErrorResponse
+// @Router       /v1/webhooks/{id}/deliveries/{deliveryId}/retry [post]
+func RetryWebhookDelivery(logger *slog.Logger, service *services.WebhookService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.retryWebhookDelivery"
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := parseUuidParam(w, r, "id")
+		if !ok {
+			return
+		}
+		deliveryId, err := strconv.ParseInt(chi.URLParam(r, "deliveryId"), 10, 64)
+		if err != nil {
+			renderError(w, r, http.StatusBadRequest, "Parameter deliveryId must be integer")
+			return
+		}
+
+		requeued, err := service.RetryDelivery(r.Context(), id, deliveryId)
+		if err != nil {
+			renderWebhookError(w, r, logger, err, fmt.Sprintf("Error while retry delivery %d", deliveryId))
+			return
+		}
+		if !requeued {
+			renderError(w, r, http.StatusNotFound, fmt.Sprintf("Dead delivery not found by id, with %d", deliveryId))
+			return
+		}
+
+		logger.Info("Delivery was moved back to queue", slog.Int64("delivery_id", deliveryId))
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func renderWebhookError(w http.ResponseWriter, r *http.Request, logger *slog.Logger, err error, msg string) {
+	var validationErr *services.ValidationError
+
+	switch {
+	case errors.As(err, &validationErr):
+		logger.Error("Webhook request is not valid", utils.Err(err))
+		renderError(w, r, http.StatusBadRequest, validationErr.Message)
+	case errors.Is(err, pgx.ErrNoRows):
+		logger.Error("Webhook subscription not found", utils.Err(err))
+		renderError(w, r, http.StatusNotFound, "Webhook subscription not found")
+	default:
+		logger.Error(msg, utils.Err(err))
+		renderError(w, r, http.StatusInternalServerError, msg)
+	}
+}
+
+// parseUuidParam parse uuid url param, writes 400 response when it is not valid.
+func parseUuidParam(w http.ResponseWriter, r *http.Request, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, name))
+	if err != nil {
+		renderError(w, r, http.StatusBadRequest, fmt.Sprintf("Parameter %s must be uuid", name))
+		return uuid.UUID{}, false
+	}
+	return id, true
+}
@@ -0,0 +1,283 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+// ErrUnsupported content is not JPEG, PNG or GIF image.
+var ErrUnsupported = errors.New("unsupported image format")
+
+// ErrTooLarge image has more pixels than allowed.
+var ErrTooLarge = errors.New("image is too large")
+
+const jpegQuality = 85
+
+// Picture decoded image with EXIF orientation of JPEG, which is applied when picture is scaled.
+type Picture struct {
+	ContentType string
+	image       image.Image
+	orientation int
+}
+
+// Decode sniff content type and decode image, dimensions are checked before pixels are decoded.
+func Decode(data []byte, maxPixels int) (*Picture, error) {
+	contentType := http.DetectContentType(data)
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif":
+	default:
+		return nil, ErrUnsupported
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+
+	picture := &Picture{ContentType: contentType, image: decoded, orientation: 1}
+	if contentType == "image/jpeg" {
+		picture.orientation = exifOrientation(data)
+	}
+	return picture, nil
+}
+
+// Width width as displayed, i.e. after orientation.
+func (p *Picture) Width() int {
+	if p.transposed() {
+		return p.image.Bounds().Dy()
+	}
+	return p.image.Bounds().Dx()
+}
+
+// Height height as displayed, i.e. after orientation.
+func (p *Picture) Height() int {
+	if p.transposed() {
+		return p.image.Bounds().Dx()
+	}
+	return p.image.Bounds().Dy()
+}
+
+// Fit scale picture down to fit max x max keeping aspect ratio, smaller picture keeps its size.
+func (p *Picture) Fit(max int) *image.RGBA {
+	bounds := p.image.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > max || height > max {
+		if width >= height {
+			width, height = max, scaled(height, max, width)
+		} else {
+			width, height = scaled(width, max, height), max
+		}
+	}
+	return orient(resize(p.image, bounds, width, height), p.orientation)
+}
+
+// Square center square of picture scaled to size x size.
+func (p *Picture) Square(size int) *image.RGBA {
+	/* center crop is the same in any orientation */
+	bounds := p.image.Bounds()
+	side := bounds.Dx()
+	if bounds.Dy() < side {
+		side = bounds.Dy()
+	}
+	x := bounds.Min.X + (bounds.Dx()-side)/2
+	y := bounds.Min.Y + (bounds.Dy()-side)/2
+	return orient(resize(p.image, image.Rect(x, y, x+side, y+side), size, size), p.orientation)
+}
+
+// Encode JPEG picture as JPEG and others as PNG, metadata as EXIF is never written.
+func Encode(img image.Image, contentType string) ([]byte, string, error) {
+	var buffer bytes.Buffer
+	if contentType == "image/jpeg" {
+		if err := jpeg.Encode(&buffer, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, "", err
+		}
+		return buffer.Bytes(), "image/jpeg", nil
+	}
+
+	if err := png.Encode(&buffer, img); err != nil {
+		return nil, "", err
+	}
+	return buffer.Bytes(), "image/png", nil
+}
+
+func (p *Picture) transposed() bool {
+	return p.orientation >= 5
+}
+
+func scaled(side int, target int, base int) int {
+	result := side * target / base
+	if result < 1 {
+		return 1
+	}
+	return result
+}
+
+// resize box filter of source rectangle into width x height image, each target pixel averages its source area.
+func resize(src image.Image, rect image.Rectangle, width int, height int) *image.RGBA {
+	at := pixels(src)
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for dy := 0; dy < height; dy++ {
+		y0 := rect.Min.Y + dy*rect.Dy()/height
+		y1 := rect.Min.Y + (dy+1)*rect.Dy()/height
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for dx := 0; dx < width; dx++ {
+			x0 := rect.Min.X + dx*rect.Dx()/width
+			x1 := rect.Min.X + (dx+1)*rect.Dx()/width
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var sum [4]uint32
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					pixel := at(x, y)
+					for i := range sum {
+						sum[i] += uint32(pixel[i])
+					}
+				}
+			}
+			count := uint32((x1 - x0) * (y1 - y0))
+			offset := dst.PixOffset(dx, dy)
+			for i := range sum {
+				dst.Pix[offset+i] = uint8((sum[i] + count/2) / count)
+			}
+		}
+	}
+	return dst
+}
+
+// pixels accessor of premultiplied RGBA pixel, decoded JPEG and PNG formats are read without color conversion by interface.
+func pixels(src image.Image) func(x, y int) [4]uint8 {
+	switch typed := src.(type) {
+	case *image.YCbCr:
+		return func(x, y int) [4]uint8 {
+			yi, ci := typed.YOffset(x, y), typed.COffset(x, y)
+			r, g, b := color.YCbCrToRGB(typed.Y[yi], typed.Cb[ci], typed.Cr[ci])
+			return [4]uint8{r, g, b, 0xff}
+		}
+	case *image.RGBA:
+		return func(x, y int) [4]uint8 {
+			offset := typed.PixOffset(x, y)
+			return [4]uint8{typed.Pix[offset], typed.Pix[offset+1], typed.Pix[offset+2], typed.Pix[offset+3]}
+		}
+	case *image.Gray:
+		return func(x, y int) [4]uint8 {
+			v := typed.Pix[typed.PixOffset(x, y)]
+			return [4]uint8{v, v, v, 0xff}
+		}
+	}
+	return func(x, y int) [4]uint8 {
+		r, g, b, a := src.At(x, y).RGBA()
+		return [4]uint8{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), uint8(a >> 8)}
+	}
+}
+
+// orient transform image by EXIF orientation (1-8) so it is displayed upright.
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	width, height := src.Rect.Dx(), src.Rect.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	if orientation >= 5 {
+		dst = image.NewRGBA(image.Rect(0, 0, height, width))
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var tx, ty int
+			switch orientation {
+			case 2:
+				tx, ty = width-1-x, y
+			case 3:
+				tx, ty = width-1-x, height-1-y
+			case 4:
+				tx, ty = x, height-1-y
+			case 5:
+				tx, ty = y, x
+			case 6:
+				tx, ty = height-1-y, x
+			case 7:
+				tx, ty = height-1-y, width-1-x
+			case 8:
+				tx, ty = y, width-1-x
+			}
+			copy(dst.Pix[dst.PixOffset(tx, ty):dst.PixOffset(tx, ty)+4], src.Pix[src.PixOffset(x, y):src.PixOffset(x, y)+4])
+		}
+	}
+	return dst
+}
+
+// exifOrientation orientation tag of IFD0 in APP1 Exif segment of JPEG, 1 when it is absent or not readable.
+func exifOrientation(data []byte) int {
+	const orientationTag = 0x0112
+
+	if len(data) < 4 || data[0] != 0xff || data[1] != 0xd8 {
+		return 1
+	}
+	for offset := 2; offset+4 <= len(data) && data[offset] == 0xff; {
+		marker := data[offset+1]
+		length := int(binary.BigEndian.Uint16(data[offset+2:]))
+		/* start of scan, metadata segments are before it */
+		if marker == 0xda || length < 2 || offset+2+length > len(data) {
+			return 1
+		}
+		segment := data[offset+4 : offset+2+length]
+		offset += 2 + length
+
+		if marker != 0xe1 || !bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			continue
+		}
+		tiff := segment[6:]
+		if len(tiff) < 8 {
+			return 1
+		}
+		var order binary.ByteOrder
+		switch string(tiff[:2]) {
+		case "II":
+			order = binary.LittleEndian
+		case "MM":
+			order = binary.BigEndian
+		default:
+			return 1
+		}
+
+		ifd := int(order.Uint32(tiff[4:]))
+		if ifd+2 > len(tiff) {
+			return 1
+		}
+		entries := int(order.Uint16(tiff[ifd:]))
+		for i := 0; i < entries; i++ {
+			entry := ifd + 2 + i*12
+			if entry+12 > len(tiff) {
+				return 1
+			}
+			if order.Uint16(tiff[entry:]) == orientationTag {
+				if value := int(order.Uint16(tiff[entry+8:])); value >= 1 && value <= 8 {
+					return value
+				}
+				return 1
+			}
+		}
+		return 1
+	}
+	return 1
+}
@@ -0,0 +1,153 @@
+package imaging
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+var (
+	red  = color.RGBA{R: 0xff, A: 0xff}
+	blue = color.RGBA{B: 0xff, A: 0xff}
+)
+
+func Test_Decode(t *testing.T) {
+	t.Run("must reject content which is not image", func(t *testing.T) {
+		_, err := Decode([]byte("<html><body>avatar</body></html>"), 1000)
+
+		assert.ErrorIs(t, err, ErrUnsupported)
+	})
+
+	t.Run("must reject image over pixel limit", func(t *testing.T) {
+		_, err := Decode(encodePng(t, halves(40, 20)), 799)
+
+		assert.ErrorIs(t, err, ErrTooLarge)
+	})
+
+	t.Run("must sniff content type regardless of extension", func(t *testing.T) {
+		picture, err := Decode(encodePng(t, halves(40, 20)), 800)
+
+		require.NoError(t, err)
+		assert.Equal(t, "image/png", picture.ContentType)
+		assert.Equal(t, 40, picture.Width())
+		assert.Equal(t, 20, picture.Height())
+	})
+}
+
+func Test_Fit(t *testing.T) {
+	picture, err := Decode(encodePng(t, halves(400, 200)), 1_000_000)
+	require.NoError(t, err)
+
+	t.Run("must scale down keeping aspect ratio", func(t *testing.T) {
+		fitted := picture.Fit(100)
+
+		assert.Equal(t, image.Rect(0, 0, 100, 50), fitted.Bounds())
+		assertColor(t, red, fitted.At(10, 25))
+		assertColor(t, blue, fitted.At(90, 25))
+	})
+
+	t.Run("must keep smaller picture", func(t *testing.T) {
+		assert.Equal(t, image.Rect(0, 0, 400, 200), picture.Fit(1000).Bounds())
+	})
+}
+
+func Test_Square(t *testing.T) {
+	picture, err := Decode(encodePng(t, halves(400, 200)), 1_000_000)
+	require.NoError(t, err)
+
+	square := picture.Square(64)
+
+	assert.Equal(t, image.Rect(0, 0, 64, 64), square.Bounds())
+	assertColor(t, red, square.At(5, 32))
+	assertColor(t, blue, square.At(58, 32))
+}
+
+func Test_Orientation(t *testing.T) {
+	/* orientation 6: stored image must be rotated clockwise, so its left half becomes top */
+	data := withOrientation(t, encodeJpeg(t, halves(64, 32)), 6)
+
+	picture, err := Decode(data, 1_000_000)
+	require.NoError(t, err)
+
+	assert.Equal(t, 32, picture.Width())
+	assert.Equal(t, 64, picture.Height())
+
+	fitted := picture.Fit(1000)
+	assert.Equal(t, image.Rect(0, 0, 32, 64), fitted.Bounds())
+	assertColor(t, red, fitted.At(16, 8))
+	assertColor(t, blue, fitted.At(16, 56))
+
+	t.Run("must strip EXIF on encode", func(t *testing.T) {
+		encoded, contentType, err := Encode(fitted, picture.ContentType)
+
+		require.NoError(t, err)
+		assert.Equal(t, "image/jpeg", contentType)
+		assert.False(t, bytes.Contains(encoded, []byte("Exif")))
+	})
+}
+
+func Test_ExifOrientation(t *testing.T) {
+	data := encodeJpeg(t, halves(8, 8))
+
+	assert.Equal(t, 1, exifOrientation(data))
+	for orientation := 1; orientation <= 8; orientation++ {
+		assert.Equal(t, orientation, exifOrientation(withOrientation(t, data, byte(orientation))))
+	}
+	assert.Equal(t, 1, exifOrientation([]byte{0xff, 0xd8, 0xff, 0xe1, 0x00}))
+}
+
+// halves image with red left half and blue right half.
+func halves(width int, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if x < width/2 {
+				img.Set(x, y, red)
+			} else {
+				img.Set(x, y, blue)
+			}
+		}
+	}
+	return img
+}
+
+func encodePng(t *testing.T, img image.Image) []byte {
+	var buffer bytes.Buffer
+	require.NoError(t, png.Encode(&buffer, img))
+	return buffer.Bytes()
+}
+
+func encodeJpeg(t *testing.T, img image.Image) []byte {
+	var buffer bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buffer, img, &jpeg.Options{Quality: 95}))
+	return buffer.Bytes()
+}
+
+// withOrientation insert APP1 Exif segment with big-endian IFD0 of single orientation entry after SOI marker.
+func withOrientation(t *testing.T, data []byte, orientation byte) []byte {
+	tiff := []byte{'M', 'M', 0, 42, 0, 0, 0, 8, 0, 1, 0x01, 0x12, 0, 3, 0, 0, 0, 1, 0, orientation, 0, 0, 0, 0, 0, 0}
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := append([]byte{0xff, 0xe1, 0, byte(len(payload) + 2)}, payload...)
+
+	require.Equal(t, []byte{0xff, 0xd8}, data[:2])
+	return append(append([]byte{0xff, 0xd8}, segment...), data[2:]...)
+}
+
+func assertColor(t *testing.T, expected color.RGBA, actual color.Color) {
+	t.Helper()
+	r, g, b, _ := actual.RGBA()
+	distance := func(x uint32, y uint8) int {
+		d := int(x>>8) - int(y)
+		if d < 0 {
+			return -d
+		}
+		return d
+	}
+	assert.True(t, distance(r, expected.R) < 40 && distance(g, expected.G) < 40 && distance(b, expected.B) < 40,
+		"expected %v, actual %v", expected, actual)
+}
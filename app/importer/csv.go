@@ -0,0 +1,180 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+	"person-service/mappers"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	FieldId        = "id"
+	FieldLogin     = "login"
+	FieldFirstName = "firstName"
+	FieldLastName  = "lastName"
+	FieldAge       = "age"
+	FieldBirthDate = "birthDate"
+)
+
+var fields = []string{FieldId, FieldLogin, FieldFirstName, FieldLastName, FieldAge, FieldBirthDate}
+
+// CSVOptions delimiter and mapping of person fields to header columns, by default header is matched
+// with field names case-insensitive (first_name is matched with firstName as well).
+type CSVOptions struct {
+	Delimiter rune
+	Columns   map[string]string
+}
+
+// ParseDelimiter parse single character delimiter, tab is accepted as tab or \t, empty value means comma.
+func ParseDelimiter(value string) (rune, error) {
+	switch {
+	case value == "":
+		return ',', nil
+	case value == "tab" || value == `\t`:
+		return '\t', nil
+	case utf8.RuneCountInString(value) == 1:
+		delimiter, _ := utf8.DecodeRuneInString(value)
+		return delimiter, nil
+	default:
+		return 0, fmt.Errorf("delimiter must be single character, got %q", value)
+	}
+}
+
+// ParseColumns parse mapping in format field:column,field:column.
+func ParseColumns(value string) (map[string]string, error) {
+	columns := make(map[string]string)
+	if strings.TrimSpace(value) == "" {
+		return columns, nil
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		field, column, ok := strings.Cut(pair, ":")
+		if !ok || !isField(strings.TrimSpace(field)) || strings.TrimSpace(column) == "" {
+			return nil, fmt.Errorf("column mapping must be field:column with field one of %s, got %q", strings.Join(fields, ", "), pair)
+		}
+		columns[strings.TrimSpace(field)] = strings.TrimSpace(column)
+	}
+	return columns, nil
+}
+
+type CSVReader struct {
+	reader    *csv.Reader
+	delimiter string
+	/* field -> index of column */
+	positions map[string]int
+}
+
+// NewCSVReader read header of CSV and resolve column of every person field.
+func NewCSVReader(r io.Reader, options CSVOptions) (*CSVReader, error) {
+	reader := csv.NewReader(skipBom(r))
+	if options.Delimiter != 0 {
+		reader.Comma = options.Delimiter
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error while read csv header: %w", err)
+	}
+
+	positions := make(map[string]int)
+	for _, field := range fields {
+		column, ok := options.Columns[field]
+		for index, name := range header {
+			name = strings.TrimSpace(name)
+			if ok && name == column || !ok && normalize(name) == normalize(field) {
+				positions[field] = index
+				break
+			}
+		}
+		if _, found := positions[field]; ok && !found {
+			return nil, fmt.Errorf("column %q of field %s not found in csv header", column, field)
+		}
+	}
+	if _, ok := positions[FieldFirstName]; !ok {
+		return nil, fmt.Errorf("csv header must contain column of field %s", FieldFirstName)
+	}
+	if _, ok := positions[FieldLastName]; !ok {
+		return nil, fmt.Errorf("csv header must contain column of field %s", FieldLastName)
+	}
+
+	return &CSVReader{reader: reader, delimiter: string(reader.Comma), positions: positions}, nil
+}
+
+func (c *CSVReader) Read() (Record, error) {
+	row, err := c.reader.Read()
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return Record{Line: parseErr.Line, Err: &RowError{Message: parseErr.Err.Error()}}, nil
+	} else if err != nil {
+		return Record{}, err
+	}
+
+	line, _ := c.reader.FieldPos(0)
+	record := Record{Line: line, Raw: strings.Join(row, c.delimiter)}
+
+	for _, value := range row {
+		if !utf8.ValidString(value) {
+			record.Err = &RowError{Message: "Row is not valid UTF-8"}
+			return record, nil
+		}
+	}
+
+	value := func(field string) string {
+		if index, ok := c.positions[field]; ok && index < len(row) {
+			return strings.TrimSpace(row[index])
+		}
+		return ""
+	}
+
+	if id := value(FieldId); id != "" {
+		parsed, err := uuid.Parse(id)
+		if err != nil {
+			record.Err = &RowError{Message: "Field id must be uuid"}
+			return record, nil
+		}
+		record.Person.Id = &parsed
+	}
+	if age := value(FieldAge); age != "" {
+		parsed, err := strconv.Atoi(age)
+		if err != nil {
+			record.Err = &RowError{Message: "Field age must be integer"}
+			return record, nil
+		}
+		record.Person.Age = parsed
+	}
+	if birthDate := value(FieldBirthDate); birthDate != "" {
+		parsed, err := time.Parse(mappers.DateLayout, birthDate)
+		if err != nil {
+			record.Err = &RowError{Message: "Field birthDate must be date in format YYYY-MM-DD"}
+			return record, nil
+		}
+		record.Person.BirthDate = &parsed
+	}
+	record.Person.Login = value(FieldLogin)
+	record.Person.FirstName = value(FieldFirstName)
+	record.Person.LastName = value(FieldLastName)
+
+	return record, nil
+}
+
+func isField(name string) bool {
+	for _, field := range fields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+func normalize(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
@@ -0,0 +1,158 @@
+package importer
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"io"
+	"person-service/db/entity"
+	"person-service/services"
+	"sort"
+	"strconv"
+)
+
+const (
+	uniqueViolation  = "23505"
+	defaultChunkSize = 500
+)
+
+// Options of import, rows are upserted by chunks of ChunkSize.
+type Options struct {
+	DryRun    bool
+	ChunkSize int
+}
+
+// Rejected row which was not imported.
+type Rejected struct {
+	Line   int
+	Reason string
+	Raw    string
+}
+
+// Report result of import, in dry run counters show what would be imported.
+type Report struct {
+	DryRun   bool
+	Total    int
+	Created  int
+	Updated  int
+	Rejected []Rejected
+}
+
+// Import read all records and upsert valid persons through service, dry run is rolled back at the end.
+// Error is returned only when import can not continue, rejected rows are collected in report.
+func Import(ctx context.Context, service *services.PersonService, reader Reader, options Options) (Report, error) {
+	const op = "importer.Import"
+
+	if options.ChunkSize <= 0 {
+		options.ChunkSize = defaultChunkSize
+	}
+
+	report := Report{DryRun: options.DryRun}
+	run := func(ctx context.Context) error {
+		chunk := make([]Record, 0, options.ChunkSize)
+		for {
+			record, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				return err
+			}
+
+			report.Total++
+			if record.Err != nil {
+				report.Rejected = append(report.Rejected, Rejected{Line: record.Line, Reason: reason(record.Err), Raw: record.Raw})
+				continue
+			}
+
+			chunk = append(chunk, record)
+			if len(chunk) == options.ChunkSize {
+				if err := importChunk(ctx, service, chunk, &report); err != nil {
+					return err
+				}
+				chunk = chunk[:0]
+			}
+		}
+
+		if len(chunk) == 0 {
+			return nil
+		}
+		return importChunk(ctx, service, chunk, &report)
+	}
+
+	var err error
+	if options.DryRun {
+		err = service.DryRun(ctx, run)
+	} else {
+		err = run(ctx)
+	}
+	if err != nil {
+		return report, fmt.Errorf("%s: %w", op, err)
+	}
+
+	/* rows rejected by database are collected after rows of the next chunk */
+	sort.SliceStable(report.Rejected, func(i, j int) bool {
+		return report.Rejected[i].Line < report.Rejected[j].Line
+	})
+	return report, nil
+}
+
+func importChunk(ctx context.Context, service *services.PersonService, chunk []Record, report *Report) error {
+	persons := make([]entity.Person, len(chunk))
+	for index, record := range chunk {
+		persons[index] = record.Person
+	}
+
+	results, err := service.ImportPersons(ctx, persons)
+	if err != nil {
+		return err
+	}
+
+	for index, result := range results {
+		switch {
+		case result.Err != nil:
+			report.Rejected = append(report.Rejected, Rejected{Line: chunk[index].Line, Reason: reason(result.Err), Raw: chunk[index].Raw})
+		case result.Created:
+			report.Created++
+		default:
+			report.Updated++
+		}
+	}
+	return nil
+}
+
+// WriteReport write rejected rows as CSV with columns line, reason, row.
+func WriteReport(w io.Writer, report Report) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"line", "reason", "row"}); err != nil {
+		return err
+	}
+	for _, rejected := range report.Rejected {
+		if err := writer.Write([]string{strconv.Itoa(rejected.Line), rejected.Reason, rejected.Raw}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func reason(err error) string {
+	var rowErr *RowError
+	var validationErr *services.ValidationError
+	var pgErr *pgconn.PgError
+
+	switch {
+	case errors.As(err, &rowErr):
+		return rowErr.Message
+	case errors.As(err, &validationErr):
+		return validationErr.Message
+	case errors.Is(err, pgx.ErrNoRows):
+		return "Person not found"
+	case errors.As(err, &pgErr) && pgErr.Code == uniqueViolation:
+		return "Person with the same login or id already exists"
+	default:
+		return "Error while import row"
+	}
+}
@@ -0,0 +1,66 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"person-service/mappers"
+	"person-service/model"
+	"unicode/utf8"
+)
+
+const maxLineSize = 1024 * 1024
+
+type NDJSONReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// NewNDJSONReader reader of one person request per line, empty lines are skipped.
+func NewNDJSONReader(r io.Reader) *NDJSONReader {
+	scanner := bufio.NewScanner(skipBom(r))
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	return &NDJSONReader{scanner: scanner}
+}
+
+func (n *NDJSONReader) Read() (Record, error) {
+	for n.scanner.Scan() {
+		n.line++
+		line := bytes.TrimSpace(n.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		record := Record{Line: n.line, Raw: string(line)}
+		if !utf8.Valid(line) {
+			record.Err = &RowError{Message: "Row is not valid UTF-8"}
+			return record, nil
+		}
+
+		var req model.PersonRequest
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&req); err != nil {
+			record.Err = &RowError{Message: "Row is not valid person: " + err.Error()}
+			return record, nil
+		}
+		if req.Timestamp != nil {
+			record.Err = &RowError{Message: "Field timestamp is managed by server and must not be sent"}
+			return record, nil
+		}
+
+		person, err := mappers.ToPerson(req)
+		if err != nil {
+			record.Err = &RowError{Message: err.Error()}
+			return record, nil
+		}
+		record.Person = person
+		return record, nil
+	}
+
+	if err := n.scanner.Err(); err != nil {
+		return Record{}, err
+	}
+	return Record{}, io.EOF
+}
@@ -0,0 +1,41 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"person-service/db/entity"
+)
+
+var utf8Bom = []byte{0xEF, 0xBB, 0xBF}
+
+// Record parsed row of import file, Err is set for rejected row.
+type Record struct {
+	Line   int
+	Raw    string
+	Person entity.Person
+	Err    error
+}
+
+// Reader stream of import records, returns io.EOF after last record.
+type Reader interface {
+	Read() (Record, error)
+}
+
+// RowError row is rejected by reader.
+type RowError struct {
+	Message string
+}
+
+func (e *RowError) Error() string {
+	return e.Message
+}
+
+// skipBom wrap reader and skip UTF-8 byte order mark, which is written by spreadsheet editors.
+func skipBom(r io.Reader) *bufio.Reader {
+	reader := bufio.NewReader(r)
+	if prefix, err := reader.Peek(len(utf8Bom)); err == nil && bytes.Equal(prefix, utf8Bom) {
+		_, _ = reader.Discard(len(utf8Bom))
+	}
+	return reader
+}
@@ -0,0 +1,115 @@
+package importer
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readAll(t *testing.T, reader Reader) []Record {
+	var records []Record
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return records
+		}
+		assert.NoError(t, err)
+		records = append(records, record)
+	}
+}
+
+func Test_CSVReader(t *testing.T) {
+	t.Run("must skip bom and map columns with custom delimiter", func(t *testing.T) {
+		file := "\xEF\xBB\xBFЛогин;Имя;Фамилия;Возраст\n" +
+			"a.sidorov;Алексей;Сидоров;18\n" +
+			"p.petrov;Пётр;Петров;возраст\n"
+		columns, err := ParseColumns("login:Логин,firstName:Имя,lastName:Фамилия,age:Возраст")
+		assert.NoError(t, err)
+
+		reader, err := NewCSVReader(strings.NewReader(file), CSVOptions{Delimiter: ';', Columns: columns})
+		assert.NoError(t, err)
+		records := readAll(t, reader)
+
+		assert.Len(t, records, 2)
+		assert.NoError(t, records[0].Err)
+		assert.Equal(t, 2, records[0].Line)
+		assert.Equal(t, "a.sidorov", records[0].Person.Login)
+		assert.Equal(t, "Алексей", records[0].Person.FirstName)
+		assert.Equal(t, 18, records[0].Person.Age)
+		assert.EqualError(t, records[1].Err, "Field age must be integer")
+		assert.Equal(t, "p.petrov;Пётр;Петров;возраст", records[1].Raw)
+	})
+
+	t.Run("must match default header and continue after broken row", func(t *testing.T) {
+		file := "first_name,last_name,age\n" +
+			"Иван,\"Иванов,30\n"
+		reader, err := NewCSVReader(strings.NewReader(file), CSVOptions{})
+		assert.NoError(t, err)
+		records := readAll(t, reader)
+
+		assert.Len(t, records, 1)
+		assert.Error(t, records[0].Err)
+	})
+
+	t.Run("must parse birth date", func(t *testing.T) {
+		file := "first_name,last_name,birth_date\n" +
+			"Иван,Иванов,1990-07-14\n" +
+			"Пётр,Петров,14.07.1990\n"
+		reader, err := NewCSVReader(strings.NewReader(file), CSVOptions{})
+		assert.NoError(t, err)
+		records := readAll(t, reader)
+
+		assert.Len(t, records, 2)
+		assert.NoError(t, records[0].Err)
+		assert.Equal(t, time.Date(1990, 7, 14, 0, 0, 0, 0, time.UTC), *records[0].Person.BirthDate)
+		assert.EqualError(t, records[1].Err, "Field birthDate must be date in format YYYY-MM-DD")
+	})
+
+	t.Run("must reject header without required columns", func(t *testing.T) {
+		_, err := NewCSVReader(strings.NewReader("login,age\n"), CSVOptions{})
+		assert.Error(t, err)
+
+		_, err = NewCSVReader(strings.NewReader("Имя,Фамилия\n"), CSVOptions{Columns: map[string]string{FieldLogin: "Логин"}})
+		assert.Error(t, err)
+	})
+
+	t.Run("must reject row which is not utf-8", func(t *testing.T) {
+		/* "Иван" in windows-1251 */
+		file := "firstName,lastName\n\xC8\xE2\xE0\xED,Ivanov\n"
+		reader, err := NewCSVReader(strings.NewReader(file), CSVOptions{})
+		assert.NoError(t, err)
+		records := readAll(t, reader)
+
+		assert.EqualError(t, records[0].Err, "Row is not valid UTF-8")
+	})
+}
+
+func Test_NDJSONReader(t *testing.T) {
+	file := "\xEF\xBB\xBF{\"firstName\": \"Алексей\", \"lastName\": \"Сидоров\", \"age\": 18, \"login\": \"a.sidorov\"}\n" +
+		"\n" +
+		"{\"firstName\": \"Пётр\", \"nickname\": \"p\"}\n"
+	records := readAll(t, NewNDJSONReader(strings.NewReader(file)))
+
+	assert.Len(t, records, 2)
+	assert.NoError(t, records[0].Err)
+	assert.Equal(t, "Алексей", records[0].Person.FirstName)
+	assert.Equal(t, "a.sidorov", records[0].Person.Login)
+	assert.Error(t, records[1].Err)
+	assert.Equal(t, 3, records[1].Line)
+}
+
+func Test_ParseDelimiter(t *testing.T) {
+	delimiter, err := ParseDelimiter("tab")
+	assert.NoError(t, err)
+	assert.Equal(t, '\t', delimiter)
+
+	delimiter, err = ParseDelimiter("")
+	assert.NoError(t, err)
+	assert.Equal(t, ',', delimiter)
+
+	_, err = ParseDelimiter(";;")
+	assert.Error(t, err)
+}
@@ -1,15 +1,28 @@
 package main
 
 import (
+	"context"
+	"crypto/rsa"
 	"fmt"
 	"github.com/go-chi/chi/v5"
 	"golang.org/x/exp/slog"
+	"net"
 	"net/http"
 	"os"
+	"person-service/blob"
 	"person-service/config"
 	"person-service/controllers"
 	"person-service/db/repository"
+	"person-service/events"
+	"person-service/graph"
+	"person-service/grpcserver"
+	"person-service/scanner"
+	"person-service/services"
 	"person-service/utils"
+	"person-service/webhooks"
+	"time"
+	/* zone database for person.age-time-zone on hosts without one */
+	_ "time/tzdata"
 )
 
 const (
@@ -20,7 +33,24 @@ const (
 var logger *slog.Logger
 var configuration *config.Config
 var storage *repository.PersonRepositoryImpl
+var transactions *repository.TxManager
+var personService *services.PersonService
+var webhookService *services.WebhookService
+var idempotencyService *services.IdempotencyService
+var contactService *services.ContactService
+var addressService *services.AddressService
+var attributeService *services.AttributeService
+var groupService *services.GroupService
+var tagService *services.TagService
+var relationshipService *services.RelationshipService
+var avatarService *services.AvatarService
+var attachmentService *services.AttachmentService
+var mergeService *services.MergeService
+var tenantService *services.TenantService
+var blobStore blob.Store
 var router *chi.Mux
+var rsaPubKey *rsa.PublicKey
+var personFeed *events.Feed
 
 func init() {
 	/* init configuration */
@@ -28,31 +58,79 @@ func init() {
 	/* init logger */
 	logger = setupLogger(configuration.Env)
 	/* init database */
-	db, err := repository.New(configuration.Datasource)
+	db, err := repository.New(context.Background(), configuration.Datasource, configuration.Person)
 	if err != nil {
 		logger.Error("Failed while init database connection", utils.Err(err))
 		os.Exit(1)
 	}
 	storage = db
 
+	/* init transaction manager */
+	txManager, err := repository.NewTxManager(storage, configuration.Datasource.Transaction)
+	if err != nil {
+		logger.Error("Failed while init transaction manager", utils.Err(err))
+		os.Exit(1)
+	}
+	transactions = txManager
+
+	/* init services */
+	personService = services.NewPersonService(storage, transactions)
+	webhookService = services.NewWebhookService(transactions, configuration.Webhooks.AllowPrivateNetworks)
+	idempotencyService = services.NewIdempotencyService(transactions, configuration.Idempotency.TTL)
+	contactService = services.NewContactService(transactions)
+	addressService = services.NewAddressService(transactions)
+	attributeService = services.NewAttributeService(transactions)
+	groupService = services.NewGroupService(transactions, configuration.Groups.DeleteMode)
+	tagService = services.NewTagService(transactions)
+	relationshipService = services.NewRelationshipService(transactions, configuration.Relationships.MaxDepth)
+	blobStore = setupBlobStore(configuration.Blob)
+	avatarService = services.NewAvatarService(transactions, blobStore,
+		configuration.Avatar.MaxPixels, configuration.Avatar.MaxDimension, configuration.Avatar.Sizes)
+	attachmentService = services.NewAttachmentService(transactions, blobStore, setupScanner(configuration.Attachments),
+		configuration.Attachments.MaxSize, configuration.Attachments.TempDir)
+	mergeService = services.NewMergeService(transactions, avatarService, configuration.Duplicates.MinScore, configuration.Duplicates.Limit)
+	tenantService = services.NewTenantService(transactions, configuration.Tenancy.Enabled, configuration.Tenancy.Required)
+
 	/* init router */
 	router = chi.NewRouter()
-	controllers.RegisterCorsMiddlewareHandlers(router)
+	controllers.RegisterCorsMiddlewareHandlers(router, configuration.Cors)
 
 	/* init security | mock security for integration testing */
 	if configuration.Security.Module != "" {
-		rsaPubKey, rsaErr := utils.ConstructRsaPublicKey(configuration.Security.Module, configuration.Security.Exponent)
+		key, rsaErr := utils.ConstructRsaPublicKey(configuration.Security.Module, configuration.Security.Exponent)
 		if rsaErr != nil {
 			logger.Error("Failed to create rsa.PublicKey", utils.Err(rsaErr))
 			os.Exit(1)
 		}
-		controllers.RegisterMiddlewareHandlers(logger, router, rsaPubKey)
-	} else {
-		controllers.RegisterMiddlewareHandlers(logger, router, nil)
+		rsaPubKey = key
 	}
+	controllers.RegisterMiddlewareHandlers(logger, router, rsaPubKey, tenantService, configuration.Tenancy)
 
 	/* register api handlers */
-	controllers.RegisterPersonHandlers(logger, router, storage)
+	controllers.RegisterTenantHandlers(logger, router, tenantService, configuration.Tenancy)
+	controllers.RegisterPersonHandlers(logger, router, personService, contactService, addressService, relationshipService, idempotencyService, configuration.Api)
+	controllers.RegisterWebhookHandlers(logger, router, webhookService)
+	controllers.RegisterAttributeHandlers(logger, router, attributeService)
+	controllers.RegisterGroupHandlers(logger, router, groupService, tagService, configuration.Api)
+	controllers.RegisterAvatarHandlers(logger, router, avatarService, configuration.Avatar, configuration.Api)
+	controllers.RegisterAttachmentHandlers(logger, router, attachmentService, configuration.Attachments)
+	controllers.RegisterMergeHandlers(logger, router, mergeService, configuration.Api)
+
+	/* init graphql schema */
+	schema, err := graph.NewSchema(logger, personService, configuration.Graphql)
+	if err != nil {
+		logger.Error("Failed to build graphql schema", utils.Err(err))
+		os.Exit(1)
+	}
+	controllers.RegisterGraphqlHandlers(logger, router, schema)
+
+	/* init change feed, it listens for events committed by every replica */
+	personFeed = events.NewFeed(logger, transactions, configuration.Stream)
+	if err = personFeed.Start(context.Background()); err != nil {
+		logger.Error("Failed to start change feed", utils.Err(err))
+		os.Exit(1)
+	}
+	controllers.RegisterPersonStreamHandlers(logger, router, personFeed, configuration.Stream, configuration.Cors)
 }
 
 // @title           person-service API
@@ -61,10 +139,34 @@ func init() {
 // @contact.name    API Support
 // @contact.email   support@swagger.io
 // @host      		localhost:9902
-// @BasePath  		/api/v1
+// @BasePath  		/api
 // @externalDocs.description  API for create/update/delete/edit persons.
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		code := runImport(os.Args[2:])
+		storage.Close()
+		os.Exit(code)
+	}
+
 	logger.Info("Starting person-service ... ", slog.String("env", configuration.Env))
+	defer storage.Close()
+
+	/* webhook deliveries are enqueued by outbox relay only */
+	if configuration.Webhooks.Enabled && !configuration.Outbox.Enabled {
+		logger.Error("Webhooks require outbox, enable outbox or disable webhooks")
+		os.Exit(1)
+	}
+	if configuration.Outbox.Enabled {
+		startOutboxRelay(context.Background())
+	}
+	if configuration.Webhooks.Enabled {
+		startWebhookWorker(context.Background())
+	}
+	startIdempotencyCleanup(context.Background())
+	startAttachmentPurge(context.Background())
+	if configuration.Grpc.Enabled {
+		startGrpcServer()
+	}
 
 	logger.Info("Starting http-s: ", slog.Int("port", configuration.Server.Port))
 
@@ -77,6 +179,132 @@ func main() {
 	logger.Error("Http-s stopped.")
 }
 
+func startOutboxRelay(ctx context.Context) {
+	var publisher events.Publisher
+
+	switch configuration.Outbox.Publisher {
+	case "webhook":
+		publisher = events.NewWebhookPublisher(configuration.Outbox.WebhookUrl, &http.Client{Timeout: configuration.Server.Timeout})
+	case "file", "stdout":
+		filePublisher, err := events.NewFilePublisher(configuration.Outbox.FilePath)
+		if err != nil {
+			logger.Error("Failed to init outbox publisher", utils.Err(err))
+			os.Exit(1)
+		}
+		publisher = filePublisher
+	default:
+		logger.Error("Unknown outbox publisher", slog.String("publisher", configuration.Outbox.Publisher))
+		os.Exit(1)
+	}
+
+	/* webhook subscriptions receive events through outbox relay */
+	if configuration.Webhooks.Enabled {
+		publisher = events.MultiPublisher{publisher, webhooks.NewDispatcher(transactions)}
+	}
+
+	go events.NewRelay(logger, transactions, publisher, configuration.Outbox).Run(ctx)
+}
+
+func startWebhookWorker(ctx context.Context) {
+	client := webhooks.NewClient(configuration.Webhooks.Timeout, configuration.Webhooks.AllowPrivateNetworks)
+	go webhooks.NewWorker(logger, transactions, client, configuration.Webhooks).Run(ctx)
+}
+
+func startIdempotencyCleanup(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(configuration.Idempotency.CleanupInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				deleted, err := idempotencyService.DeleteExpired(ctx)
+				if err != nil {
+					logger.Error("Failed to delete expired idempotency keys", utils.Err(err))
+				} else if deleted > 0 {
+					logger.Info("Expired idempotency keys were deleted", slog.Int64("count", deleted))
+				}
+			}
+		}
+	}()
+}
+
+func startAttachmentPurge(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(configuration.Attachments.PurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				expired, purged, err := attachmentService.PurgeAttachments(ctx)
+				if err != nil {
+					logger.Error("Failed to purge attachments", utils.Err(err))
+				} else if expired > 0 || purged > 0 {
+					logger.Info("Attachments were purged", slog.Int64("expired", expired), slog.Int("files", purged))
+				}
+			}
+		}
+	}()
+}
+
+func startGrpcServer() {
+	listener, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", configuration.Grpc.Port))
+	if err != nil {
+		logger.Error("Failed to listen grpc port", utils.Err(err))
+		os.Exit(1)
+	}
+
+	logger.Info("Starting grpc: ", slog.Int("port", configuration.Grpc.Port))
+	server := grpcserver.NewServer(logger, personService, tenantService, rsaPubKey, configuration.Grpc, configuration.Tenancy)
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			logger.Error("Grpc start failed, ", utils.Err(err))
+		}
+	}()
+}
+
+func setupBlobStore(storage config.Blob) blob.Store {
+	switch storage.Type {
+	case "s3":
+		store, err := blob.NewS3Store(storage.Endpoint, storage.Bucket, storage.Region, storage.AccessKey, storage.SecretKey,
+			&http.Client{Timeout: configuration.Server.Timeout})
+		if err != nil {
+			logger.Error("Failed to init blob store", utils.Err(err))
+			os.Exit(1)
+		}
+		return store
+	case "filesystem":
+		store, err := blob.NewFileStore(storage.Path)
+		if err != nil {
+			logger.Error("Failed to init blob store", utils.Err(err))
+			os.Exit(1)
+		}
+		return store
+	default:
+		logger.Error("Unknown blob store", slog.String("type", storage.Type))
+		os.Exit(1)
+	}
+	return nil
+}
+
+func setupScanner(attachments config.Attachments) scanner.Scanner {
+	switch attachments.Scanner {
+	case "clamd":
+		return scanner.NewClamd(attachments.ClamdAddress, attachments.ScanTimeout)
+	case "none":
+		return scanner.Noop{}
+	default:
+		logger.Error("Unknown attachment scanner", slog.String("scanner", attachments.Scanner))
+		os.Exit(1)
+	}
+	return nil
+}
+
 func setupLogger(env string) *slog.Logger {
 	var log *slog.Logger
 
@@ -11,6 +11,7 @@ import (
 	"github.com/testcontainers/testcontainers-go/wait"
 	"io"
 	"net/http"
+	"os"
 	"person-service/model"
 	"person-service/utils"
 	"strings"
@@ -20,8 +21,7 @@ import (
 
 var postgresContainer, ctx = initPostgresContainerAndContext()
 
-func Test_PersonService(t *testing.T) {
-
+func TestMain(m *testing.M) {
 	/* init server */
 	server := setupHttpServer(configuration, router)
 	go func() {
@@ -30,6 +30,17 @@ func Test_PersonService(t *testing.T) {
 		}
 	}()
 
+	code := m.Run()
+
+	/* close postgres postgresContainer */
+	if err := postgresContainer.Terminate(ctx); err != nil {
+		panic(err)
+	}
+
+	os.Exit(code)
+}
+
+func Test_PersonService(t *testing.T) {
 	client := &http.Client{}
 
 	t.Run("must return 200 when create person", func(t *testing.T) {
@@ -50,6 +61,28 @@ func Test_PersonService(t *testing.T) {
 		assert.Equal(t, 200, resp.StatusCode)
 		assert.Equal(t, "Алексей", result.FirstName)
 		assert.Equal(t, "Сидоров", result.LastName)
+		assert.False(t, result.CreatedAt.IsZero())
+		assert.Equal(t, time.UTC, result.CreatedAt.Location())
+		assert.Equal(t, result.CreatedAt, result.UpdatedAt)
+	})
+
+	t.Run("must return 400 when create person with timestamp", func(t *testing.T) {
+		resp, err := http.Post(
+			"http://localhost:9902/api/v1/person/create",
+			"application/json",
+			bytes.NewBuffer([]byte(
+				`{
+					"firstName": "Алексей",
+    				"lastName": "Сидоров",
+    				"age": 18,
+    				"timestamp": "2020-01-01T00:00:00Z"
+				}`,
+			)),
+		)
+
+		parseResponseBytes(err, t, resp)
+
+		assert.Equal(t, 400, resp.StatusCode)
 	})
 
 	t.Run("must return 200 when update person", func(t *testing.T) {
@@ -78,6 +111,7 @@ func Test_PersonService(t *testing.T) {
 		assert.Equal(t, 200, resp.StatusCode)
 		assert.Equal(t, "Алексей", result.FirstName)
 		assert.Equal(t, "Петров", result.LastName)
+		assert.True(t, result.UpdatedAt.After(result.CreatedAt))
 	})
 
 	t.Run("must return 200 when find person", func(t *testing.T) {
@@ -133,13 +167,6 @@ func Test_PersonService(t *testing.T) {
 			string(result),
 		)
 	})
-
-	/* close postgres postgresContainer */
-	defer func() {
-		if err := postgresContainer.Terminate(ctx); err != nil {
-			panic(err)
-		}
-	}()
 }
 
 // initPostgresContainerAndContext method create new postgresContainer with postgres and initialize context.
@@ -197,6 +224,17 @@ func parseResponse(err error, response *http.Response, t *testing.T) *model.Pers
 	return res
 }
 
+// sendJson send JSON body, headers are pairs of name and value.
+func sendJson(t *testing.T, method string, url string, body string, headers ...string) (*http.Response, []byte) {
+	req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	resp, err := http.DefaultClient.Do(req)
+	return resp, parseResponseBytes(err, t, resp)
+}
+
 func parseResponseBytes(err error, t *testing.T, response *http.Response) []byte {
 	if err != nil {
 		t.Fatalf("Error while parse response: %v", err)
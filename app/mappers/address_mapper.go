@@ -0,0 +1,85 @@
+package mappers
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"person-service/db/entity"
+	"person-service/model"
+	"strings"
+	"time"
+)
+
+// DateLayout format of dates in API models.
+const DateLayout = "2006-01-02"
+
+// ToAddress convert request to address, returns error when date is not valid.
+func ToAddress(request model.AddressRequest) (entity.Address, error) {
+	validFrom, err := parseDate("validFrom", request.ValidFrom)
+	if err != nil {
+		return entity.Address{}, err
+	}
+	validTo, err := parseDate("validTo", request.ValidTo)
+	if err != nil {
+		return entity.Address{}, err
+	}
+
+	return entity.Address{
+		Type:       request.Type,
+		Lines:      request.Lines,
+		City:       request.City,
+		Region:     request.Region,
+		PostalCode: request.PostalCode,
+		Country:    request.Country,
+		ValidFrom:  validFrom,
+		ValidTo:    validTo,
+	}, nil
+}
+
+func ToAddressResponse(address entity.Address) model.AddressResponse {
+	return model.AddressResponse{
+		Id:         address.Id,
+		Type:       address.Type,
+		Lines:      address.Lines,
+		City:       address.City,
+		Region:     address.Region,
+		PostalCode: address.PostalCode,
+		Country:    address.Country,
+		ValidFrom:  formatDate(address.ValidFrom),
+		ValidTo:    formatDate(address.ValidTo),
+		CreatedAt:  address.CreatedAt.UTC(),
+		UpdatedAt:  address.UpdatedAt.UTC(),
+	}
+}
+
+func ToAddressesResponse(addresses []entity.Address) []model.AddressResponse {
+	responses := make([]model.AddressResponse, len(addresses))
+	for index, address := range addresses {
+		responses[index] = ToAddressResponse(address)
+	}
+	return responses
+}
+
+// WithAddresses embed addresses into person responses.
+func WithAddresses(persons []model.PersonResponse, addresses map[uuid.UUID][]entity.Address) {
+	for index := range persons {
+		persons[index].Addresses = ToAddressesResponse(addresses[persons[index].Id])
+	}
+}
+
+func parseDate(field string, value string) (*time.Time, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	date, err := time.Parse(DateLayout, strings.TrimSpace(value))
+	if err != nil {
+		return nil, fmt.Errorf("Field %s must be date in format YYYY-MM-DD", field)
+	}
+	return &date, nil
+}
+
+func formatDate(date *time.Time) string {
+	if date == nil {
+		return ""
+	}
+	return date.Format(DateLayout)
+}
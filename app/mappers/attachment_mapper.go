@@ -0,0 +1,32 @@
+package mappers
+
+import (
+	"person-service/db/entity"
+	"person-service/model"
+	"time"
+)
+
+// ParseRetention retention date of attachment, empty value is no retention date.
+func ParseRetention(value string) (*time.Time, error) {
+	return parseDate("retainUntil", value)
+}
+
+func ToAttachmentResponse(attachment entity.Attachment) model.AttachmentResponse {
+	return model.AttachmentResponse{
+		Id:          attachment.Id,
+		FileName:    attachment.FileName,
+		ContentType: attachment.ContentType,
+		Size:        attachment.Size,
+		Sha256:      attachment.Sha256,
+		RetainUntil: formatDate(attachment.RetainUntil),
+		CreatedAt:   attachment.CreatedAt.UTC(),
+	}
+}
+
+func ToAttachmentsResponse(attachments []entity.Attachment) []model.AttachmentResponse {
+	responses := make([]model.AttachmentResponse, len(attachments))
+	for index, attachment := range attachments {
+		responses[index] = ToAttachmentResponse(attachment)
+	}
+	return responses
+}
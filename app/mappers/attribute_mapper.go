@@ -0,0 +1,36 @@
+package mappers
+
+import (
+	"person-service/db/entity"
+	"person-service/model"
+)
+
+func ToAttributeDefinition(request model.AttributeDefinitionRequest) entity.AttributeDefinition {
+	return entity.AttributeDefinition{
+		Name:       request.Name,
+		Type:       entity.AttributeType(request.Type),
+		Required:   request.Required,
+		Pattern:    request.Pattern,
+		EnumValues: request.EnumValues,
+	}
+}
+
+func ToAttributeDefinitionResponse(definition entity.AttributeDefinition) model.AttributeDefinitionResponse {
+	return model.AttributeDefinitionResponse{
+		Name:       definition.Name,
+		Type:       string(definition.Type),
+		Required:   definition.Required,
+		Pattern:    definition.Pattern,
+		EnumValues: definition.EnumValues,
+		CreatedAt:  definition.CreatedAt.UTC(),
+		UpdatedAt:  definition.UpdatedAt.UTC(),
+	}
+}
+
+func ToAttributeDefinitionsResponse(definitions []entity.AttributeDefinition) []model.AttributeDefinitionResponse {
+	responses := make([]model.AttributeDefinitionResponse, len(definitions))
+	for index, definition := range definitions {
+		responses[index] = ToAttributeDefinitionResponse(definition)
+	}
+	return responses
+}
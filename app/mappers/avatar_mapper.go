@@ -0,0 +1,17 @@
+package mappers
+
+import (
+	"person-service/db/entity"
+	"person-service/model"
+)
+
+func ToAvatarResponse(avatar entity.Avatar) model.AvatarResponse {
+	return model.AvatarResponse{
+		ContentType: avatar.ContentType,
+		Width:       avatar.Width,
+		Height:      avatar.Height,
+		Sizes:       avatar.Sizes,
+		ETag:        avatar.ETag,
+		UpdatedAt:   avatar.UpdatedAt.UTC(),
+	}
+}
@@ -0,0 +1,78 @@
+package mappers
+
+import (
+	"github.com/google/uuid"
+	"person-service/db/entity"
+	"person-service/model"
+)
+
+func EmailToContact(request model.EmailRequest) entity.Contact {
+	return entity.Contact{
+		Kind:     entity.ContactEmail,
+		Value:    request.Email,
+		Type:     request.Type,
+		Primary:  request.Primary,
+		Verified: request.Verified,
+	}
+}
+
+func PhoneToContact(request model.PhoneRequest) entity.Contact {
+	return entity.Contact{
+		Kind:     entity.ContactPhone,
+		Value:    request.Phone,
+		Type:     request.Type,
+		Primary:  request.Primary,
+		Verified: request.Verified,
+	}
+}
+
+func ToEmailResponse(contact entity.Contact) model.EmailResponse {
+	return model.EmailResponse{
+		Id:        contact.Id,
+		Email:     contact.Value,
+		Type:      contact.Type,
+		Primary:   contact.Primary,
+		Verified:  contact.Verified,
+		CreatedAt: contact.CreatedAt.UTC(),
+		UpdatedAt: contact.UpdatedAt.UTC(),
+	}
+}
+
+func ToEmailsResponse(contacts []entity.Contact) []model.EmailResponse {
+	emails := make([]model.EmailResponse, len(contacts))
+	for index, contact := range contacts {
+		emails[index] = ToEmailResponse(contact)
+	}
+	return emails
+}
+
+func ToPhoneResponse(contact entity.Contact) model.PhoneResponse {
+	return model.PhoneResponse{
+		Id:        contact.Id,
+		Phone:     contact.Value,
+		Type:      contact.Type,
+		Primary:   contact.Primary,
+		Verified:  contact.Verified,
+		CreatedAt: contact.CreatedAt.UTC(),
+		UpdatedAt: contact.UpdatedAt.UTC(),
+	}
+}
+
+func ToPhonesResponse(contacts []entity.Contact) []model.PhoneResponse {
+	phones := make([]model.PhoneResponse, len(contacts))
+	for index, contact := range contacts {
+		phones[index] = ToPhoneResponse(contact)
+	}
+	return phones
+}
+
+// WithContacts embed contacts into person responses, persons without contacts get empty lists.
+func WithContacts(persons []model.PersonResponse, emails, phones map[uuid.UUID][]entity.Contact) {
+	for index := range persons {
+		id := persons[index].Id
+		persons[index].Contacts = &model.ContactsResponse{
+			Emails: ToEmailsResponse(emails[id]),
+			Phones: ToPhonesResponse(phones[id]),
+		}
+	}
+}
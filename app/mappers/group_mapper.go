@@ -0,0 +1,41 @@
+package mappers
+
+import (
+	"person-service/db/entity"
+	"person-service/model"
+)
+
+func ToGroup(request model.GroupRequest) entity.Group {
+	return entity.Group{
+		ParentId:    request.ParentId,
+		Name:        request.Name,
+		Description: request.Description,
+	}
+}
+
+func ToGroupResponse(group entity.Group) model.GroupResponse {
+	return model.GroupResponse{
+		Id:          group.Id,
+		ParentId:    group.ParentId,
+		Name:        group.Name,
+		Description: group.Description,
+		CreatedAt:   group.CreatedAt.UTC(),
+		UpdatedAt:   group.UpdatedAt.UTC(),
+	}
+}
+
+func ToGroupsResponse(groups []entity.Group) []model.GroupResponse {
+	responses := make([]model.GroupResponse, len(groups))
+	for index, group := range groups {
+		responses[index] = ToGroupResponse(group)
+	}
+	return responses
+}
+
+func ToTagsResponse(tags []entity.Tag) []model.TagResponse {
+	responses := make([]model.TagResponse, len(tags))
+	for index, tag := range tags {
+		responses[index] = model.TagResponse{Tag: tag.Name, Persons: tag.Persons}
+	}
+	return responses
+}
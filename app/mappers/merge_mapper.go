@@ -0,0 +1,63 @@
+package mappers
+
+import (
+	"encoding/json"
+	"person-service/db/entity"
+	"person-service/model"
+)
+
+func ToDuplicatesResponse(duplicates []entity.Duplicate) []model.DuplicateResponse {
+	responses := make([]model.DuplicateResponse, len(duplicates))
+	for index, duplicate := range duplicates {
+		responses[index] = model.DuplicateResponse{
+			Person:  ToPersonResponse(duplicate.Person),
+			Score:   duplicate.Score,
+			Reasons: duplicate.Reasons,
+		}
+	}
+	return responses
+}
+
+// ToMergeFields sources of merged fields by name, absent fields are not chosen.
+func ToMergeFields(fields model.MergeFields) map[string]string {
+	sources := make(map[string]string)
+	for name, source := range map[string]string{
+		"firstName":  fields.FirstName,
+		"lastName":   fields.LastName,
+		"birthDate":  fields.BirthDate,
+		"login":      fields.Login,
+		"attributes": fields.Attributes,
+	} {
+		if source != "" {
+			sources[name] = source
+		}
+	}
+	return sources
+}
+
+func ToMergeResponse(merge entity.PersonMerge) model.MergeResponse {
+	response := model.MergeResponse{
+		Id:         merge.Id,
+		SurvivorId: merge.SurvivorId,
+		MergedId:   merge.MergedId,
+		Fields: model.MergeFields{
+			FirstName:  merge.Fields["firstName"],
+			LastName:   merge.Fields["lastName"],
+			BirthDate:  merge.Fields["birthDate"],
+			Login:      merge.Fields["login"],
+			Attributes: merge.Fields["attributes"],
+		},
+		MergedAt: merge.MergedAt.UTC(),
+	}
+	/* snapshot is written by merge from person response, it is always valid */
+	_ = json.Unmarshal(merge.Merged, &response.Merged)
+	return response
+}
+
+func ToMergesResponse(merges []entity.PersonMerge) []model.MergeResponse {
+	responses := make([]model.MergeResponse, len(merges))
+	for index, merge := range merges {
+		responses[index] = ToMergeResponse(merge)
+	}
+	return responses
+}
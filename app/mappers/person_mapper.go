@@ -5,24 +5,56 @@ import (
 	"person-service/model"
 )
 
-func ToPerson(request model.PersonRequest) entity.Person {
+// ToPerson convert request to person, returns error when birth date is not valid.
+// Approximate birth date of response sent back is not taken as exact one, age gives it again.
+func ToPerson(request model.PersonRequest) (entity.Person, error) {
+	birthDate, err := parseDate("birthDate", request.BirthDate)
+	if err != nil {
+		return entity.Person{}, err
+	}
+	if request.BirthDateApproximate {
+		birthDate = nil
+	}
+
 	return entity.Person{
-		Id:        &request.Id,
-		FirstName: request.FirstName,
-		LastName:  request.LastName,
-		Age:       request.Age,
-		Timestamp: &request.Timestamp,
+		Id:         &request.Id,
+		FirstName:  request.FirstName,
+		LastName:   request.LastName,
+		Age:        request.Age,
+		BirthDate:  birthDate,
+		Login:      request.Login,
+		Attributes: request.Attributes,
+	}, nil
+}
+
+// ToPersonRequest convert person to request, approximate birth date is left out so it follows age.
+func ToPersonRequest(entity entity.Person) model.PersonRequest {
+	request := model.PersonRequest{
+		Id:         *entity.Id,
+		FirstName:  entity.FirstName,
+		LastName:   entity.LastName,
+		Age:        entity.Age,
+		Login:      entity.Login,
+		Attributes: entity.Attributes,
 	}
+	if !entity.BirthDateApproximate {
+		request.BirthDate = formatDate(entity.BirthDate)
+	}
+	return request
 }
 
 func ToPersonResponse(entity entity.Person) model.PersonResponse {
 	return model.PersonResponse{
-		Id:        *entity.Id,
-		FirstName: entity.FirstName,
-		LastName:  entity.LastName,
-		Age:       entity.Age,
-		Timestamp: *entity.Timestamp,
-		Login:     entity.Login,
+		Id:                   *entity.Id,
+		FirstName:            entity.FirstName,
+		LastName:             entity.LastName,
+		Age:                  entity.Age,
+		BirthDate:            formatDate(entity.BirthDate),
+		BirthDateApproximate: entity.BirthDateApproximate,
+		Login:                entity.Login,
+		Attributes:           toAttributes(entity.Attributes),
+		CreatedAt:            entity.CreatedAt.UTC(),
+		UpdatedAt:            entity.UpdatedAt.UTC(),
 	}
 }
 
@@ -33,3 +65,11 @@ func ToPersonsResponse(entities []entity.Person) []model.PersonResponse {
 	}
 	return persons
 }
+
+// toAttributes attributes of response, person without attributes gets empty object.
+func toAttributes(attributes map[string]any) model.Attributes {
+	if attributes == nil {
+		return model.Attributes{}
+	}
+	return attributes
+}
@@ -0,0 +1,45 @@
+package mappers
+
+import (
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"person-service/db/entity"
+	"person-service/model"
+	personv1 "person-service/proto/person/v1"
+)
+
+// FromPersonProto map grpc person to entity, output only timestamps are ignored.
+func FromPersonProto(id uuid.UUID, person *personv1.Person) entity.Person {
+	return entity.Person{
+		Id:        &id,
+		FirstName: person.GetFirstName(),
+		LastName:  person.GetLastName(),
+		Age:       int(person.GetAge()),
+		Login:     person.GetLogin(),
+	}
+}
+
+func ToPersonProto(entity entity.Person) *personv1.Person {
+	return &personv1.Person{
+		Id:         entity.Id.String(),
+		FirstName:  entity.FirstName,
+		LastName:   entity.LastName,
+		Age:        int32(entity.Age),
+		Login:      entity.Login,
+		CreateTime: timestamppb.New(entity.CreatedAt),
+		UpdateTime: timestamppb.New(entity.UpdatedAt),
+	}
+}
+
+// PersonResponseToProto map person payload of domain event.
+func PersonResponseToProto(response model.PersonResponse) *personv1.Person {
+	return &personv1.Person{
+		Id:         response.Id.String(),
+		FirstName:  response.FirstName,
+		LastName:   response.LastName,
+		Age:        int32(response.Age),
+		Login:      response.Login,
+		CreateTime: timestamppb.New(response.CreatedAt),
+		UpdateTime: timestamppb.New(response.UpdatedAt),
+	}
+}
@@ -0,0 +1,37 @@
+package mappers
+
+import (
+	"person-service/db/entity"
+	"person-service/model"
+)
+
+func ToRelationshipResponse(relationship entity.Relationship) model.RelationshipResponse {
+	return model.RelationshipResponse{
+		Id:        relationship.Id,
+		Type:      relationship.Type,
+		PersonId:  relationship.RelatedId,
+		Incoming:  relationship.Incoming,
+		CreatedAt: relationship.CreatedAt.UTC(),
+	}
+}
+
+func ToRelationshipsResponse(relationships []entity.Relationship) []model.RelationshipResponse {
+	responses := make([]model.RelationshipResponse, len(relationships))
+	for index, relationship := range relationships {
+		responses[index] = ToRelationshipResponse(relationship)
+	}
+	return responses
+}
+
+func ToHierarchyResponse(nodes []entity.HierarchyNode) []model.HierarchyNodeResponse {
+	responses := make([]model.HierarchyNodeResponse, len(nodes))
+	for index, node := range nodes {
+		responses[index] = model.HierarchyNodeResponse{
+			Person:    ToPersonResponse(node.Person),
+			Depth:     node.Depth,
+			Truncated: node.Truncated,
+			Nodes:     ToHierarchyResponse(node.Nodes),
+		}
+	}
+	return responses
+}
@@ -0,0 +1,39 @@
+package mappers
+
+import (
+	"person-service/db/entity"
+	"person-service/model"
+)
+
+func ToTenant(request model.TenantRequest) entity.Tenant {
+	return entity.Tenant{
+		Name:         request.Name,
+		MaxPersons:   request.MaxPersons,
+		MaxAge:       request.MaxAge,
+		LoginPattern: request.LoginPattern,
+		RequireLogin: request.RequireLogin,
+	}
+}
+
+func ToTenantResponse(tenant entity.Tenant) model.TenantResponse {
+	return model.TenantResponse{
+		Id:           tenant.Id,
+		Name:         tenant.Name,
+		Status:       tenant.Status,
+		MaxPersons:   tenant.MaxPersons,
+		MaxAge:       tenant.MaxAge,
+		LoginPattern: tenant.LoginPattern,
+		RequireLogin: tenant.RequireLogin,
+		Persons:      tenant.Persons,
+		CreatedAt:    tenant.CreatedAt.UTC(),
+		UpdatedAt:    tenant.UpdatedAt.UTC(),
+	}
+}
+
+func ToTenantsResponse(tenants []entity.Tenant) []model.TenantResponse {
+	responses := make([]model.TenantResponse, len(tenants))
+	for index, tenant := range tenants {
+		responses[index] = ToTenantResponse(tenant)
+	}
+	return responses
+}
@@ -0,0 +1,89 @@
+package mappers
+
+import (
+	"person-service/db/entity"
+	"person-service/model"
+)
+
+func ToWebhookSubscription(request model.WebhookRequest) entity.WebhookSubscription {
+	active := true
+	if request.Active != nil {
+		active = *request.Active
+	}
+
+	eventTypes := request.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+
+	return entity.WebhookSubscription{
+		Url:        request.Url,
+		EventTypes: eventTypes,
+		Secret:     request.Secret,
+		Active:     active,
+	}
+}
+
+func ToWebhookResponse(subscription entity.WebhookSubscription, withSecret bool) model.WebhookResponse {
+	response := model.WebhookResponse{
+		Id:         subscription.Id,
+		Url:        subscription.Url,
+		EventTypes: subscription.EventTypes,
+		Active:     subscription.Active,
+		CreatedAt:  subscription.CreatedAt.UTC(),
+		UpdatedAt:  subscription.UpdatedAt.UTC(),
+	}
+	if withSecret {
+		response.Secret = subscription.Secret
+	}
+	return response
+}
+
+func ToWebhooksResponse(subscriptions []entity.WebhookSubscription) []model.WebhookResponse {
+	webhooks := make([]model.WebhookResponse, len(subscriptions))
+	for index, subscription := range subscriptions {
+		webhooks[index] = ToWebhookResponse(subscription, false)
+	}
+	return webhooks
+}
+
+func ToWebhookDeliveriesResponse(
+	deliveries []entity.WebhookDelivery,
+	attempts map[int64][]entity.WebhookDeliveryAttempt,
+) []model.WebhookDeliveryResponse {
+	responses := make([]model.WebhookDeliveryResponse, len(deliveries))
+	for index, delivery := range deliveries {
+		response := model.WebhookDeliveryResponse{
+			Id:          delivery.Id,
+			EventId:     delivery.EventId,
+			EventType:   delivery.EventType,
+			Status:      delivery.Status,
+			Attempts:    delivery.Attempts,
+			CreatedAt:   delivery.CreatedAt.UTC(),
+			DeliveredAt: delivery.DeliveredAt,
+			Log:         make([]model.WebhookAttemptResponse, 0, len(attempts[delivery.Id])),
+		}
+		if delivery.Status == entity.DeliveryPending {
+			nextAttemptAt := delivery.NextAttemptAt.UTC()
+			response.NextAttemptAt = &nextAttemptAt
+		}
+		if delivery.LastError != nil {
+			response.LastError = *delivery.LastError
+		}
+
+		for _, attempt := range attempts[delivery.Id] {
+			log := model.WebhookAttemptResponse{
+				ResponseStatus: attempt.ResponseStatus,
+				DurationMs:     attempt.Duration.Milliseconds(),
+				AttemptedAt:    attempt.AttemptedAt.UTC(),
+			}
+			if attempt.Error != nil {
+				log.Error = *attempt.Error
+			}
+			response.Log = append(response.Log, log)
+		}
+
+		responses[index] = response
+	}
+	return responses
+}
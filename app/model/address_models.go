@@ -0,0 +1,39 @@
+package model
+
+import (
+	"encoding/xml"
+	"github.com/google/uuid"
+	"time"
+)
+
+// AddressRequest model info
+// @Description Model for create or update postal address of person, type is one of home, work, billing, shipping, other,
+// @Description country is ISO 3166-1 alpha-2 code, validFrom and validTo are dates (YYYY-MM-DD), absent means open end.
+type AddressRequest struct {
+	XMLName    xml.Name `json:"-" xml:"address" swaggerignore:"true"`
+	Type       string   `json:"type,omitempty" xml:"type,omitempty"`
+	Lines      []string `json:"lines" xml:"lines>line"`
+	City       string   `json:"city" xml:"city"`
+	Region     string   `json:"region,omitempty" xml:"region,omitempty"`
+	PostalCode string   `json:"postalCode,omitempty" xml:"postalCode,omitempty"`
+	Country    string   `json:"country" xml:"country"`
+	ValidFrom  string   `json:"validFrom,omitempty" xml:"validFrom,omitempty"`
+	ValidTo    string   `json:"validTo,omitempty" xml:"validTo,omitempty"`
+}
+
+// AddressResponse model info
+// @Description Model of postal address of person.
+type AddressResponse struct {
+	XMLName    xml.Name  `json:"-" xml:"address" swaggerignore:"true"`
+	Id         uuid.UUID `json:"id" xml:"id"`
+	Type       string    `json:"type" xml:"type"`
+	Lines      []string  `json:"lines" xml:"lines>line"`
+	City       string    `json:"city" xml:"city"`
+	Region     string    `json:"region,omitempty" xml:"region,omitempty"`
+	PostalCode string    `json:"postalCode,omitempty" xml:"postalCode,omitempty"`
+	Country    string    `json:"country" xml:"country"`
+	ValidFrom  string    `json:"validFrom,omitempty" xml:"validFrom,omitempty"`
+	ValidTo    string    `json:"validTo,omitempty" xml:"validTo,omitempty"`
+	CreatedAt  time.Time `json:"createdAt" xml:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt" xml:"updatedAt"`
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"encoding/xml"
+	"github.com/google/uuid"
+	"time"
+)
+
+// AttachmentRetentionRequest model info
+// @Description Model for change retention date of attachment, empty retainUntil keeps attachment until it is deleted.
+type AttachmentRetentionRequest struct {
+	XMLName     xml.Name `json:"-" xml:"retention" swaggerignore:"true"`
+	RetainUntil string   `json:"retainUntil" xml:"retainUntil" example:"2030-12-31"`
+}
+
+// AttachmentResponse model info
+// @Description Model of person attachment, content is downloaded from /content. Attachment is purged after retainUntil.
+type AttachmentResponse struct {
+	XMLName     xml.Name  `json:"-" xml:"attachment" swaggerignore:"true"`
+	Id          uuid.UUID `json:"id" xml:"id"`
+	FileName    string    `json:"fileName" xml:"fileName"`
+	ContentType string    `json:"contentType" xml:"contentType"`
+	Size        int64     `json:"size" xml:"size"`
+	Sha256      string    `json:"sha256" xml:"sha256"`
+	RetainUntil string    `json:"retainUntil,omitempty" xml:"retainUntil,omitempty" example:"2030-12-31"`
+	CreatedAt   time.Time `json:"createdAt" xml:"createdAt"`
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// AttributeDefinitionRequest model info
+// @Description Model for create or update definition of custom person attribute.
+type AttributeDefinitionRequest struct {
+	XMLName xml.Name `json:"-" xml:"attributeDefinition" swaggerignore:"true"`
+	/* name is taken from path on update */
+	Name       string   `json:"name" xml:"name"`
+	Type       string   `json:"type" xml:"type" enums:"string,int,bool,date,enum"`
+	Required   bool     `json:"required" xml:"required"`
+	Pattern    string   `json:"pattern,omitempty" xml:"pattern,omitempty"`
+	EnumValues []string `json:"enumValues,omitempty" xml:"enumValues>value,omitempty"`
+}
+
+// AttributeDefinitionResponse model info
+// @Description Model of custom person attribute definition.
+type AttributeDefinitionResponse struct {
+	XMLName    xml.Name  `json:"-" xml:"attributeDefinition" swaggerignore:"true"`
+	Name       string    `json:"name" xml:"name"`
+	Type       string    `json:"type" xml:"type"`
+	Required   bool      `json:"required" xml:"required"`
+	Pattern    string    `json:"pattern,omitempty" xml:"pattern,omitempty"`
+	EnumValues []string  `json:"enumValues,omitempty" xml:"enumValues>value,omitempty"`
+	CreatedAt  time.Time `json:"createdAt" xml:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt" xml:"updatedAt"`
+}
+
+// Attributes values of custom attributes by name, XML form is list of <attribute name="...">value</attribute>.
+type Attributes map[string]any
+
+type xmlAttribute struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:",chardata"`
+}
+
+func (a Attributes) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	names := make([]string, 0, len(a))
+	for name := range a {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	attributes := struct {
+		Items []xmlAttribute `xml:"attribute"`
+	}{}
+	for _, name := range names {
+		attributes.Items = append(attributes.Items, xmlAttribute{Name: name, Value: fmt.Sprint(a[name])})
+	}
+	return e.EncodeElement(attributes, start)
+}
+
+// UnmarshalXML values are read as strings, they are converted by attribute definitions.
+func (a *Attributes) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var attributes struct {
+		Items []xmlAttribute `xml:"attribute"`
+	}
+	if err := d.DecodeElement(&attributes, &start); err != nil {
+		return err
+	}
+
+	*a = make(Attributes, len(attributes.Items))
+	for _, item := range attributes.Items {
+		(*a)[item.Name] = item.Value
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// AvatarResponse model info
+// @Description Model of stored avatar, original fits max dimension and thumbnails are squares of sizes.
+type AvatarResponse struct {
+	XMLName     xml.Name  `json:"-" xml:"avatar" swaggerignore:"true"`
+	ContentType string    `json:"contentType" xml:"contentType"`
+	Width       int       `json:"width" xml:"width"`
+	Height      int       `json:"height" xml:"height"`
+	Sizes       []int     `json:"sizes" xml:"sizes>size"`
+	ETag        string    `json:"etag" xml:"etag"`
+	UpdatedAt   time.Time `json:"updatedAt" xml:"updatedAt"`
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"encoding/xml"
+	"github.com/google/uuid"
+	"time"
+)
+
+// EmailRequest model info
+// @Description Model for create or update email of person, type is one of personal, work, other.
+type EmailRequest struct {
+	XMLName  xml.Name `json:"-" xml:"email" swaggerignore:"true"`
+	Email    string   `json:"email" xml:"email"`
+	Type     string   `json:"type,omitempty" xml:"type,omitempty"`
+	Primary  bool     `json:"primary" xml:"primary"`
+	Verified bool     `json:"verified" xml:"verified"`
+}
+
+// EmailResponse model info
+// @Description Model of email of person.
+type EmailResponse struct {
+	XMLName   xml.Name  `json:"-" xml:"email" swaggerignore:"true"`
+	Id        uuid.UUID `json:"id" xml:"id"`
+	Email     string    `json:"email" xml:"email"`
+	Type      string    `json:"type" xml:"type"`
+	Primary   bool      `json:"primary" xml:"primary"`
+	Verified  bool      `json:"verified" xml:"verified"`
+	CreatedAt time.Time `json:"createdAt" xml:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt" xml:"updatedAt"`
+}
+
+// PhoneRequest model info
+// @Description Model for create or update phone of person, phone is normalized to E.164, type is one of mobile, home, work, fax, other.
+type PhoneRequest struct {
+	XMLName  xml.Name `json:"-" xml:"phone" swaggerignore:"true"`
+	Phone    string   `json:"phone" xml:"phone"`
+	Type     string   `json:"type,omitempty" xml:"type,omitempty"`
+	Primary  bool     `json:"primary" xml:"primary"`
+	Verified bool     `json:"verified" xml:"verified"`
+}
+
+// PhoneResponse model info
+// @Description Model of phone of person.
+type PhoneResponse struct {
+	XMLName   xml.Name  `json:"-" xml:"phone" swaggerignore:"true"`
+	Id        uuid.UUID `json:"id" xml:"id"`
+	Phone     string    `json:"phone" xml:"phone"`
+	Type      string    `json:"type" xml:"type"`
+	Primary   bool      `json:"primary" xml:"primary"`
+	Verified  bool      `json:"verified" xml:"verified"`
+	CreatedAt time.Time `json:"createdAt" xml:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt" xml:"updatedAt"`
+}
+
+// ContactsResponse model info
+// @Description Contacts of person embedded by expand=contacts.
+type ContactsResponse struct {
+	Emails []EmailResponse `json:"emails" xml:"emails>email"`
+	Phones []PhoneResponse `json:"phones" xml:"phones>phone"`
+}
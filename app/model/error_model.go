@@ -1,12 +1,16 @@
 package model
 
+import "encoding/xml"
+
 type ErrorResponse struct {
-	Status  string `json:"status"`
-	Message string `json:"message"`
+	XMLName xml.Name `json:"-" xml:"error" swaggerignore:"true"`
+	Status  string   `json:"status" xml:"status"`
+	Message string   `json:"message" xml:"message"`
 }
 
 const (
 	InternalError     = "500"
+	BadRequestError   = "400"
 	NotFoundError     = "404"
 	UnAuthorizedError = "401"
 )
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"encoding/xml"
+	"github.com/google/uuid"
+	"time"
+)
+
+// GroupRequest model info
+// @Description Model for create or update group of persons, group without parentId is root group.
+type GroupRequest struct {
+	XMLName     xml.Name   `json:"-" xml:"group" swaggerignore:"true"`
+	Name        string     `json:"name" xml:"name"`
+	ParentId    *uuid.UUID `json:"parentId,omitempty" xml:"parentId,omitempty"`
+	Description string     `json:"description,omitempty" xml:"description,omitempty"`
+}
+
+// GroupResponse model info
+// @Description Model of group of persons.
+type GroupResponse struct {
+	XMLName     xml.Name   `json:"-" xml:"group" swaggerignore:"true"`
+	Id          uuid.UUID  `json:"id" xml:"id"`
+	ParentId    *uuid.UUID `json:"parentId,omitempty" xml:"parentId,omitempty"`
+	Name        string     `json:"name" xml:"name"`
+	Description string     `json:"description,omitempty" xml:"description,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt" xml:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt" xml:"updatedAt"`
+}
+
+// MembersRequest model info
+// @Description Model for bulk add or remove of group members or tagged persons.
+type MembersRequest struct {
+	XMLName   xml.Name    `json:"-" xml:"members" swaggerignore:"true"`
+	PersonIds []uuid.UUID `json:"personIds" xml:"personIds>id"`
+}
+
+// MembersResponse model info
+// @Description Count of persons which were added or removed, persons which already were in requested state are not counted.
+type MembersResponse struct {
+	XMLName xml.Name `json:"-" xml:"members" swaggerignore:"true"`
+	Changed int64    `json:"changed" xml:"changed"`
+}
+
+// TagResponse model info
+// @Description Model of tag with count of tagged persons.
+type TagResponse struct {
+	XMLName xml.Name `json:"-" xml:"tag" swaggerignore:"true"`
+	Tag     string   `json:"tag" xml:"tag"`
+	Persons int      `json:"persons" xml:"persons"`
+}
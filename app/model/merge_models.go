@@ -0,0 +1,51 @@
+package model
+
+import (
+	"encoding/xml"
+	"github.com/google/uuid"
+	"time"
+)
+
+// DuplicateResponse model info
+// @Description Person which may duplicate person in path, score is between 0 and 1. Reasons: similar_name,
+// @Description swapped_name (first and last name are swapped), shared_email, shared_phone, same_birth_date.
+type DuplicateResponse struct {
+	XMLName xml.Name       `json:"-" xml:"duplicate" swaggerignore:"true"`
+	Person  PersonResponse `json:"person" xml:"person"`
+	Score   float64        `json:"score" xml:"score" example:"0.9"`
+	Reasons []string       `json:"reasons" xml:"reasons>reason"`
+}
+
+// MergeFields model info
+// @Description Source of merged fields: survivor (person in path) or duplicate, attributes may be merged from both
+// @Description (survivor wins on the same name). Absent fields keep survivor values except empty login and approximate
+// @Description birth date, attributes are merged from both.
+type MergeFields struct {
+	FirstName  string `json:"firstName,omitempty" xml:"firstName,omitempty" enums:"survivor,duplicate"`
+	LastName   string `json:"lastName,omitempty" xml:"lastName,omitempty" enums:"survivor,duplicate"`
+	BirthDate  string `json:"birthDate,omitempty" xml:"birthDate,omitempty" enums:"survivor,duplicate"`
+	Login      string `json:"login,omitempty" xml:"login,omitempty" enums:"survivor,duplicate"`
+	Attributes string `json:"attributes,omitempty" xml:"attributes,omitempty" enums:"survivor,duplicate,both"`
+}
+
+// MergeRequest model info
+// @Description Model for merge of duplicate person into person in path.
+type MergeRequest struct {
+	XMLName     xml.Name    `json:"-" xml:"merge" swaggerignore:"true"`
+	DuplicateId uuid.UUID   `json:"duplicateId" xml:"duplicateId"`
+	Fields      MergeFields `json:"fields" xml:"fields"`
+}
+
+// MergeResponse model info
+// @Description Merge of person into survivor, merged is merged person as it was before merge, its id redirects to
+// @Description survivor. Person is survivor after merge, it is present only in response of merge.
+type MergeResponse struct {
+	XMLName    xml.Name        `json:"-" xml:"merge" swaggerignore:"true"`
+	Id         uuid.UUID       `json:"id" xml:"id"`
+	SurvivorId uuid.UUID       `json:"survivorId" xml:"survivorId"`
+	MergedId   uuid.UUID       `json:"mergedId" xml:"mergedId"`
+	Fields     MergeFields     `json:"fields" xml:"fields"`
+	Merged     PersonResponse  `json:"merged" xml:"merged"`
+	Person     *PersonResponse `json:"person,omitempty" xml:"person,omitempty"`
+	MergedAt   time.Time       `json:"mergedAt" xml:"mergedAt"`
+}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"encoding/xml"
+	"github.com/google/uuid"
+)
+
+const (
+	BatchModeAtomic     = "atomic"
+	BatchModeBestEffort = "best-effort"
+)
+
+// PersonBatchRequest model info
+// @Description Batch of person operations, atomic mode (default) rolls back all operations on first failure.
+type PersonBatchRequest struct {
+	XMLName    xml.Name               `json:"-" xml:"batch" swaggerignore:"true"`
+	Mode       string                 `json:"mode,omitempty" xml:"mode,omitempty" enums:"atomic,best-effort"`
+	Operations []PersonBatchOperation `json:"operations" xml:"operations>operation"`
+}
+
+// PersonBatchOperation model info
+// @Description Single operation of batch, delete requires only id.
+type PersonBatchOperation struct {
+	Op     string         `json:"op" xml:"op" enums:"create,upsert,delete"`
+	Id     uuid.UUID      `json:"id,omitempty" xml:"id,omitempty"`
+	Person *PersonRequest `json:"person,omitempty" xml:"person,omitempty"`
+}
+
+// PersonBatchResponse model info
+// @Description Results of batch operations in request order.
+type PersonBatchResponse struct {
+	XMLName   xml.Name            `json:"-" xml:"batch" swaggerignore:"true"`
+	Mode      string              `json:"mode" xml:"mode"`
+	Committed bool                `json:"committed" xml:"committed"`
+	Results   []PersonBatchResult `json:"results" xml:"results>result"`
+}
+
+// PersonBatchResult model info
+// @Description Result of single batch operation, status is http status of the same single request.
+type PersonBatchResult struct {
+	Index  int             `json:"index" xml:"index"`
+	Op     string          `json:"op" xml:"op"`
+	Status int             `json:"status" xml:"status"`
+	Id     *uuid.UUID      `json:"id,omitempty" xml:"id,omitempty"`
+	Person *PersonResponse `json:"person,omitempty" xml:"person,omitempty"`
+	Error  string          `json:"error,omitempty" xml:"error,omitempty"`
+}
+
+// PersonImportResponse model info
+// @Description Result of import, in dry run counters show what would be imported.
+type PersonImportResponse struct {
+	XMLName  xml.Name            `json:"-" xml:"import" swaggerignore:"true"`
+	DryRun   bool                `json:"dryRun" xml:"dryRun"`
+	Total    int                 `json:"total" xml:"total"`
+	Created  int                 `json:"created" xml:"created"`
+	Updated  int                 `json:"updated" xml:"updated"`
+	Rejected []PersonImportError `json:"rejected" xml:"rejected>row"`
+}
+
+// PersonImportError model info
+// @Description Rejected row of import file.
+type PersonImportError struct {
+	Line   int    `json:"line" xml:"line"`
+	Reason string `json:"reason" xml:"reason"`
+	Row    string `json:"row" xml:"row"`
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"encoding/xml"
 	"fmt"
 	"github.com/google/uuid"
 	"time"
@@ -9,28 +10,50 @@ import (
 // PersonRequest model info
 // @Description Model for create or update person entity.
 type PersonRequest struct {
-	Id        uuid.UUID `json:"id,omitempty"`
-	FirstName string    `json:"firstName"`
-	LastName  string    `json:"lastName"`
-	Age       int       `json:"age"`
-	Timestamp time.Time `json:"timestamp,omitempty"`
+	XMLName   xml.Name  `json:"-" xml:"person" swaggerignore:"true"`
+	Id        uuid.UUID `json:"id,omitempty" xml:"id,omitempty"`
+	FirstName string    `json:"firstName" xml:"firstName"`
+	LastName  string    `json:"lastName" xml:"lastName"`
+	/* ignored when birthDate is sent, age alone gives approximate birth date */
+	Age int `json:"age" xml:"age"`
+	/* YYYY-MM-DD */
+	BirthDate string `json:"birthDate,omitempty" xml:"birthDate,omitempty"`
+	/* birth date echoed from response with approximate flag is ignored, so it stays approximate */
+	BirthDateApproximate bool   `json:"birthDateApproximate,omitempty" xml:"birthDateApproximate,omitempty"`
+	Login                string `json:"login" xml:"login"`
+	/* values of attributes defined in registry, absent attributes keep stored ones on update */
+	Attributes Attributes `json:"attributes,omitempty" xml:"attributes,omitempty" swaggertype:"object"`
+	/* timestamps are managed by database, request with timestamp is rejected */
+	Timestamp *time.Time `json:"timestamp,omitempty" xml:"timestamp,omitempty" swaggerignore:"true"`
 }
 
 // PersonResponse model info
 // @Description Model for response on API operations.
 type PersonResponse struct {
-	Id        uuid.UUID `json:"id"`
-	FirstName string    `json:"firstName"`
-	LastName  string    `json:"lastName"`
-	Age       int       `json:"age"`
-	Timestamp time.Time `json:"timestamp"`
-	Login     string    `json:"login"`
+	XMLName   xml.Name  `json:"-" xml:"person" swaggerignore:"true"`
+	Id        uuid.UUID `json:"id" xml:"id"`
+	FirstName string    `json:"firstName" xml:"firstName"`
+	LastName  string    `json:"lastName" xml:"lastName"`
+	/* computed from birth date for today */
+	Age int `json:"age" xml:"age"`
+	/* YYYY-MM-DD, approximate one was derived from age */
+	BirthDate            string     `json:"birthDate" xml:"birthDate"`
+	BirthDateApproximate bool       `json:"birthDateApproximate" xml:"birthDateApproximate"`
+	Login                string     `json:"login" xml:"login"`
+	Attributes           Attributes `json:"attributes" xml:"attributes" swaggertype:"object"`
+	CreatedAt            time.Time  `json:"createdAt" xml:"createdAt"`
+	UpdatedAt            time.Time  `json:"updatedAt" xml:"updatedAt"`
+	/* present only when requested by expand=contacts */
+	Contacts *ContactsResponse `json:"contacts,omitempty" xml:"contacts,omitempty"`
+	/* present when requested by expand=addresses and person has addresses, always present in events of address changes */
+	Addresses []AddressResponse `json:"addresses,omitempty" xml:"addresses>address,omitempty"`
 }
 
 // PersonDeleteResponse model info
 // @Description Model for response on delete operation.
 type PersonDeleteResponse struct {
-	Message string `json:"message"`
+	XMLName xml.Name `json:"-" xml:"result" swaggerignore:"true"`
+	Message string   `json:"message" xml:"message"`
 }
 
 func CreateSuccessDeleteResponse(id string) PersonDeleteResponse {
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"encoding/xml"
+	"github.com/google/uuid"
+	"time"
+)
+
+// RelationshipRequest model info
+// @Description Model for create relationship, person of personId is <type> of person in path (manager, report, parent,
+// @Description child, spouse, sibling, emergency_contact).
+type RelationshipRequest struct {
+	XMLName  xml.Name  `json:"-" xml:"relationship" swaggerignore:"true"`
+	Type     string    `json:"type" xml:"type"`
+	PersonId uuid.UUID `json:"personId" xml:"personId"`
+}
+
+// RelationshipResponse model info
+// @Description Model of relationship seen from person in path, person of personId is <type> of it. Incoming relationship
+// @Description was created from the other person, its type is inverse type when there is one.
+type RelationshipResponse struct {
+	XMLName   xml.Name  `json:"-" xml:"relationship" swaggerignore:"true"`
+	Id        uuid.UUID `json:"id" xml:"id"`
+	Type      string    `json:"type" xml:"type"`
+	PersonId  uuid.UUID `json:"personId" xml:"personId"`
+	Incoming  bool      `json:"incoming,omitempty" xml:"incoming,omitempty"`
+	CreatedAt time.Time `json:"createdAt" xml:"createdAt"`
+}
+
+// HierarchyNodeResponse model info
+// @Description Person of hierarchy at depth from requested person, nodes are the next level.
+// @Description Truncated node has more levels below depth limit.
+type HierarchyNodeResponse struct {
+	XMLName   xml.Name                `json:"-" xml:"node" swaggerignore:"true"`
+	Person    PersonResponse          `json:"person" xml:"person"`
+	Depth     int                     `json:"depth" xml:"depth"`
+	Truncated bool                    `json:"truncated,omitempty" xml:"truncated,omitempty"`
+	Nodes     []HierarchyNodeResponse `json:"nodes,omitempty" xml:"nodes>node,omitempty"`
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"encoding/xml"
+	"github.com/google/uuid"
+	"time"
+)
+
+// TenantRequest model info
+// @Description Model for create or update tenant, zero and empty settings keep defaults of person validation.
+type TenantRequest struct {
+	XMLName      xml.Name `json:"-" xml:"tenant" swaggerignore:"true"`
+	Name         string   `json:"name" xml:"name"`
+	MaxPersons   int      `json:"maxPersons" xml:"maxPersons"`
+	MaxAge       int      `json:"maxAge" xml:"maxAge"`
+	LoginPattern string   `json:"loginPattern,omitempty" xml:"loginPattern,omitempty"`
+	RequireLogin bool     `json:"requireLogin" xml:"requireLogin"`
+}
+
+// TenantResponse model info
+// @Description Model of tenant with count of its persons.
+type TenantResponse struct {
+	XMLName      xml.Name  `json:"-" xml:"tenant" swaggerignore:"true"`
+	Id           uuid.UUID `json:"id" xml:"id"`
+	Name         string    `json:"name" xml:"name"`
+	Status       string    `json:"status" xml:"status" enums:"active,suspended"`
+	MaxPersons   int       `json:"maxPersons" xml:"maxPersons"`
+	MaxAge       int       `json:"maxAge" xml:"maxAge"`
+	LoginPattern string    `json:"loginPattern,omitempty" xml:"loginPattern,omitempty"`
+	RequireLogin bool      `json:"requireLogin" xml:"requireLogin"`
+	Persons      int       `json:"persons" xml:"persons"`
+	CreatedAt    time.Time `json:"createdAt" xml:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt" xml:"updatedAt"`
+}
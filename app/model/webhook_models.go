@@ -0,0 +1,55 @@
+package model
+
+import (
+	"encoding/xml"
+	"github.com/google/uuid"
+	"time"
+)
+
+// WebhookRequest model info
+// @Description Model for create or update webhook subscription, empty eventTypes means all events.
+type WebhookRequest struct {
+	XMLName    xml.Name `json:"-" xml:"webhook" swaggerignore:"true"`
+	Url        string   `json:"url" xml:"url"`
+	EventTypes []string `json:"eventTypes" xml:"eventTypes>eventType"`
+	Secret     string   `json:"secret,omitempty" xml:"secret,omitempty"`
+	Active     *bool    `json:"active,omitempty" xml:"active,omitempty"`
+}
+
+// WebhookResponse model info
+// @Description Model of webhook subscription, secret is returned only when it was created or changed.
+type WebhookResponse struct {
+	XMLName    xml.Name  `json:"-" xml:"webhook" swaggerignore:"true"`
+	Id         uuid.UUID `json:"id" xml:"id"`
+	Url        string    `json:"url" xml:"url"`
+	EventTypes []string  `json:"eventTypes" xml:"eventTypes>eventType"`
+	Active     bool      `json:"active" xml:"active"`
+	Secret     string    `json:"secret,omitempty" xml:"secret,omitempty"`
+	CreatedAt  time.Time `json:"createdAt" xml:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt" xml:"updatedAt"`
+}
+
+// WebhookDeliveryResponse model info
+// @Description Model of webhook delivery with log of attempts.
+type WebhookDeliveryResponse struct {
+	XMLName       xml.Name                 `json:"-" xml:"delivery" swaggerignore:"true"`
+	Id            int64                    `json:"id" xml:"id"`
+	EventId       int64                    `json:"eventId" xml:"eventId"`
+	EventType     string                   `json:"eventType" xml:"eventType"`
+	Status        string                   `json:"status" xml:"status"`
+	Attempts      int                      `json:"attempts" xml:"attempts"`
+	NextAttemptAt *time.Time               `json:"nextAttemptAt,omitempty" xml:"nextAttemptAt,omitempty"`
+	LastError     string                   `json:"lastError,omitempty" xml:"lastError,omitempty"`
+	CreatedAt     time.Time                `json:"createdAt" xml:"createdAt"`
+	DeliveredAt   *time.Time               `json:"deliveredAt,omitempty" xml:"deliveredAt,omitempty"`
+	Log           []WebhookAttemptResponse `json:"log" xml:"log>attempt"`
+}
+
+// WebhookAttemptResponse model info
+// @Description Model of single delivery attempt.
+type WebhookAttemptResponse struct {
+	ResponseStatus *int      `json:"responseStatus,omitempty" xml:"responseStatus,omitempty"`
+	Error          string    `json:"error,omitempty" xml:"error,omitempty"`
+	DurationMs     int64     `json:"durationMs" xml:"durationMs"`
+	AttemptedAt    time.Time `json:"attemptedAt" xml:"attemptedAt"`
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"person-service/config"
+	"person-service/db/entity"
+	"person-service/events"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingPublisher keeps published events, fails while failures > 0, optional before is called ahead of publishing.
+type recordingPublisher struct {
+	mu       sync.Mutex
+	failures int
+	events   []events.Event
+	before   func()
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, event events.Event) error {
+	if p.before != nil {
+		p.before()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.failures > 0 {
+		p.failures--
+		return errors.New("publisher unavailable")
+	}
+	p.events = append(p.events, event)
+	return nil
+}
+
+func (p *recordingPublisher) typesOf(personId uuid.UUID) []events.Type {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var types []events.Type
+	for _, event := range p.events {
+		if event.PersonId == personId {
+			types = append(types, event.Type)
+		}
+	}
+	return types
+}
+
+func Test_OutboxRelay(t *testing.T) {
+	outbox := config.Outbox{BatchSize: 100, RetryBackoff: time.Millisecond, MaxBackoff: time.Millisecond, Lease: time.Minute}
+
+	t.Run("must publish person lifecycle events in order", func(t *testing.T) {
+		publisher := &recordingPublisher{}
+		relay := events.NewRelay(logger, transactions, publisher, outbox)
+
+		created, err := personService.CreatePerson(ctx, entity.Person{FirstName: "Анна", LastName: "Смирнова", Age: 25})
+		assert.NoError(t, err)
+		created.LastName = "Кузнецова"
+		_, err = personService.UpdatePerson(ctx, created)
+		assert.NoError(t, err)
+		_, err = personService.DeletePerson(ctx, *created.Id)
+		assert.NoError(t, err)
+
+		drain(t, relay)
+
+		assert.Equal(t,
+			[]events.Type{events.PersonCreated, events.PersonUpdated, events.PersonDeleted},
+			publisher.typesOf(*created.Id),
+		)
+	})
+
+	t.Run("must redeliver event after publisher failure", func(t *testing.T) {
+		publisher := &recordingPublisher{}
+		relay := events.NewRelay(logger, transactions, publisher, outbox)
+		drain(t, relay)
+
+		created, err := personService.CreatePerson(ctx, entity.Person{FirstName: "Олег", LastName: "Морозов", Age: 40})
+		assert.NoError(t, err)
+		_, err = personService.UpdatePerson(ctx, created)
+		assert.NoError(t, err)
+
+		publisher.failures = 1
+		_, err = relay.ProcessBatch(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, publisher.typesOf(*created.Id))
+
+		time.Sleep(10 * time.Millisecond)
+		drain(t, relay)
+
+		assert.Equal(t, []events.Type{events.PersonCreated, events.PersonUpdated}, publisher.typesOf(*created.Id))
+	})
+
+	t.Run("must not publish event claimed by other relay", func(t *testing.T) {
+		other := events.NewRelay(logger, transactions, &recordingPublisher{}, outbox)
+		drain(t, other)
+
+		var once sync.Once
+		var claimed int
+		publisher := &recordingPublisher{}
+		publisher.before = func() {
+			/* claim of the first batch is committed, other relay skips leased event */
+			once.Do(func() { claimed, _ = other.ProcessBatch(ctx) })
+		}
+		relay := events.NewRelay(logger, transactions, publisher, outbox)
+
+		created, err := personService.CreatePerson(ctx, entity.Person{FirstName: "Инна", LastName: "Захарова", Age: 36})
+		assert.NoError(t, err)
+		drain(t, relay)
+
+		assert.Equal(t, 0, claimed)
+		assert.Equal(t, []events.Type{events.PersonCreated}, publisher.typesOf(*created.Id))
+	})
+
+	t.Run("must not write event when person does not exist", func(t *testing.T) {
+		publisher := &recordingPublisher{}
+		relay := events.NewRelay(logger, transactions, publisher, outbox)
+		id := uuid.New()
+
+		_, err := personService.DeletePerson(ctx, id)
+		assert.NoError(t, err)
+		drain(t, relay)
+
+		assert.Empty(t, publisher.typesOf(id))
+	})
+}
+
+func drain(t *testing.T, relay *events.Relay) {
+	for {
+		processed, err := relay.ProcessBatch(ctx)
+		assert.NoError(t, err)
+		if err != nil || processed == 0 {
+			return
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"person-service/model"
+	"testing"
+)
+
+func Test_PersonAddressesApi(t *testing.T) {
+	t.Run("must validate address and touch person", func(t *testing.T) {
+		created := createPersonV2(t, `{"firstName": "Марк", "lastName": "Адресов", "age": 38}`)
+		addressesUrl := fmt.Sprintf("http://localhost:9902/api/v2/persons/%s/addresses", created.Id)
+
+		resp, body := sendJson(t, http.MethodPost, addressesUrl,
+			`{"type": "Shipping", "lines": ["Unter den Linden 77"], "city": "Berlin", "postalCode": "10117", "country": "de", "validFrom": "2024-01-01"}`)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		var address model.AddressResponse
+		assert.NoError(t, json.Unmarshal(body, &address))
+		assert.Equal(t, "DE", address.Country)
+		assert.Equal(t, "shipping", address.Type)
+		assert.Equal(t, "2024-01-01", address.ValidFrom)
+
+		resp, err := http.Get(fmt.Sprintf("http://localhost:9902/api/v2/persons/%s?expand=addresses", created.Id))
+		person := parseResponse(err, resp, t)
+		assert.True(t, person.UpdatedAt.After(created.UpdatedAt))
+		assert.Equal(t, []model.AddressResponse{address}, person.Addresses)
+
+		resp, _ = sendJson(t, http.MethodPost, addressesUrl, `{"lines": ["Unter den Linden 77"], "city": "Berlin", "postalCode": "1011", "country": "DE"}`)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		resp, _ = sendJson(t, http.MethodPost, addressesUrl, `{"lines": ["Unter den Linden 77"], "city": "Berlin", "postalCode": "10117", "country": "DE", "validTo": "01.01.2024"}`)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("must filter persons by country and city", func(t *testing.T) {
+		lisbon := createPersonV2(t, `{"firstName": "Ана", "lastName": "Силва", "age": 40}`)
+		porto := createPersonV2(t, `{"firstName": "Жуан", "lastName": "Силва", "age": 41}`)
+		sendJson(t, http.MethodPost, fmt.Sprintf("http://localhost:9902/api/v2/persons/%s/addresses", lisbon.Id),
+			`{"lines": ["Rua Augusta 1"], "city": "Lisboa", "postalCode": "1100-048", "country": "PT"}`)
+		sendJson(t, http.MethodPost, fmt.Sprintf("http://localhost:9902/api/v2/persons/%s/addresses", porto.Id),
+			`{"lines": ["Rua de Santa Catarina 1"], "city": "Porto", "postalCode": "4000-447", "country": "PT"}`)
+
+		resp, err := http.Get("http://localhost:9902/api/v2/persons?country=pt&city=LISBOA")
+		var persons []model.PersonResponse
+		assert.NoError(t, json.Unmarshal(parseResponseBytes(err, t, resp), &persons))
+		assert.Len(t, persons, 1)
+		assert.Equal(t, lisbon.Id, persons[0].Id)
+	})
+
+	t.Run("must delete address", func(t *testing.T) {
+		created := createPersonV2(t, `{"firstName": "Ким", "lastName": "Адресов", "age": 39}`)
+		_, body := sendJson(t, http.MethodPost, fmt.Sprintf("http://localhost:9902/api/v2/persons/%s/addresses", created.Id),
+			`{"lines": ["Sheikh Zayed Road 1"], "city": "Dubai", "country": "AE"}`)
+		var address model.AddressResponse
+		assert.NoError(t, json.Unmarshal(body, &address))
+
+		addressUrl := fmt.Sprintf("http://localhost:9902/api/v2/persons/%s/addresses/%s", created.Id, address.Id)
+		req, _ := http.NewRequest(http.MethodDelete, addressUrl, nil)
+		resp, err := http.DefaultClient.Do(req)
+		parseResponseBytes(err, t, resp)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		resp, err = http.DefaultClient.Do(req)
+		parseResponseBytes(err, t, resp)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
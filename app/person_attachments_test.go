@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"mime/multipart"
+	"net/http"
+	"person-service/blob"
+	"person-service/model"
+	"strings"
+	"testing"
+)
+
+func Test_PersonAttachments(t *testing.T) {
+	person := createPersonV2(t, `{"firstName": "Ада", "lastName": "Документова", "age": 40}`)
+	url := fmt.Sprintf("http://localhost:9902/api/v2/persons/%s/attachments", person.Id)
+	contract := []byte("%PDF-1.7 employment contract")
+	checksum := sha256.Sum256(contract)
+
+	var uploaded model.AttachmentResponse
+	t.Run("must upload raw content with verified checksum", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, url+"?fileName=contract.pdf&retainUntil=2099-12-31", bytes.NewReader(contract))
+		req.Header.Set("Content-Type", "application/octet-stream")
+		req.Header.Set("X-Checksum-Sha256", hex.EncodeToString(checksum[:]))
+		resp, err := http.DefaultClient.Do(req)
+		body := parseResponseBytes(err, t, resp)
+
+		assert.Equal(t, http.StatusCreated, resp.StatusCode, string(body))
+		assert.NoError(t, json.Unmarshal(body, &uploaded))
+		assert.Equal(t, "contract.pdf", uploaded.FileName)
+		assert.Equal(t, "application/pdf", uploaded.ContentType)
+		assert.Equal(t, int64(len(contract)), uploaded.Size)
+		assert.Equal(t, hex.EncodeToString(checksum[:]), uploaded.Sha256)
+		assert.Equal(t, "2099-12-31", uploaded.RetainUntil)
+	})
+
+	t.Run("must reject checksum mismatch", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, url+"?fileName=contract.pdf", bytes.NewReader(contract))
+		req.Header.Set("X-Checksum-Sha256", strings.Repeat("0", 64))
+		resp, err := http.DefaultClient.Do(req)
+		parseResponseBytes(err, t, resp)
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("must upload multipart content and list attachments", func(t *testing.T) {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		file, _ := form.CreateFormFile("file", "паспорт.txt")
+		_, _ = file.Write([]byte("passport scan"))
+		assert.NoError(t, form.Close())
+
+		resp, err := http.Post(url, form.FormDataContentType(), &body)
+		parseResponseBytes(err, t, resp)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		resp, err = http.Get(url)
+		var attachments []model.AttachmentResponse
+		assert.NoError(t, json.Unmarshal(parseResponseBytes(err, t, resp), &attachments))
+		assert.Len(t, attachments, 2)
+		assert.Equal(t, "паспорт.txt", attachments[1].FileName)
+	})
+
+	t.Run("must download content with checksum headers", func(t *testing.T) {
+		resp, err := http.Get(fmt.Sprintf("%s/%s/content", url, uploaded.Id))
+		body := parseResponseBytes(err, t, resp)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, contract, body)
+		assert.Equal(t, uploaded.Sha256, resp.Header.Get("X-Checksum-Sha256"))
+		assert.Equal(t, `attachment; filename=contract.pdf`, resp.Header.Get("Content-Disposition"))
+
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/%s/content", url, uploaded.Id), nil)
+		req.Header.Set("If-None-Match", resp.Header.Get("ETag"))
+		resp, err = http.DefaultClient.Do(req)
+		parseResponseBytes(err, t, resp)
+		assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+	})
+
+	t.Run("must change retention date", func(t *testing.T) {
+		resp, body := sendJson(t, http.MethodPut, fmt.Sprintf("%s/%s/retention", url, uploaded.Id), `{"retainUntil": ""}`)
+		assert.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+		var updated model.AttachmentResponse
+		assert.NoError(t, json.Unmarshal(body, &updated))
+		assert.Empty(t, updated.RetainUntil)
+
+		resp, _ = sendJson(t, http.MethodPut, fmt.Sprintf("%s/%s/retention", url, uploaded.Id), `{"retainUntil": "2000-01-01"}`)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("must purge files on person deletion", func(t *testing.T) {
+		attachments, err := attachmentService.LoadAttachments(context.Background(), person.Id)
+		assert.NoError(t, err)
+
+		req, _ := http.NewRequest(http.MethodDelete, fmt.Sprintf("http://localhost:9902/api/v2/persons/%s", person.Id), nil)
+		resp, err := http.DefaultClient.Do(req)
+		parseResponseBytes(err, t, resp)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		_, purged, err := attachmentService.PurgeAttachments(context.Background())
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, purged, len(attachments))
+		for _, attachment := range attachments {
+			_, err := blobStore.Get(context.Background(), attachment.BlobKey)
+			assert.ErrorIs(t, err, blob.ErrNotFound)
+		}
+	})
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"person-service/model"
+	"testing"
+)
+
+func Test_PersonAttributes(t *testing.T) {
+	resp, body := sendJson(t, http.MethodPost, "http://localhost:9902/api/v2/admin/attributes",
+		`{"name": "department", "type": "enum", "enumValues": ["sales", "support"]}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode, string(body))
+	resp, body = sendJson(t, http.MethodPost, "http://localhost:9902/api/v2/admin/attributes", `{"name": "badge", "type": "int"}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode, string(body))
+
+	t.Run("must reject duplicate and invalid definitions", func(t *testing.T) {
+		resp, _ := sendJson(t, http.MethodPost, "http://localhost:9902/api/v2/admin/attributes", `{"name": "badge", "type": "int"}`)
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+		resp, _ = sendJson(t, http.MethodPost, "http://localhost:9902/api/v2/admin/attributes", `{"name": "level", "type": "enum"}`)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("must store validated attributes", func(t *testing.T) {
+		created := createPersonV2(t, `{"firstName": "Ника", "lastName": "Отделова", "age": 28, "attributes": {"department": "sales", "badge": 17}}`)
+		assert.Equal(t, "sales", created.Attributes["department"])
+		assert.Equal(t, float64(17), created.Attributes["badge"])
+
+		resp, _ := sendJson(t, http.MethodPost, "http://localhost:9902/api/v2/persons",
+			`{"firstName": "Ника", "lastName": "Отделова", "age": 28, "attributes": {"department": "marketing"}}`)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp, _ = sendJson(t, http.MethodPost, "http://localhost:9902/api/v2/persons",
+			`{"firstName": "Ника", "lastName": "Отделова", "age": 28, "attributes": {"unknown": "value"}}`)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("must filter persons by attribute", func(t *testing.T) {
+		support := createPersonV2(t, `{"firstName": "Ия", "lastName": "Отделова", "age": 31, "attributes": {"department": "support", "badge": 9001}}`)
+
+		resp, err := http.Get("http://localhost:9902/api/v2/persons?attr.department=support&attr.badge=9001")
+		var persons []model.PersonResponse
+		assert.NoError(t, json.Unmarshal(parseResponseBytes(err, t, resp), &persons))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Len(t, persons, 1)
+		assert.Equal(t, support.Id, persons[0].Id)
+
+		resp, err = http.Get("http://localhost:9902/api/v2/persons?attr.badge=many")
+		parseResponseBytes(err, t, resp)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("must remove values of deleted definition", func(t *testing.T) {
+		created := createPersonV2(t, `{"firstName": "Ада", "lastName": "Отделова", "age": 40, "attributes": {"badge": 5}}`)
+
+		req, _ := http.NewRequest(http.MethodDelete, "http://localhost:9902/api/v2/admin/attributes/badge", nil)
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		resp, err = http.Get(fmt.Sprintf("http://localhost:9902/api/v2/persons/%s", created.Id))
+		found := parseResponse(err, resp, t)
+		assert.Empty(t, found.Attributes)
+	})
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"person-service/model"
+	"testing"
+)
+
+func Test_PersonAvatar(t *testing.T) {
+	person := createPersonV2(t, `{"firstName": "Ася", "lastName": "Аватарова", "age": 31}`)
+	url := fmt.Sprintf("http://localhost:9902/api/v2/persons/%s/avatar", person.Id)
+
+	t.Run("must upload multipart avatar and make thumbnails", func(t *testing.T) {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		file, _ := form.CreateFormFile("file", "avatar.bin")
+		_, _ = file.Write(avatarImage(t, 1200, 600))
+		assert.NoError(t, form.Close())
+
+		resp, data := sendAvatar(t, url, form.FormDataContentType(), body.Bytes())
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode, string(data))
+		var avatar model.AvatarResponse
+		assert.NoError(t, json.Unmarshal(data, &avatar))
+		assert.Equal(t, "image/png", avatar.ContentType)
+		assert.Equal(t, 1024, avatar.Width)
+		assert.Equal(t, 512, avatar.Height)
+		assert.Equal(t, []int{64, 128, 256}, avatar.Sizes)
+	})
+
+	t.Run("must serve thumbnail with cache headers and revalidate by etag", func(t *testing.T) {
+		resp, err := http.Get(url + "?size=64")
+		data := parseResponseBytes(err, t, resp)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+		assert.Equal(t, "private, max-age=3600", resp.Header.Get("Cache-Control"))
+		assert.NotEmpty(t, resp.Header.Get("Last-Modified"))
+		config, err := png.DecodeConfig(bytes.NewReader(data))
+		assert.NoError(t, err)
+		assert.Equal(t, 64, config.Width)
+		assert.Equal(t, 64, config.Height)
+
+		req, _ := http.NewRequest(http.MethodGet, url+"?size=64", nil)
+		req.Header.Set("If-None-Match", resp.Header.Get("ETag"))
+		revalidated, err := http.DefaultClient.Do(req)
+		parseResponseBytes(err, t, revalidated)
+		assert.Equal(t, http.StatusNotModified, revalidated.StatusCode)
+
+		resp, err = http.Get(url + "?size=100")
+		parseResponseBytes(err, t, resp)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("must replace avatar by raw upload", func(t *testing.T) {
+		before, err := http.Get(url)
+		parseResponseBytes(err, t, before)
+
+		resp, data := sendAvatar(t, url, "application/octet-stream", avatarImage(t, 300, 300))
+		assert.Equal(t, http.StatusOK, resp.StatusCode, string(data))
+
+		after, err := http.Get(url)
+		parseResponseBytes(err, t, after)
+		assert.NotEqual(t, before.Header.Get("ETag"), after.Header.Get("ETag"))
+	})
+
+	t.Run("must reject content which is not image and too large upload", func(t *testing.T) {
+		resp, _ := sendAvatar(t, url, "image/png", []byte("<svg xmlns='http://www.w3.org/2000/svg'/>"))
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp, _ = sendAvatar(t, url, "image/png", make([]byte, 2<<20))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	})
+
+	t.Run("must delete avatar", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodDelete, url, nil)
+		resp, err := http.DefaultClient.Do(req)
+		parseResponseBytes(err, t, resp)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		resp, err = http.Get(fmt.Sprintf("http://localhost:9902/api/v1/person/%s/avatar", person.Id))
+		parseResponseBytes(err, t, resp)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func sendAvatar(t *testing.T, url string, contentType string, body []byte) (*http.Response, []byte) {
+	req, _ := http.NewRequest(http.MethodPut, url, bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	return resp, data
+}
+
+func avatarImage(t *testing.T, width int, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: uint8(width), A: 0xff})
+		}
+	}
+
+	var buffer bytes.Buffer
+	assert.NoError(t, png.Encode(&buffer, img))
+	return buffer.Bytes()
+}
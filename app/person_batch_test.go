@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"person-service/model"
+	"testing"
+)
+
+func Test_PersonBatch(t *testing.T) {
+	t.Run("must execute operations in order and return per-item results", func(t *testing.T) {
+		existing := createPersonV2(t, `{"firstName": "Софья", "lastName": "Волкова", "age": 26}`)
+
+		resp, result := postBatch(t, fmt.Sprintf(`{"operations": [
+			{"op": "create", "person": {"firstName": "Глеб", "lastName": "Новиков", "age": 20}},
+			{"op": "create", "person": {"firstName": "Ирина", "lastName": "Новикова", "age": 21}},
+			{"op": "upsert", "id": "%s", "person": {"firstName": "Софья", "lastName": "Волкова", "age": 27}},
+			{"op": "delete", "id": "%s"}
+		]}`, existing.Id, existing.Id))
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.True(t, result.Committed)
+		assert.Len(t, result.Results, 4)
+		assert.Equal(t, http.StatusCreated, result.Results[0].Status)
+		assert.Equal(t, "Глеб", result.Results[0].Person.FirstName)
+		assert.Equal(t, http.StatusCreated, result.Results[1].Status)
+		assert.Equal(t, http.StatusOK, result.Results[2].Status)
+		assert.Equal(t, 27, result.Results[2].Person.Age)
+		assert.Equal(t, http.StatusNoContent, result.Results[3].Status)
+
+		resp, err := http.Get(fmt.Sprintf("http://localhost:9902/api/v2/persons/%s", existing.Id))
+		parseResponseBytes(err, t, resp)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("must roll back atomic batch on failed operation", func(t *testing.T) {
+		createPersonV2(t, `{"firstName": "Юрий", "lastName": "Титов", "age": 44, "login": "y.titov"}`)
+
+		resp, result := postBatch(t, `{"mode": "atomic", "operations": [
+			{"op": "create", "person": {"firstName": "Денис", "lastName": "Фомин", "age": 30, "login": "d.fomin"}},
+			{"op": "create", "person": {"firstName": "Юрий", "lastName": "Титов", "age": 44, "login": "y.titov"}}
+		]}`)
+
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		assert.False(t, result.Committed)
+
+		resp, err := http.Get("http://localhost:9902/api/v2/persons?login=d.fomin")
+		assert.Equal(t, "[]\n", string(parseResponseBytes(err, t, resp)))
+	})
+
+	t.Run("must keep successful operations of best-effort batch", func(t *testing.T) {
+		createPersonV2(t, `{"firstName": "Нина", "lastName": "Егорова", "age": 52, "login": "n.egorova"}`)
+
+		resp, result := postBatch(t, `{"mode": "best-effort", "operations": [
+			{"op": "create", "person": {"firstName": "Роман", "lastName": "Гусев", "age": 33, "login": "r.gusev"}},
+			{"op": "create", "person": {"firstName": "Нина", "lastName": "Егорова", "age": 52, "login": "n.egorova"}},
+			{"op": "create", "person": {"firstName": "", "lastName": "Без имени", "age": 1}},
+			{"op": "delete", "id": "7d444840-9dc0-11d1-b245-5ffdce74fad2"}
+		]}`)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.True(t, result.Committed)
+		assert.Equal(t, http.StatusCreated, result.Results[0].Status)
+		assert.Equal(t, http.StatusConflict, result.Results[1].Status)
+		assert.Equal(t, http.StatusBadRequest, result.Results[2].Status)
+		assert.Equal(t, http.StatusNotFound, result.Results[3].Status)
+
+		resp, err := http.Get("http://localhost:9902/api/v2/persons?login=r.gusev")
+		assert.Contains(t, string(parseResponseBytes(err, t, resp)), `"login":"r.gusev"`)
+	})
+}
+
+func postBatch(t *testing.T, body string) (*http.Response, model.PersonBatchResponse) {
+	resp, err := http.Post("http://localhost:9902/api/v2/persons/batch", "application/json", bytes.NewBufferString(body))
+	responseBytes := parseResponseBytes(err, t, resp)
+
+	var result model.PersonBatchResponse
+	if err := json.Unmarshal(responseBytes, &result); err != nil {
+		t.Fatalf("Error while parse response: %v", err)
+	}
+
+	return resp, result
+}
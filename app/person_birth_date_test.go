@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"person-service/model"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_PersonBirthDate(t *testing.T) {
+	today := time.Now().UTC()
+
+	t.Run("must compute age from birth date", func(t *testing.T) {
+		birthDate := today.AddDate(-30, 0, -1).Format("2006-01-02")
+		created := createPersonV2(t, fmt.Sprintf(`{"firstName": "Юлия", "lastName": "Осипова", "age": 5, "birthDate": "%s"}`, birthDate))
+
+		assert.Equal(t, 30, created.Age)
+		assert.Equal(t, birthDate, created.BirthDate)
+		assert.False(t, created.BirthDateApproximate)
+	})
+
+	t.Run("must keep approximate birth date of person created with age", func(t *testing.T) {
+		created := createPersonV2(t, `{"firstName": "Роман", "lastName": "Осипов", "age": 44}`)
+		assert.Equal(t, 44, created.Age)
+		assert.True(t, created.BirthDateApproximate)
+
+		req, _ := http.NewRequest(http.MethodPatch, fmt.Sprintf("http://localhost:9902/api/v2/persons/%s", created.Id),
+			strings.NewReader(`{"firstName": "Рома"}`))
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		resp, err := http.DefaultClient.Do(req)
+		patched := parseResponse(err, resp, t)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, created.BirthDate, patched.BirthDate)
+		assert.True(t, patched.BirthDateApproximate)
+	})
+
+	t.Run("must reject birth date in the future", func(t *testing.T) {
+		body := fmt.Sprintf(`{"firstName": "Юлия", "lastName": "Осипова", "birthDate": "%s"}`, today.AddDate(0, 0, 2).Format("2006-01-02"))
+		resp, _ := sendJson(t, http.MethodPost, "http://localhost:9902/api/v2/persons", body)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp, _ = sendJson(t, http.MethodPost, "http://localhost:9902/api/v2/persons", `{"firstName": "Юлия", "lastName": "Осипова", "birthDate": "30.01.1990"}`)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("must filter by age range", func(t *testing.T) {
+		/* the day before 120th and 121st birthdays, ages are far from other test persons */
+		younger := createPersonV2(t, fmt.Sprintf(`{"firstName": "Зоя", "lastName": "Долгова", "birthDate": "%s"}`,
+			today.AddDate(-120, 0, 1).Format("2006-01-02")))
+		older := createPersonV2(t, fmt.Sprintf(`{"firstName": "Зоя", "lastName": "Долгова", "birthDate": "%s"}`,
+			today.AddDate(-121, 0, 1).Format("2006-01-02")))
+		assert.Equal(t, 119, younger.Age)
+		assert.Equal(t, 120, older.Age)
+
+		_, result := postGraphql(t, `{ persons(filter: {lastName: "Долгова", minAge: 120, maxAge: 120}, first: 10) { edges { node { id age } } } }`, nil)
+		assert.Empty(t, result.Errors)
+
+		var connection graphqlConnection
+		assert.NoError(t, json.Unmarshal(result.Data["persons"], &connection))
+		assert.Len(t, connection.Edges, 1)
+		assert.Equal(t, older.Id.String(), connection.Edges[0].Node.Id)
+
+		resp, err := http.Get("http://localhost:9902/api/v2/persons?minAge=120&maxAge=120")
+		var persons []model.PersonResponse
+		assert.NoError(t, json.Unmarshal(parseResponseBytes(err, t, resp), &persons))
+		assert.Len(t, persons, 1)
+		assert.Equal(t, older.Id, persons[0].Id)
+
+		resp, err = http.Get("http://localhost:9902/api/v2/persons?minAge=old")
+		parseResponseBytes(err, t, resp)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("must apply patched age to person with exact birth date", func(t *testing.T) {
+		created := createPersonV2(t, fmt.Sprintf(`{"firstName": "Вера", "lastName": "Осипова", "birthDate": "%s"}`,
+			today.AddDate(-30, 0, -1).Format("2006-01-02")))
+
+		req, _ := http.NewRequest(http.MethodPatch, fmt.Sprintf("http://localhost:9902/api/v2/persons/%s", created.Id),
+			strings.NewReader(`{"age": 40}`))
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		resp, err := http.DefaultClient.Do(req)
+		patched := parseResponse(err, resp, t)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, 40, patched.Age)
+		assert.True(t, patched.BirthDateApproximate)
+	})
+
+	t.Run("must keep approximate birth date on echoed update", func(t *testing.T) {
+		created := createPersonV2(t, `{"firstName": "Лев", "lastName": "Осипов", "age": 52}`)
+
+		echoed, _ := json.Marshal(created)
+		req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("http://localhost:9902/api/v2/persons/%s", created.Id), bytes.NewReader(echoed))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		updated := parseResponse(err, resp, t)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, created.BirthDate, updated.BirthDate)
+		assert.True(t, updated.BirthDateApproximate)
+	})
+}
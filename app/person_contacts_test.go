@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"person-service/model"
+	"testing"
+)
+
+func Test_PersonContactsApi(t *testing.T) {
+	t.Run("must normalize contacts and embed them on expand", func(t *testing.T) {
+		created := createPersonV2(t, `{"firstName": "Вера", "lastName": "Орлова", "age": 31}`)
+		contactsUrl := fmt.Sprintf("http://localhost:9902/api/v2/persons/%s", created.Id)
+
+		resp, body := sendJson(t, http.MethodPost, contactsUrl+"/emails", `{"email": "Vera.Orlova@Example.COM", "type": "work", "primary": true}`)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		var email model.EmailResponse
+		assert.NoError(t, json.Unmarshal(body, &email))
+		assert.Equal(t, "Vera.Orlova@example.com", email.Email)
+		assert.Equal(t, fmt.Sprintf("/api/v2/persons/%s/emails/%s", created.Id, email.Id), resp.Header.Get("Location"))
+
+		resp, body = sendJson(t, http.MethodPost, contactsUrl+"/phones", `{"phone": "+7 (912) 345-67-89", "type": "mobile"}`)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		var phone model.PhoneResponse
+		assert.NoError(t, json.Unmarshal(body, &phone))
+		assert.Equal(t, "+79123456789", phone.Phone)
+
+		resp, err := http.Get(contactsUrl + "?expand=contacts")
+		person := parseResponse(err, resp, t)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, []model.EmailResponse{email}, person.Contacts.Emails)
+		assert.Equal(t, "+79123456789", person.Contacts.Phones[0].Phone)
+
+		resp, err = http.Get(contactsUrl)
+		assert.Nil(t, parseResponse(err, resp, t).Contacts)
+	})
+
+	t.Run("must keep single primary email", func(t *testing.T) {
+		created := createPersonV2(t, `{"firstName": "Юрий", "lastName": "Орлов", "age": 33}`)
+		emailsUrl := fmt.Sprintf("http://localhost:9902/api/v2/persons/%s/emails", created.Id)
+
+		sendJson(t, http.MethodPost, emailsUrl, `{"email": "y.orlov@example.com", "primary": true}`)
+		sendJson(t, http.MethodPost, emailsUrl, `{"email": "yury@example.com", "primary": true}`)
+
+		resp, err := http.Get(emailsUrl)
+		var emails []model.EmailResponse
+		assert.NoError(t, json.Unmarshal(parseResponseBytes(err, t, resp), &emails))
+		assert.Len(t, emails, 2)
+		assert.Equal(t, "yury@example.com", emails[0].Email)
+		assert.True(t, emails[0].Primary)
+		assert.False(t, emails[1].Primary)
+
+		/* primary email of one person can't be primary email of another */
+		other := createPersonV2(t, `{"firstName": "Яна", "lastName": "Орлова", "age": 30}`)
+		resp, _ = sendJson(t, http.MethodPost, fmt.Sprintf("http://localhost:9902/api/v2/persons/%s/emails", other.Id),
+			`{"email": "YURY@example.com", "primary": true}`)
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
+
+	t.Run("must reject invalid contacts and expand", func(t *testing.T) {
+		created := createPersonV2(t, `{"firstName": "Ия", "lastName": "Орлова", "age": 35}`)
+		personUrl := fmt.Sprintf("http://localhost:9902/api/v2/persons/%s", created.Id)
+
+		resp, _ := sendJson(t, http.MethodPost, personUrl+"/emails", `{"email": "Ия <ia@example.com>"}`)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp, _ = sendJson(t, http.MethodPost, personUrl+"/phones", `{"phone": "8 912 345 67 89"}`)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp, err := http.Get(personUrl + "?expand=friends")
+		parseResponseBytes(err, t, resp)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("must delete contacts", func(t *testing.T) {
+		created := createPersonV2(t, `{"firstName": "Ада", "lastName": "Орлова", "age": 36}`)
+		resp, body := sendJson(t, http.MethodPost, fmt.Sprintf("http://localhost:9902/api/v2/persons/%s/phones", created.Id), `{"phone": "0049 30 1234567"}`)
+		var phone model.PhoneResponse
+		assert.NoError(t, json.Unmarshal(body, &phone))
+
+		phoneUrl := fmt.Sprintf("http://localhost:9902/api/v2/persons/%s/phones/%s", created.Id, phone.Id)
+		req, _ := http.NewRequest(http.MethodDelete, phoneUrl, nil)
+		resp, err := http.DefaultClient.Do(req)
+		parseResponseBytes(err, t, resp)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		resp, err = http.Get(phoneUrl)
+		parseResponseBytes(err, t, resp)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
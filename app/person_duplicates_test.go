@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"person-service/model"
+	"testing"
+)
+
+func Test_PersonDuplicates(t *testing.T) {
+	yuri := createPersonV2(t, `{"firstName": "Юрий", "lastName": "Щукин", "birthDate": "1985-03-02"}`)
+	swapped := createPersonV2(t, `{"firstName": "Shchukin", "lastName": "Yuriy", "birthDate": "1985-03-02", "login": "y.shchukin"}`)
+	sharing := createPersonV2(t, `{"firstName": "Дарина", "lastName": "Кольцова", "age": 40}`)
+	manager := createPersonV2(t, `{"firstName": "Глеб", "lastName": "Веснин", "age": 50}`)
+
+	personUrl := func(id uuid.UUID) string {
+		return fmt.Sprintf("http://localhost:9902/api/v2/persons/%s", id)
+	}
+	resp, _ := sendJson(t, http.MethodPost, personUrl(yuri.Id)+"/emails", `{"email": "shchukin@example.com", "type": "work", "primary": true}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	resp, _ = sendJson(t, http.MethodPost, personUrl(swapped.Id)+"/emails", `{"email": "yuriy@example.com", "type": "home", "primary": true}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	resp, _ = sendJson(t, http.MethodPost, personUrl(yuri.Id)+"/phones", `{"phone": "+79001112233", "type": "mobile"}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	resp, _ = sendJson(t, http.MethodPost, personUrl(sharing.Id)+"/phones", `{"phone": "+79001112233", "type": "home"}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	resp, _ = sendJson(t, http.MethodPost, personUrl(swapped.Id)+"/relationships", fmt.Sprintf(`{"type": "manager", "personId": "%s"}`, manager.Id))
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	t.Run("must find transliterated, swapped and contact sharing duplicates", func(t *testing.T) {
+		resp, err := http.Get(fmt.Sprintf("http://localhost:9902/api/v1/person/%s/duplicates", yuri.Id))
+		var duplicates []model.DuplicateResponse
+		assert.NoError(t, json.Unmarshal(parseResponseBytes(err, t, resp), &duplicates))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.NotEmpty(t, resp.Header.Get("Deprecation"))
+
+		reasons := make(map[uuid.UUID][]string)
+		for _, duplicate := range duplicates {
+			reasons[duplicate.Person.Id] = duplicate.Reasons
+		}
+		assert.Equal(t, swapped.Id, duplicates[0].Person.Id)
+		assert.Equal(t, []string{"swapped_name", "same_birth_date"}, reasons[swapped.Id])
+		assert.Equal(t, []string{"shared_phone"}, reasons[sharing.Id])
+		assert.NotContains(t, reasons, manager.Id)
+	})
+
+	t.Run("must merge duplicate and redirect its id", func(t *testing.T) {
+		resp, body := sendJson(t, http.MethodPost, personUrl(yuri.Id)+"/merge",
+			fmt.Sprintf(`{"duplicateId": "%s", "fields": {"lastName": "survivor"}}`, swapped.Id))
+		assert.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+		var merge model.MergeResponse
+		assert.NoError(t, json.Unmarshal(body, &merge))
+		assert.Equal(t, swapped.Id, merge.MergedId)
+		assert.Equal(t, "Shchukin", merge.Merged.FirstName)
+		assert.Equal(t, "Юрий", merge.Person.FirstName)
+		assert.Equal(t, "y.shchukin", merge.Person.Login)
+		assert.Equal(t, "duplicate", merge.Fields.Login)
+
+		client := &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}}
+		resp, err := client.Get(personUrl(swapped.Id) + "?expand=contacts")
+		parseResponseBytes(err, t, resp)
+		assert.Equal(t, http.StatusPermanentRedirect, resp.StatusCode)
+		assert.Equal(t, fmt.Sprintf("/api/v2/persons/%s?expand=contacts", yuri.Id), resp.Header.Get("Location"))
+
+		/* emails are moved, survivor keeps its primary one */
+		resp, err = http.Get(personUrl(swapped.Id) + "?expand=contacts")
+		person := parseResponse(err, resp, t)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, yuri.Id, person.Id)
+		assert.Len(t, person.Contacts.Emails, 2)
+		for _, email := range person.Contacts.Emails {
+			assert.Equal(t, email.Email == "shchukin@example.com", email.Primary, email.Email)
+		}
+
+		resp, err = http.Get(personUrl(yuri.Id) + "/relationships?type=manager")
+		var relationships []model.RelationshipResponse
+		assert.NoError(t, json.Unmarshal(parseResponseBytes(err, t, resp), &relationships))
+		assert.Len(t, relationships, 1)
+		assert.Equal(t, manager.Id, relationships[0].PersonId)
+
+		resp, err = http.Get(personUrl(yuri.Id) + "/merges")
+		var merges []model.MergeResponse
+		assert.NoError(t, json.Unmarshal(parseResponseBytes(err, t, resp), &merges))
+		assert.Len(t, merges, 1)
+		assert.Nil(t, merges[0].Person)
+		assert.Equal(t, "y.shchukin", merges[0].Merged.Login)
+	})
+
+	t.Run("must reject invalid merges", func(t *testing.T) {
+		resp, _ := sendJson(t, http.MethodPost, personUrl(yuri.Id)+"/merge", fmt.Sprintf(`{"duplicateId": "%s"}`, yuri.Id))
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp, _ = sendJson(t, http.MethodPost, personUrl(yuri.Id)+"/merge", fmt.Sprintf(`{"duplicateId": "%s"}`, swapped.Id))
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp, _ = sendJson(t, http.MethodPost, personUrl(yuri.Id)+"/merge",
+			fmt.Sprintf(`{"duplicateId": "%s", "fields": {"login": "both"}}`, sharing.Id))
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/vmihailenco/msgpack/v5"
+	"net/http"
+	"person-service/model"
+	"strings"
+	"testing"
+)
+
+func Test_PersonContentNegotiation(t *testing.T) {
+	client := &http.Client{}
+
+	t.Run("must create person from xml and respond with xml", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, "http://localhost:9902/api/v2/persons", strings.NewReader(
+			`<person><firstName>Вера</firstName><lastName>Орлова</lastName><age>31</age><login>v.orlova</login></person>`,
+		))
+		req.Header.Set("Content-Type", "application/xml")
+		req.Header.Set("Accept", "application/xml")
+		resp, err := client.Do(req)
+		body := parseResponseBytes(err, t, resp)
+
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, "application/xml", resp.Header.Get("Content-Type"))
+
+		var result model.PersonResponse
+		assert.NoError(t, xml.Unmarshal(body, &result))
+		assert.Equal(t, "Вера", result.FirstName)
+		assert.Equal(t, "v.orlova", result.Login)
+	})
+
+	t.Run("must respond with msgpack and cbor", func(t *testing.T) {
+		created := createPersonV2(t, `{"firstName": "Лев", "lastName": "Зуев", "age": 60}`)
+		url := fmt.Sprintf("http://localhost:9902/api/v2/persons/%s", created.Id)
+
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		req.Header.Set("Accept", "application/msgpack")
+		resp, err := client.Do(req)
+		body := parseResponseBytes(err, t, resp)
+
+		var fromMsgpack map[string]any
+		assert.Equal(t, "application/msgpack", resp.Header.Get("Content-Type"))
+		assert.NoError(t, msgpack.Unmarshal(body, &fromMsgpack))
+		assert.Equal(t, "Лев", fromMsgpack["firstName"])
+
+		req, _ = http.NewRequest(http.MethodGet, url, nil)
+		req.Header.Set("Accept", "application/cbor")
+		resp, err = client.Do(req)
+		body = parseResponseBytes(err, t, resp)
+
+		var fromCbor model.PersonResponse
+		assert.Equal(t, "application/cbor", resp.Header.Get("Content-Type"))
+		assert.NoError(t, cbor.Unmarshal(body, &fromCbor))
+		assert.Equal(t, created.Id, fromCbor.Id)
+	})
+
+	t.Run("must encode lists and errors with negotiated codec", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "http://localhost:9902/api/v2/persons?login=v.orlova", nil)
+		req.Header.Set("Accept", "application/xml")
+		resp, err := client.Do(req)
+		body := string(parseResponseBytes(err, t, resp))
+		assert.Contains(t, body, "<items><person>")
+
+		req, _ = http.NewRequest(http.MethodGet, "http://localhost:9902/api/v2/persons/7d444840-9dc0-11d1-b245-5ffdce74fad2", nil)
+		req.Header.Set("Accept", "text/xml")
+		resp, err = client.Do(req)
+		body = string(parseResponseBytes(err, t, resp))
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Contains(t, body, "<error><status>404</status>")
+	})
+
+	t.Run("must respond 406 on unsupported accept", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "http://localhost:9902/api/v2/persons", nil)
+		req.Header.Set("Accept", "text/html")
+		resp, err := client.Do(req)
+		parseResponseBytes(err, t, resp)
+
+		assert.Equal(t, http.StatusNotAcceptable, resp.StatusCode)
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	})
+
+	t.Run("must respond 415 on unsupported content type", func(t *testing.T) {
+		resp, err := http.Post("http://localhost:9902/api/v2/persons", "text/plain",
+			bytes.NewBufferString(`firstName=Иван`))
+		parseResponseBytes(err, t, resp)
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+	})
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func Test_PersonExport(t *testing.T) {
+	createPersonV2(t, `{"firstName": "Эдуард", "lastName": "Экспортов", "age": 45, "login": "e.export"}`)
+
+	t.Run("must stream csv filtered by login", func(t *testing.T) {
+		resp, err := http.Get("http://localhost:9902/api/v2/persons/export?format=csv&login=e.export")
+		body := string(parseResponseBytes(err, t, resp))
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/csv; charset=utf-8", resp.Header.Get("Content-Type"))
+		assert.Contains(t, resp.Header.Get("Content-Disposition"), `attachment; filename="persons-`)
+		assert.Equal(t, 2, strings.Count(body, "\n"))
+		assert.Contains(t, body, "e.export,Эдуард,Экспортов,45")
+	})
+
+	t.Run("must stream all persons as ndjson", func(t *testing.T) {
+		resp, err := http.Get("http://localhost:9902/api/v2/persons/export?format=ndjson")
+		body := string(parseResponseBytes(err, t, resp))
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, body, `"login":"e.export"`)
+	})
+
+	t.Run("must stream xlsx workbook", func(t *testing.T) {
+		resp, err := http.Get("http://localhost:9902/api/v1/persons/export?format=xlsx&login=e.export")
+		body := parseResponseBytes(err, t, resp)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, resp.Header.Get("Content-Disposition"), ".xlsx")
+		_, err = zip.NewReader(bytes.NewReader(body), int64(len(body)))
+		assert.NoError(t, err)
+	})
+
+	t.Run("must return 400 for unknown format", func(t *testing.T) {
+		resp, err := http.Get("http://localhost:9902/api/v2/persons/export?format=pdf")
+		parseResponseBytes(err, t, resp)
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+type graphqlResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+type graphqlPerson struct {
+	Id        string  `json:"id"`
+	FirstName string  `json:"firstName"`
+	Age       int     `json:"age"`
+	Login     *string `json:"login"`
+}
+
+type graphqlConnection struct {
+	Edges []struct {
+		Cursor string        `json:"cursor"`
+		Node   graphqlPerson `json:"node"`
+	} `json:"edges"`
+	PageInfo struct {
+		HasNextPage bool    `json:"hasNextPage"`
+		EndCursor   *string `json:"endCursor"`
+	} `json:"pageInfo"`
+}
+
+func Test_PersonGraphqlApi(t *testing.T) {
+	t.Run("must create, update and delete person", func(t *testing.T) {
+		resp, result := postGraphql(t, `mutation ($input: PersonInput!) { createPerson(input: $input) { id firstName login } }`,
+			map[string]any{"input": map[string]any{"firstName": "Глеб", "lastName": "Соколов", "age": 27, "login": "g.sokolov"}})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Empty(t, result.Errors)
+
+		var created graphqlPerson
+		assert.NoError(t, json.Unmarshal(result.Data["createPerson"], &created))
+		assert.Equal(t, "g.sokolov", *created.Login)
+
+		_, result = postGraphql(t, fmt.Sprintf(
+			`mutation { updatePerson(id: "%s", input: {firstName: "Глеб", lastName: "Соколов", age: 28}) { age login } }`, created.Id,
+		), nil)
+		var updated graphqlPerson
+		assert.NoError(t, json.Unmarshal(result.Data["updatePerson"], &updated))
+		assert.Equal(t, 28, updated.Age)
+		assert.Nil(t, updated.Login)
+
+		_, result = postGraphql(t, fmt.Sprintf(`mutation { deletePerson(id: "%s") }`, created.Id), nil)
+		assert.Empty(t, result.Errors)
+
+		_, result = postGraphql(t, fmt.Sprintf(`{ person(id: "%s") { id } }`, created.Id), nil)
+		assert.Equal(t, "null", string(result.Data["person"]))
+	})
+
+	t.Run("must load persons by id and login in one query", func(t *testing.T) {
+		first := createPersonV2(t, `{"firstName": "Зоя", "lastName": "Карпова", "age": 44, "login": "z.karpova"}`)
+		second := createPersonV2(t, `{"firstName": "Ян", "lastName": "Карпов", "age": 46}`)
+
+		query := fmt.Sprintf(`{ a: person(id: "%s") { firstName } b: person(id: "%s") { firstName } c: personByLogin(login: "z.karpova") { id } }`,
+			first.Id, second.Id)
+		resp, err := http.Get("http://localhost:9902/graphql?query=" + url.QueryEscape(query))
+		var result graphqlResponse
+		assert.NoError(t, json.Unmarshal(parseResponseBytes(err, t, resp), &result))
+
+		assert.Empty(t, result.Errors)
+		assert.JSONEq(t, `{"firstName": "Зоя"}`, string(result.Data["a"]))
+		assert.JSONEq(t, `{"firstName": "Ян"}`, string(result.Data["b"]))
+		assert.JSONEq(t, fmt.Sprintf(`{"id": "%s"}`, first.Id), string(result.Data["c"]))
+	})
+
+	t.Run("must page persons by cursor", func(t *testing.T) {
+		for _, age := range []int{71, 72, 73} {
+			createPersonV2(t, fmt.Sprintf(`{"firstName": "Пётр", "lastName": "Граф%d", "age": %d}`, age, age))
+		}
+
+		query := `query ($after: String) {
+			persons(filter: {lastName: "граф", minAge: 71}, sort: {field: AGE, direction: DESC}, first: 2, after: $after) {
+				edges { node { age } } pageInfo { hasNextPage endCursor }
+			}
+		}`
+		var ages []int
+		var after any
+		for {
+			_, result := postGraphql(t, query, map[string]any{"after": after})
+			assert.Empty(t, result.Errors)
+
+			var page graphqlConnection
+			assert.NoError(t, json.Unmarshal(result.Data["persons"], &page))
+			for _, edge := range page.Edges {
+				ages = append(ages, edge.Node.Age)
+			}
+			if !page.PageInfo.HasNextPage {
+				break
+			}
+			after = *page.PageInfo.EndCursor
+		}
+		assert.Equal(t, []int{73, 72, 71}, ages)
+	})
+
+	t.Run("must map service errors to codes", func(t *testing.T) {
+		_, result := postGraphql(t, `mutation { createPerson(input: {firstName: "", lastName: "Карпов", age: 46}) { id } }`, nil)
+		assert.Equal(t, "BAD_USER_INPUT", result.Errors[0].Extensions["code"])
+
+		input := map[string]any{"input": map[string]any{"firstName": "Ия", "lastName": "Кац", "age": 20, "login": "i.katz"}}
+		mutation := `mutation ($input: PersonInput!) { createPerson(input: $input) { id } }`
+		_, result = postGraphql(t, mutation, input)
+		assert.Empty(t, result.Errors)
+		_, result = postGraphql(t, mutation, input)
+		assert.Equal(t, "CONFLICT", result.Errors[0].Extensions["code"])
+	})
+
+	t.Run("must reject mutation with get and too large page", func(t *testing.T) {
+		resp, err := http.Get("http://localhost:9902/graphql?query=" + url.QueryEscape(`mutation { deletePerson(id: "6ba7b810-9dad-11d1-80b4-00c04fd430c8") }`))
+		parseResponseBytes(err, t, resp)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		_, result := postGraphql(t, `{ persons(first: 1000) { edges { cursor } } }`, nil)
+		assert.NotEmpty(t, result.Errors)
+	})
+}
+
+// postGraphql post query, headers are pairs of name and value.
+func postGraphql(t *testing.T, query string, variables map[string]any, headers ...string) (*http.Response, graphqlResponse) {
+	body, _ := json.Marshal(map[string]any{"query": query, "variables": variables})
+	resp, data := sendJson(t, http.MethodPost, "http://localhost:9902/graphql", string(body), headers...)
+
+	var result graphqlResponse
+	assert.NoError(t, json.Unmarshal(data, &result))
+	return resp, result
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"person-service/model"
+	"testing"
+)
+
+func Test_PersonGroups(t *testing.T) {
+	root := createGroup(t, `{"name": "Engineering"}`)
+	child := createGroup(t, fmt.Sprintf(`{"name": "Backend", "parentId": "%s"}`, root.Id))
+	first := createPersonV2(t, `{"firstName": "Глеб", "lastName": "Группов", "age": 33}`)
+	second := createPersonV2(t, `{"firstName": "Вера", "lastName": "Группова", "age": 29}`)
+
+	t.Run("must reject duplicate name and cycle", func(t *testing.T) {
+		resp, _ := sendJson(t, http.MethodPost, "http://localhost:9902/api/v2/groups", fmt.Sprintf(`{"name": "backend", "parentId": "%s"}`, root.Id))
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+		resp, _ = sendJson(t, http.MethodPut, fmt.Sprintf("http://localhost:9902/api/v2/groups/%s", root.Id),
+			fmt.Sprintf(`{"name": "Engineering", "parentId": "%s"}`, child.Id))
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("must add members in bulk and list them with descendants", func(t *testing.T) {
+		body := fmt.Sprintf(`{"personIds": ["%s", "%s", "%s"]}`, first.Id, second.Id, first.Id)
+		resp, result := sendJson(t, http.MethodPost, fmt.Sprintf("http://localhost:9902/api/v2/groups/%s/members", child.Id), body)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.JSONEq(t, `{"changed": 2}`, string(result))
+
+		resp, _ = sendJson(t, http.MethodPost, fmt.Sprintf("http://localhost:9902/api/v2/groups/%s/members", child.Id), `{"personIds": ["00000000-0000-0000-0000-000000000001"]}`)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		assert.Len(t, loadPersons(t, fmt.Sprintf("http://localhost:9902/api/v2/groups/%s/members", root.Id)), 0)
+		assert.Len(t, loadPersons(t, fmt.Sprintf("http://localhost:9902/api/v2/groups/%s/members?descendants=true", root.Id)), 2)
+		assert.Len(t, loadPersons(t, fmt.Sprintf("http://localhost:9902/api/v2/persons?group=%s", root.Id)), 2)
+
+		resp, result = sendJson(t, http.MethodDelete, fmt.Sprintf("http://localhost:9902/api/v2/groups/%s/members", child.Id),
+			fmt.Sprintf(`{"personIds": ["%s"]}`, second.Id))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.JSONEq(t, `{"changed": 1}`, string(result))
+	})
+
+	t.Run("must filter persons by tags", func(t *testing.T) {
+		resp, _ := sendJson(t, http.MethodPost, "http://localhost:9902/api/v2/tags/Oncall/members", fmt.Sprintf(`{"personIds": ["%s", "%s"]}`, first.Id, second.Id))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		resp, _ = sendJson(t, http.MethodPost, "http://localhost:9902/api/v2/tags/mentor/members", fmt.Sprintf(`{"personIds": ["%s"]}`, second.Id))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		persons := loadPersons(t, "http://localhost:9902/api/v2/persons?tag=oncall&tag=mentor")
+		assert.Len(t, persons, 1)
+		assert.Equal(t, second.Id, persons[0].Id)
+		assert.Len(t, loadPersons(t, "http://localhost:9902/api/v2/tags/oncall/members"), 2)
+
+		resp, _ = sendJson(t, http.MethodPost, "http://localhost:9902/api/v2/tags/%20/members", fmt.Sprintf(`{"personIds": ["%s"]}`, second.Id))
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("must restrict delete of group with children", func(t *testing.T) {
+		resp, _ := sendJson(t, http.MethodDelete, fmt.Sprintf("http://localhost:9902/api/v2/groups/%s", root.Id), "")
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+		sendJson(t, http.MethodDelete, fmt.Sprintf("http://localhost:9902/api/v2/groups/%s/members", child.Id), fmt.Sprintf(`{"personIds": ["%s"]}`, first.Id))
+		resp, _ = sendJson(t, http.MethodDelete, fmt.Sprintf("http://localhost:9902/api/v2/groups/%s", child.Id), "")
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		resp, _ = sendJson(t, http.MethodDelete, fmt.Sprintf("http://localhost:9902/api/v2/groups/%s", root.Id), "")
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+}
+
+func createGroup(t *testing.T, body string) model.GroupResponse {
+	resp, result := sendJson(t, http.MethodPost, "http://localhost:9902/api/v2/groups", body)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode, string(result))
+
+	var group model.GroupResponse
+	assert.NoError(t, json.Unmarshal(result, &group))
+	return group
+}
+
+func loadPersons(t *testing.T, url string) []model.PersonResponse {
+	resp, err := http.Get(url)
+	result := parseResponseBytes(err, t, resp)
+	assert.Equal(t, http.StatusOK, resp.StatusCode, string(result))
+
+	var persons []model.PersonResponse
+	assert.NoError(t, json.Unmarshal(result, &persons))
+	return persons
+}
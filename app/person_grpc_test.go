@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"io"
+	"net"
+	"person-service/grpcserver"
+	personv1 "person-service/proto/person/v1"
+	"testing"
+	"time"
+)
+
+func Test_PersonGrpcApi(t *testing.T) {
+	conn := dialGrpc(t)
+	client := personv1.NewPersonServiceClient(conn)
+
+	t.Run("must create, update and delete person", func(t *testing.T) {
+		created, err := client.CreatePerson(ctx, &personv1.CreatePersonRequest{Person: &personv1.Person{
+			FirstName: "Артём", LastName: "Белов", Age: 35, Login: "a.belov",
+		}})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, created.Id)
+		assert.True(t, created.CreateTime.IsValid())
+
+		found, err := client.GetPersonByLogin(ctx, &personv1.GetPersonByLoginRequest{Login: "a.belov"})
+		assert.NoError(t, err)
+		assert.Equal(t, created.Id, found.Id)
+
+		created.Age = 36
+		updated, err := client.UpdatePerson(ctx, &personv1.UpdatePersonRequest{Person: created})
+		assert.NoError(t, err)
+		assert.Equal(t, int32(36), updated.Age)
+
+		_, err = client.DeletePerson(ctx, &personv1.DeletePersonRequest{Id: created.Id})
+		assert.NoError(t, err)
+
+		_, err = client.GetPerson(ctx, &personv1.GetPersonRequest{Id: created.Id})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("must map service errors to codes", func(t *testing.T) {
+		_, err := client.CreatePerson(ctx, &personv1.CreatePersonRequest{Person: &personv1.Person{LastName: "Белов", Age: 35}})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = client.GetPerson(ctx, &personv1.GetPersonRequest{Id: "not-uuid"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		person := &personv1.Person{FirstName: "Дарья", LastName: "Белова", Age: 30, Login: "d.belova"}
+		_, err = client.CreatePerson(ctx, &personv1.CreatePersonRequest{Person: person})
+		assert.NoError(t, err)
+		_, err = client.CreatePerson(ctx, &personv1.CreatePersonRequest{Person: person})
+		assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	})
+
+	t.Run("must stream persons filtered by login", func(t *testing.T) {
+		_, err := client.CreatePerson(ctx, &personv1.CreatePersonRequest{Person: &personv1.Person{
+			FirstName: "Кира", LastName: "Лебедева", Age: 22, Login: "k.lebedeva",
+		}})
+		assert.NoError(t, err)
+
+		stream, err := client.ListPersons(ctx, &personv1.ListPersonsRequest{Login: "k.lebedeva"})
+		assert.NoError(t, err)
+
+		var persons []*personv1.Person
+		for {
+			person, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			assert.NoError(t, err)
+			persons = append(persons, person)
+		}
+		assert.Len(t, persons, 1)
+		assert.Equal(t, "Кира", persons[0].FirstName)
+	})
+
+	t.Run("must stream events of watched person", func(t *testing.T) {
+		created, err := client.CreatePerson(ctx, &personv1.CreatePersonRequest{Person: &personv1.Person{
+			FirstName: "Марат", LastName: "Ахметов", Age: 41,
+		}})
+		assert.NoError(t, err)
+
+		watchCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		stream, err := client.WatchPersons(watchCtx, &personv1.WatchPersonsRequest{AfterEventId: 1, PersonId: created.Id})
+		assert.NoError(t, err)
+
+		event, err := stream.Recv()
+		assert.NoError(t, err)
+		assert.Equal(t, personv1.PersonEvent_TYPE_CREATED, event.Type)
+		assert.Equal(t, "Марат", event.Person.FirstName)
+
+		_, err = client.DeletePerson(ctx, &personv1.DeletePersonRequest{Id: created.Id})
+		assert.NoError(t, err)
+
+		event, err = stream.Recv()
+		assert.NoError(t, err)
+		assert.Equal(t, personv1.PersonEvent_TYPE_DELETED, event.Type)
+		assert.Equal(t, created.Id, event.Person.Id)
+	})
+
+	t.Run("must expose service by reflection", func(t *testing.T) {
+		stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+		assert.NoError(t, err)
+
+		err = stream.Send(&reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+		})
+		assert.NoError(t, err)
+		resp, err := stream.Recv()
+		assert.NoError(t, err)
+
+		var names []string
+		for _, service := range resp.GetListServicesResponse().GetService() {
+			names = append(names, service.Name)
+		}
+		assert.Contains(t, names, "person.v1.PersonService")
+	})
+}
+
+func dialGrpc(t *testing.T) *grpc.ClientConn {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpcserver.NewServer(logger, personService, tenantService, nil, configuration.Grpc, configuration.Tenancy)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Error while dial grpc: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
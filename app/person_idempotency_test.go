@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func Test_IdempotencyKey(t *testing.T) {
+	post := func(url string, key string, body string) *http.Response {
+		req, _ := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", key)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Error while send request: %v", err)
+		}
+		return resp
+	}
+	body := `{"firstName": "Кирилл", "lastName": "Повторов", "age": 28}`
+
+	t.Run("must replay first response for retry with the same key", func(t *testing.T) {
+		key := uuid.NewString()
+
+		first := post("http://localhost:9902/api/v2/persons", key, body)
+		created := parseResponse(nil, first, t)
+		retry := post("http://localhost:9902/api/v2/persons", key, body)
+		replayed := parseResponse(nil, retry, t)
+
+		assert.Equal(t, http.StatusCreated, first.StatusCode)
+		assert.Equal(t, http.StatusCreated, retry.StatusCode)
+		assert.Equal(t, created.Id, replayed.Id)
+		assert.Equal(t, first.Header.Get("Location"), retry.Header.Get("Location"))
+		assert.Equal(t, "true", retry.Header.Get("Idempotent-Replayed"))
+	})
+
+	t.Run("must return 422 when key is reused with different payload", func(t *testing.T) {
+		key := uuid.NewString()
+
+		resp := post("http://localhost:9902/api/v1/person/create", key, body)
+		parseResponse(nil, resp, t)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp = post("http://localhost:9902/api/v1/person/create", key, `{"firstName": "Кирилл", "lastName": "Другой", "age": 28}`)
+		parseResponseBytes(nil, t, resp)
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	})
+
+	t.Run("must serialize concurrent requests with the same key", func(t *testing.T) {
+		key := uuid.NewString()
+		ids := make([]uuid.UUID, 5)
+
+		var wg sync.WaitGroup
+		for i := range ids {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				ids[i] = parseResponse(nil, post("http://localhost:9902/api/v2/persons", key, body), t).Id
+			}(i)
+		}
+		wg.Wait()
+
+		for _, id := range ids {
+			assert.Equal(t, ids[0], id)
+		}
+	})
+
+	t.Run("must replay batch response", func(t *testing.T) {
+		key := uuid.NewString()
+		batch := fmt.Sprintf(`{"operations": [{"op": "create", "person": %s}]}`, body)
+
+		first := string(parseResponseBytes(nil, t, post("http://localhost:9902/api/v2/persons/batch", key, batch)))
+		retry := post("http://localhost:9902/api/v2/persons/batch", key, batch)
+
+		assert.Equal(t, http.StatusOK, retry.StatusCode)
+		assert.Equal(t, first, string(parseResponseBytes(nil, t, retry)))
+		assert.Equal(t, "true", retry.Header.Get("Idempotent-Replayed"))
+	})
+}
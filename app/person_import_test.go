@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/url"
+	"person-service/model"
+	"strings"
+	"testing"
+)
+
+func Test_PersonImport(t *testing.T) {
+	file := "\xEF\xBB\xBFЛогин;Имя;Фамилия;Возраст\n" +
+		"i.import;Илья;Импортов;31\n" +
+		"e.import;;Пустой;20\n" +
+		"m.import;Мария;Импортова;200\n"
+	query := url.Values{
+		"delimiter": {";"},
+		"columns":   {"login:Логин,firstName:Имя,lastName:Фамилия,age:Возраст"},
+	}.Encode()
+
+	t.Run("must validate rows without saving in dry run", func(t *testing.T) {
+		resp, report := postImport(t, "text/csv", query+"&dryRun=true", file)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, 3, report.Total)
+		assert.Equal(t, 1, report.Created)
+		assert.Len(t, report.Rejected, 2)
+		assert.Equal(t, 3, report.Rejected[0].Line)
+		assert.Equal(t, 4, report.Rejected[1].Line)
+
+		resp, err := http.Get("http://localhost:9902/api/v2/persons?login=i.import")
+		assert.Equal(t, "[]\n", string(parseResponseBytes(err, t, resp)))
+	})
+
+	t.Run("must upsert rows by login", func(t *testing.T) {
+		_, report := postImport(t, "text/csv", query, file)
+		assert.Equal(t, 1, report.Created)
+
+		_, report = postImport(t, "application/x-ndjson", "",
+			`{"login": "i.import", "firstName": "Илья", "lastName": "Импортов", "age": 32}`+"\n")
+		assert.Equal(t, 1, report.Updated)
+		assert.Empty(t, report.Rejected)
+
+		resp, err := http.Get("http://localhost:9902/api/v2/persons?login=i.import")
+		assert.Contains(t, string(parseResponseBytes(err, t, resp)), `"age":32`)
+	})
+
+	t.Run("must return downloadable report of rejected rows", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, "http://localhost:9902/api/v2/persons/import?report=csv&dryRun=true&"+query, strings.NewReader(file))
+		req.Header.Set("Content-Type", "text/csv")
+		resp, err := http.DefaultClient.Do(req)
+		body := string(parseResponseBytes(err, t, resp))
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, resp.Header.Get("Content-Disposition"), "attachment")
+		assert.Contains(t, body, "line,reason,row\n")
+		assert.Contains(t, body, "Field firstName must not be empty")
+	})
+}
+
+func postImport(t *testing.T, contentType string, query string, body string) (*http.Response, model.PersonImportResponse) {
+	req, _ := http.NewRequest(http.MethodPost, "http://localhost:9902/api/v2/persons/import?"+query, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	resp, err := http.DefaultClient.Do(req)
+	responseBytes := parseResponseBytes(err, t, resp)
+
+	var report model.PersonImportResponse
+	if err := json.Unmarshal(responseBytes, &report); err != nil {
+		t.Fatalf("Error while parse response: %v", err)
+	}
+
+	return resp, report
+}
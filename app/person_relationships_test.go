@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"person-service/model"
+	"testing"
+)
+
+func Test_PersonRelationships(t *testing.T) {
+	ceo := createPersonV2(t, `{"firstName": "Лев", "lastName": "Начальников", "age": 58}`)
+	cto := createPersonV2(t, `{"firstName": "Ян", "lastName": "Начальников", "age": 45}`)
+	engineer := createPersonV2(t, `{"firstName": "Ива", "lastName": "Начальникова", "age": 27}`)
+
+	relate := func(personId fmt.Stringer, relationshipType string, relatedId fmt.Stringer) (*http.Response, []byte) {
+		return sendJson(t, http.MethodPost, fmt.Sprintf("http://localhost:9902/api/v2/persons/%s/relationships", personId),
+			fmt.Sprintf(`{"type": "%s", "personId": "%s"}`, relationshipType, relatedId))
+	}
+
+	t.Run("must create relationship with inverse type", func(t *testing.T) {
+		resp, body := relate(cto.Id, "manager", ceo.Id)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode, string(body))
+		/* the same as engineer is report of cto */
+		resp, body = relate(cto.Id, "report", engineer.Id)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode, string(body))
+
+		resp, err := http.Get(fmt.Sprintf("http://localhost:9902/api/v2/persons/%s/relationships?type=manager", engineer.Id))
+		var relationships []model.RelationshipResponse
+		assert.NoError(t, json.Unmarshal(parseResponseBytes(err, t, resp), &relationships))
+		assert.Len(t, relationships, 1)
+		assert.Equal(t, cto.Id, relationships[0].PersonId)
+		assert.True(t, relationships[0].Incoming)
+	})
+
+	t.Run("must reject second manager and cycle", func(t *testing.T) {
+		resp, _ := relate(engineer.Id, "manager", ceo.Id)
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+		resp, _ = relate(ceo.Id, "manager", engineer.Id)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("must load reports tree and chain of command", func(t *testing.T) {
+		resp, err := http.Get(fmt.Sprintf("http://localhost:9902/api/v2/persons/%s/reports?depth=1", ceo.Id))
+		var reports []model.HierarchyNodeResponse
+		assert.NoError(t, json.Unmarshal(parseResponseBytes(err, t, resp), &reports))
+		assert.Len(t, reports, 1)
+		assert.Equal(t, cto.Id, reports[0].Person.Id)
+		assert.True(t, reports[0].Truncated)
+
+		resp, err = http.Get(fmt.Sprintf("http://localhost:9902/api/v2/persons/%s/chain", engineer.Id))
+		var chain []model.HierarchyNodeResponse
+		assert.NoError(t, json.Unmarshal(parseResponseBytes(err, t, resp), &chain))
+		assert.Len(t, chain, 1)
+		assert.Equal(t, cto.Id, chain[0].Person.Id)
+		assert.Len(t, chain[0].Nodes, 1)
+		assert.Equal(t, ceo.Id, chain[0].Nodes[0].Person.Id)
+		assert.Equal(t, 2, chain[0].Nodes[0].Depth)
+
+		resp, err = http.Get(fmt.Sprintf("http://localhost:9902/api/v2/persons/%s/chain?depth=100", engineer.Id))
+		parseResponseBytes(err, t, resp)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+	"net/http"
+	"person-service/events"
+	"person-service/handlers"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_PersonEventStream(t *testing.T) {
+	t.Run("must stream events and replay them after Last-Event-ID", func(t *testing.T) {
+		frames := openEventStream(t, "")
+
+		created := createPersonV2(t, `{"firstName": "Нина", "lastName": "Фомина", "age": 52}`)
+		frame := nextPersonFrame(t, frames, created.Id.String())
+		assert.Equal(t, string(events.PersonCreated), frame["event"])
+
+		/* event committed while client is offline is replayed on reconnect */
+		req, _ := http.NewRequest(http.MethodDelete, fmt.Sprintf("http://localhost:9902/api/v2/persons/%s", created.Id), nil)
+		resp, err := http.DefaultClient.Do(req)
+		parseResponseBytes(err, t, resp)
+
+		frames = openEventStream(t, frame["id"])
+		frame = nextPersonFrame(t, frames, created.Id.String())
+		assert.Equal(t, string(events.PersonDeleted), frame["event"])
+	})
+
+	t.Run("must reject malformed Last-Event-ID", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "http://localhost:9902/api/v1/persons/stream", nil)
+		req.Header.Set("Last-Event-ID", "first")
+		resp, err := http.DefaultClient.Do(req)
+		parseResponseBytes(err, t, resp)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("must stream events over websocket", func(t *testing.T) {
+		ws, err := websocket.Dial("ws://localhost:9902/api/v1/persons/stream/ws", "", "http://localhost:9902")
+		assert.NoError(t, err)
+		t.Cleanup(func() { _ = ws.Close() })
+		assert.NoError(t, ws.SetReadDeadline(time.Now().Add(10*time.Second)))
+
+		/* heartbeat confirms subscription before person is created */
+		var message map[string]any
+		assert.NoError(t, websocket.JSON.Receive(ws, &message))
+		assert.Equal(t, "heartbeat", message["type"])
+
+		created := createPersonV2(t, `{"firstName": "Олег", "lastName": "Фомин", "age": 54}`)
+		for message["personId"] != created.Id.String() {
+			message = nil
+			assert.NoError(t, websocket.JSON.Receive(ws, &message))
+		}
+		assert.Equal(t, string(events.PersonCreated), message["type"])
+	})
+
+	t.Run("must select person events protocol and reject foreign origin", func(t *testing.T) {
+		ws, err := websocket.Dial("ws://localhost:9902/api/v1/persons/stream/ws", handlers.PersonEventsProtocol, "http://localhost:9902")
+		assert.NoError(t, err)
+		t.Cleanup(func() { _ = ws.Close() })
+		assert.Equal(t, []string{handlers.PersonEventsProtocol}, ws.Config().Protocol)
+
+		_, err = websocket.Dial("ws://localhost:9902/api/v1/persons/stream/ws", "", "https://evil.example.com")
+		assert.Error(t, err)
+	})
+}
+
+// openEventStream returns frames of SSE stream, stream is subscribed when the first heartbeat is received.
+// Headers are pairs of name and value.
+func openEventStream(t *testing.T, lastEventId string, headers ...string) <-chan map[string]string {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost:9902/api/v1/persons/stream", nil)
+	if lastEventId != "" {
+		req.Header.Set("Last-Event-ID", lastEventId)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Error while open event stream: %v", err)
+	}
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	/* replayed frames precede the first heartbeat, they are buffered until test reads them */
+	frames := make(chan map[string]string, 1024)
+	subscribed := make(chan struct{})
+	var once sync.Once
+	go func() {
+		defer close(frames)
+		defer resp.Body.Close()
+
+		scanner := bufio.NewScanner(resp.Body)
+		frame := map[string]string{}
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "" && len(frame) > 0:
+				frames <- frame
+				frame = map[string]string{}
+			case strings.HasPrefix(line, ":"):
+				once.Do(func() { close(subscribed) })
+			case line != "":
+				field, value, _ := strings.Cut(line, ": ")
+				frame[field] = value
+			}
+		}
+	}()
+
+	select {
+	case <-subscribed:
+	case <-ctx.Done():
+		t.Fatalf("Event stream was not subscribed")
+	}
+	return frames
+}
+
+// nextPersonFrame skips frames of other persons.
+func nextPersonFrame(t *testing.T, frames <-chan map[string]string, personId string) map[string]string {
+	for frame := range frames {
+		var event events.Event
+		assert.NoError(t, json.Unmarshal([]byte(frame["data"]), &event))
+		if event.PersonId.String() == personId {
+			return frame
+		}
+	}
+	t.Fatalf("Event of person %s was not received", personId)
+	return nil
+}
@@ -0,0 +1,383 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"person-service/config"
+	"person-service/db/entity"
+	"person-service/events"
+	"person-service/model"
+	personv1 "person-service/proto/person/v1"
+	"person-service/services"
+	"person-service/webhooks"
+	"strings"
+	"testing"
+	"time"
+)
+
+const tenantHeader = "X-Tenant-ID"
+
+func Test_PersonTenants(t *testing.T) {
+	acme := createTenant(t, entity.Tenant{Name: "acme", MaxPersons: 2, MaxAge: 100, LoginPattern: `^[a-z]+\.[a-z]+$`, RequireLogin: true})
+	globex := createTenant(t, entity.Tenant{Name: "globex"})
+	acmeId, globexId := acme.Id.String(), globex.Id.String()
+
+	t.Run("must reject tenant admin api without security", func(t *testing.T) {
+		resp, _ := sendJson(t, http.MethodPost, "http://localhost:9902/api/v2/admin/tenants", `{"name": "initech"}`)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp, _ = sendJson(t, http.MethodPost, fmt.Sprintf("http://localhost:9902/api/v2/admin/tenants/%s/suspend", acme.Id), "")
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("must isolate persons of tenants", func(t *testing.T) {
+		resp, body := sendJson(t, http.MethodPost, "http://localhost:9902/api/v2/persons",
+			`{"firstName": "Тина", "lastName": "Арендова", "age": 30, "login": "tina.arendova"}`, tenantHeader, acmeId)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode, string(body))
+		var created model.PersonResponse
+		assert.NoError(t, json.Unmarshal(body, &created))
+		personUrl := fmt.Sprintf("http://localhost:9902/api/v2/persons/%s", created.Id)
+
+		resp, _ = sendJson(t, http.MethodGet, personUrl, "", tenantHeader, acmeId)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp, _ = sendJson(t, http.MethodGet, personUrl, "", tenantHeader, globexId)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		resp, _ = http.Get(personUrl)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp, body = sendJson(t, http.MethodGet, "http://localhost:9902/api/v2/persons?login=tina.arendova", "", tenantHeader, globexId)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		var persons []model.PersonResponse
+		assert.NoError(t, json.Unmarshal(body, &persons))
+		assert.Empty(t, persons)
+	})
+
+	t.Run("must allow the same login in other tenant", func(t *testing.T) {
+		resp, body := sendJson(t, http.MethodPost, "http://localhost:9902/api/v2/persons",
+			`{"firstName": "Тина", "lastName": "Арендова", "age": 30, "login": "tina.arendova"}`, tenantHeader, globexId)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode, string(body))
+	})
+
+	t.Run("must validate persons by tenant settings", func(t *testing.T) {
+		for name, person := range map[string]string{
+			"missing login":          `{"firstName": "Тина", "lastName": "Арендова", "age": 30}`,
+			"login of other pattern": `{"firstName": "Тина", "lastName": "Арендова", "age": 30, "login": "Tina"}`,
+			"age above tenant limit": `{"firstName": "Тина", "lastName": "Арендова", "age": 120, "login": "old.tina"}`,
+		} {
+			resp, _ := sendJson(t, http.MethodPost, "http://localhost:9902/api/v2/persons", person, tenantHeader, acmeId)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, name)
+		}
+	})
+
+	t.Run("must reject persons above quota", func(t *testing.T) {
+		resp, body := sendJson(t, http.MethodPost, "http://localhost:9902/api/v2/persons",
+			`{"firstName": "Ева", "lastName": "Арендова", "age": 25, "login": "eva.arendova"}`, tenantHeader, acmeId)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode, string(body))
+
+		resp, _ = sendJson(t, http.MethodPost, "http://localhost:9902/api/v2/persons",
+			`{"firstName": "Юна", "lastName": "Арендова", "age": 21, "login": "yuna.arendova"}`, tenantHeader, acmeId)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		tenant, err := tenantService.FindTenant(ctx, acme.Id)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, tenant.Persons)
+	})
+
+	t.Run("must reject requests of suspended and unknown tenant", func(t *testing.T) {
+		_, err := tenantService.SuspendTenant(ctx, globex.Id)
+		assert.NoError(t, err)
+
+		resp, _ := sendJson(t, http.MethodGet, "http://localhost:9902/api/v2/persons", "", tenantHeader, globexId)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		_, err = tenantService.ResumeTenant(ctx, globex.Id)
+		assert.NoError(t, err)
+
+		resp, _ = sendJson(t, http.MethodGet, "http://localhost:9902/api/v2/persons", "", tenantHeader, globexId)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp, _ = sendJson(t, http.MethodGet, "http://localhost:9902/api/v2/persons", "", tenantHeader, "00000000-0000-0000-0000-0000000000ff")
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		resp, _ = sendJson(t, http.MethodGet, "http://localhost:9902/api/v2/persons", "", tenantHeader, "acme")
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("must reject invalid and duplicate tenants", func(t *testing.T) {
+		var pgErr *pgconn.PgError
+		_, err := tenantService.CreateTenant(ctx, entity.Tenant{Name: "acme"})
+		assert.ErrorAs(t, err, &pgErr)
+
+		var validationErr *services.ValidationError
+		_, err = tenantService.CreateTenant(ctx, entity.Tenant{Name: "initech", LoginPattern: "[a-z"})
+		assert.ErrorAs(t, err, &validationErr)
+	})
+}
+
+// createTenant provisions tenant through service, admin api is served only with security.
+func createTenant(t *testing.T, tenant entity.Tenant) entity.Tenant {
+	created, err := tenantService.CreateTenant(ctx, tenant)
+	assert.NoError(t, err)
+	return created
+}
+
+func Test_PersonTenantIsolation(t *testing.T) {
+	umbrella, hooli := createTenant(t, entity.Tenant{Name: "umbrella"}), createTenant(t, entity.Tenant{Name: "hooli"})
+	umbrellaId, hooliId := umbrella.Id.String(), hooli.Id.String()
+
+	/* persons of both tenants share names, login and phone, so every lookup of hooli would match umbrella rows */
+	leaked := createTenantPerson(t, umbrellaId, `{"firstName": "Ося", "lastName": "Утечкин", "age": 40, "login": "o.utechkin"}`)
+	manager := createTenantPerson(t, umbrellaId, `{"firstName": "Лука", "lastName": "Утечкин", "age": 60}`)
+	own := createTenantPerson(t, hooliId, `{"firstName": "Ося", "lastName": "Утечкин", "age": 40, "login": "o.utechkin"}`)
+
+	leakedUrl := fmt.Sprintf("http://localhost:9902/api/v2/persons/%s", leaked.Id)
+	ownUrl := fmt.Sprintf("http://localhost:9902/api/v2/persons/%s", own.Id)
+	for url, body := range map[string]string{
+		leakedUrl + "/emails":                            `{"email": "o.utechkin@example.com", "type": "work", "primary": true}`,
+		leakedUrl + "/phones":                            `{"phone": "+79005550101", "type": "mobile"}`,
+		leakedUrl + "/addresses":                         `{"lines": ["Rua Augusta 1"], "city": "Lisboa", "postalCode": "1100-048", "country": "PT"}`,
+		leakedUrl + "/relationships":                     fmt.Sprintf(`{"type": "manager", "personId": "%s"}`, manager.Id),
+		"http://localhost:9902/api/v2/tags/leak/members": fmt.Sprintf(`{"personIds": ["%s"]}`, leaked.Id),
+	} {
+		resp, result := sendJson(t, http.MethodPost, url, body, tenantHeader, umbrellaId)
+		assert.Contains(t, []int{http.StatusCreated, http.StatusOK}, resp.StatusCode, string(result))
+	}
+	resp, _ := sendJson(t, http.MethodPost, ownUrl+"/phones", `{"phone": "+79005550101", "type": "mobile"}`, tenantHeader, hooliId)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp, body := sendJson(t, http.MethodPost, "http://localhost:9902/api/v2/groups", `{"name": "Leakers"}`, tenantHeader, umbrellaId)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode, string(body))
+	var group model.GroupResponse
+	assert.NoError(t, json.Unmarshal(body, &group))
+	groupUrl := fmt.Sprintf("http://localhost:9902/api/v2/groups/%s", group.Id)
+	resp, _ = sendJson(t, http.MethodPost, groupUrl+"/members", fmt.Sprintf(`{"personIds": ["%s"]}`, leaked.Id), tenantHeader, umbrellaId)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	upload := func(url string, method string, contentType string, content []byte) []byte {
+		req, _ := http.NewRequest(method, url, bytes.NewReader(content))
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set(tenantHeader, umbrellaId)
+		resp, err := http.DefaultClient.Do(req)
+		body := parseResponseBytes(err, t, resp)
+		assert.Contains(t, []int{http.StatusCreated, http.StatusOK}, resp.StatusCode, string(body))
+		return body
+	}
+	var attachment model.AttachmentResponse
+	assert.NoError(t, json.Unmarshal(upload(leakedUrl+"/attachments?fileName=leak.txt", http.MethodPost, "text/plain", []byte("secret")), &attachment))
+	upload(leakedUrl+"/avatar", http.MethodPut, "image/png", avatarImage(t, 64, 64))
+
+	t.Run("must not list or export persons of other tenant", func(t *testing.T) {
+		for _, url := range []string{
+			"http://localhost:9902/api/v2/persons",
+			"http://localhost:9902/api/v2/persons?login=o.utechkin",
+			"http://localhost:9902/api/v2/persons?country=PT&city=Lisboa",
+			"http://localhost:9902/api/v2/persons?tag=leak",
+			"http://localhost:9902/api/v2/tags/leak/members",
+		} {
+			resp, body := sendJson(t, http.MethodGet, url, "", tenantHeader, hooliId)
+			assert.Equal(t, http.StatusOK, resp.StatusCode, url)
+			assert.NotContains(t, string(body), leaked.Id.String(), url)
+			assert.NotContains(t, string(body), manager.Id.String(), url)
+		}
+
+		resp, body := sendJson(t, http.MethodGet, "http://localhost:9902/api/v2/persons/export?format=ndjson", "", tenantHeader, hooliId)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, string(body), own.Id.String())
+		assert.NotContains(t, string(body), leaked.Id.String())
+		assert.NotContains(t, string(body), manager.Id.String())
+	})
+
+	t.Run("must not serve sub-resources of other tenant", func(t *testing.T) {
+		for _, url := range []string{
+			leakedUrl + "/emails",
+			leakedUrl + "/phones",
+			leakedUrl + "/addresses",
+			leakedUrl + "/relationships",
+			leakedUrl + "/reports",
+			leakedUrl + "/attachments",
+			fmt.Sprintf("%s/attachments/%s/content", leakedUrl, attachment.Id),
+			leakedUrl + "/avatar",
+			groupUrl,
+			groupUrl + "/members",
+		} {
+			resp, _ := sendJson(t, http.MethodGet, url, "", tenantHeader, hooliId)
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode, url)
+		}
+
+		/* persons of other tenant can not be referenced either */
+		resp, _ := sendJson(t, http.MethodPost, ownUrl+"/relationships", fmt.Sprintf(`{"type": "manager", "personId": "%s"}`, manager.Id),
+			tenantHeader, hooliId)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		resp, _ = sendJson(t, http.MethodPost, "http://localhost:9902/api/v2/tags/leak/members", fmt.Sprintf(`{"personIds": ["%s"]}`, leaked.Id),
+			tenantHeader, hooliId)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("must not find duplicates of other tenant or merge them", func(t *testing.T) {
+		resp, body := sendJson(t, http.MethodGet, fmt.Sprintf("http://localhost:9902/api/v1/person/%s/duplicates", own.Id), "",
+			tenantHeader, hooliId)
+		assert.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+		var duplicates []model.DuplicateResponse
+		assert.NoError(t, json.Unmarshal(body, &duplicates))
+		for _, duplicate := range duplicates {
+			assert.NotEqual(t, leaked.Id, duplicate.Person.Id)
+			assert.NotEqual(t, manager.Id, duplicate.Person.Id)
+		}
+
+		resp, _ = sendJson(t, http.MethodPost, fmt.Sprintf("http://localhost:9902/api/v1/person/%s/merge", own.Id),
+			fmt.Sprintf(`{"duplicateId": "%s"}`, leaked.Id), tenantHeader, hooliId)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		resp, _ = sendJson(t, http.MethodGet, leakedUrl, "", tenantHeader, umbrellaId)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("must not query persons of other tenant by graphql", func(t *testing.T) {
+		query := `query ($after: String) {
+			persons(filter: {lastName: "утечкин"}, first: 1, after: $after) { edges { node { id } } pageInfo { hasNextPage endCursor } }
+		}`
+		var ids []string
+		var after any
+		for {
+			_, result := postGraphql(t, query, map[string]any{"after": after}, tenantHeader, hooliId)
+			assert.Empty(t, result.Errors)
+
+			var page graphqlConnection
+			assert.NoError(t, json.Unmarshal(result.Data["persons"], &page))
+			for _, edge := range page.Edges {
+				ids = append(ids, edge.Node.Id)
+			}
+			if !page.PageInfo.HasNextPage {
+				break
+			}
+			after = *page.PageInfo.EndCursor
+		}
+		assert.Equal(t, []string{own.Id.String()}, ids)
+
+		_, result := postGraphql(t, fmt.Sprintf(`{ person(id: "%s") { id } }`, leaked.Id), nil, tenantHeader, hooliId)
+		assert.Equal(t, "null", string(result.Data["person"]))
+	})
+
+	t.Run("must not serve persons of other tenant by grpc", func(t *testing.T) {
+		client := personv1.NewPersonServiceClient(dialGrpc(t))
+		hooliCtx := metadata.AppendToOutgoingContext(ctx, strings.ToLower(tenantHeader), hooliId)
+
+		_, err := client.GetPerson(hooliCtx, &personv1.GetPersonRequest{Id: leaked.Id.String()})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+
+		stream, err := client.ListPersons(hooliCtx, &personv1.ListPersonsRequest{Login: "o.utechkin"})
+		assert.NoError(t, err)
+		var ids []string
+		for {
+			person, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			assert.NoError(t, err)
+			ids = append(ids, person.Id)
+		}
+		assert.Equal(t, []string{own.Id.String()}, ids)
+	})
+
+	t.Run("must not stream or replay events of other tenant", func(t *testing.T) {
+		frames := openEventStream(t, "", tenantHeader, hooliId)
+		marker := createTenantPerson(t, hooliId, `{"firstName": "Метка", "lastName": "Потокова", "age": 30}`)
+		markerFrame := nextPersonFrame(t, frames, marker.Id.String())
+
+		foreign := createTenantPerson(t, umbrellaId, `{"firstName": "Чужой", "lastName": "Потоков", "age": 31}`)
+		last := createTenantPerson(t, hooliId, `{"firstName": "Свой", "lastName": "Потоков", "age": 32}`)
+		assert.NotContains(t, personIdsUntil(t, frames, last.Id.String()), foreign.Id.String())
+
+		replayed := openEventStream(t, markerFrame["id"], tenantHeader, hooliId)
+		assert.NotContains(t, personIdsUntil(t, replayed, last.Id.String()), foreign.Id.String())
+
+		config, err := websocket.NewConfig("ws://localhost:9902/api/v1/persons/stream/ws?lastEventId="+markerFrame["id"], "http://localhost:9902")
+		assert.NoError(t, err)
+		config.Header = http.Header{tenantHeader: {hooliId}}
+		ws, err := websocket.DialConfig(config)
+		assert.NoError(t, err)
+		t.Cleanup(func() { _ = ws.Close() })
+		assert.NoError(t, ws.SetReadDeadline(time.Now().Add(10*time.Second)))
+		var message map[string]any
+		for message["personId"] != last.Id.String() {
+			message = nil
+			if !assert.NoError(t, websocket.JSON.Receive(ws, &message)) {
+				break
+			}
+			assert.NotEqual(t, foreign.Id.String(), message["personId"])
+		}
+	})
+
+	t.Run("must fan out webhooks to subscribers of event tenant only", func(t *testing.T) {
+		relay := events.NewRelay(logger, transactions, webhooks.NewDispatcher(transactions), config.Outbox{BatchSize: 100, Lease: time.Minute})
+		worker := webhooks.NewWorker(logger, transactions, &http.Client{}, config.Webhooks{BatchSize: 100, MaxAttempts: 1, Lease: time.Minute})
+		drain(t, relay)
+
+		receivers := map[string]*webhookReceiver{}
+		for _, tenantId := range []string{umbrellaId, hooliId} {
+			receiver := &webhookReceiver{secret: "tenant-secret", status: http.StatusOK}
+			server := httptest.NewServer(receiver)
+			t.Cleanup(server.Close)
+			receivers[tenantId] = receiver
+
+			resp, body := sendJson(t, http.MethodPost, "http://localhost:9902/api/v1/webhooks",
+				fmt.Sprintf(`{"url": "%s", "eventTypes": ["PersonCreated"], "secret": "tenant-secret"}`, server.URL), tenantHeader, tenantId)
+			assert.Equal(t, http.StatusCreated, resp.StatusCode, string(body))
+		}
+		umbrellaPerson := createTenantPerson(t, umbrellaId, `{"firstName": "Веб", "lastName": "Хуков", "age": 33}`)
+		hooliPerson := createTenantPerson(t, hooliId, `{"firstName": "Веб", "lastName": "Хукова", "age": 34}`)
+
+		drain(t, relay)
+		for {
+			processed, err := worker.ProcessBatch(ctx)
+			assert.NoError(t, err)
+			if err != nil || processed == 0 {
+				break
+			}
+		}
+
+		for tenantId, expected := range map[string]uuid.UUID{umbrellaId: umbrellaPerson.Id, hooliId: hooliPerson.Id} {
+			receiver := receivers[tenantId]
+			assert.Empty(t, receiver.errors)
+			assert.Len(t, receiver.received, 1, tenantId)
+			for _, event := range receiver.received {
+				assert.Equal(t, expected, event.PersonId)
+				assert.Equal(t, uuid.MustParse(tenantId), event.TenantId)
+			}
+		}
+	})
+}
+
+func createTenantPerson(t *testing.T, tenantId string, body string) model.PersonResponse {
+	resp, result := sendJson(t, http.MethodPost, "http://localhost:9902/api/v2/persons", body, tenantHeader, tenantId)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode, string(result))
+
+	var created model.PersonResponse
+	assert.NoError(t, json.Unmarshal(result, &created))
+	return created
+}
+
+// personIdsUntil ids of persons of frames up to frame of person, frame of person included.
+func personIdsUntil(t *testing.T, frames <-chan map[string]string, personId string) []string {
+	var ids []string
+	for frame := range frames {
+		var event events.Event
+		assert.NoError(t, json.Unmarshal([]byte(frame["data"]), &event))
+		ids = append(ids, event.PersonId.String())
+		if event.PersonId.String() == personId {
+			return ids
+		}
+	}
+	t.Fatalf("Event of person %s was not received", personId)
+	return nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"person-service/model"
+	"strings"
+	"testing"
+)
+
+func Test_PersonResourceApi(t *testing.T) {
+	client := &http.Client{}
+
+	t.Run("must return 201 with location when create person", func(t *testing.T) {
+		resp, err := http.Post(
+			"http://localhost:9902/api/v2/persons",
+			"application/json",
+			bytes.NewBufferString(`{"firstName": "Ольга", "lastName": "Петрова", "age": 29}`),
+		)
+		result := parseResponse(err, resp, t)
+
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, fmt.Sprintf("/api/v2/persons/%s", result.Id), resp.Header.Get("Location"))
+		assert.Empty(t, resp.Header.Get("Deprecation"))
+	})
+
+	t.Run("must patch only sent fields", func(t *testing.T) {
+		created := createPersonV2(t, `{"firstName": "Пётр", "lastName": "Кузнецов", "age": 40, "login": "p.kuznetsov"}`)
+
+		req, _ := http.NewRequest(
+			http.MethodPatch,
+			fmt.Sprintf("http://localhost:9902/api/v2/persons/%s", created.Id),
+			strings.NewReader(`{"age": 41}`),
+		)
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		resp, err := client.Do(req)
+		result := parseResponse(err, resp, t)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, 41, result.Age)
+		assert.Equal(t, "Пётр", result.FirstName)
+		assert.Equal(t, "p.kuznetsov", result.Login)
+		assert.True(t, result.UpdatedAt.After(created.UpdatedAt))
+	})
+
+	t.Run("must apply json patch and reject failed test operation", func(t *testing.T) {
+		created := createPersonV2(t, `{"firstName": "Лев", "lastName": "Зайцев", "age": 50, "login": "l.zaitsev"}`)
+		url := fmt.Sprintf("http://localhost:9902/api/v2/persons/%s", created.Id)
+
+		req, _ := http.NewRequest(http.MethodPatch, url, strings.NewReader(
+			`[{"op": "test", "path": "/age", "value": 50}, {"op": "replace", "path": "/age", "value": 51}, {"op": "remove", "path": "/login"}]`,
+		))
+		req.Header.Set("Content-Type", "application/json-patch+json")
+		resp, err := client.Do(req)
+		result := parseResponse(err, resp, t)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, 51, result.Age)
+		assert.Equal(t, "", result.Login)
+
+		req, _ = http.NewRequest(http.MethodPatch, url, strings.NewReader(
+			`[{"op": "test", "path": "/age", "value": 50}, {"op": "replace", "path": "/age", "value": 52}]`,
+		))
+		req.Header.Set("Content-Type", "application/json-patch+json")
+		resp, err = client.Do(req)
+		parseResponseBytes(err, t, resp)
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
+
+	t.Run("must return 400 when patched person is not valid", func(t *testing.T) {
+		created := createPersonV2(t, `{"firstName": "Вера", "lastName": "Лебедева", "age": 31}`)
+
+		req, _ := http.NewRequest(
+			http.MethodPatch,
+			fmt.Sprintf("http://localhost:9902/api/v2/persons/%s", created.Id),
+			strings.NewReader(`{"firstName": null}`),
+		)
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		resp, err := client.Do(req)
+		parseResponseBytes(err, t, resp)
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("must filter persons by login", func(t *testing.T) {
+		createPersonV2(t, `{"firstName": "Анна", "lastName": "Орлова", "age": 22, "login": "a.orlova"}`)
+
+		resp, err := http.Get("http://localhost:9902/api/v2/persons?login=a.orlova")
+		result := parseResponseBytes(err, t, resp)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, string(result), `"login":"a.orlova"`)
+
+		resp, err = http.Get("http://localhost:9902/api/v2/persons?login=unknown")
+		result = parseResponseBytes(err, t, resp)
+		assert.Equal(t, "[]\n", string(result))
+	})
+
+	t.Run("must return 204 on delete and 404 afterwards", func(t *testing.T) {
+		created := createPersonV2(t, `{"firstName": "Иван", "lastName": "Белов", "age": 35}`)
+		url := fmt.Sprintf("http://localhost:9902/api/v2/persons/%s", created.Id)
+
+		req, _ := http.NewRequest(http.MethodDelete, url, nil)
+		resp, err := client.Do(req)
+		parseResponseBytes(err, t, resp)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		resp, err = http.Get(url)
+		parseResponseBytes(err, t, resp)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("must mark v1 routes as deprecated", func(t *testing.T) {
+		resp, err := http.Get("http://localhost:9902/api/v1/persons")
+		parseResponseBytes(err, t, resp)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "true", resp.Header.Get("Deprecation"))
+		assert.NotEmpty(t, resp.Header.Get("Sunset"))
+		assert.Contains(t, resp.Header.Get("Link"), `</api/v2/persons>; rel="successor-version"`)
+	})
+}
+
+func createPersonV2(t *testing.T, body string) *model.PersonResponse {
+	resp, err := http.Post("http://localhost:9902/api/v2/persons", "application/json", bytes.NewBufferString(body))
+	result := parseResponse(err, resp, t)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	return result
+}
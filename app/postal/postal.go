@@ -0,0 +1,69 @@
+package postal
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// countries.json holds every ISO 3166-1 alpha-2 country: postalCode is pattern of upper case code,
+// noPostalCode marks countries without postal codes, empty rule accepts any or no code.
+//
+//go:embed countries.json
+var countriesJson []byte
+
+type rule struct {
+	PostalCode   string `json:"postalCode"`
+	NoPostalCode bool   `json:"noPostalCode"`
+
+	pattern *regexp.Regexp
+}
+
+var rules = mustLoadRules()
+
+func mustLoadRules() map[string]rule {
+	var loaded map[string]rule
+	if err := json.Unmarshal(countriesJson, &loaded); err != nil {
+		panic(fmt.Sprintf("postal: countries.json is not valid: %s", err))
+	}
+
+	for country, r := range loaded {
+		if r.PostalCode != "" {
+			r.pattern = regexp.MustCompile(r.PostalCode)
+			loaded[country] = r
+		}
+	}
+	return loaded
+}
+
+// NormalizeCountry returns upper case ISO 3166-1 alpha-2 code, false for unknown country.
+func NormalizeCountry(country string) (string, bool) {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	_, ok := rules[country]
+	return country, ok
+}
+
+// NormalizePostalCode validate postal code by rule of known country, returns upper case code without surrounding spaces.
+func NormalizePostalCode(country string, postalCode string) (string, error) {
+	r, ok := rules[country]
+	if !ok {
+		return "", fmt.Errorf("unknown country: %s", country)
+	}
+
+	postalCode = strings.ToUpper(strings.TrimSpace(postalCode))
+	switch {
+	case r.NoPostalCode && postalCode != "":
+		return "", fmt.Errorf("country %s does not use postal codes", country)
+	case r.NoPostalCode:
+		return "", nil
+	case r.pattern == nil:
+		return postalCode, nil
+	case postalCode == "":
+		return "", fmt.Errorf("postal code is required for country %s", country)
+	case !r.pattern.MatchString(postalCode):
+		return "", fmt.Errorf("postal code %s is not valid for country %s", postalCode, country)
+	}
+	return postalCode, nil
+}
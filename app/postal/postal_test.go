@@ -0,0 +1,43 @@
+package postal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_NormalizeCountry(t *testing.T) {
+	country, ok := NormalizeCountry(" de ")
+	assert.True(t, ok)
+	assert.Equal(t, "DE", country)
+
+	_, ok = NormalizeCountry("XX")
+	assert.False(t, ok)
+	assert.Len(t, rules, 249)
+}
+
+func Test_NormalizePostalCode(t *testing.T) {
+	for _, valid := range [][3]string{
+		{"RU", "101000", "101000"},
+		{"GB", "sw1a 1aa", "SW1A 1AA"},
+		{"CA", "K1A 0B1", "K1A 0B1"},
+		{"US", "94105-1804", "94105-1804"},
+		{"NL", "1011 ab", "1011 AB"},
+		{"AE", "", ""},
+		{"AF", "", ""},
+	} {
+		code, err := NormalizePostalCode(valid[0], valid[1])
+		assert.NoError(t, err, valid[1])
+		assert.Equal(t, valid[2], code)
+	}
+
+	for _, invalid := range [][2]string{
+		{"RU", "10100"},
+		{"DE", ""},
+		{"US", "9410"},
+		{"AE", "12345"},
+		{"XX", "12345"},
+	} {
+		_, err := NormalizePostalCode(invalid[0], invalid[1])
+		assert.Error(t, err, invalid[1])
+	}
+}
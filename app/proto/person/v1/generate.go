@@ -0,0 +1,4 @@
+// Package personv1 generated protobuf messages and grpc stubs of person api.
+package personv1
+
+//go:generate protoc -I ../../.. --go_out=../../.. --go_opt=paths=source_relative --go-grpc_out=../../.. --go-grpc_opt=paths=source_relative proto/person/v1/person_service.proto
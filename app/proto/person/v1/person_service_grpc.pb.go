@@ -0,0 +1,401 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: proto/person/v1/person_service.proto
+
+package personv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	PersonService_CreatePerson_FullMethodName     = "/person.v1.PersonService/CreatePerson"
+	PersonService_GetPerson_FullMethodName        = "/person.v1.PersonService/GetPerson"
+	PersonService_GetPersonByLogin_FullMethodName = "/person.v1.PersonService/GetPersonByLogin"
+	PersonService_UpdatePerson_FullMethodName     = "/person.v1.PersonService/UpdatePerson"
+	PersonService_DeletePerson_FullMethodName     = "/person.v1.PersonService/DeletePerson"
+	PersonService_ListPersons_FullMethodName      = "/person.v1.PersonService/ListPersons"
+	PersonService_WatchPersons_FullMethodName     = "/person.v1.PersonService/WatchPersons"
+)
+
+// PersonServiceClient is the client API for PersonService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PersonServiceClient interface {
+	// CreatePerson creates person, id is generated when it is empty.
+	CreatePerson(ctx context.Context, in *CreatePersonRequest, opts ...grpc.CallOption) (*Person, error)
+	// GetPerson returns person by id.
+	GetPerson(ctx context.Context, in *GetPersonRequest, opts ...grpc.CallOption) (*Person, error)
+	// GetPersonByLogin returns person by login.
+	GetPersonByLogin(ctx context.Context, in *GetPersonByLoginRequest, opts ...grpc.CallOption) (*Person, error)
+	// UpdatePerson replaces stored person.
+	UpdatePerson(ctx context.Context, in *UpdatePersonRequest, opts ...grpc.CallOption) (*Person, error)
+	// DeletePerson removes person.
+	DeletePerson(ctx context.Context, in *DeletePersonRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// ListPersons streams all persons ordered by id.
+	ListPersons(ctx context.Context, in *ListPersonsRequest, opts ...grpc.CallOption) (PersonService_ListPersonsClient, error)
+	// WatchPersons streams person events after given event id until client cancels.
+	WatchPersons(ctx context.Context, in *WatchPersonsRequest, opts ...grpc.CallOption) (PersonService_WatchPersonsClient, error)
+}
+
+type personServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPersonServiceClient(cc grpc.ClientConnInterface) PersonServiceClient {
+	return &personServiceClient{cc}
+}
+
+func (c *personServiceClient) CreatePerson(ctx context.Context, in *CreatePersonRequest, opts ...grpc.CallOption) (*Person, error) {
+	out := new(Person)
+	err := c.cc.Invoke(ctx, PersonService_CreatePerson_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *personServiceClient) GetPerson(ctx context.Context, in *GetPersonRequest, opts ...grpc.CallOption) (*Person, error) {
+	out := new(Person)
+	err := c.cc.Invoke(ctx, PersonService_GetPerson_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *personServiceClient) GetPersonByLogin(ctx context.Context, in *GetPersonByLoginRequest, opts ...grpc.CallOption) (*Person, error) {
+	out := new(Person)
+	err := c.cc.Invoke(ctx, PersonService_GetPersonByLogin_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *personServiceClient) UpdatePerson(ctx context.Context, in *UpdatePersonRequest, opts ...grpc.CallOption) (*Person, error) {
+	out := new(Person)
+	err := c.cc.Invoke(ctx, PersonService_UpdatePerson_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *personServiceClient) DeletePerson(ctx context.Context, in *DeletePersonRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, PersonService_DeletePerson_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *personServiceClient) ListPersons(ctx context.Context, in *ListPersonsRequest, opts ...grpc.CallOption) (PersonService_ListPersonsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &PersonService_ServiceDesc.Streams[0], PersonService_ListPersons_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &personServiceListPersonsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type PersonService_ListPersonsClient interface {
+	Recv() (*Person, error)
+	grpc.ClientStream
+}
+
+type personServiceListPersonsClient struct {
+	grpc.ClientStream
+}
+
+func (x *personServiceListPersonsClient) Recv() (*Person, error) {
+	m := new(Person)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *personServiceClient) WatchPersons(ctx context.Context, in *WatchPersonsRequest, opts ...grpc.CallOption) (PersonService_WatchPersonsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &PersonService_ServiceDesc.Streams[1], PersonService_WatchPersons_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &personServiceWatchPersonsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type PersonService_WatchPersonsClient interface {
+	Recv() (*PersonEvent, error)
+	grpc.ClientStream
+}
+
+type personServiceWatchPersonsClient struct {
+	grpc.ClientStream
+}
+
+func (x *personServiceWatchPersonsClient) Recv() (*PersonEvent, error) {
+	m := new(PersonEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// PersonServiceServer is the server API for PersonService service.
+// All implementations must embed UnimplementedPersonServiceServer
+// for forward compatibility
+type PersonServiceServer interface {
+	// CreatePerson creates person, id is generated when it is empty.
+	CreatePerson(context.Context, *CreatePersonRequest) (*Person, error)
+	// GetPerson returns person by id.
+	GetPerson(context.Context, *GetPersonRequest) (*Person, error)
+	// GetPersonByLogin returns person by login.
+	GetPersonByLogin(context.Context, *GetPersonByLoginRequest) (*Person, error)
+	// UpdatePerson replaces stored person.
+	UpdatePerson(context.Context, *UpdatePersonRequest) (*Person, error)
+	// DeletePerson removes person.
+	DeletePerson(context.Context, *DeletePersonRequest) (*emptypb.Empty, error)
+	// ListPersons streams all persons ordered by id.
+	ListPersons(*ListPersonsRequest, PersonService_ListPersonsServer) error
+	// WatchPersons streams person events after given event id until client cancels.
+	WatchPersons(*WatchPersonsRequest, PersonService_WatchPersonsServer) error
+	mustEmbedUnimplementedPersonServiceServer()
+}
+
+// UnimplementedPersonServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedPersonServiceServer struct {
+}
+
+func (UnimplementedPersonServiceServer) CreatePerson(context.Context, *CreatePersonRequest) (*Person, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePerson not implemented")
+}
+func (UnimplementedPersonServiceServer) GetPerson(context.Context, *GetPersonRequest) (*Person, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPerson not implemented")
+}
+func (UnimplementedPersonServiceServer) GetPersonByLogin(context.Context, *GetPersonByLoginRequest) (*Person, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPersonByLogin not implemented")
+}
+func (UnimplementedPersonServiceServer) UpdatePerson(context.Context, *UpdatePersonRequest) (*Person, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdatePerson not implemented")
+}
+func (UnimplementedPersonServiceServer) DeletePerson(context.Context, *DeletePersonRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletePerson not implemented")
+}
+func (UnimplementedPersonServiceServer) ListPersons(*ListPersonsRequest, PersonService_ListPersonsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListPersons not implemented")
+}
+func (UnimplementedPersonServiceServer) WatchPersons(*WatchPersonsRequest, PersonService_WatchPersonsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchPersons not implemented")
+}
+func (UnimplementedPersonServiceServer) mustEmbedUnimplementedPersonServiceServer() {}
+
+// UnsafePersonServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PersonServiceServer will
+// result in compilation errors.
+type UnsafePersonServiceServer interface {
+	mustEmbedUnimplementedPersonServiceServer()
+}
+
+func RegisterPersonServiceServer(s grpc.ServiceRegistrar, srv PersonServiceServer) {
+	s.RegisterService(&PersonService_ServiceDesc, srv)
+}
+
+func _PersonService_CreatePerson_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreatePersonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PersonServiceServer).CreatePerson(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PersonService_CreatePerson_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PersonServiceServer).CreatePerson(ctx, req.(*CreatePersonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PersonService_GetPerson_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPersonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PersonServiceServer).GetPerson(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PersonService_GetPerson_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PersonServiceServer).GetPerson(ctx, req.(*GetPersonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PersonService_GetPersonByLogin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPersonByLoginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PersonServiceServer).GetPersonByLogin(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PersonService_GetPersonByLogin_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PersonServiceServer).GetPersonByLogin(ctx, req.(*GetPersonByLoginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PersonService_UpdatePerson_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdatePersonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PersonServiceServer).UpdatePerson(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PersonService_UpdatePerson_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PersonServiceServer).UpdatePerson(ctx, req.(*UpdatePersonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PersonService_DeletePerson_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeletePersonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PersonServiceServer).DeletePerson(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PersonService_DeletePerson_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PersonServiceServer).DeletePerson(ctx, req.(*DeletePersonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PersonService_ListPersons_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListPersonsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PersonServiceServer).ListPersons(m, &personServiceListPersonsServer{stream})
+}
+
+type PersonService_ListPersonsServer interface {
+	Send(*Person) error
+	grpc.ServerStream
+}
+
+type personServiceListPersonsServer struct {
+	grpc.ServerStream
+}
+
+func (x *personServiceListPersonsServer) Send(m *Person) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _PersonService_WatchPersons_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchPersonsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PersonServiceServer).WatchPersons(m, &personServiceWatchPersonsServer{stream})
+}
+
+type PersonService_WatchPersonsServer interface {
+	Send(*PersonEvent) error
+	grpc.ServerStream
+}
+
+type personServiceWatchPersonsServer struct {
+	grpc.ServerStream
+}
+
+func (x *personServiceWatchPersonsServer) Send(m *PersonEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// PersonService_ServiceDesc is the grpc.ServiceDesc for PersonService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PersonService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "person.v1.PersonService",
+	HandlerType: (*PersonServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreatePerson",
+			Handler:    _PersonService_CreatePerson_Handler,
+		},
+		{
+			MethodName: "GetPerson",
+			Handler:    _PersonService_GetPerson_Handler,
+		},
+		{
+			MethodName: "GetPersonByLogin",
+			Handler:    _PersonService_GetPersonByLogin_Handler,
+		},
+		{
+			MethodName: "UpdatePerson",
+			Handler:    _PersonService_UpdatePerson_Handler,
+		},
+		{
+			MethodName: "DeletePerson",
+			Handler:    _PersonService_DeletePerson_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListPersons",
+			Handler:       _PersonService_ListPersons_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchPersons",
+			Handler:       _PersonService_WatchPersons_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/person/v1/person_service.proto",
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+	"person-service/db/entity"
+	"testing"
+)
+
+/*
+Benchmarks compare pgx repository with the previous lib/pq implementation (database/sql, no statement cache).
+Run with: CONFIG_PATH=./configuration/application-test.yaml go test -run '^$' -bench . -benchmem
+*/
+
+const bulkSize = 1000
+
+func Benchmark_SavePerson_Pgx(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := storage.SavePerson(ctx, benchPerson(i)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_SavePerson_LibPq(b *testing.B) {
+	db := openLibPq(b)
+	today := storage.Today()
+
+	for i := 0; i < b.N; i++ {
+		p := benchPerson(i)
+		_, err := db.Exec(
+			`INSERT INTO person(id, first_name, last_name, birth_date) VALUES ($1, $2, $3, $4)`,
+			uuid.New().String(), p.FirstName, p.LastName, entity.BirthDateAt(p.Age, today),
+		)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_FindPersonById_Pgx(b *testing.B) {
+	saved, err := storage.SavePerson(ctx, benchPerson(0))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := storage.FindPersonById(ctx, saved.Id); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_FindPersonById_LibPq(b *testing.B) {
+	db := openLibPq(b)
+	saved, err := storage.SavePerson(ctx, benchPerson(0))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var person entity.Person
+		err := db.QueryRow(
+			`SELECT p.id, p.first_name, p.last_name, p.birth_date, COALESCE(p.login, ''), p.created_at, p.updated_at FROM person p WHERE p.id = $1`,
+			saved.Id.String(),
+		).Scan(&person.Id, &person.FirstName, &person.LastName, &person.BirthDate, &person.Login, &person.CreatedAt, &person.UpdatedAt)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_BulkInsert_PgxCopy(b *testing.B) {
+	persons := make([]entity.Person, bulkSize)
+	for i := range persons {
+		persons[i] = benchPerson(i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := storage.SavePersons(ctx, persons); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_BulkInsert_LibPq(b *testing.B) {
+	db := openLibPq(b)
+	today := storage.Today()
+
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < bulkSize; j++ {
+			p := benchPerson(j)
+			_, err := db.Exec(
+				`INSERT INTO person(id, first_name, last_name, birth_date) VALUES ($1, $2, $3, $4)`,
+				uuid.New().String(), p.FirstName, p.LastName, entity.BirthDateAt(p.Age, today),
+			)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func benchPerson(i int) entity.Person {
+	return entity.Person{FirstName: "Алексей", LastName: fmt.Sprintf("Сидоров-%d", i), Age: 18 + i%50}
+}
+
+func openLibPq(b *testing.B) *sql.DB {
+	datasource := configuration.Datasource
+	db, err := sql.Open("postgres", fmt.Sprintf(
+		"host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
+		datasource.Host, datasource.Port, datasource.User, datasource.Password, datasource.DbName,
+	))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { _ = db.Close() })
+
+	return db
+}
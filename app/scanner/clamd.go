@@ -0,0 +1,105 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+const clamdChunkSize = 64 << 10
+
+// Clamd scanner of local clamd daemon, content is streamed by INSTREAM command.
+// Address is host:port of TCP socket or path of unix socket.
+type Clamd struct {
+	address string
+	timeout time.Duration
+}
+
+func NewClamd(address string, timeout time.Duration) *Clamd {
+	return &Clamd{address: address, timeout: timeout}
+}
+
+func (c *Clamd) Scan(ctx context.Context, content io.Reader) (Verdict, error) {
+	network := "tcp"
+	if strings.HasPrefix(c.address, "/") {
+		network = "unix"
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, c.address)
+	if err != nil {
+		return Verdict{}, fmt.Errorf("connect clamd: %w", err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(c.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return Verdict{}, err
+	}
+
+	if err := stream(conn, content); err != nil {
+		return Verdict{}, fmt.Errorf("stream to clamd: %w", err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil {
+		return Verdict{}, fmt.Errorf("read clamd reply: %w", err)
+	}
+	return parseReply(strings.TrimSuffix(reply, "\x00"))
+}
+
+// stream send null terminated INSTREAM command and content as chunks prefixed by big-endian length, empty chunk ends it.
+func stream(conn net.Conn, content io.Reader) error {
+	writer := bufio.NewWriterSize(conn, clamdChunkSize+4)
+	if _, err := writer.WriteString("zINSTREAM\x00"); err != nil {
+		return err
+	}
+
+	chunk := make([]byte, clamdChunkSize)
+	var length [4]byte
+	for {
+		n, err := io.ReadFull(content, chunk)
+		if n > 0 {
+			binary.BigEndian.PutUint32(length[:], uint32(n))
+			if _, err := writer.Write(length[:]); err != nil {
+				return err
+			}
+			if _, err := writer.Write(chunk[:n]); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	binary.BigEndian.PutUint32(length[:], 0)
+	if _, err := writer.Write(length[:]); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+// parseReply "stream: OK", "stream: <threat> FOUND" or "<message> ERROR".
+func parseReply(reply string) (Verdict, error) {
+	result := strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+	switch {
+	case result == "OK":
+		return Verdict{}, nil
+	case strings.HasSuffix(result, " FOUND"):
+		return Verdict{Threat: strings.TrimSuffix(result, " FOUND")}, nil
+	default:
+		return Verdict{}, fmt.Errorf("clamd: %s", reply)
+	}
+}
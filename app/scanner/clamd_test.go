@@ -0,0 +1,95 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+/* marker recognized by stand-in, the real test file is not kept in repository so local antivirus leaves it alone */
+const eicar = "EICAR-STANDARD-ANTIVIRUS-TEST-FILE"
+
+func Test_Clamd(t *testing.T) {
+	address := startClamdStandIn(t)
+	scanner := NewClamd(address, time.Second)
+
+	t.Run("must accept clean content", func(t *testing.T) {
+		verdict, err := scanner.Scan(context.Background(), strings.NewReader(strings.Repeat("contract ", 20_000)))
+
+		require.NoError(t, err)
+		assert.True(t, verdict.Clean())
+	})
+
+	t.Run("must report found threat", func(t *testing.T) {
+		verdict, err := scanner.Scan(context.Background(), strings.NewReader(eicar))
+
+		require.NoError(t, err)
+		assert.False(t, verdict.Clean())
+		assert.Equal(t, "Eicar-Test-Signature", verdict.Threat)
+	})
+
+	t.Run("must fail when clamd is not reachable", func(t *testing.T) {
+		_, err := NewClamd("127.0.0.1:1", time.Second).Scan(context.Background(), strings.NewReader("x"))
+
+		assert.ErrorContains(t, err, "connect clamd")
+	})
+}
+
+func Test_ParseReply(t *testing.T) {
+	_, err := parseReply("INSTREAM size limit exceeded. ERROR")
+
+	assert.EqualError(t, err, "clamd: INSTREAM size limit exceeded. ERROR")
+}
+
+// startClamdStandIn clamd speaking INSTREAM, it finds EICAR test signature.
+func startClamdStandIn(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				if command, err := reader.ReadString(0); err != nil || command != "zINSTREAM\x00" {
+					_, _ = conn.Write([]byte("UNKNOWN COMMAND\x00"))
+					return
+				}
+
+				var content bytes.Buffer
+				for {
+					var length uint32
+					if err := binary.Read(reader, binary.BigEndian, &length); err != nil {
+						return
+					}
+					if length == 0 {
+						break
+					}
+					if _, err := io.CopyN(&content, reader, int64(length)); err != nil {
+						return
+					}
+				}
+
+				if bytes.Contains(content.Bytes(), []byte("EICAR-STANDARD-ANTIVIRUS-TEST-FILE")) {
+					_, _ = conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+				} else {
+					_, _ = conn.Write([]byte("stream: OK\x00"))
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
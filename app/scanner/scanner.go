@@ -0,0 +1,27 @@
+package scanner
+
+import (
+	"context"
+	"io"
+)
+
+// Verdict result of scan, Threat is name of found threat, empty for clean content.
+type Verdict struct {
+	Threat string
+}
+
+func (v Verdict) Clean() bool {
+	return v.Threat == ""
+}
+
+// Scanner checks content before it is stored, error means content was not scanned.
+type Scanner interface {
+	Scan(ctx context.Context, content io.Reader) (Verdict, error)
+}
+
+// Noop accepts any content without reading it.
+type Noop struct{}
+
+func (Noop) Scan(context.Context, io.Reader) (Verdict, error) {
+	return Verdict{}, nil
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"person-service/db/entity"
+	"person-service/db/repository"
+	"person-service/events"
+	"person-service/mappers"
+	"person-service/postal"
+	"strings"
+	"unicode/utf8"
+)
+
+var addressTypes = map[string]bool{"home": true, "work": true, "billing": true, "shipping": true, "other": true}
+
+const (
+	defaultAddressType = "other"
+	maxAddressLines    = 4
+	maxAddressLine     = 200
+)
+
+/* rolls back transaction of change which did not change addresses */
+var errAddressNotChanged = errors.New("address was not changed")
+
+// AddressService postal addresses of person. Address change updates person like change of its fields does:
+// updated_at of person is set and PersonUpdated event with all addresses of person is written.
+type AddressService struct {
+	transactions *repository.TxManager
+}
+
+func NewAddressService(transactions *repository.TxManager) *AddressService {
+	return &AddressService{transactions: transactions}
+}
+
+// CreateAddress validate, normalize and save address of existing person.
+func (s *AddressService) CreateAddress(ctx context.Context, address entity.Address) (entity.Address, error) {
+	const op = "services.CreateAddress"
+
+	if err := normalizeAddress(&address); err != nil {
+		return entity.Address{}, err
+	}
+
+	var saved entity.Address
+	err := s.changeAddresses(ctx, address.PersonId, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		address.Id = uuid.New()
+
+		var err error
+		saved, err = uow.Addresses.SaveAddress(ctx, address)
+		return err
+	})
+	if err != nil {
+		return entity.Address{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return saved, nil
+}
+
+// UpdateAddress validate, normalize and replace address of person, returns pgx.ErrNoRows for unknown address.
+func (s *AddressService) UpdateAddress(ctx context.Context, address entity.Address) (entity.Address, error) {
+	const op = "services.UpdateAddress"
+
+	if err := normalizeAddress(&address); err != nil {
+		return entity.Address{}, err
+	}
+
+	var updated entity.Address
+	err := s.changeAddresses(ctx, address.PersonId, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		var err error
+		updated, err = uow.Addresses.UpdateAddress(ctx, address)
+		return err
+	})
+	if err != nil {
+		return entity.Address{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return updated, nil
+}
+
+// DeleteAddress delete address of person, returns false for unknown address.
+func (s *AddressService) DeleteAddress(ctx context.Context, personId uuid.UUID, id uuid.UUID) (bool, error) {
+	const op = "services.DeleteAddress"
+
+	var deleted bool
+	err := s.changeAddresses(ctx, personId, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		var err error
+		if deleted, err = uow.Addresses.DeleteAddress(ctx, personId, id); err == nil && !deleted {
+			/* nothing was changed, event is not written */
+			return errAddressNotChanged
+		}
+		return err
+	})
+	if errors.Is(err, errAddressNotChanged) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return deleted, nil
+}
+
+// FindAddress find address of person by id.
+func (s *AddressService) FindAddress(ctx context.Context, personId uuid.UUID, id uuid.UUID) (entity.Address, error) {
+	return s.transactions.Repositories().Addresses.FindAddress(ctx, personId, id)
+}
+
+// LoadPersonAddresses load addresses of existing person, returns pgx.ErrNoRows for unknown person.
+func (s *AddressService) LoadPersonAddresses(ctx context.Context, personId uuid.UUID) ([]entity.Address, error) {
+	const op = "services.LoadPersonAddresses"
+
+	repositories := s.transactions.Repositories()
+	if _, err := repositories.Persons.FindPersonById(ctx, &personId); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	addresses, err := repositories.Addresses.LoadAddresses(ctx, []uuid.UUID{personId})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return addresses[personId], nil
+}
+
+// LoadAddresses load addresses of persons grouped by person id, used to expand person responses.
+func (s *AddressService) LoadAddresses(ctx context.Context, personIds []uuid.UUID) (map[uuid.UUID][]entity.Address, error) {
+	return s.transactions.Repositories().Addresses.LoadAddresses(ctx, personIds)
+}
+
+// changeAddresses runs change of addresses with locked person, then touches person and writes PersonUpdated event.
+func (s *AddressService) changeAddresses(ctx context.Context, personId uuid.UUID, change func(ctx context.Context, uow *repository.UnitOfWork) error) error {
+	return s.transactions.WithinTransaction(ctx, repository.TxOptions{}, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		if _, err := uow.Persons.FindPersonByIdForUpdate(ctx, &personId); err != nil {
+			return err
+		}
+		if err := change(ctx, uow); err != nil {
+			return err
+		}
+
+		person, err := uow.Persons.TouchPerson(ctx, personId)
+		if err != nil {
+			return err
+		}
+		addresses, err := uow.Addresses.LoadAddresses(ctx, []uuid.UUID{personId})
+		if err != nil {
+			return err
+		}
+
+		response := mappers.ToPersonResponse(person)
+		response.Addresses = mappers.ToAddressesResponse(addresses[personId])
+		return appendPersonResponseEvent(ctx, uow, events.PersonUpdated, response)
+	})
+}
+
+func normalizeAddress(address *entity.Address) error {
+	address.Type = strings.ToLower(strings.TrimSpace(address.Type))
+	if address.Type == "" {
+		address.Type = defaultAddressType
+	}
+	if !addressTypes[address.Type] {
+		return &ValidationError{Message: fmt.Sprintf("Unknown address type: %s", address.Type)}
+	}
+
+	lines := make([]string, 0, len(address.Lines))
+	for _, line := range address.Lines {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+		if utf8.RuneCountInString(line) > maxAddressLine {
+			return &ValidationError{Message: fmt.Sprintf("Address line must not be longer than %d characters", maxAddressLine)}
+		}
+	}
+	if len(lines) == 0 || len(lines) > maxAddressLines {
+		return &ValidationError{Message: fmt.Sprintf("Field lines must have from 1 to %d non-empty lines", maxAddressLines)}
+	}
+	address.Lines = lines
+
+	address.City = strings.TrimSpace(address.City)
+	if address.City == "" {
+		return &ValidationError{Message: "Field city must not be empty"}
+	}
+	address.Region = strings.TrimSpace(address.Region)
+
+	country, ok := postal.NormalizeCountry(address.Country)
+	if !ok {
+		return &ValidationError{Message: fmt.Sprintf("Field country must be ISO 3166-1 alpha-2 code: %s", address.Country)}
+	}
+	address.Country = country
+
+	postalCode, err := postal.NormalizePostalCode(country, address.PostalCode)
+	if err != nil {
+		return &ValidationError{Message: fmt.Sprintf("Field postalCode is not valid: %s", err)}
+	}
+	address.PostalCode = postalCode
+
+	if address.ValidFrom != nil && address.ValidTo != nil && address.ValidTo.Before(*address.ValidFrom) {
+		return &ValidationError{Message: "Field validTo must not be before validFrom"}
+	}
+	return nil
+}
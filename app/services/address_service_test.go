@@ -0,0 +1,42 @@
+package services
+
+import (
+	"github.com/stretchr/testify/assert"
+	"person-service/db/entity"
+	"testing"
+	"time"
+)
+
+func Test_NormalizeAddress(t *testing.T) {
+	t.Run("address is trimmed and normalized", func(t *testing.T) {
+		address := entity.Address{
+			Lines:      []string{" ul. Tverskaya, 1 ", "", "kv. 5"},
+			City:       " Moscow ",
+			PostalCode: "125009",
+			Country:    "ru",
+		}
+
+		assert.NoError(t, normalizeAddress(&address))
+		assert.Equal(t, []string{"ul. Tverskaya, 1", "kv. 5"}, address.Lines)
+		assert.Equal(t, "Moscow", address.City)
+		assert.Equal(t, "RU", address.Country)
+		assert.Equal(t, defaultAddressType, address.Type)
+	})
+
+	t.Run("invalid address is rejected", func(t *testing.T) {
+		from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+		to := from.AddDate(0, 0, -1)
+
+		for name, address := range map[string]entity.Address{
+			"unknown type":        {Type: "summer", Lines: []string{"1 Main St"}, City: "Boston", PostalCode: "02110", Country: "US"},
+			"no lines":            {Lines: []string{" "}, City: "Boston", PostalCode: "02110", Country: "US"},
+			"no city":             {Lines: []string{"1 Main St"}, PostalCode: "02110", Country: "US"},
+			"unknown country":     {Lines: []string{"1 Main St"}, City: "Boston", PostalCode: "02110", Country: "USA"},
+			"invalid postal code": {Lines: []string{"1 Main St"}, City: "Boston", PostalCode: "0211", Country: "US"},
+			"inverted validity":   {Lines: []string{"1 Main St"}, City: "Boston", PostalCode: "02110", Country: "US", ValidFrom: &from, ValidTo: &to},
+		} {
+			var validationErr *ValidationError
+			assert.ErrorAs(t, normalizeAddress(&address), &validationErr, name)
+		}
+	})
+}
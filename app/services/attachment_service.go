@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"person-service/blob"
+	"person-service/db/entity"
+	"person-service/db/repository"
+	"person-service/scanner"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	maxFileName = 255
+	/* files deleted from blob store in one transaction of purge */
+	purgeBatchSize = 100
+)
+
+var sha256Hex = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// ErrAttachmentTooLarge content of attachment is over max size.
+var ErrAttachmentTooLarge = errors.New("attachment is too large")
+
+// InfectedError content of attachment is rejected by scanner.
+type InfectedError struct {
+	Threat string
+}
+
+func (e *InfectedError) Error() string {
+	return fmt.Sprintf("attachment is infected: %s", e.Threat)
+}
+
+// AttachmentService documents of persons. Upload is spooled to temporary file while SHA-256 checksum is computed,
+// scanned and only then stored in blob store. Files of deleted attachments (by api, retention or person delete)
+// are queued by database trigger and deleted from blob store by PurgeAttachments.
+type AttachmentService struct {
+	transactions *repository.TxManager
+	store        blob.Store
+	scanner      scanner.Scanner
+	maxSize      int64
+	tempDir      string
+}
+
+func NewAttachmentService(transactions *repository.TxManager, store blob.Store, scanner scanner.Scanner, maxSize int64, tempDir string) *AttachmentService {
+	return &AttachmentService{transactions: transactions, store: store, scanner: scanner, maxSize: maxSize, tempDir: tempDir}
+}
+
+// CreateAttachment store content and save attachment of existing person. Sha256 of attachment is expected checksum
+// when it is set, content type is sniffed when it is absent or generic.
+func (s *AttachmentService) CreateAttachment(ctx context.Context, attachment entity.Attachment, content io.Reader) (entity.Attachment, error) {
+	const op = "services.CreateAttachment"
+
+	repositories := s.transactions.Repositories()
+	if err := normalizeAttachment(&attachment, repositories.Persons.Today()); err != nil {
+		return entity.Attachment{}, err
+	}
+	if _, err := repositories.Persons.FindPersonById(ctx, &attachment.PersonId); err != nil {
+		return entity.Attachment{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	file, err := os.CreateTemp(s.tempDir, "attachment-*")
+	if err != nil {
+		return entity.Attachment{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+	}()
+
+	if err := s.spool(file, content, &attachment); err != nil {
+		return entity.Attachment{}, err
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return entity.Attachment{}, fmt.Errorf("%s: %w", op, err)
+	}
+	verdict, err := s.scanner.Scan(ctx, file)
+	if err != nil {
+		return entity.Attachment{}, fmt.Errorf("%s: scan: %w", op, err)
+	}
+	if !verdict.Clean() {
+		return entity.Attachment{}, &InfectedError{Threat: verdict.Threat}
+	}
+
+	attachment.Id = uuid.New()
+	attachment.BlobKey = fmt.Sprintf("attachments/%s/%s", attachment.PersonId, attachment.Id)
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return entity.Attachment{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := s.store.Put(ctx, attachment.BlobKey, file, attachment.Size, attachment.ContentType); err != nil {
+		return entity.Attachment{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	saved, err := repositories.Attachments.SaveAttachment(ctx, attachment)
+	if err != nil {
+		/* row was not saved, so trigger does not queue the file */
+		_ = s.store.Delete(ctx, attachment.BlobKey)
+		return entity.Attachment{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return saved, nil
+}
+
+// FindAttachment find attachment of person by id.
+func (s *AttachmentService) FindAttachment(ctx context.Context, personId uuid.UUID, id uuid.UUID) (entity.Attachment, error) {
+	return s.transactions.Repositories().Attachments.FindAttachment(ctx, personId, id)
+}
+
+// LoadAttachments load attachments of existing person, returns pgx.ErrNoRows for unknown person.
+func (s *AttachmentService) LoadAttachments(ctx context.Context, personId uuid.UUID) ([]entity.Attachment, error) {
+	const op = "services.LoadAttachments"
+
+	repositories := s.transactions.Repositories()
+	if _, err := repositories.Persons.FindPersonById(ctx, &personId); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	attachments, err := repositories.Attachments.LoadAttachments(ctx, personId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return attachments, nil
+}
+
+// OpenAttachment find attachment and open its content for reading, reader must be closed.
+func (s *AttachmentService) OpenAttachment(ctx context.Context, personId uuid.UUID, id uuid.UUID) (entity.Attachment, io.ReadCloser, error) {
+	const op = "services.OpenAttachment"
+
+	attachment, err := s.FindAttachment(ctx, personId, id)
+	if err != nil {
+		return entity.Attachment{}, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	content, err := s.store.Get(ctx, attachment.BlobKey)
+	if err != nil {
+		return entity.Attachment{}, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return attachment, content, nil
+}
+
+// UpdateRetention set retention date of attachment, nil keeps attachment until it is deleted.
+func (s *AttachmentService) UpdateRetention(ctx context.Context, personId uuid.UUID, id uuid.UUID, retainUntil *time.Time) (entity.Attachment, error) {
+	const op = "services.UpdateRetention"
+
+	repositories := s.transactions.Repositories()
+	if err := checkRetention(retainUntil, repositories.Persons.Today()); err != nil {
+		return entity.Attachment{}, err
+	}
+
+	updated, err := repositories.Attachments.UpdateRetention(ctx, personId, id, retainUntil)
+	if err != nil {
+		return entity.Attachment{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return updated, nil
+}
+
+// DeleteAttachment delete attachment of person, its file is purged later. Returns false for unknown id.
+func (s *AttachmentService) DeleteAttachment(ctx context.Context, personId uuid.UUID, id uuid.UUID) (bool, error) {
+	const op = "services.DeleteAttachment"
+
+	deleted, err := s.transactions.Repositories().Attachments.DeleteAttachment(ctx, personId, id)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return deleted, nil
+}
+
+// PurgeAttachments delete attachments past retention date and delete queued files from blob store.
+// Replicas purge concurrently, each one takes keys not locked by others.
+func (s *AttachmentService) PurgeAttachments(ctx context.Context) (expired int64, purged int, err error) {
+	const op = "services.PurgeAttachments"
+
+	repositories := s.transactions.Repositories()
+	if expired, err = repositories.Attachments.DeleteExpired(ctx, repositories.Persons.Today()); err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for {
+		var keys []string
+		err = s.transactions.WithinTransaction(ctx, repository.TxOptions{}, func(ctx context.Context, uow *repository.UnitOfWork) error {
+			var err error
+			if keys, err = uow.Attachments.LockPurge(ctx, purgeBatchSize); err != nil || len(keys) == 0 {
+				return err
+			}
+			for _, key := range keys {
+				if err := s.store.Delete(ctx, key); err != nil {
+					return err
+				}
+			}
+			return uow.Attachments.DeletePurge(ctx, keys)
+		})
+		if err != nil {
+			return expired, purged, fmt.Errorf("%s: %w", op, err)
+		}
+
+		purged += len(keys)
+		if len(keys) < purgeBatchSize {
+			return expired, purged, nil
+		}
+	}
+}
+
+// spool copy content to file while size and checksum are computed, generic content type is sniffed from file.
+func (s *AttachmentService) spool(file *os.File, content io.Reader, attachment *entity.Attachment) error {
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(file, hash), io.LimitReader(content, s.maxSize+1))
+	switch {
+	case err != nil:
+		return err
+	case size > s.maxSize:
+		return ErrAttachmentTooLarge
+	case size == 0:
+		return &ValidationError{Message: "Attachment content must not be empty"}
+	}
+
+	checksum := hex.EncodeToString(hash.Sum(nil))
+	if attachment.Sha256 != "" && attachment.Sha256 != checksum {
+		return &ValidationError{Message: fmt.Sprintf("Checksum mismatch, SHA-256 of received content is %s", checksum)}
+	}
+	attachment.Sha256 = checksum
+	attachment.Size = size
+
+	if attachment.ContentType == "" || attachment.ContentType == "application/octet-stream" {
+		head := make([]byte, 512)
+		n, err := file.ReadAt(head, 0)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		attachment.ContentType = http.DetectContentType(head[:n])
+	}
+	return nil
+}
+
+// normalizeAttachment validate metadata sent with content, file name is reduced to base name without control characters.
+func normalizeAttachment(attachment *entity.Attachment, today time.Time) error {
+	name := attachment.FileName
+	if index := strings.LastIndexAny(name, `/\`); index >= 0 {
+		name = name[index+1:]
+	}
+	name = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name))
+	switch {
+	case name == "" || name == "." || name == "..":
+		return &ValidationError{Message: "File name is required"}
+	case !utf8.ValidString(name) || len(name) > maxFileName:
+		return &ValidationError{Message: fmt.Sprintf("File name must be valid UTF-8 up to %d bytes", maxFileName)}
+	}
+	attachment.FileName = name
+
+	if attachment.ContentType != "" {
+		mediaType, params, err := mime.ParseMediaType(attachment.ContentType)
+		if err != nil {
+			return &ValidationError{Message: "Content-Type is not valid media type"}
+		}
+		attachment.ContentType = mime.FormatMediaType(mediaType, params)
+	}
+
+	attachment.Sha256 = strings.ToLower(strings.TrimSpace(attachment.Sha256))
+	if attachment.Sha256 != "" && !sha256Hex.MatchString(attachment.Sha256) {
+		return &ValidationError{Message: "Checksum must be SHA-256 in hex"}
+	}
+
+	return checkRetention(attachment.RetainUntil, today)
+}
+
+func checkRetention(retainUntil *time.Time, today time.Time) error {
+	if retainUntil != nil && retainUntil.Before(today) {
+		return &ValidationError{Message: "Field retainUntil must not be in the past"}
+	}
+	return nil
+}
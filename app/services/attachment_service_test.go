@@ -0,0 +1,64 @@
+package services
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"person-service/db/entity"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_NormalizeAttachment(t *testing.T) {
+	today := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	t.Run("must reduce file name to base name", func(t *testing.T) {
+		attachment := entity.Attachment{FileName: " C:\\scans/../contract\x00 2024.pdf ", ContentType: "Application/PDF", Sha256: strings.Repeat("AB", 32)}
+
+		require.NoError(t, normalizeAttachment(&attachment, today))
+		assert.Equal(t, "contract 2024.pdf", attachment.FileName)
+		assert.Equal(t, "application/pdf", attachment.ContentType)
+		assert.Equal(t, strings.Repeat("ab", 32), attachment.Sha256)
+	})
+
+	t.Run("must reject invalid metadata", func(t *testing.T) {
+		yesterday := today.AddDate(0, 0, -1)
+		for expected, attachment := range map[string]entity.Attachment{
+			"File name is required":                     {FileName: "../"},
+			"Content-Type is not valid media type":      {FileName: "a.pdf", ContentType: "pdf;;"},
+			"Checksum must be SHA-256 in hex":           {FileName: "a.pdf", Sha256: "md5"},
+			"Field retainUntil must not be in the past": {FileName: "a.pdf", RetainUntil: &yesterday},
+		} {
+			assert.EqualError(t, normalizeAttachment(&attachment, today), expected)
+		}
+		assert.NoError(t, normalizeAttachment(&entity.Attachment{FileName: "a.pdf", RetainUntil: &today}, today))
+	})
+}
+
+func Test_SpoolAttachment(t *testing.T) {
+	service := NewAttachmentService(nil, nil, nil, 16, "")
+	spool := func(content string, attachment *entity.Attachment) error {
+		file, err := os.CreateTemp(t.TempDir(), "spool-*")
+		require.NoError(t, err)
+		defer file.Close()
+		return service.spool(file, strings.NewReader(content), attachment)
+	}
+
+	t.Run("must compute checksum and size and sniff content type", func(t *testing.T) {
+		attachment := entity.Attachment{ContentType: "application/octet-stream"}
+
+		require.NoError(t, spool("%PDF-1.7 signed", &attachment))
+		assert.Equal(t, int64(15), attachment.Size)
+		assert.Equal(t, "application/pdf", attachment.ContentType)
+		assert.Len(t, attachment.Sha256, 64)
+	})
+
+	t.Run("must reject mismatching checksum, too large and empty content", func(t *testing.T) {
+		err := spool("contract", &entity.Attachment{Sha256: strings.Repeat("0", 64)})
+		assert.ErrorContains(t, err, "Checksum mismatch")
+
+		assert.ErrorIs(t, spool(strings.Repeat("x", 17), &entity.Attachment{}), ErrAttachmentTooLarge)
+		assert.EqualError(t, spool("", &entity.Attachment{}), "Attachment content must not be empty")
+	})
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"person-service/db/entity"
+	"person-service/db/repository"
+	"person-service/mappers"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var attributeName = regexp.MustCompile(`^[a-z][a-zA-Z0-9_]{0,62}$`)
+
+var attributeTypes = map[entity.AttributeType]bool{
+	entity.AttributeString: true,
+	entity.AttributeInt:    true,
+	entity.AttributeBool:   true,
+	entity.AttributeDate:   true,
+	entity.AttributeEnum:   true,
+}
+
+const maxAttributeLength = 1000
+
+// AttributeService registry of custom person attributes.
+type AttributeService struct {
+	transactions *repository.TxManager
+}
+
+func NewAttributeService(transactions *repository.TxManager) *AttributeService {
+	return &AttributeService{transactions: transactions}
+}
+
+// CreateDefinition save new attribute definition, existing name violates primary key.
+func (s *AttributeService) CreateDefinition(ctx context.Context, definition entity.AttributeDefinition) (entity.AttributeDefinition, error) {
+	const op = "services.CreateDefinition"
+
+	if err := validateDefinition(definition); err != nil {
+		return entity.AttributeDefinition{}, err
+	}
+
+	saved, err := s.transactions.Repositories().Attributes.SaveDefinition(ctx, definition)
+	if err != nil {
+		return entity.AttributeDefinition{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return saved, nil
+}
+
+// UpdateDefinition update attribute definition, type must not be changed as stored values would not match it.
+// Stored values are validated against changed definition when person is written next time.
+func (s *AttributeService) UpdateDefinition(ctx context.Context, definition entity.AttributeDefinition) (entity.AttributeDefinition, error) {
+	const op = "services.UpdateDefinition"
+
+	if err := validateDefinition(definition); err != nil {
+		return entity.AttributeDefinition{}, err
+	}
+
+	var updated entity.AttributeDefinition
+	err := s.transactions.WithinTransaction(ctx, repository.TxOptions{}, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		existing, err := uow.Attributes.FindDefinition(ctx, definition.Name)
+		if err != nil {
+			return err
+		}
+		if existing.Type != definition.Type {
+			return &ValidationError{Message: fmt.Sprintf("Type of attribute %s must not be changed", definition.Name)}
+		}
+
+		updated, err = uow.Attributes.UpdateDefinition(ctx, definition)
+		return err
+	})
+	if err != nil {
+		return entity.AttributeDefinition{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return updated, nil
+}
+
+// DeleteDefinition delete attribute definition and its values of all persons, returns false for unknown name.
+func (s *AttributeService) DeleteDefinition(ctx context.Context, name string) (bool, error) {
+	const op = "services.DeleteDefinition"
+
+	var deleted bool
+	err := s.transactions.WithinTransaction(ctx, repository.TxOptions{}, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		var err error
+		deleted, err = uow.Attributes.DeleteDefinition(ctx, name)
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return deleted, nil
+}
+
+// FindDefinition find attribute definition by name.
+func (s *AttributeService) FindDefinition(ctx context.Context, name string) (entity.AttributeDefinition, error) {
+	return s.transactions.Repositories().Attributes.FindDefinition(ctx, name)
+}
+
+// LoadDefinitions load all attribute definitions.
+func (s *AttributeService) LoadDefinitions(ctx context.Context) ([]entity.AttributeDefinition, error) {
+	return s.transactions.Repositories().Attributes.LoadDefinitions(ctx)
+}
+
+func validateDefinition(definition entity.AttributeDefinition) error {
+	switch {
+	case !attributeName.MatchString(definition.Name):
+		return &ValidationError{Message: "Field name must start with lower-case letter and contain only letters, digits and _ (up to 63)"}
+	case !attributeTypes[definition.Type]:
+		return &ValidationError{Message: fmt.Sprintf("Unknown attribute type: %s", definition.Type)}
+	case definition.Pattern != "" && definition.Type != entity.AttributeString:
+		return &ValidationError{Message: "Field pattern is allowed only for string attribute"}
+	case len(definition.EnumValues) > 0 && definition.Type != entity.AttributeEnum:
+		return &ValidationError{Message: "Field enumValues is allowed only for enum attribute"}
+	case len(definition.EnumValues) == 0 && definition.Type == entity.AttributeEnum:
+		return &ValidationError{Message: "Field enumValues must not be empty for enum attribute"}
+	}
+
+	if _, err := regexp.Compile(definition.Pattern); err != nil {
+		return &ValidationError{Message: fmt.Sprintf("Field pattern is not valid regular expression: %s", err)}
+	}
+
+	seen := make(map[string]bool, len(definition.EnumValues))
+	for _, value := range definition.EnumValues {
+		if strings.TrimSpace(value) == "" || seen[value] {
+			return &ValidationError{Message: "Field enumValues must contain unique non-empty values"}
+		}
+		seen[value] = true
+	}
+	return nil
+}
+
+// normalizeAttributes validate attribute values against definitions and convert them to stored types,
+// null value is the same as absent one. Values of int, bool and date may be sent as strings (XML, query).
+func normalizeAttributes(definitions []entity.AttributeDefinition, values map[string]any) (map[string]any, error) {
+	byName := make(map[string]entity.AttributeDefinition, len(definitions))
+	for _, definition := range definitions {
+		byName[definition.Name] = definition
+	}
+
+	/* sorted names give stable error of the first invalid attribute */
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	normalized := make(map[string]any, len(values))
+	for _, name := range names {
+		if values[name] == nil {
+			continue
+		}
+		definition, ok := byName[name]
+		if !ok {
+			return nil, &ValidationError{Message: fmt.Sprintf("Unknown attribute: %s", name)}
+		}
+		value, err := normalizeAttribute(definition, values[name])
+		if err != nil {
+			return nil, err
+		}
+		normalized[name] = value
+	}
+
+	for _, definition := range definitions {
+		if _, ok := normalized[definition.Name]; definition.Required && !ok {
+			return nil, &ValidationError{Message: fmt.Sprintf("Attribute %s is required", definition.Name)}
+		}
+	}
+	return normalized, nil
+}
+
+func normalizeAttribute(definition entity.AttributeDefinition, value any) (any, error) {
+	invalid := func(expected string) error {
+		return &ValidationError{Message: fmt.Sprintf("Attribute %s must be %s", definition.Name, expected)}
+	}
+
+	switch definition.Type {
+	case entity.AttributeString:
+		text, ok := value.(string)
+		if !ok || len([]rune(text)) > maxAttributeLength {
+			return nil, invalid(fmt.Sprintf("string up to %d characters", maxAttributeLength))
+		}
+		/* pattern is validated when definition is saved */
+		if definition.Pattern != "" && !regexp.MustCompile(definition.Pattern).MatchString(text) {
+			return nil, invalid(fmt.Sprintf("string matching %s", definition.Pattern))
+		}
+		return text, nil
+	case entity.AttributeInt:
+		integer, ok := toInt64(value)
+		if !ok {
+			return nil, invalid("integer")
+		}
+		return integer, nil
+	case entity.AttributeBool:
+		switch typed := value.(type) {
+		case bool:
+			return typed, nil
+		case string:
+			if parsed, err := strconv.ParseBool(typed); err == nil {
+				return parsed, nil
+			}
+		}
+		return nil, invalid("boolean")
+	case entity.AttributeDate:
+		text, ok := value.(string)
+		if !ok {
+			return nil, invalid("date in format YYYY-MM-DD")
+		}
+		date, err := time.Parse(mappers.DateLayout, text)
+		if err != nil {
+			return nil, invalid("date in format YYYY-MM-DD")
+		}
+		return date.Format(mappers.DateLayout), nil
+	case entity.AttributeEnum:
+		text, _ := value.(string)
+		for _, allowed := range definition.EnumValues {
+			if text == allowed {
+				return text, nil
+			}
+		}
+		return nil, invalid("one of " + strings.Join(definition.EnumValues, ", "))
+	}
+	return nil, fmt.Errorf("unknown attribute type: %s", definition.Type)
+}
+
+// toInt64 integer of any decoded number: float64 of JSON, sized integers of MessagePack and CBOR or string.
+func toInt64(value any) (int64, bool) {
+	switch typed := value.(type) {
+	case float64:
+		if typed != math.Trunc(typed) || math.Abs(typed) > 1<<53 {
+			return 0, false
+		}
+		return int64(typed), true
+	case json.Number:
+		integer, err := typed.Int64()
+		return integer, err == nil
+	case string:
+		integer, err := strconv.ParseInt(typed, 10, 64)
+		return integer, err == nil
+	}
+
+	reflected := reflect.ValueOf(value)
+	switch reflected.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return reflected.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if reflected.Uint() > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(reflected.Uint()), true
+	case reflect.Float32:
+		return toInt64(reflected.Float())
+	}
+	return 0, false
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"person-service/db/entity"
+	"testing"
+)
+
+func Test_ValidateDefinition(t *testing.T) {
+	t.Run("valid definitions are accepted", func(t *testing.T) {
+		for _, definition := range []entity.AttributeDefinition{
+			{Name: "department", Type: entity.AttributeString, Pattern: `^[A-Z]{2}\d+$`},
+			{Name: "badge_no", Type: entity.AttributeInt, Required: true},
+			{Name: "level", Type: entity.AttributeEnum, EnumValues: []string{"junior", "senior"}},
+		} {
+			assert.NoError(t, validateDefinition(definition), definition.Name)
+		}
+	})
+
+	t.Run("invalid definitions are rejected", func(t *testing.T) {
+		for name, definition := range map[string]entity.AttributeDefinition{
+			"invalid name":       {Name: "Department", Type: entity.AttributeString},
+			"unknown type":       {Name: "department", Type: "text"},
+			"pattern of int":     {Name: "badge", Type: entity.AttributeInt, Pattern: `\d+`},
+			"invalid pattern":    {Name: "department", Type: entity.AttributeString, Pattern: `[a-`},
+			"enum without value": {Name: "level", Type: entity.AttributeEnum},
+			"duplicate value":    {Name: "level", Type: entity.AttributeEnum, EnumValues: []string{"junior", "junior"}},
+			"values of bool":     {Name: "active", Type: entity.AttributeBool, EnumValues: []string{"yes"}},
+		} {
+			var validationErr *ValidationError
+			assert.ErrorAs(t, validateDefinition(definition), &validationErr, name)
+		}
+	})
+}
+
+func Test_NormalizeAttributes(t *testing.T) {
+	definitions := []entity.AttributeDefinition{
+		{Name: "code", Type: entity.AttributeString, Pattern: `^[A-Z]{2}$`},
+		{Name: "badge", Type: entity.AttributeInt, Required: true},
+		{Name: "active", Type: entity.AttributeBool},
+		{Name: "hired", Type: entity.AttributeDate},
+		{Name: "level", Type: entity.AttributeEnum, EnumValues: []string{"junior", "senior"}},
+	}
+
+	t.Run("values are converted to stored types", func(t *testing.T) {
+		normalized, err := normalizeAttributes(definitions, map[string]any{
+			"code":   "RU",
+			"badge":  json.Number("42"),
+			"active": "true",
+			"hired":  "2021-03-09",
+			"level":  "senior",
+			"absent": nil,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"code": "RU", "badge": int64(42), "active": true, "hired": "2021-03-09", "level": "senior"}, normalized)
+	})
+
+	t.Run("invalid values are rejected", func(t *testing.T) {
+		for name, values := range map[string]map[string]any{
+			"missing required":  {"code": "RU"},
+			"unknown attribute": {"badge": 1, "room": "12"},
+			"pattern mismatch":  {"badge": 1, "code": "rus"},
+			"fractional int":    {"badge": 1.5},
+			"invalid bool":      {"badge": 1, "active": "maybe"},
+			"invalid date":      {"badge": 1, "hired": "09.03.2021"},
+			"unknown enum":      {"badge": 1, "level": "middle"},
+		} {
+			var validationErr *ValidationError
+			_, err := normalizeAttributes(definitions, values)
+			assert.ErrorAs(t, err, &validationErr, name)
+		}
+	})
+}
+
+func Test_ToInt64(t *testing.T) {
+	for value, expected := range map[any]int64{float64(7): 7, "-3": -3, uint16(12): 12, int8(-1): -1} {
+		integer, ok := toInt64(value)
+		assert.True(t, ok, value)
+		assert.Equal(t, expected, integer)
+	}
+
+	for _, value := range []any{float64(1.2), "1e3", uint64(1 << 63), true} {
+		_, ok := toInt64(value)
+		assert.False(t, ok, value)
+	}
+}
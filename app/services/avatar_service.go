@@ -0,0 +1,188 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"io"
+	"person-service/blob"
+	"person-service/db/entity"
+	"person-service/db/repository"
+	"person-service/imaging"
+	"strconv"
+	"strings"
+)
+
+// AvatarOriginal variant of stored image which is not a thumbnail.
+const AvatarOriginal = "original"
+
+// AvatarService avatar images of persons. Image is decoded, scaled and re-encoded, so metadata as EXIF is never stored.
+// Files are kept in blob store, database row points to their current version.
+type AvatarService struct {
+	transactions *repository.TxManager
+	store        blob.Store
+	maxPixels    int
+	maxDimension int
+	sizes        []int
+}
+
+func NewAvatarService(transactions *repository.TxManager, store blob.Store, maxPixels int, maxDimension int, sizes []int) *AvatarService {
+	return &AvatarService{transactions: transactions, store: store, maxPixels: maxPixels, maxDimension: maxDimension, sizes: sizes}
+}
+
+// SaveAvatar process image and replace avatar of existing person, files of replaced avatar are deleted after commit.
+func (s *AvatarService) SaveAvatar(ctx context.Context, personId uuid.UUID, data []byte) (entity.Avatar, error) {
+	const op = "services.SaveAvatar"
+
+	if _, err := s.transactions.Repositories().Persons.FindPersonById(ctx, &personId); err != nil {
+		return entity.Avatar{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	avatar, files, err := s.process(personId, data)
+	if err != nil {
+		return entity.Avatar{}, err
+	}
+	/* files of new version are written first, row never points to missing files */
+	for variant, file := range files {
+		if err := s.store.Put(ctx, avatarKey(avatar, variant), bytes.NewReader(file), int64(len(file)), avatar.ContentType); err != nil {
+			return entity.Avatar{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	var saved, replaced entity.Avatar
+	err = s.transactions.WithinTransaction(ctx, repository.ReadCommitted, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		if err := uow.Avatars.LockAvatar(ctx, personId); err != nil {
+			return err
+		}
+		existing, err := uow.Avatars.FindAvatar(ctx, personId)
+		switch {
+		case err == nil:
+			replaced = existing
+		case !errors.Is(err, pgx.ErrNoRows):
+			return err
+		}
+
+		saved, err = uow.Avatars.SaveAvatar(ctx, avatar)
+		return err
+	})
+	if err != nil {
+		return entity.Avatar{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if replaced.ETag != "" && replaced.ETag != saved.ETag {
+		s.deleteFiles(ctx, replaced)
+	}
+	return saved, nil
+}
+
+// FindAvatar find avatar of person.
+func (s *AvatarService) FindAvatar(ctx context.Context, personId uuid.UUID) (entity.Avatar, error) {
+	return s.transactions.Repositories().Avatars.FindAvatar(ctx, personId)
+}
+
+// Variant name of stored file of requested size, 0 is original. Size must be one of generated thumbnails.
+func (s *AvatarService) Variant(avatar entity.Avatar, size int) (string, error) {
+	if size == 0 {
+		return AvatarOriginal, nil
+	}
+	for _, stored := range avatar.Sizes {
+		if stored == size {
+			return strconv.Itoa(size), nil
+		}
+	}
+
+	sizes := make([]string, 0, len(avatar.Sizes))
+	for _, stored := range avatar.Sizes {
+		sizes = append(sizes, strconv.Itoa(stored))
+	}
+	return "", &ValidationError{Message: fmt.Sprintf("Parameter size must be one of: %s", strings.Join(sizes, ", "))}
+}
+
+// OpenVariant open file of avatar variant for reading, reader must be closed.
+func (s *AvatarService) OpenVariant(ctx context.Context, avatar entity.Avatar, variant string) (io.ReadCloser, error) {
+	const op = "services.OpenVariant"
+
+	file, err := s.store.Get(ctx, avatarKey(avatar, variant))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return file, nil
+}
+
+// DeleteAvatar delete avatar of person and its files, returns false when person has no avatar.
+func (s *AvatarService) DeleteAvatar(ctx context.Context, personId uuid.UUID) (bool, error) {
+	const op = "services.DeleteAvatar"
+
+	var deleted entity.Avatar
+	err := s.transactions.WithinTransaction(ctx, repository.ReadCommitted, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		if err := uow.Avatars.LockAvatar(ctx, personId); err != nil {
+			return err
+		}
+
+		var err error
+		deleted, err = uow.Avatars.DeleteAvatar(ctx, personId)
+		return err
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.deleteFiles(ctx, deleted)
+	return true, nil
+}
+
+// process decode image and encode original scaled to max dimension and square thumbnails of configured sizes.
+// ETag is hash of uploaded content, so the same upload keeps its version.
+func (s *AvatarService) process(personId uuid.UUID, data []byte) (entity.Avatar, map[string][]byte, error) {
+	picture, err := imaging.Decode(data, s.maxPixels)
+	switch {
+	case errors.Is(err, imaging.ErrUnsupported):
+		return entity.Avatar{}, nil, &ValidationError{Message: "Avatar must be JPEG, PNG or GIF image"}
+	case errors.Is(err, imaging.ErrTooLarge):
+		return entity.Avatar{}, nil, &ValidationError{Message: fmt.Sprintf("Avatar must not have more than %d pixels", s.maxPixels)}
+	case err != nil:
+		return entity.Avatar{}, nil, err
+	}
+
+	hash := sha256.Sum256(data)
+	original := picture.Fit(s.maxDimension)
+	avatar := entity.Avatar{
+		PersonId: personId,
+		ETag:     hex.EncodeToString(hash[:16]),
+		Width:    original.Bounds().Dx(),
+		Height:   original.Bounds().Dy(),
+		Sizes:    make([]int, 0, len(s.sizes)),
+	}
+
+	files := make(map[string][]byte, len(s.sizes)+1)
+	if files[AvatarOriginal], avatar.ContentType, err = imaging.Encode(original, picture.ContentType); err != nil {
+		return entity.Avatar{}, nil, err
+	}
+	for _, size := range s.sizes {
+		if files[strconv.Itoa(size)], _, err = imaging.Encode(picture.Square(size), picture.ContentType); err != nil {
+			return entity.Avatar{}, nil, err
+		}
+		avatar.Sizes = append(avatar.Sizes, size)
+	}
+	return avatar, files, nil
+}
+
+/* files left by failed delete are not referenced by any avatar, so error is not reported after commit */
+func (s *AvatarService) deleteFiles(ctx context.Context, avatar entity.Avatar) {
+	_ = s.store.Delete(ctx, avatarKey(avatar, AvatarOriginal))
+	for _, size := range avatar.Sizes {
+		_ = s.store.Delete(ctx, avatarKey(avatar, strconv.Itoa(size)))
+	}
+}
+
+func avatarKey(avatar entity.Avatar, variant string) string {
+	return fmt.Sprintf("avatars/%s/%s/%s", avatar.PersonId, avatar.ETag, variant)
+}
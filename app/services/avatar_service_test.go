@@ -0,0 +1,83 @@
+package services
+
+import (
+	"bytes"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"image"
+	"image/color"
+	"image/png"
+	"person-service/db/entity"
+	"testing"
+)
+
+func Test_ProcessAvatar(t *testing.T) {
+	service := NewAvatarService(nil, nil, 1_000_000, 100, []int{16, 32})
+	personId := uuid.New()
+
+	t.Run("must scale original and make square thumbnails", func(t *testing.T) {
+		data := encodeAvatar(t, 400, 200)
+
+		avatar, files, err := service.process(personId, data)
+
+		require.NoError(t, err)
+		assert.Equal(t, personId, avatar.PersonId)
+		assert.Equal(t, "image/png", avatar.ContentType)
+		assert.Equal(t, 100, avatar.Width)
+		assert.Equal(t, 50, avatar.Height)
+		assert.Equal(t, []int{16, 32}, avatar.Sizes)
+		assert.Len(t, avatar.ETag, 32)
+		assert.Len(t, files, 3)
+
+		thumbnail, err := png.DecodeConfig(bytes.NewReader(files["32"]))
+		require.NoError(t, err)
+		assert.Equal(t, 32, thumbnail.Width)
+		assert.Equal(t, 32, thumbnail.Height)
+
+		again, _, err := service.process(personId, data)
+		require.NoError(t, err)
+		assert.Equal(t, avatar.ETag, again.ETag)
+	})
+
+	t.Run("must reject content which is not image", func(t *testing.T) {
+		_, _, err := service.process(personId, []byte("just text"))
+
+		assert.EqualError(t, err, "Avatar must be JPEG, PNG or GIF image")
+	})
+
+	t.Run("must reject image over pixel limit", func(t *testing.T) {
+		_, _, err := service.process(personId, encodeAvatar(t, 2000, 1000))
+
+		assert.EqualError(t, err, "Avatar must not have more than 1000000 pixels")
+	})
+}
+
+func Test_AvatarVariant(t *testing.T) {
+	service := NewAvatarService(nil, nil, 0, 0, nil)
+	avatar := entity.Avatar{Sizes: []int{64, 128}}
+
+	variant, err := service.Variant(avatar, 0)
+	require.NoError(t, err)
+	assert.Equal(t, AvatarOriginal, variant)
+
+	variant, err = service.Variant(avatar, 128)
+	require.NoError(t, err)
+	assert.Equal(t, "128", variant)
+
+	_, err = service.Variant(avatar, 100)
+	assert.EqualError(t, err, "Parameter size must be one of: 64, 128")
+}
+
+func encodeAvatar(t *testing.T, width int, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 0x80, A: 0xff})
+		}
+	}
+
+	var buffer bytes.Buffer
+	require.NoError(t, png.Encode(&buffer, img))
+	return buffer.Bytes()
+}
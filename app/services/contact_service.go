@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"net/mail"
+	"person-service/db/entity"
+	"person-service/db/repository"
+	"strings"
+)
+
+/* known types of contacts, empty type means other */
+var contactTypes = map[entity.ContactKind]map[string]bool{
+	entity.ContactEmail: {"personal": true, "work": true, "other": true},
+	entity.ContactPhone: {"mobile": true, "home": true, "work": true, "fax": true, "other": true},
+}
+
+const defaultContactType = "other"
+
+// ContactService emails and phones of person, at most one contact of every kind is primary.
+type ContactService struct {
+	transactions *repository.TxManager
+}
+
+func NewContactService(transactions *repository.TxManager) *ContactService {
+	return &ContactService{transactions: transactions}
+}
+
+// CreateContact validate, normalize and save contact of existing person, primary contact demotes current primary one.
+func (s *ContactService) CreateContact(ctx context.Context, contact entity.Contact) (entity.Contact, error) {
+	const op = "services.CreateContact"
+
+	if err := normalizeContact(&contact); err != nil {
+		return entity.Contact{}, err
+	}
+
+	var saved entity.Contact
+	err := s.transactions.WithinTransaction(ctx, repository.TxOptions{}, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		if _, err := uow.Persons.FindPersonById(ctx, &contact.PersonId); err != nil {
+			return err
+		}
+
+		contact.Id = uuid.New()
+		if contact.Primary {
+			if err := uow.Contacts.ClearPrimary(ctx, contact.Kind, contact.PersonId, contact.Id); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		saved, err = uow.Contacts.SaveContact(ctx, contact)
+		return err
+	})
+	if err != nil {
+		return entity.Contact{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return saved, nil
+}
+
+// UpdateContact validate, normalize and replace contact of person, returns pgx.ErrNoRows for unknown contact.
+func (s *ContactService) UpdateContact(ctx context.Context, contact entity.Contact) (entity.Contact, error) {
+	const op = "services.UpdateContact"
+
+	if err := normalizeContact(&contact); err != nil {
+		return entity.Contact{}, err
+	}
+
+	var updated entity.Contact
+	err := s.transactions.WithinTransaction(ctx, repository.TxOptions{}, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		if contact.Primary {
+			if err := uow.Contacts.ClearPrimary(ctx, contact.Kind, contact.PersonId, contact.Id); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		updated, err = uow.Contacts.UpdateContact(ctx, contact)
+		return err
+	})
+	if err != nil {
+		return entity.Contact{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return updated, nil
+}
+
+// DeleteContact delete contact of person, returns false for unknown contact.
+func (s *ContactService) DeleteContact(ctx context.Context, kind entity.ContactKind, personId uuid.UUID, id uuid.UUID) (bool, error) {
+	return s.transactions.Repositories().Contacts.DeleteContact(ctx, kind, personId, id)
+}
+
+// FindContact find contact of person by id.
+func (s *ContactService) FindContact(ctx context.Context, kind entity.ContactKind, personId uuid.UUID, id uuid.UUID) (entity.Contact, error) {
+	return s.transactions.Repositories().Contacts.FindContact(ctx, kind, personId, id)
+}
+
+// LoadPersonContacts load contacts of existing person, returns pgx.ErrNoRows for unknown person.
+func (s *ContactService) LoadPersonContacts(ctx context.Context, kind entity.ContactKind, personId uuid.UUID) ([]entity.Contact, error) {
+	const op = "services.LoadPersonContacts"
+
+	repositories := s.transactions.Repositories()
+	if _, err := repositories.Persons.FindPersonById(ctx, &personId); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	contacts, err := repositories.Contacts.LoadContacts(ctx, kind, []uuid.UUID{personId})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return contacts[personId], nil
+}
+
+// LoadContacts load emails and phones of persons grouped by person id, used to expand person responses.
+func (s *ContactService) LoadContacts(ctx context.Context, personIds []uuid.UUID) (map[uuid.UUID][]entity.Contact, map[uuid.UUID][]entity.Contact, error) {
+	const op = "services.LoadContacts"
+
+	repositories := s.transactions.Repositories()
+	emails, err := repositories.Contacts.LoadContacts(ctx, entity.ContactEmail, personIds)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	phones, err := repositories.Contacts.LoadContacts(ctx, entity.ContactPhone, personIds)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return emails, phones, nil
+}
+
+func normalizeContact(contact *entity.Contact) error {
+	types, ok := contactTypes[contact.Kind]
+	if !ok {
+		return fmt.Errorf("unknown contact kind: %s", contact.Kind)
+	}
+
+	contact.Type = strings.ToLower(strings.TrimSpace(contact.Type))
+	if contact.Type == "" {
+		contact.Type = defaultContactType
+	}
+	if !types[contact.Type] {
+		return &ValidationError{Message: fmt.Sprintf("Unknown %s type: %s", contact.Kind, contact.Type)}
+	}
+
+	var err error
+	switch contact.Kind {
+	case entity.ContactEmail:
+		contact.Value, err = NormalizeEmail(contact.Value)
+	case entity.ContactPhone:
+		contact.Value, err = NormalizePhone(contact.Value)
+	}
+	return err
+}
+
+// NormalizeEmail validate bare RFC 5322 address and lower case its domain, local part is case-sensitive.
+func NormalizeEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+	invalid := &ValidationError{Message: fmt.Sprintf("Field email is not valid address: %s", email)}
+
+	address, err := mail.ParseAddress(email)
+	/* display name, angle brackets and comments are not part of address */
+	if err != nil || address.Name != "" || strings.ContainsAny(email, "<>") {
+		return "", invalid
+	}
+
+	/* quoted local part is kept as sent, parsed address drops quotes */
+	at := strings.LastIndex(email, "@")
+	domain := email[at+1:]
+	if !strings.EqualFold(domain, address.Address[strings.LastIndex(address.Address, "@")+1:]) {
+		return "", invalid
+	}
+	return email[:at] + "@" + strings.ToLower(domain), nil
+}
+
+// NormalizePhone convert phone in international format to E.164, spaces, dots, dashes and parentheses are dropped,
+// international prefix 00 is replaced by +.
+func NormalizePhone(phone string) (string, error) {
+	invalid := &ValidationError{Message: fmt.Sprintf("Field phone must be international number in E.164 format: %s", phone)}
+
+	digits := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '.', '-', '(', ')':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(phone))
+
+	if strings.HasPrefix(digits, "00") {
+		digits = "+" + digits[2:]
+	}
+	if !strings.HasPrefix(digits, "+") {
+		return "", invalid
+	}
+	digits = digits[1:]
+
+	/* country code does not start with zero, number has at most 15 digits */
+	if len(digits) < 8 || len(digits) > 15 || digits[0] == '0' {
+		return "", invalid
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return "", invalid
+		}
+	}
+
+	return "+" + digits, nil
+}
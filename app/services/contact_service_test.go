@@ -0,0 +1,40 @@
+package services
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_NormalizeEmail(t *testing.T) {
+	for input, expected := range map[string]string{
+		"Ivan.Petrov@Example.COM":  "Ivan.Petrov@example.com",
+		" a+tag@mail.example.org ": "a+tag@mail.example.org",
+		`"john doe"@example.com`:   `"john doe"@example.com`,
+	} {
+		email, err := NormalizeEmail(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, email)
+	}
+
+	for _, input := range []string{"", "ivan", "ivan@", "Ivan <ivan@example.com>", "<ivan@example.com>", "ivan@example.com (Ivan)", "a@b@c"} {
+		_, err := NormalizeEmail(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func Test_NormalizePhone(t *testing.T) {
+	for input, expected := range map[string]string{
+		"+7 (912) 345-67-89": "+79123456789",
+		"0049 30 1234567":    "+49301234567",
+		"+1.415.555.2671":    "+14155552671",
+	} {
+		phone, err := NormalizePhone(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, phone)
+	}
+
+	for _, input := range []string{"", "89123456789", "+0123456789", "+1234567", "+1234567890123456", "+7912abc4567"} {
+		_, err := NormalizePhone(input)
+		assert.Error(t, err, input)
+	}
+}
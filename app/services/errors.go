@@ -0,0 +1,10 @@
+package services
+
+// ValidationError request is not valid, handlers respond with 400.
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
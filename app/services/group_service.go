@@ -0,0 +1,293 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"person-service/db/entity"
+	"person-service/db/repository"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// GroupDeleteRestrict group with children or members is not deleted.
+	GroupDeleteRestrict = "restrict"
+	// GroupDeleteCascade group is deleted together with descendants and memberships.
+	GroupDeleteCascade = "cascade"
+)
+
+const (
+	maxGroupName        = 100
+	maxGroupDescription = 1000
+	/* unknown persons listed in error */
+	maxReportedIds = 5
+)
+
+// ErrGroupNotEmpty group has children or members and delete mode is restrict.
+var ErrGroupNotEmpty = errors.New("group has children or members")
+
+// GroupService hierarchy of person groups and their members. Membership is not part of person,
+// so change of members neither touches persons nor writes events.
+type GroupService struct {
+	transactions *repository.TxManager
+	cascade      bool
+}
+
+func NewGroupService(transactions *repository.TxManager, deleteMode string) *GroupService {
+	return &GroupService{transactions: transactions, cascade: deleteMode == GroupDeleteCascade}
+}
+
+// CreateGroup validate and save group under existing parent.
+func (s *GroupService) CreateGroup(ctx context.Context, group entity.Group) (entity.Group, error) {
+	const op = "services.CreateGroup"
+
+	if err := normalizeGroup(&group); err != nil {
+		return entity.Group{}, err
+	}
+
+	var saved entity.Group
+	err := s.transactions.WithinTransaction(ctx, repository.TxOptions{}, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		if err := checkParent(ctx, uow, group.ParentId); err != nil {
+			return err
+		}
+
+		group.Id = uuid.New()
+		var err error
+		saved, err = uow.Groups.SaveGroup(ctx, group)
+		return err
+	})
+	if err != nil {
+		return entity.Group{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return saved, nil
+}
+
+// UpdateGroup validate and update group, returns pgx.ErrNoRows for unknown group.
+// Group must not be moved under itself or its descendant.
+func (s *GroupService) UpdateGroup(ctx context.Context, group entity.Group) (entity.Group, error) {
+	const op = "services.UpdateGroup"
+
+	if err := normalizeGroup(&group); err != nil {
+		return entity.Group{}, err
+	}
+
+	var updated entity.Group
+	err := s.transactions.WithinTransaction(ctx, repository.ReadCommitted, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		if group.ParentId != nil {
+			if err := uow.Groups.LockTree(ctx); err != nil {
+				return err
+			}
+		}
+		if _, err := uow.Groups.FindGroupForUpdate(ctx, group.Id); err != nil {
+			return err
+		}
+		if err := checkParent(ctx, uow, group.ParentId); err != nil {
+			return err
+		}
+		if group.ParentId != nil {
+			cycle, err := uow.Groups.InSubtree(ctx, group.Id, *group.ParentId)
+			if err != nil {
+				return err
+			}
+			if cycle {
+				return &ValidationError{Message: "Group must not be moved under itself or its descendant"}
+			}
+		}
+
+		var err error
+		updated, err = uow.Groups.UpdateGroup(ctx, group)
+		return err
+	})
+	if err != nil {
+		return entity.Group{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return updated, nil
+}
+
+// DeleteGroup delete group by configured mode, returns false for unknown group
+// and ErrGroupNotEmpty for group with children or members in restrict mode.
+func (s *GroupService) DeleteGroup(ctx context.Context, id uuid.UUID) (bool, error) {
+	const op = "services.DeleteGroup"
+
+	var deleted bool
+	err := s.transactions.WithinTransaction(ctx, repository.TxOptions{}, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		/* locked group makes new children and members wait, so emptiness holds until delete */
+		if _, err := uow.Groups.FindGroupForUpdate(ctx, id); errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if !s.cascade {
+			empty, err := uow.Groups.IsEmpty(ctx, id)
+			if err != nil {
+				return err
+			}
+			if !empty {
+				return ErrGroupNotEmpty
+			}
+		}
+
+		var err error
+		deleted, err = uow.Groups.DeleteGroup(ctx, id)
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return deleted, nil
+}
+
+// FindGroup find group by id.
+func (s *GroupService) FindGroup(ctx context.Context, id uuid.UUID) (entity.Group, error) {
+	return s.transactions.Repositories().Groups.FindGroup(ctx, id)
+}
+
+// LoadGroups load all groups, hierarchy is given by parent ids.
+func (s *GroupService) LoadGroups(ctx context.Context) ([]entity.Group, error) {
+	return s.transactions.Repositories().Groups.LoadGroups(ctx)
+}
+
+// AddMembers add existing persons to group, returns count of persons which were not members yet.
+func (s *GroupService) AddMembers(ctx context.Context, id uuid.UUID, personIds []uuid.UUID) (int64, error) {
+	const op = "services.AddMembers"
+
+	personIds, err := uniqueIds(personIds)
+	if err != nil {
+		return 0, err
+	}
+
+	var added int64
+	err = s.transactions.WithinTransaction(ctx, repository.TxOptions{}, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		if _, err := uow.Groups.FindGroup(ctx, id); err != nil {
+			return err
+		}
+		if err := checkPersons(ctx, uow, personIds); err != nil {
+			return err
+		}
+
+		var err error
+		added, err = uow.Groups.AddMembers(ctx, id, personIds)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return added, nil
+}
+
+// RemoveMembers remove persons from group, returns count of removed members.
+func (s *GroupService) RemoveMembers(ctx context.Context, id uuid.UUID, personIds []uuid.UUID) (int64, error) {
+	const op = "services.RemoveMembers"
+
+	personIds, err := uniqueIds(personIds)
+	if err != nil {
+		return 0, err
+	}
+
+	var removed int64
+	err = s.transactions.WithinTransaction(ctx, repository.TxOptions{}, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		if _, err := uow.Groups.FindGroup(ctx, id); err != nil {
+			return err
+		}
+
+		var err error
+		removed, err = uow.Groups.RemoveMembers(ctx, id, personIds)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return removed, nil
+}
+
+// LoadMembers load page of 50 members of group, members of descendants are included with descendants.
+func (s *GroupService) LoadMembers(ctx context.Context, id uuid.UUID, descendants bool, page *string) ([]entity.Person, error) {
+	const op = "services.LoadMembers"
+
+	repositories := s.transactions.Repositories()
+	if _, err := repositories.Groups.FindGroup(ctx, id); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	persons, err := repositories.Persons.LoadPersons(ctx, repository.PersonFilter{Group: &id, DirectMembers: !descendants}, page)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return persons, nil
+}
+
+func normalizeGroup(group *entity.Group) error {
+	group.Name = strings.TrimSpace(group.Name)
+	group.Description = strings.TrimSpace(group.Description)
+
+	switch {
+	case group.Name == "" || utf8.RuneCountInString(group.Name) > maxGroupName:
+		return &ValidationError{Message: fmt.Sprintf("Field name must contain from 1 to %d characters", maxGroupName)}
+	case utf8.RuneCountInString(group.Description) > maxGroupDescription:
+		return &ValidationError{Message: fmt.Sprintf("Field description must contain at most %d characters", maxGroupDescription)}
+	}
+	return nil
+}
+
+// checkParent parent of group must exist, nil parent makes root group.
+func checkParent(ctx context.Context, uow *repository.UnitOfWork, parentId *uuid.UUID) error {
+	if parentId == nil {
+		return nil
+	}
+	if _, err := uow.Groups.FindGroup(ctx, *parentId); errors.Is(err, pgx.ErrNoRows) {
+		return &ValidationError{Message: fmt.Sprintf("Parent group not found: %s", parentId)}
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// checkPersons all persons must exist, first unknown ones are reported.
+func checkPersons(ctx context.Context, uow *repository.UnitOfWork, personIds []uuid.UUID) error {
+	persons, err := uow.Persons.FindPersonsByIds(ctx, personIds)
+	if err != nil {
+		return err
+	}
+	if len(persons) == len(personIds) {
+		return nil
+	}
+
+	found := make(map[uuid.UUID]bool, len(persons))
+	for _, person := range persons {
+		found[*person.Id] = true
+	}
+	var unknown []string
+	for _, id := range personIds {
+		if !found[id] && len(unknown) < maxReportedIds {
+			unknown = append(unknown, id.String())
+		}
+	}
+	return &ValidationError{Message: fmt.Sprintf("Persons not found: %s", strings.Join(unknown, ", "))}
+}
+
+// uniqueIds person ids without duplicates in request order, at least one id is required.
+func uniqueIds(ids []uuid.UUID) ([]uuid.UUID, error) {
+	if len(ids) == 0 {
+		return nil, &ValidationError{Message: "Field personIds must not be empty"}
+	}
+
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique, nil
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"person-service/db/entity"
+	"strings"
+	"testing"
+)
+
+func Test_NormalizeGroup(t *testing.T) {
+	group := entity.Group{Name: "  Backend ", Description: " Services team "}
+	assert.NoError(t, normalizeGroup(&group))
+	assert.Equal(t, "Backend", group.Name)
+	assert.Equal(t, "Services team", group.Description)
+
+	for name, group := range map[string]entity.Group{
+		"empty name":       {Name: " "},
+		"long name":        {Name: strings.Repeat("я", maxGroupName+1)},
+		"long description": {Name: "Backend", Description: strings.Repeat("a", maxGroupDescription+1)},
+	} {
+		var validationErr *ValidationError
+		assert.ErrorAs(t, normalizeGroup(&group), &validationErr, name)
+	}
+}
+
+func Test_UniqueIds(t *testing.T) {
+	first, second := uuid.New(), uuid.New()
+
+	ids, err := uniqueIds([]uuid.UUID{first, second, first})
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{first, second}, ids)
+
+	var validationErr *ValidationError
+	_, err = uniqueIds(nil)
+	assert.ErrorAs(t, err, &validationErr)
+}
+
+func Test_NormalizeTag(t *testing.T) {
+	for tag, expected := range map[string]string{" OnCall ": "oncall", "Команда-1": "команда-1", "team:backend": "team:backend"} {
+		normalized, err := NormalizeTag(tag)
+		assert.NoError(t, err, tag)
+		assert.Equal(t, expected, normalized)
+	}
+
+	for _, tag := range []string{"", "-draft", "with space", strings.Repeat("a", 64)} {
+		_, err := NormalizeTag(tag)
+		assert.Error(t, err, tag)
+	}
+}
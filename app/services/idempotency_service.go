@@ -0,0 +1,98 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"person-service/db/entity"
+	"person-service/db/repository"
+	"time"
+)
+
+// ErrIdempotencyKeyReused key was already used for request with different payload, handlers respond with 422.
+var ErrIdempotencyKeyReused = errors.New("idempotency key was used for different request")
+
+var errFailedResponse = errors.New("failed response is not stored")
+
+// IdempotentResponse response of request, which is stored and replayed by key.
+type IdempotentResponse struct {
+	Status  int
+	Headers map[string]string
+	Body    []byte
+}
+
+// IdempotencyService executes request once per key and principal, retries replay the first response.
+type IdempotencyService struct {
+	transactions *repository.TxManager
+	ttl          time.Duration
+}
+
+func NewIdempotencyService(transactions *repository.TxManager, ttl time.Duration) *IdempotencyService {
+	return &IdempotencyService{transactions: transactions, ttl: ttl}
+}
+
+// Execute run fn once for principal and key. Key is stored in the same transaction as changes of fn,
+// which joins it through ctx, so response is stored only when changes are committed.
+// Response with status 5xx is rolled back and not stored, so request can be retried with the same key.
+// Returns stored response and true when request was already executed.
+func (s *IdempotencyService) Execute(
+	ctx context.Context,
+	principal string,
+	key string,
+	requestHash []byte,
+	fn func(ctx context.Context) IdempotentResponse,
+) (IdempotentResponse, bool, error) {
+	const op = "services.IdempotencyService.Execute"
+
+	var response IdempotentResponse
+	var replayed bool
+	err := s.transactions.WithinTransaction(ctx, repository.TxOptions{}, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		replayed = false
+		acquired, err := uow.Idempotency.Acquire(ctx, principal, key, requestHash, s.ttl)
+		if err != nil {
+			return err
+		}
+
+		if !acquired {
+			stored, err := uow.Idempotency.Find(ctx, principal, key)
+			if err != nil {
+				return err
+			}
+			if !bytes.Equal(stored.RequestHash, requestHash) {
+				return ErrIdempotencyKeyReused
+			}
+			response = IdempotentResponse{Status: stored.Status, Headers: stored.Headers, Body: stored.Body}
+			replayed = true
+			return nil
+		}
+
+		response = fn(ctx)
+		if response.Status >= 500 {
+			return errFailedResponse
+		}
+		return uow.Idempotency.Complete(ctx, entity.IdempotencyKey{
+			Principal: principal,
+			Key:       key,
+			Status:    response.Status,
+			Headers:   response.Headers,
+			Body:      response.Body,
+		})
+	})
+
+	switch {
+	case errors.Is(err, errFailedResponse):
+		return response, false, nil
+	case errors.Is(err, ErrIdempotencyKeyReused):
+		return IdempotentResponse{}, false, ErrIdempotencyKeyReused
+	case err != nil:
+		return IdempotentResponse{}, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return response, replayed, nil
+}
+
+// DeleteExpired delete keys after their TTL.
+func (s *IdempotencyService) DeleteExpired(ctx context.Context) (int64, error) {
+	return s.transactions.Repositories().Idempotency.DeleteExpired(ctx)
+}
@@ -0,0 +1,333 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"math"
+	"person-service/db/entity"
+	"person-service/db/repository"
+	"person-service/events"
+	"person-service/mappers"
+	"sort"
+	"strings"
+)
+
+// Sources of merged field, attributes of both persons are united and survivor wins on the same name.
+const (
+	MergeSurvivor  = "survivor"
+	MergeDuplicate = "duplicate"
+	MergeBoth      = "both"
+)
+
+// Reasons of duplicate candidate.
+const (
+	ReasonSimilarName   = "similar_name"
+	ReasonSwappedName   = "swapped_name"
+	ReasonSharedEmail   = "shared_email"
+	ReasonSharedPhone   = "shared_phone"
+	ReasonSameBirthDate = "same_birth_date"
+)
+
+const (
+	nameWeight      = 0.5
+	contactWeight   = 0.4
+	birthDateWeight = 0.1
+	/* name similarity which is reported as reason */
+	similarNameThreshold = 0.5
+)
+
+// mergeFields fields of person which source is chosen by merge.
+var mergeFields = []string{"firstName", "lastName", "birthDate", "login", "attributes"}
+
+// MergeService finds persons which duplicate each other and merges them.
+type MergeService struct {
+	transactions *repository.TxManager
+	avatars      *AvatarService
+	minScore     float64
+	limit        int
+}
+
+func NewMergeService(transactions *repository.TxManager, avatars *AvatarService, minScore float64, limit int) *MergeService {
+	return &MergeService{transactions: transactions, avatars: avatars, minScore: minScore, limit: limit}
+}
+
+// FindDuplicates find candidates which may duplicate person ordered by score. Names are compared by trigrams of
+// transliterated keys, which do not depend on order of first and last name; shared email or phone and the same
+// birth date raise score, candidates below min score are skipped.
+func (s *MergeService) FindDuplicates(ctx context.Context, personId uuid.UUID) ([]entity.Duplicate, error) {
+	const op = "services.FindDuplicates"
+
+	repositories := s.transactions.Repositories()
+	person, err := repositories.Persons.FindPersonById(ctx, &personId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	/* query does not know birth dates, it ranks twice as many candidates as returned */
+	candidates, err := repositories.Merges.FindDuplicates(ctx, personId, 2*s.limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	duplicates := make([]entity.Duplicate, 0, len(candidates))
+	for _, candidate := range candidates {
+		if duplicate := scoreDuplicate(person, candidate); duplicate.Score >= s.minScore {
+			duplicates = append(duplicates, duplicate)
+		}
+	}
+	sort.SliceStable(duplicates, func(i, j int) bool {
+		return duplicates[i].Score > duplicates[j].Score
+	})
+	if len(duplicates) > s.limit {
+		duplicates = duplicates[:s.limit]
+	}
+	return duplicates, nil
+}
+
+// MergePersons merge duplicate into survivor. Fields choose source of person fields by name, absent ones keep
+// survivor values except empty login, approximate birth date and attributes, which are united.
+// Contacts, addresses, attachments, memberships and relationships of duplicate are moved to survivor, avatar of
+// duplicate is dropped. Duplicate is deleted, its id redirects to survivor and merge is recorded in history.
+func (s *MergeService) MergePersons(ctx context.Context, survivorId uuid.UUID, duplicateId uuid.UUID, fields map[string]string) (entity.Person, entity.PersonMerge, error) {
+	const op = "services.MergePersons"
+
+	if survivorId == duplicateId {
+		return entity.Person{}, entity.PersonMerge{}, &ValidationError{Message: "Person must not be merged into itself"}
+	}
+
+	var merged entity.Person
+	var merge entity.PersonMerge
+	var avatar entity.Avatar
+	/* records are moved after avatar and relationship type locks, rows of previous lock holder are seen at read committed only */
+	err := s.transactions.WithinTransaction(ctx, repository.ReadCommitted, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		/* rows are locked in order of ids, so concurrent merges of the same persons do not deadlock */
+		ids := []uuid.UUID{survivorId, duplicateId}
+		if ids[1].String() < ids[0].String() {
+			ids[0], ids[1] = ids[1], ids[0]
+		}
+		locked := make(map[uuid.UUID]entity.Person, len(ids))
+		for _, id := range ids {
+			person, err := uow.Persons.FindPersonByIdForUpdate(ctx, &id)
+			if err != nil {
+				return err
+			}
+			locked[id] = person
+		}
+		survivor, duplicate := locked[survivorId], locked[duplicateId]
+
+		person, sources, err := mergePerson(survivor, duplicate, fields)
+		if err != nil {
+			return err
+		}
+		rules, err := loadPersonRules(ctx, uow)
+		if err != nil {
+			return err
+		}
+		if err = preparePerson(&person, rules, uow.Persons.Today()); err != nil {
+			return err
+		}
+
+		if err = s.moveRecords(ctx, uow, duplicateId, survivorId); err != nil {
+			return err
+		}
+		if err = uow.Avatars.LockAvatar(ctx, duplicateId); err != nil {
+			return err
+		}
+		if avatar, err = uow.Avatars.DeleteAvatar(ctx, duplicateId); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		snapshot, err := json.Marshal(mappers.ToPersonResponse(duplicate))
+		if err != nil {
+			return err
+		}
+		/* duplicate goes first, survivor may take its login */
+		if _, err = uow.Persons.DeletePerson(ctx, duplicateId); err != nil {
+			return err
+		}
+		if merged, err = uow.Persons.UpdatePerson(ctx, person); err != nil {
+			return err
+		}
+		merge, err = uow.Merges.SaveMerge(ctx, entity.PersonMerge{
+			SurvivorId: survivorId,
+			MergedId:   duplicateId,
+			Merged:     snapshot,
+			Fields:     sources,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err = appendPersonEvent(ctx, uow, events.PersonDeleted, duplicate); err != nil {
+			return err
+		}
+		return appendPersonEvent(ctx, uow, events.PersonUpdated, merged)
+	})
+	if err != nil {
+		return entity.Person{}, entity.PersonMerge{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if avatar.ETag != "" {
+		s.avatars.deleteFiles(ctx, avatar)
+	}
+	return merged, merge, nil
+}
+
+// LoadMerges load history of merges into existing person.
+func (s *MergeService) LoadMerges(ctx context.Context, personId uuid.UUID) ([]entity.PersonMerge, error) {
+	const op = "services.LoadMerges"
+
+	repositories := s.transactions.Repositories()
+	if _, err := repositories.Persons.FindPersonById(ctx, &personId); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	merges, err := repositories.Merges.LoadMerges(ctx, personId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return merges, nil
+}
+
+// moveRecords move records of duplicate to survivor, relationships are locked and checked like on create.
+func (s *MergeService) moveRecords(ctx context.Context, uow *repository.UnitOfWork, duplicateId uuid.UUID, survivorId uuid.UUID) error {
+	var symmetric, single, hierarchical []string
+	for name, kind := range relationshipTypes {
+		if kind.inverse == name {
+			symmetric = append(symmetric, name)
+		}
+		if kind.single {
+			single = append(single, name)
+		}
+		if kind.hierarchical {
+			hierarchical = append(hierarchical, name)
+		}
+	}
+	/* locks are taken in the same order by every merge */
+	sort.Strings(hierarchical)
+	for _, name := range hierarchical {
+		if err := uow.Relationships.LockType(ctx, name); err != nil {
+			return err
+		}
+	}
+
+	if err := uow.Merges.MoveRecords(ctx, duplicateId, survivorId, symmetric, single); err != nil {
+		return err
+	}
+
+	relationships, err := uow.Relationships.LoadRelationships(ctx, survivorId)
+	if err != nil {
+		return err
+	}
+	for _, relationship := range relationships {
+		if relationship.PersonId != survivorId || !relationshipTypes[relationship.Type].hierarchical {
+			continue
+		}
+		cycle, err := uow.Relationships.InChain(ctx, relationship.RelatedId, relationship.Type, survivorId)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return &ValidationError{Message: fmt.Sprintf("Merge would make cycle of %s relationships", relationship.Type)}
+		}
+	}
+	return nil
+}
+
+// scoreDuplicate score candidate by name similarity, shared contacts and the same birth date.
+func scoreDuplicate(person entity.Person, candidate entity.DuplicateCandidate) entity.Duplicate {
+	duplicate := entity.Duplicate{Person: candidate.Person, Reasons: make([]string, 0)}
+
+	switch {
+	case candidate.NameSimilarity < similarNameThreshold:
+	case candidate.Swapped:
+		duplicate.Reasons = append(duplicate.Reasons, ReasonSwappedName)
+	default:
+		duplicate.Reasons = append(duplicate.Reasons, ReasonSimilarName)
+	}
+	score := nameWeight * candidate.NameSimilarity
+
+	if candidate.SharedEmail {
+		duplicate.Reasons = append(duplicate.Reasons, ReasonSharedEmail)
+	}
+	if candidate.SharedPhone {
+		duplicate.Reasons = append(duplicate.Reasons, ReasonSharedPhone)
+	}
+	if candidate.SharedEmail || candidate.SharedPhone {
+		score += contactWeight
+	}
+
+	if sameBirthDate(person, candidate.Person) {
+		duplicate.Reasons = append(duplicate.Reasons, ReasonSameBirthDate)
+		score += birthDateWeight
+	}
+
+	duplicate.Score = math.Round(score*1000) / 1000
+	return duplicate
+}
+
+// sameBirthDate reports whether both persons have the same exact birth date, approximate ones are derived from age.
+func sameBirthDate(person entity.Person, other entity.Person) bool {
+	return person.BirthDate != nil && other.BirthDate != nil &&
+		!person.BirthDateApproximate && !other.BirthDateApproximate && person.BirthDate.Equal(*other.BirthDate)
+}
+
+// mergePerson survivor with fields taken from sources, returns source of every field.
+func mergePerson(survivor entity.Person, duplicate entity.Person, fields map[string]string) (entity.Person, map[string]string, error) {
+	sources := make(map[string]string, len(mergeFields))
+	for _, field := range mergeFields {
+		sources[field] = MergeSurvivor
+	}
+	if survivor.BirthDateApproximate && !duplicate.BirthDateApproximate {
+		sources["birthDate"] = MergeDuplicate
+	}
+	if survivor.Login == "" && duplicate.Login != "" {
+		sources["login"] = MergeDuplicate
+	}
+	sources["attributes"] = MergeBoth
+
+	for field, source := range fields {
+		if _, ok := sources[field]; !ok {
+			return entity.Person{}, nil, &ValidationError{Message: fmt.Sprintf("Unknown merge field: %s", field)}
+		}
+		switch source = strings.ToLower(strings.TrimSpace(source)); {
+		case source == MergeSurvivor || source == MergeDuplicate:
+		case source == MergeBoth && field == "attributes":
+		case field == "attributes":
+			return entity.Person{}, nil, &ValidationError{Message: fmt.Sprintf("Field %s must be merged from survivor, duplicate or both", field)}
+		default:
+			return entity.Person{}, nil, &ValidationError{Message: fmt.Sprintf("Field %s must be merged from survivor or duplicate", field)}
+		}
+		sources[field] = source
+	}
+
+	pick := func(field string) entity.Person {
+		if sources[field] == MergeDuplicate {
+			return duplicate
+		}
+		return survivor
+	}
+	person := survivor
+	person.FirstName = pick("firstName").FirstName
+	person.LastName = pick("lastName").LastName
+	born := pick("birthDate")
+	person.BirthDate, person.BirthDateApproximate, person.Age = born.BirthDate, born.BirthDateApproximate, born.Age
+	person.Login = pick("login").Login
+
+	switch sources["attributes"] {
+	case MergeDuplicate:
+		person.Attributes = duplicate.Attributes
+	case MergeBoth:
+		attributes := make(map[string]any, len(survivor.Attributes)+len(duplicate.Attributes))
+		for name, value := range duplicate.Attributes {
+			attributes[name] = value
+		}
+		for name, value := range survivor.Attributes {
+			attributes[name] = value
+		}
+		person.Attributes = attributes
+	}
+	return person, sources, nil
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"person-service/db/entity"
+	"testing"
+	"time"
+)
+
+func Test_ScoreDuplicate(t *testing.T) {
+	born := time.Date(1990, time.May, 17, 0, 0, 0, 0, time.UTC)
+	other := time.Date(1991, time.May, 17, 0, 0, 0, 0, time.UTC)
+	person := entity.Person{FirstName: "Иван", LastName: "Петров", BirthDate: &born}
+
+	t.Run("similar name", func(t *testing.T) {
+		duplicate := scoreDuplicate(person, entity.DuplicateCandidate{
+			Person:         entity.Person{FirstName: "Ivan", LastName: "Petrov", BirthDate: &other},
+			NameSimilarity: 1,
+		})
+		assert.Equal(t, 0.5, duplicate.Score)
+		assert.Equal(t, []string{ReasonSimilarName}, duplicate.Reasons)
+	})
+
+	t.Run("swapped name, shared contacts and birth date", func(t *testing.T) {
+		duplicate := scoreDuplicate(person, entity.DuplicateCandidate{
+			Person:         entity.Person{FirstName: "Petrov", LastName: "Ivan", BirthDate: &born},
+			NameSimilarity: 0.9, Swapped: true, SharedEmail: true, SharedPhone: true,
+		})
+		assert.Equal(t, 0.95, duplicate.Score)
+		assert.Equal(t, []string{ReasonSwappedName, ReasonSharedEmail, ReasonSharedPhone, ReasonSameBirthDate}, duplicate.Reasons)
+	})
+
+	t.Run("approximate birth date is not compared", func(t *testing.T) {
+		duplicate := scoreDuplicate(person, entity.DuplicateCandidate{
+			Person:         entity.Person{FirstName: "Anna", LastName: "Smirnova", BirthDate: &born, BirthDateApproximate: true},
+			NameSimilarity: 0.1, Swapped: true, SharedPhone: true,
+		})
+		assert.Equal(t, 0.45, duplicate.Score)
+		assert.Equal(t, []string{ReasonSharedPhone}, duplicate.Reasons)
+	})
+}
+
+func Test_MergePerson(t *testing.T) {
+	survivorId, duplicateId := uuid.New(), uuid.New()
+	approximate := time.Date(1990, time.January, 1, 0, 0, 0, 0, time.UTC)
+	exact := time.Date(1990, time.May, 17, 0, 0, 0, 0, time.UTC)
+	survivor := entity.Person{Id: &survivorId, FirstName: "Ivan", LastName: "Petrov", Age: 36,
+		BirthDate: &approximate, BirthDateApproximate: true,
+		Attributes: map[string]any{"team": "core", "level": int64(3)}}
+	duplicate := entity.Person{Id: &duplicateId, FirstName: "Иван", LastName: "Петров", Age: 36, Login: "i.petrov",
+		BirthDate: &exact, Attributes: map[string]any{"team": "ops", "badge": "B-12"}}
+
+	t.Run("defaults", func(t *testing.T) {
+		person, sources, err := mergePerson(survivor, duplicate, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, &survivorId, person.Id)
+		assert.Equal(t, "Ivan", person.FirstName)
+		assert.Equal(t, "Petrov", person.LastName)
+		assert.Equal(t, &exact, person.BirthDate)
+		assert.False(t, person.BirthDateApproximate)
+		assert.Equal(t, "i.petrov", person.Login)
+		assert.Equal(t, map[string]any{"team": "core", "level": int64(3), "badge": "B-12"}, person.Attributes)
+		assert.Equal(t, map[string]string{"firstName": MergeSurvivor, "lastName": MergeSurvivor,
+			"birthDate": MergeDuplicate, "login": MergeDuplicate, "attributes": MergeBoth}, sources)
+	})
+
+	t.Run("chosen sources", func(t *testing.T) {
+		person, sources, err := mergePerson(survivor, duplicate, map[string]string{
+			"firstName": "Duplicate", "birthDate": " survivor", "login": "survivor", "attributes": "duplicate",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "Иван", person.FirstName)
+		assert.Equal(t, "Petrov", person.LastName)
+		assert.Equal(t, &approximate, person.BirthDate)
+		assert.True(t, person.BirthDateApproximate)
+		assert.Equal(t, "", person.Login)
+		assert.Equal(t, duplicate.Attributes, person.Attributes)
+		assert.Equal(t, MergeDuplicate, sources["firstName"])
+	})
+
+	t.Run("invalid sources are rejected", func(t *testing.T) {
+		for name, fields := range map[string]map[string]string{
+			"unknown field":    {"age": MergeSurvivor},
+			"unknown source":   {"firstName": "newest"},
+			"both of not attr": {"login": MergeBoth},
+		} {
+			var validationErr *ValidationError
+			_, _, err := mergePerson(survivor, duplicate, fields)
+			assert.ErrorAs(t, err, &validationErr, name)
+		}
+	})
+}
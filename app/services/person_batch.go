@@ -0,0 +1,291 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"person-service/db/entity"
+	"person-service/db/repository"
+	"person-service/events"
+	"person-service/mappers"
+	"person-service/utils"
+	"time"
+)
+
+// OperationType type of batch operation.
+type OperationType string
+
+const (
+	OperationCreate OperationType = "create"
+	OperationUpsert OperationType = "upsert"
+	OperationDelete OperationType = "delete"
+)
+
+// ErrBatchAborted result of valid operation which was rolled back together with batch.
+var ErrBatchAborted = errors.New("operation was rolled back together with batch")
+
+// PersonOperation single operation of batch, delete uses only id of person.
+type PersonOperation struct {
+	Type   OperationType
+	Person entity.Person
+}
+
+// PersonOperationResult result of operation, Created is true when upsert inserted new person.
+type PersonOperationResult struct {
+	Person  entity.Person
+	Created bool
+	Err     error
+}
+
+// BatchPersons execute operations in request order.
+// Consecutive operations of the same type are executed by one statement.
+// Atomic batch is rolled back on first failed operation, error is returned together with per-item results.
+// Best-effort batch executes failed group again operation by operation, every one in its own savepoint.
+// Operations without id get generated one.
+func (s *PersonService) BatchPersons(ctx context.Context, operations []PersonOperation, atomic bool) ([]PersonOperationResult, error) {
+	const op = "services.BatchPersons"
+
+	results := make([]PersonOperationResult, len(operations))
+	valid := make([]bool, len(operations))
+	invalid := 0
+	today := s.persons.Today()
+	rules, err := s.personRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	stored, err := s.storedAttributes(ctx, operations)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for index, operation := range operations {
+		if operation.Type != OperationDelete {
+			/* upsert keeps stored birth date while approximate one gives the same age and stored attributes when they are absent */
+			operation.Person = withBirthDate(operation.Person, nil, today)
+			if operation.Person.Attributes == nil && operation.Person.Id != nil {
+				operation.Person.Attributes = stored[*operation.Person.Id]
+			}
+		}
+		if err := validateOperation(&operation, rules, today); err != nil {
+			results[index].Err = err
+			invalid++
+			continue
+		}
+		operations[index] = operation
+		valid[index] = true
+		if operation.Type != OperationDelete && (operation.Person.Id == nil || utils.IsNullableUUID(operation.Person.Id)) {
+			id := uuid.New()
+			operations[index].Person.Id = &id
+		}
+	}
+
+	if atomic && invalid > 0 {
+		abort(results)
+		return results, fmt.Errorf("%s: %w", op, results[firstFailed(results)].Err)
+	}
+
+	err = s.transactions.WithinTransaction(ctx, repository.ReadCommitted, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		for _, group := range groupOperations(operations, valid) {
+			if atomic {
+				if err := executeGroup(ctx, uow, rules.tenant, operations, group, results); err != nil {
+					return err
+				}
+				if index := firstFailed(results); index >= 0 {
+					return results[index].Err
+				}
+				continue
+			}
+
+			err := s.transactions.WithinTransaction(ctx, repository.TxOptions{}, func(ctx context.Context, uow *repository.UnitOfWork) error {
+				return executeGroup(ctx, uow, rules.tenant, operations, group, results)
+			})
+			if err == nil {
+				continue
+			}
+
+			/* isolate failed operations of group */
+			for _, index := range group {
+				single := []int{index}
+				err := s.transactions.WithinTransaction(ctx, repository.TxOptions{}, func(ctx context.Context, uow *repository.UnitOfWork) error {
+					return executeGroup(ctx, uow, rules.tenant, operations, single, results)
+				})
+				if err != nil {
+					results[index] = PersonOperationResult{Err: err}
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		/* nothing was committed */
+		abort(results)
+		return results, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return results, nil
+}
+
+// groupOperations split valid operations into groups of consecutive operations with the same type.
+func groupOperations(operations []PersonOperation, valid []bool) [][]int {
+	var groups [][]int
+	var last OperationType
+	for index, operation := range operations {
+		if !valid[index] {
+			continue
+		}
+		if len(groups) == 0 || operation.Type != last {
+			groups = append(groups, nil)
+			last = operation.Type
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], index)
+	}
+	return groups
+}
+
+// executeGroup execute operations of the same type and write their events, results are set by index.
+// Group which created persons fails as a whole when quota of tenant is exceeded.
+func executeGroup(ctx context.Context, uow *repository.UnitOfWork, tenant entity.Tenant, operations []PersonOperation, group []int, results []PersonOperationResult) error {
+	persons := make([]entity.Person, len(group))
+	ids := make([]uuid.UUID, len(group))
+	for position, index := range group {
+		persons[position] = operations[index].Person
+		ids[position] = *operations[index].Person.Id
+	}
+
+	var changed []entity.Person
+	var inserted map[uuid.UUID]bool
+	var err error
+
+	if operations[group[0]].Type != OperationDelete {
+		if err = lockQuota(ctx, uow, tenant); err != nil {
+			return err
+		}
+	}
+	switch operations[group[0]].Type {
+	case OperationCreate:
+		if _, err = uow.Persons.SavePersons(ctx, persons); err == nil {
+			changed, err = uow.Persons.FindPersonsByIds(ctx, ids)
+		}
+		inserted = make(map[uuid.UUID]bool, len(ids))
+		for _, id := range ids {
+			inserted[id] = true
+		}
+	case OperationUpsert:
+		changed, inserted, err = uow.Persons.UpsertPersons(ctx, persons)
+	case OperationDelete:
+		changed, err = uow.Persons.DeletePersons(ctx, ids)
+	}
+	if err != nil {
+		return err
+	}
+	for _, created := range inserted {
+		if created {
+			if err = checkQuota(ctx, uow, tenant); err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	byId := make(map[uuid.UUID]entity.Person, len(changed))
+	for _, person := range changed {
+		byId[*person.Id] = person
+	}
+
+	outbox := make([]entity.OutboxEvent, 0, len(changed))
+	for position, index := range group {
+		person, ok := byId[ids[position]]
+		if !ok {
+			results[index] = PersonOperationResult{Err: fmt.Errorf("person %s: %w", ids[position], pgx.ErrNoRows)}
+			continue
+		}
+		/* the same person may be deleted twice in one group */
+		delete(byId, ids[position])
+
+		results[index] = PersonOperationResult{Person: person, Created: inserted[*person.Id]}
+
+		eventType := events.PersonUpdated
+		switch {
+		case operations[index].Type == OperationDelete:
+			eventType = events.PersonDeleted
+		case inserted[*person.Id]:
+			eventType = events.PersonCreated
+		}
+		payload, err := json.Marshal(mappers.ToPersonResponse(person))
+		if err != nil {
+			return err
+		}
+		outbox = append(outbox, entity.OutboxEvent{AggregateId: *person.Id, EventType: string(eventType), Payload: payload})
+	}
+
+	if len(outbox) == 0 {
+		return nil
+	}
+	return uow.Outbox.AppendAll(ctx, outbox)
+}
+
+// storedAttributes attributes of existing persons upserted without attributes, they are kept like on update.
+func (s *PersonService) storedAttributes(ctx context.Context, operations []PersonOperation) (map[uuid.UUID]map[string]any, error) {
+	var ids []uuid.UUID
+	for _, operation := range operations {
+		person := operation.Person
+		if operation.Type == OperationUpsert && person.Attributes == nil && person.Id != nil && !utils.IsNullableUUID(person.Id) {
+			ids = append(ids, *person.Id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	/* joins transaction of dry run */
+	var existing []entity.Person
+	err := s.transactions.WithinTransaction(ctx, repository.TxOptions{}, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		var err error
+		existing, err = uow.Persons.FindPersonsByIds(ctx, ids)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stored := make(map[uuid.UUID]map[string]any, len(existing))
+	for _, person := range existing {
+		stored[*person.Id] = person.Attributes
+	}
+	return stored, nil
+}
+
+func validateOperation(operation *PersonOperation, rules personRules, today time.Time) error {
+	switch operation.Type {
+	case OperationCreate, OperationUpsert:
+		return preparePerson(&operation.Person, rules, today)
+	case OperationDelete:
+		if operation.Person.Id == nil || utils.IsNullableUUID(operation.Person.Id) {
+			return &ValidationError{Message: "Field id is required for delete operation"}
+		}
+		return nil
+	default:
+		return &ValidationError{Message: fmt.Sprintf("Unknown operation: %s", operation.Type)}
+	}
+}
+
+// abort mark results without own error as rolled back.
+func abort(results []PersonOperationResult) {
+	for index := range results {
+		if results[index].Err == nil {
+			results[index] = PersonOperationResult{Err: ErrBatchAborted}
+		}
+	}
+}
+
+func firstFailed(results []PersonOperationResult) int {
+	for index := range results {
+		if results[index].Err != nil && !errors.Is(results[index].Err, ErrBatchAborted) {
+			return index
+		}
+	}
+	return -1
+}
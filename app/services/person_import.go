@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"person-service/db/entity"
+	"person-service/db/repository"
+	"person-service/utils"
+)
+
+var errDryRun = errors.New("dry run is rolled back")
+
+// ImportPersons upsert chunk of imported persons in best-effort mode.
+// Person without id is matched with existing one by login, person without both is created.
+func (s *PersonService) ImportPersons(ctx context.Context, persons []entity.Person) ([]PersonOperationResult, error) {
+	const op = "services.ImportPersons"
+
+	var logins []string
+	for _, person := range persons {
+		if (person.Id == nil || utils.IsNullableUUID(person.Id)) && person.Login != "" {
+			logins = append(logins, person.Login)
+		}
+	}
+
+	if len(logins) > 0 {
+		/* joins transaction of dry run */
+		var existing []entity.Person
+		err := s.transactions.WithinTransaction(ctx, repository.TxOptions{}, func(ctx context.Context, uow *repository.UnitOfWork) error {
+			var err error
+			existing, err = uow.Persons.FindPersonsByLogins(ctx, logins)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		byLogin := make(map[string]entity.Person, len(existing))
+		for _, person := range existing {
+			byLogin[person.Login] = person
+		}
+		for index, person := range persons {
+			if found, ok := byLogin[person.Login]; ok && (person.Id == nil || utils.IsNullableUUID(person.Id)) {
+				persons[index].Id = found.Id
+			}
+		}
+	}
+
+	operations := make([]PersonOperation, len(persons))
+	for index, person := range persons {
+		operations[index] = PersonOperation{Type: OperationUpsert, Person: person}
+	}
+
+	return s.BatchPersons(ctx, operations, false)
+}
+
+// DryRun runs fn in transaction which is always rolled back.
+func (s *PersonService) DryRun(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := s.transactions.WithinTransaction(ctx, repository.TxOptions{}, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		if err := fn(ctx); err != nil {
+			return err
+		}
+		return errDryRun
+	})
+	if errors.Is(err, errDryRun) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,406 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"person-service/db/entity"
+	"person-service/db/repository"
+	"person-service/events"
+	"person-service/mappers"
+	"person-service/model"
+	"person-service/utils"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const maxAge = 150
+
+// PersonService person use-cases, every change is stored together with its domain event.
+type PersonService struct {
+	persons      *repository.PersonRepositoryImpl
+	transactions *repository.TxManager
+}
+
+func NewPersonService(persons *repository.PersonRepositoryImpl, transactions *repository.TxManager) *PersonService {
+	return &PersonService{persons: persons, transactions: transactions}
+}
+
+// CreatePerson save new person and PersonCreated event.
+func (s *PersonService) CreatePerson(ctx context.Context, p entity.Person) (entity.Person, error) {
+	const op = "services.CreatePerson"
+
+	rules, err := s.personRules(ctx)
+	if err != nil {
+		return entity.Person{}, fmt.Errorf("%s: %w", op, err)
+	}
+	today := s.persons.Today()
+	p = withBirthDate(p, nil, today)
+	if err := preparePerson(&p, rules, today); err != nil {
+		return entity.Person{}, err
+	}
+
+	var saved entity.Person
+	err = s.transactions.WithinTransaction(ctx, repository.ReadCommitted, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		if err := lockQuota(ctx, uow, rules.tenant); err != nil {
+			return err
+		}
+		var err error
+		if saved, err = uow.Persons.SavePerson(ctx, p); err != nil {
+			return err
+		}
+		if err = checkQuota(ctx, uow, rules.tenant); err != nil {
+			return err
+		}
+		return appendPersonEvent(ctx, uow, events.PersonCreated, saved)
+	})
+	if err != nil {
+		return entity.Person{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return saved, nil
+}
+
+// UpdatePerson update existing person or creates new one for empty id.
+func (s *PersonService) UpdatePerson(ctx context.Context, p entity.Person) (entity.Person, error) {
+	const op = "services.UpdatePerson"
+
+	eventType := events.PersonUpdated
+	if p.Id == nil || utils.IsNullableUUID(p.Id) {
+		eventType = events.PersonCreated
+	}
+
+	today := s.persons.Today()
+	var updated entity.Person
+	err := s.transactions.WithinTransaction(ctx, repository.ReadCommitted, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		rules, err := loadPersonRules(ctx, uow)
+		if err != nil {
+			return err
+		}
+		if eventType == events.PersonCreated {
+			if err = lockQuota(ctx, uow, rules.tenant); err != nil {
+				return err
+			}
+		}
+
+		var original *entity.Person
+		if eventType == events.PersonUpdated && (p.BirthDate == nil || p.Attributes == nil) {
+			/* stored birth date is kept while it gives the same age, absent attributes keep stored ones */
+			found, err := uow.Persons.FindPersonByIdForUpdate(ctx, p.Id)
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				return err
+			}
+			if found.Id != nil {
+				original = &found
+			}
+		}
+		person := withBirthDate(p, original, today)
+		if person.Attributes == nil && original != nil {
+			person.Attributes = original.Attributes
+		}
+		if err := preparePerson(&person, rules, today); err != nil {
+			return err
+		}
+
+		if updated, err = uow.Persons.UpdatePerson(ctx, person); err != nil {
+			return err
+		}
+		if eventType == events.PersonCreated {
+			if err = checkQuota(ctx, uow, rules.tenant); err != nil {
+				return err
+			}
+		}
+		return appendPersonEvent(ctx, uow, eventType, updated)
+	})
+	if err != nil {
+		return entity.Person{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return updated, nil
+}
+
+// PatchPerson apply patch to stored person, patched person is validated and only changed columns are saved.
+// Row is locked while patch is applied, PersonUpdated event is written only when person was changed.
+func (s *PersonService) PatchPerson(ctx context.Context, id uuid.UUID, patch func(person *entity.Person) error) (entity.Person, error) {
+	const op = "services.PatchPerson"
+
+	var patched entity.Person
+	err := s.transactions.WithinTransaction(ctx, repository.TxOptions{}, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		original, err := uow.Persons.FindPersonByIdForUpdate(ctx, &id)
+		if err != nil {
+			return err
+		}
+
+		person := original
+		if err = patch(&person); err != nil {
+			return err
+		}
+		rules, err := loadPersonRules(ctx, uow)
+		if err != nil {
+			return err
+		}
+		today := uow.Persons.Today()
+		person = withBirthDate(person, &original, today)
+		if err = preparePerson(&person, rules, today); err != nil {
+			return err
+		}
+
+		var changed bool
+		if patched, changed, err = uow.Persons.PatchPerson(ctx, original, person); err != nil || !changed {
+			return err
+		}
+		return appendPersonEvent(ctx, uow, events.PersonUpdated, patched)
+	})
+	if err != nil {
+		return entity.Person{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return patched, nil
+}
+
+// DeletePerson remove person, PersonDeleted event is written only when person existed.
+func (s *PersonService) DeletePerson(ctx context.Context, id uuid.UUID) (string, error) {
+	const op = "services.DeletePerson"
+
+	var deletedId string
+	err := s.transactions.WithinTransaction(ctx, repository.TxOptions{}, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		existing, err := uow.Persons.FindPersonById(ctx, &id)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		if deletedId, err = uow.Persons.DeletePerson(ctx, id); err != nil {
+			return err
+		}
+
+		if existing.Id == nil {
+			return nil
+		}
+		return appendPersonEvent(ctx, uow, events.PersonDeleted, existing)
+	})
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return deletedId, nil
+}
+
+// FindPersonById find person by id.
+func (s *PersonService) FindPersonById(ctx context.Context, id *uuid.UUID) (entity.Person, error) {
+	return s.persons.FindPersonById(ctx, id)
+}
+
+// FindSurvivor find id of person which merged person was merged into, returns pgx.ErrNoRows for not merged id.
+func (s *PersonService) FindSurvivor(ctx context.Context, mergedId uuid.UUID) (uuid.UUID, error) {
+	return s.transactions.Repositories().Merges.FindSurvivor(ctx, mergedId)
+}
+
+// FindPersonByLogin find person by login.
+func (s *PersonService) FindPersonByLogin(ctx context.Context, login string) (entity.Person, error) {
+	return s.persons.FindPersonByLogin(ctx, login)
+}
+
+// FindPersonsByIds find persons by ids, missing persons are absent in result.
+func (s *PersonService) FindPersonsByIds(ctx context.Context, ids []uuid.UUID) ([]entity.Person, error) {
+	return s.persons.FindPersonsByIds(ctx, ids)
+}
+
+// FindPersonsByLogins find persons by logins, missing persons are absent in result.
+func (s *PersonService) FindPersonsByLogins(ctx context.Context, logins []string) ([]entity.Person, error) {
+	return s.persons.FindPersonsByLogins(ctx, logins)
+}
+
+// SearchPersons load page of persons matching filter in sort order after cursor.
+func (s *PersonService) SearchPersons(ctx context.Context, filter repository.PersonFilter, sort repository.PersonSort, after *repository.PersonCursor, limit int) ([]entity.Person, error) {
+	return s.persons.SearchPersons(ctx, filter, sort, after, limit)
+}
+
+// LoadPersons load page of persons.
+func (s *PersonService) LoadPersons(ctx context.Context, filter repository.PersonFilter, page *string) ([]entity.Person, error) {
+	return s.persons.LoadPersons(ctx, filter, page)
+}
+
+// ExportPersons pass all persons matching filter to fn, persons are read in read-only transaction with cursor.
+func (s *PersonService) ExportPersons(ctx context.Context, filter repository.PersonFilter, fetchSize int, fn func(person entity.Person) error) error {
+	const op = "services.ExportPersons"
+
+	err := s.transactions.WithinTransaction(ctx, repository.TxOptions{AccessMode: pgx.ReadOnly}, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		return uow.Persons.StreamPersons(ctx, filter, fetchSize, fn)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// AttributeFilter convert raw attribute values of listing filter by attribute definitions.
+func (s *PersonService) AttributeFilter(ctx context.Context, raw map[string]string) (map[string]any, error) {
+	const op = "services.AttributeFilter"
+
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	rules, err := s.personRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	values := make(map[string]any, len(raw))
+	for name, value := range raw {
+		values[name] = value
+	}
+	/* filter matches some attributes only, so required ones are not checked */
+	optional := make([]entity.AttributeDefinition, len(rules.definitions))
+	for index, definition := range rules.definitions {
+		definition.Required = false
+		optional[index] = definition
+	}
+	return normalizeAttributes(optional, values)
+}
+
+// personRules rules persons of current tenant are validated by, joins running transaction.
+func (s *PersonService) personRules(ctx context.Context) (personRules, error) {
+	var rules personRules
+	err := s.transactions.WithinTransaction(ctx, repository.TxOptions{}, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		var err error
+		rules, err = loadPersonRules(ctx, uow)
+		return err
+	})
+	return rules, err
+}
+
+// personRules registry of custom attributes and settings of tenant persons are validated by.
+type personRules struct {
+	definitions []entity.AttributeDefinition
+	tenant      entity.Tenant
+	login       *regexp.Regexp
+}
+
+// loadPersonRules rules of tenant of ctx, ctx without tenant gets rules of zero tenant, which keep defaults.
+func loadPersonRules(ctx context.Context, uow *repository.UnitOfWork) (personRules, error) {
+	definitions, err := uow.Attributes.LoadDefinitions(ctx)
+	if err != nil {
+		return personRules{}, err
+	}
+
+	tenant, err := uow.Tenants.FindCurrentTenant(ctx)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return personRules{}, err
+	}
+	return newPersonRules(definitions, tenant)
+}
+
+func newPersonRules(definitions []entity.AttributeDefinition, tenant entity.Tenant) (personRules, error) {
+	rules := personRules{definitions: definitions, tenant: tenant}
+	if tenant.LoginPattern != "" {
+		login, err := regexp.Compile(tenant.LoginPattern)
+		if err != nil {
+			return personRules{}, err
+		}
+		rules.login = login
+	}
+	return rules, nil
+}
+
+// LoadEvents load up to limit person events with id greater than afterId, personId optionally filters events of one person.
+func (s *PersonService) LoadEvents(ctx context.Context, afterId int64, personId *uuid.UUID, limit int) ([]events.Event, error) {
+	const op = "services.LoadEvents"
+
+	stored, err := s.transactions.Repositories().Outbox.FetchAfter(ctx, afterId, personId, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	loaded := make([]events.Event, len(stored))
+	for index, event := range stored {
+		loaded[index] = events.FromOutbox(event)
+	}
+	return loaded, nil
+}
+
+// LastEventId id of the latest person event, watchers start after it.
+func (s *PersonService) LastEventId(ctx context.Context) (int64, error) {
+	return s.transactions.Repositories().Outbox.LastId(ctx)
+}
+
+// withBirthDate set age of person with birth date, person without it gets approximate one derived from age.
+// Birth date of original person is kept while it gives the same age.
+func withBirthDate(person entity.Person, original *entity.Person, today time.Time) entity.Person {
+	switch {
+	case person.BirthDate != nil:
+		person.BirthDateApproximate = false
+		person.Age = entity.AgeAt(*person.BirthDate, today)
+	case original != nil && original.BirthDate != nil && original.Age == person.Age:
+		person.BirthDate = original.BirthDate
+		person.BirthDateApproximate = original.BirthDateApproximate
+	default:
+		birthDate := entity.BirthDateAt(person.Age, today)
+		person.BirthDate = &birthDate
+		person.BirthDateApproximate = true
+	}
+	return person
+}
+
+// preparePerson validate person prepared by withBirthDate and normalize its attributes.
+func preparePerson(person *entity.Person, rules personRules, today time.Time) error {
+	if err := validatePerson(*person, rules, today); err != nil {
+		return err
+	}
+
+	attributes, err := normalizeAttributes(rules.definitions, person.Attributes)
+	if err != nil {
+		return err
+	}
+	person.Attributes = attributes
+	return nil
+}
+
+// validatePerson validate person prepared by withBirthDate, tenant may lower age limit and restrict login.
+func validatePerson(person entity.Person, rules personRules, today time.Time) error {
+	ageLimit := maxAge
+	if rules.tenant.MaxAge > 0 {
+		ageLimit = rules.tenant.MaxAge
+	}
+
+	switch {
+	case strings.TrimSpace(person.FirstName) == "":
+		return &ValidationError{Message: "Field firstName must not be empty"}
+	case strings.TrimSpace(person.LastName) == "":
+		return &ValidationError{Message: "Field lastName must not be empty"}
+	case !person.BirthDateApproximate && person.BirthDate.After(today):
+		return &ValidationError{Message: "Field birthDate must not be in the future"}
+	case !person.BirthDateApproximate && person.Age > ageLimit:
+		return &ValidationError{Message: fmt.Sprintf("Field birthDate must not be more than %d years ago", ageLimit)}
+	case person.Age < 0 || person.Age > ageLimit:
+		return &ValidationError{Message: fmt.Sprintf("Field age must be between 0 and %d", ageLimit)}
+	case rules.tenant.RequireLogin && strings.TrimSpace(person.Login) == "":
+		return &ValidationError{Message: "Field login is required"}
+	case rules.login != nil && person.Login != "" && !rules.login.MatchString(person.Login):
+		return &ValidationError{Message: fmt.Sprintf("Field login must match pattern %s", rules.login)}
+	}
+	return nil
+}
+
+func appendPersonEvent(ctx context.Context, uow *repository.UnitOfWork, eventType events.Type, person entity.Person) error {
+	return appendPersonResponseEvent(ctx, uow, eventType, mappers.ToPersonResponse(person))
+}
+
+// appendPersonResponseEvent append event with prepared payload, used when payload embeds sub-resources of person.
+func appendPersonResponseEvent(ctx context.Context, uow *repository.UnitOfWork, eventType events.Type, person model.PersonResponse) error {
+	payload, err := json.Marshal(person)
+	if err != nil {
+		return err
+	}
+
+	_, err = uow.Outbox.Append(ctx, entity.OutboxEvent{
+		AggregateId: person.Id,
+		EventType:   string(eventType),
+		Payload:     payload,
+	})
+	return err
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"github.com/stretchr/testify/assert"
+	"person-service/db/entity"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) *time.Time {
+	value := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return &value
+}
+
+func Test_WithBirthDate(t *testing.T) {
+	today := *date(2024, 2, 29)
+
+	t.Run("age is computed from birth date", func(t *testing.T) {
+		person := withBirthDate(entity.Person{Age: 99, BirthDate: date(2006, 3, 1)}, nil, today)
+		assert.Equal(t, 17, person.Age)
+		assert.False(t, person.BirthDateApproximate)
+
+		person = withBirthDate(entity.Person{BirthDate: date(2006, 2, 28)}, nil, today)
+		assert.Equal(t, 18, person.Age)
+	})
+
+	t.Run("age alone gives approximate birth date", func(t *testing.T) {
+		person := withBirthDate(entity.Person{Age: 18}, nil, today)
+		assert.Equal(t, date(2006, 2, 28), person.BirthDate)
+		assert.True(t, person.BirthDateApproximate)
+		assert.Equal(t, 18, entity.AgeAt(*person.BirthDate, today))
+	})
+
+	t.Run("stored birth date is kept while age is the same", func(t *testing.T) {
+		original := entity.Person{Age: 40, BirthDate: date(1983, 11, 2), BirthDateApproximate: true}
+
+		person := withBirthDate(entity.Person{Age: 40}, &original, today)
+		assert.Equal(t, original.BirthDate, person.BirthDate)
+		assert.True(t, person.BirthDateApproximate)
+
+		person = withBirthDate(entity.Person{Age: 41}, &original, today)
+		assert.Equal(t, date(1983, 2, 28), person.BirthDate)
+	})
+}
+
+func Test_ValidatePerson(t *testing.T) {
+	today := *date(2024, 5, 1)
+
+	valid := withBirthDate(entity.Person{FirstName: "Анна", LastName: "Белова", BirthDate: date(1874, 5, 1)}, nil, today)
+	assert.NoError(t, validatePerson(valid, personRules{}, today))
+
+	for name, person := range map[string]entity.Person{
+		"future birth date":  {FirstName: "Анна", LastName: "Белова", BirthDate: date(2024, 5, 2)},
+		"too old birth date": {FirstName: "Анна", LastName: "Белова", BirthDate: date(1873, 5, 1)},
+		"negative age":       {FirstName: "Анна", LastName: "Белова", Age: -1},
+		"too big age":        {FirstName: "Анна", LastName: "Белова", Age: maxAge + 1},
+	} {
+		var validationErr *ValidationError
+		assert.ErrorAs(t, validatePerson(withBirthDate(person, nil, today), personRules{}, today), &validationErr, name)
+	}
+}
+
+func Test_ValidatePersonOfTenant(t *testing.T) {
+	today := *date(2024, 5, 1)
+	rules, err := newPersonRules(nil, entity.Tenant{MaxAge: 100, LoginPattern: `^[a-z]+\.[a-z]+$`, RequireLogin: true})
+	assert.NoError(t, err)
+
+	valid := withBirthDate(entity.Person{FirstName: "Анна", LastName: "Белова", Age: 100, Login: "anna.belova"}, nil, today)
+	assert.NoError(t, validatePerson(valid, rules, today))
+
+	for name, person := range map[string]entity.Person{
+		"age above tenant limit": {FirstName: "Анна", LastName: "Белова", Age: 101, Login: "anna.belova"},
+		"missing login":          {FirstName: "Анна", LastName: "Белова", Age: 30},
+		"login of other pattern": {FirstName: "Анна", LastName: "Белова", Age: 30, Login: "Anna"},
+	} {
+		var validationErr *ValidationError
+		assert.ErrorAs(t, validatePerson(withBirthDate(person, nil, today), rules, today), &validationErr, name)
+	}
+}
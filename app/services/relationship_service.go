@@ -0,0 +1,282 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"person-service/db/entity"
+	"person-service/db/repository"
+	"sort"
+	"strings"
+)
+
+// relationshipType kind of relationship, related person is <type> of person.
+type relationshipType struct {
+	/* name of relationship seen from related person, the same name for symmetric type, empty without inverse */
+	inverse string
+	/* person has at most one relationship of type */
+	single bool
+	/* relationships form hierarchy without cycles, related person is above person */
+	hierarchical bool
+}
+
+var relationshipTypes = map[string]relationshipType{
+	"manager":           {inverse: "report", single: true, hierarchical: true},
+	"parent":            {inverse: "child", hierarchical: true},
+	"spouse":            {inverse: "spouse"},
+	"sibling":           {inverse: "sibling"},
+	"emergency_contact": {},
+}
+
+// DefaultHierarchyType type of reports tree and chain of command when type is not requested.
+const DefaultHierarchyType = "manager"
+
+// ErrSingleRelationship person already has relationship of type which allows only one.
+var ErrSingleRelationship = errors.New("person already has relationship of this type")
+
+// RelationshipService typed directed relationships between persons. Relationships are not part of person,
+// so their change neither touches persons nor writes events.
+type RelationshipService struct {
+	transactions *repository.TxManager
+	maxDepth     int
+}
+
+func NewRelationshipService(transactions *repository.TxManager, maxDepth int) *RelationshipService {
+	return &RelationshipService{transactions: transactions, maxDepth: maxDepth}
+}
+
+// CreateRelationship save relationship "related person is <type> of person", inverse type is stored in canonical direction.
+// Hierarchical relationship must not make cycle, checks of the same type are serialized by lock.
+// Returned relationship is seen from person.
+func (s *RelationshipService) CreateRelationship(ctx context.Context, relationship entity.Relationship) (entity.Relationship, error) {
+	const op = "services.CreateRelationship"
+
+	viewer := relationship.PersonId
+	canonical, err := canonicalRelationship(relationship)
+	if err != nil {
+		return entity.Relationship{}, err
+	}
+	kind := relationshipTypes[canonical.Type]
+
+	/* checks after type lock see relationships of previous lock holder at read committed only */
+	var saved entity.Relationship
+	err = s.transactions.WithinTransaction(ctx, repository.ReadCommitted, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		if kind.single || kind.hierarchical {
+			if err := uow.Relationships.LockType(ctx, canonical.Type); err != nil {
+				return err
+			}
+		}
+		if _, err := uow.Persons.FindPersonById(ctx, &viewer); err != nil {
+			return err
+		}
+		if err := checkPersons(ctx, uow, []uuid.UUID{relationship.RelatedId}); err != nil {
+			return err
+		}
+
+		if kind.single {
+			count, err := uow.Relationships.CountRelationships(ctx, canonical.PersonId, canonical.Type)
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				return ErrSingleRelationship
+			}
+		}
+		if kind.hierarchical {
+			/* person must not be above related person already */
+			cycle, err := uow.Relationships.InChain(ctx, canonical.RelatedId, canonical.Type, canonical.PersonId)
+			if err != nil {
+				return err
+			}
+			if cycle {
+				return &ValidationError{Message: fmt.Sprintf("Relationship %s would make cycle", canonical.Type)}
+			}
+		}
+
+		canonical.Id = uuid.New()
+		var err error
+		saved, err = uow.Relationships.SaveRelationship(ctx, canonical)
+		return err
+	})
+	if err != nil {
+		return entity.Relationship{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return viewOf(saved, viewer), nil
+}
+
+// DeleteRelationship delete relationship of person in any direction, returns false for unknown relationship.
+func (s *RelationshipService) DeleteRelationship(ctx context.Context, personId uuid.UUID, id uuid.UUID) (bool, error) {
+	const op = "services.DeleteRelationship"
+
+	deleted, err := s.transactions.Repositories().Relationships.DeleteRelationship(ctx, personId, id)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return deleted, nil
+}
+
+// LoadRelationships load relationships of existing person seen from it, optionally only of type seen from person.
+func (s *RelationshipService) LoadRelationships(ctx context.Context, personId uuid.UUID, relationshipType string) ([]entity.Relationship, error) {
+	const op = "services.LoadRelationships"
+
+	repositories := s.transactions.Repositories()
+	if _, err := repositories.Persons.FindPersonById(ctx, &personId); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	stored, err := repositories.Relationships.LoadRelationships(ctx, personId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	relationships := make([]entity.Relationship, 0, len(stored))
+	for _, relationship := range stored {
+		view := viewOf(relationship, personId)
+		if relationshipType == "" || view.Type == relationshipType {
+			relationships = append(relationships, view)
+		}
+	}
+	return relationships, nil
+}
+
+// LoadReports load tree of persons below person by hierarchical type up to depth, zero depth is max depth.
+func (s *RelationshipService) LoadReports(ctx context.Context, personId uuid.UUID, relationshipType string, depth int) ([]entity.HierarchyNode, error) {
+	return s.loadHierarchy(ctx, "services.LoadReports", personId, relationshipType, depth, (*repository.RelationshipRepositoryImpl).LoadReports)
+}
+
+// LoadChain load tree of persons above person by hierarchical type up to depth (chain of command for manager),
+// zero depth is max depth.
+func (s *RelationshipService) LoadChain(ctx context.Context, personId uuid.UUID, relationshipType string, depth int) ([]entity.HierarchyNode, error) {
+	return s.loadHierarchy(ctx, "services.LoadChain", personId, relationshipType, depth, (*repository.RelationshipRepositoryImpl).LoadChain)
+}
+
+type hierarchyLoader func(r *repository.RelationshipRepositoryImpl, ctx context.Context, personId uuid.UUID, relationshipType string, depth int) ([]entity.HierarchyNode, error)
+
+func (s *RelationshipService) loadHierarchy(ctx context.Context, op string, personId uuid.UUID, relationshipType string, depth int, load hierarchyLoader) ([]entity.HierarchyNode, error) {
+	if relationshipType == "" {
+		relationshipType = DefaultHierarchyType
+	}
+	if !relationshipTypes[relationshipType].hierarchical {
+		return nil, &ValidationError{Message: fmt.Sprintf("Relationship type must be hierarchical: %s", strings.Join(hierarchicalTypes(), ", "))}
+	}
+	if depth == 0 {
+		depth = s.maxDepth
+	}
+	if depth < 1 || depth > s.maxDepth {
+		return nil, &ValidationError{Message: fmt.Sprintf("Parameter depth must be from 1 to %d", s.maxDepth)}
+	}
+
+	repositories := s.transactions.Repositories()
+	if _, err := repositories.Persons.FindPersonById(ctx, &personId); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	/* one more level tells which nodes of the last level are truncated */
+	nodes, err := load(repositories.Relationships, ctx, personId, relationshipType, depth+1)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return buildHierarchy(nodes, personId, depth), nil
+}
+
+// buildHierarchy tree of flat nodes ordered by depth, nodes below depth only mark their links as truncated.
+// Person reached by several paths is repeated under every link.
+func buildHierarchy(nodes []entity.HierarchyNode, rootId uuid.UUID, depth int) []entity.HierarchyNode {
+	type link struct{ personId, linkId uuid.UUID }
+
+	byLink := make(map[uuid.UUID][]entity.HierarchyNode)
+	truncated := make(map[uuid.UUID]bool)
+	seen := make(map[link]bool)
+	for _, node := range nodes {
+		key := link{personId: *node.Person.Id, linkId: node.LinkId}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if node.Depth > depth {
+			truncated[node.LinkId] = true
+			continue
+		}
+		byLink[node.LinkId] = append(byLink[node.LinkId], node)
+	}
+
+	var build func(linkId uuid.UUID, path map[uuid.UUID]bool) []entity.HierarchyNode
+	build = func(linkId uuid.UUID, path map[uuid.UUID]bool) []entity.HierarchyNode {
+		children := make([]entity.HierarchyNode, 0, len(byLink[linkId]))
+		for _, node := range byLink[linkId] {
+			id := *node.Person.Id
+			if path[id] {
+				continue
+			}
+			path[id] = true
+			node.Nodes = build(id, path)
+			delete(path, id)
+
+			node.Truncated = node.Depth == depth && truncated[id]
+			children = append(children, node)
+		}
+		return children
+	}
+
+	return build(rootId, map[uuid.UUID]bool{rootId: true})
+}
+
+// canonicalRelationship relationship in stored direction: inverse type is swapped to its type,
+// symmetric relationship starts with lower id so the same pair is stored once.
+func canonicalRelationship(relationship entity.Relationship) (entity.Relationship, error) {
+	relationshipType := strings.ToLower(strings.TrimSpace(relationship.Type))
+	if _, ok := relationshipTypes[relationshipType]; !ok {
+		inverted := false
+		for name, kind := range relationshipTypes {
+			if kind.inverse == relationshipType && kind.inverse != "" {
+				relationshipType, inverted = name, true
+				break
+			}
+		}
+		if !inverted {
+			return entity.Relationship{}, &ValidationError{Message: fmt.Sprintf("Unknown relationship type: %s", relationship.Type)}
+		}
+		relationship.PersonId, relationship.RelatedId = relationship.RelatedId, relationship.PersonId
+	}
+	relationship.Type = relationshipType
+
+	if relationship.PersonId == relationship.RelatedId {
+		return entity.Relationship{}, &ValidationError{Message: "Person must not be related to itself"}
+	}
+	if relationshipTypes[relationshipType].inverse == relationshipType && relationship.PersonId.String() > relationship.RelatedId.String() {
+		relationship.PersonId, relationship.RelatedId = relationship.RelatedId, relationship.PersonId
+	}
+	return relationship, nil
+}
+
+// viewOf relationship seen from person: related person is Type of person.
+func viewOf(relationship entity.Relationship, personId uuid.UUID) entity.Relationship {
+	if relationship.PersonId == personId {
+		return relationship
+	}
+
+	kind := relationshipTypes[relationship.Type]
+	/* symmetric relationship is the same from both sides */
+	relationship.Incoming = kind.inverse != relationship.Type
+	relationship.PersonId, relationship.RelatedId = relationship.RelatedId, relationship.PersonId
+	if kind.inverse != "" {
+		relationship.Type = kind.inverse
+	}
+	return relationship
+}
+
+func hierarchicalTypes() []string {
+	var names []string
+	for name, kind := range relationshipTypes {
+		if kind.hierarchical {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"person-service/db/entity"
+	"testing"
+)
+
+func Test_CanonicalRelationship(t *testing.T) {
+	low := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	high := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+
+	t.Run("inverse type is stored in opposite direction", func(t *testing.T) {
+		canonical, err := canonicalRelationship(entity.Relationship{PersonId: low, RelatedId: high, Type: " Report "})
+		assert.NoError(t, err)
+		assert.Equal(t, entity.Relationship{PersonId: high, RelatedId: low, Type: "manager"}, canonical)
+	})
+
+	t.Run("symmetric type starts with lower id", func(t *testing.T) {
+		canonical, err := canonicalRelationship(entity.Relationship{PersonId: high, RelatedId: low, Type: "spouse"})
+		assert.NoError(t, err)
+		assert.Equal(t, entity.Relationship{PersonId: low, RelatedId: high, Type: "spouse"}, canonical)
+	})
+
+	t.Run("invalid relationships are rejected", func(t *testing.T) {
+		for name, relationship := range map[string]entity.Relationship{
+			"unknown type": {PersonId: low, RelatedId: high, Type: "friend"},
+			"self":         {PersonId: low, RelatedId: low, Type: "manager"},
+		} {
+			var validationErr *ValidationError
+			_, err := canonicalRelationship(relationship)
+			assert.ErrorAs(t, err, &validationErr, name)
+		}
+	})
+}
+
+func Test_ViewOf(t *testing.T) {
+	person, related := uuid.New(), uuid.New()
+
+	view := viewOf(entity.Relationship{PersonId: person, RelatedId: related, Type: "manager"}, related)
+	assert.Equal(t, entity.Relationship{PersonId: related, RelatedId: person, Type: "report", Incoming: true}, view)
+
+	view = viewOf(entity.Relationship{PersonId: person, RelatedId: related, Type: "emergency_contact"}, related)
+	assert.Equal(t, "emergency_contact", view.Type)
+	assert.True(t, view.Incoming)
+
+	view = viewOf(entity.Relationship{PersonId: person, RelatedId: related, Type: "sibling"}, related)
+	assert.Equal(t, person, view.RelatedId)
+	assert.False(t, view.Incoming)
+}
+
+func Test_BuildHierarchy(t *testing.T) {
+	root, lead, first, second, intern := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	node := func(id uuid.UUID, linkId uuid.UUID, depth int) entity.HierarchyNode {
+		return entity.HierarchyNode{Person: entity.Person{Id: &id}, LinkId: linkId, Depth: depth}
+	}
+
+	/* loaded one level deeper than requested depth 2 */
+	tree := buildHierarchy([]entity.HierarchyNode{
+		node(lead, root, 1),
+		node(first, lead, 2),
+		node(second, lead, 2),
+		node(second, lead, 2),
+		node(intern, first, 3),
+	}, root, 2)
+
+	assert.Len(t, tree, 1)
+	assert.Equal(t, lead, *tree[0].Person.Id)
+	assert.False(t, tree[0].Truncated)
+	assert.Len(t, tree[0].Nodes, 2)
+	assert.True(t, tree[0].Nodes[0].Truncated)
+	assert.False(t, tree[0].Nodes[1].Truncated)
+	assert.Empty(t, tree[0].Nodes[0].Nodes)
+}
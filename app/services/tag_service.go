@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"person-service/db/entity"
+	"person-service/db/repository"
+	"regexp"
+	"strings"
+)
+
+var tagPattern = regexp.MustCompile(`^[\p{Ll}\p{Lo}\p{N}][\p{Ll}\p{Lo}\p{N}_.:-]{0,62}$`)
+
+// TagService free-form tags of persons, tag exists while some person has it.
+type TagService struct {
+	transactions *repository.TxManager
+}
+
+func NewTagService(transactions *repository.TxManager) *TagService {
+	return &TagService{transactions: transactions}
+}
+
+// AddTag tag existing persons, returns count of persons which were not tagged yet.
+func (s *TagService) AddTag(ctx context.Context, tag string, personIds []uuid.UUID) (int64, error) {
+	const op = "services.AddTag"
+
+	tag, err := NormalizeTag(tag)
+	if err != nil {
+		return 0, err
+	}
+	if personIds, err = uniqueIds(personIds); err != nil {
+		return 0, err
+	}
+
+	var added int64
+	err = s.transactions.WithinTransaction(ctx, repository.TxOptions{}, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		if err := checkPersons(ctx, uow, personIds); err != nil {
+			return err
+		}
+
+		var err error
+		added, err = uow.Tags.AddTag(ctx, tag, personIds)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return added, nil
+}
+
+// RemoveTag untag persons, returns count of persons which were tagged.
+func (s *TagService) RemoveTag(ctx context.Context, tag string, personIds []uuid.UUID) (int64, error) {
+	const op = "services.RemoveTag"
+
+	tag, err := NormalizeTag(tag)
+	if err != nil {
+		return 0, err
+	}
+	if personIds, err = uniqueIds(personIds); err != nil {
+		return 0, err
+	}
+
+	removed, err := s.transactions.Repositories().Tags.RemoveTag(ctx, tag, personIds)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return removed, nil
+}
+
+// LoadTags load all tags with counts of tagged persons.
+func (s *TagService) LoadTags(ctx context.Context) ([]entity.Tag, error) {
+	return s.transactions.Repositories().Tags.LoadTags(ctx)
+}
+
+// LoadTagged load page of 50 persons with tag.
+func (s *TagService) LoadTagged(ctx context.Context, tag string, page *string) ([]entity.Person, error) {
+	const op = "services.LoadTagged"
+
+	tag, err := NormalizeTag(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	persons, err := s.transactions.Repositories().Persons.LoadPersons(ctx, repository.PersonFilter{Tags: []string{tag}}, page)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return persons, nil
+}
+
+// NormalizeTag trimmed lower-case tag of letters, digits and _ . : - up to 63 characters.
+func NormalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if !tagPattern.MatchString(tag) {
+		return "", &ValidationError{Message: "Tag must start with letter or digit and contain only letters, digits and _ . : - (up to 63)"}
+	}
+	return tag, nil
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"person-service/db/entity"
+	"person-service/db/repository"
+	"regexp"
+	"strings"
+)
+
+const maxTenantName = 200
+
+var (
+	// ErrTenantRequired request has no tenant while tenancy.required is set.
+	ErrTenantRequired = errors.New("tenant is required")
+	// ErrTenantMismatch tenant of header differs from tenant claimed by token.
+	ErrTenantMismatch = errors.New("tenant of header does not match tenant of token")
+	// ErrTenantNotClaimed header requests tenant while token claims none, only claim picks tenant of secured request.
+	ErrTenantNotClaimed = errors.New("tenant of header is not claimed by token")
+	// ErrTenantNotFound tenant of request does not exist.
+	ErrTenantNotFound = errors.New("tenant not found")
+	// ErrTenantSuspended tenant of request is suspended.
+	ErrTenantSuspended = errors.New("tenant is suspended")
+	// ErrQuotaExceeded tenant would have more persons than its quota allows.
+	ErrQuotaExceeded = errors.New("quota of persons of tenant is exceeded")
+)
+
+// TenantService resolution of request tenant and administration of tenants.
+type TenantService struct {
+	transactions *repository.TxManager
+	enabled      bool
+	required     bool
+}
+
+func NewTenantService(transactions *repository.TxManager, enabled bool, required bool) *TenantService {
+	return &TenantService{transactions: transactions, enabled: enabled, required: required}
+}
+
+// ResolveTenant tenant serving request by tenant id claimed by token and requested by header, empty values are absent.
+// Header must match the claim, it picks tenant alone only for unsecured requests. Request without tenant
+// is served by default tenant unless tenant is required, every request is when tenancy is disabled.
+func (s *TenantService) ResolveTenant(ctx context.Context, claimed string, requested string, secured bool) (uuid.UUID, error) {
+	const op = "services.ResolveTenant"
+
+	if !s.enabled {
+		return entity.DefaultTenantId, nil
+	}
+
+	tenantId, err := requestTenant(claimed, requested, secured)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	if tenantId == nil {
+		if s.required {
+			return uuid.UUID{}, ErrTenantRequired
+		}
+		tenantId = &entity.DefaultTenantId
+	}
+
+	tenant, err := s.transactions.Repositories().Tenants.FindTenant(ctx, *tenantId)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return uuid.UUID{}, ErrTenantNotFound
+	case err != nil:
+		return uuid.UUID{}, fmt.Errorf("%s: %w", op, err)
+	case tenant.Status == entity.TenantSuspended:
+		return uuid.UUID{}, ErrTenantSuspended
+	}
+
+	return tenant.Id, nil
+}
+
+// CreateTenant provision new active tenant.
+func (s *TenantService) CreateTenant(ctx context.Context, tenant entity.Tenant) (entity.Tenant, error) {
+	const op = "services.CreateTenant"
+
+	if err := validateTenant(&tenant); err != nil {
+		return entity.Tenant{}, err
+	}
+
+	tenant.Id = uuid.New()
+	saved, err := s.transactions.Repositories().Tenants.SaveTenant(ctx, tenant)
+	if err != nil {
+		return entity.Tenant{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return saved, nil
+}
+
+// UpdateTenant update name and settings of tenant, lowered quota does not delete persons but stops creating them.
+func (s *TenantService) UpdateTenant(ctx context.Context, tenant entity.Tenant) (entity.Tenant, error) {
+	const op = "services.UpdateTenant"
+
+	if err := validateTenant(&tenant); err != nil {
+		return entity.Tenant{}, err
+	}
+
+	updated, err := s.transactions.Repositories().Tenants.UpdateTenant(ctx, tenant)
+	if err != nil {
+		return entity.Tenant{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return updated, nil
+}
+
+// SuspendTenant reject requests of tenant until it is resumed, data of tenant is kept.
+func (s *TenantService) SuspendTenant(ctx context.Context, id uuid.UUID) (entity.Tenant, error) {
+	return s.updateStatus(ctx, id, entity.TenantSuspended)
+}
+
+// ResumeTenant serve requests of suspended tenant again.
+func (s *TenantService) ResumeTenant(ctx context.Context, id uuid.UUID) (entity.Tenant, error) {
+	return s.updateStatus(ctx, id, entity.TenantActive)
+}
+
+func (s *TenantService) updateStatus(ctx context.Context, id uuid.UUID, status string) (entity.Tenant, error) {
+	const op = "services.UpdateTenantStatus"
+
+	if id == entity.DefaultTenantId && status == entity.TenantSuspended {
+		return entity.Tenant{}, &ValidationError{Message: "Default tenant must not be suspended"}
+	}
+
+	updated, err := s.transactions.Repositories().Tenants.UpdateStatus(ctx, id, status)
+	if err != nil {
+		return entity.Tenant{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return updated, nil
+}
+
+// FindTenant find tenant by id.
+func (s *TenantService) FindTenant(ctx context.Context, id uuid.UUID) (entity.Tenant, error) {
+	return s.transactions.Repositories().Tenants.FindTenant(ctx, id)
+}
+
+// LoadTenants load all tenants ordered by name.
+func (s *TenantService) LoadTenants(ctx context.Context) ([]entity.Tenant, error) {
+	return s.transactions.Repositories().Tenants.LoadTenants(ctx)
+}
+
+// requestTenant tenant id of claim or header, nil when request has none.
+func requestTenant(claimed string, requested string, secured bool) (*uuid.UUID, error) {
+	if secured && strings.TrimSpace(claimed) == "" && strings.TrimSpace(requested) != "" {
+		return nil, ErrTenantNotClaimed
+	}
+
+	var tenantId *uuid.UUID
+	for _, value := range []string{claimed, requested} {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			return nil, &ValidationError{Message: fmt.Sprintf("Tenant id is not valid uuid: %s", value)}
+		}
+		if tenantId != nil && *tenantId != parsed {
+			return nil, ErrTenantMismatch
+		}
+		tenantId = &parsed
+	}
+	return tenantId, nil
+}
+
+func validateTenant(tenant *entity.Tenant) error {
+	tenant.Name = strings.TrimSpace(tenant.Name)
+	switch {
+	case tenant.Name == "":
+		return &ValidationError{Message: "Field name must not be empty"}
+	case len(tenant.Name) > maxTenantName:
+		return &ValidationError{Message: fmt.Sprintf("Field name must not be longer than %d", maxTenantName)}
+	case tenant.MaxPersons < 0:
+		return &ValidationError{Message: "Field maxPersons must not be negative"}
+	case tenant.MaxAge < 0 || tenant.MaxAge > maxAge:
+		return &ValidationError{Message: fmt.Sprintf("Field maxAge must be between 0 and %d", maxAge)}
+	}
+	if _, err := regexp.Compile(tenant.LoginPattern); err != nil {
+		return &ValidationError{Message: fmt.Sprintf("Field loginPattern is not valid regular expression: %s", err)}
+	}
+	return nil
+}
+
+// lockQuota lock quota of tenant with limited persons before persons are created, transaction must run with
+// repository.ReadCommitted so that checkQuota counts persons of concurrent transactions committed meanwhile.
+func lockQuota(ctx context.Context, uow *repository.UnitOfWork, tenant entity.Tenant) error {
+	if tenant.MaxPersons == 0 {
+		return nil
+	}
+	return uow.Tenants.LockQuota(ctx)
+}
+
+// checkQuota fail with ErrQuotaExceeded when tenant has more persons than its quota, it is called after persons
+// were created in transaction, so created persons are rolled back.
+func checkQuota(ctx context.Context, uow *repository.UnitOfWork, tenant entity.Tenant) error {
+	if tenant.MaxPersons == 0 {
+		return nil
+	}
+
+	count, err := uow.Tenants.CountPersons(ctx)
+	if err != nil {
+		return err
+	}
+	if count > tenant.MaxPersons {
+		return ErrQuotaExceeded
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"person-service/db/entity"
+	"testing"
+)
+
+func Test_ValidateTenant(t *testing.T) {
+	tenant := entity.Tenant{Name: "  Acme ", MaxPersons: 10, MaxAge: 120, LoginPattern: `^[a-z.]+$`}
+	assert.NoError(t, validateTenant(&tenant))
+	assert.Equal(t, "Acme", tenant.Name)
+
+	for name, tenant := range map[string]entity.Tenant{
+		"blank name":           {Name: " "},
+		"negative quota":       {Name: "Acme", MaxPersons: -1},
+		"too big age":          {Name: "Acme", MaxAge: maxAge + 1},
+		"invalid pattern":      {Name: "Acme", LoginPattern: "[a-z"},
+		"negative age":         {Name: "Acme", MaxAge: -1},
+		"too long tenant name": {Name: string(make([]byte, maxTenantName+1))},
+	} {
+		var validationErr *ValidationError
+		assert.ErrorAs(t, validateTenant(&tenant), &validationErr, name)
+	}
+}
+
+func Test_RequestTenant(t *testing.T) {
+	claimed, other := uuid.New(), uuid.New()
+
+	tenantId, err := requestTenant("", "", true)
+	assert.NoError(t, err)
+	assert.Nil(t, tenantId)
+
+	tenantId, err = requestTenant(claimed.String(), "", true)
+	assert.NoError(t, err)
+	assert.Equal(t, claimed, *tenantId)
+
+	tenantId, err = requestTenant("", " "+other.String(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, other, *tenantId)
+
+	_, err = requestTenant("", other.String(), true)
+	assert.ErrorIs(t, err, ErrTenantNotClaimed)
+
+	tenantId, err = requestTenant(claimed.String(), claimed.String(), true)
+	assert.NoError(t, err)
+	assert.Equal(t, claimed, *tenantId)
+
+	_, err = requestTenant(claimed.String(), other.String(), true)
+	assert.ErrorIs(t, err, ErrTenantMismatch)
+
+	var validationErr *ValidationError
+	_, err = requestTenant("", "acme", false)
+	assert.ErrorAs(t, err, &validationErr)
+}
+
+func Test_ResolveTenantDisabled(t *testing.T) {
+	service := NewTenantService(nil, false, true)
+
+	tenantId, err := service.ResolveTenant(context.Background(), "", uuid.NewString(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.DefaultTenantId, tenantId)
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"net/url"
+	"person-service/db/entity"
+	"person-service/db/repository"
+	"person-service/events"
+	"person-service/webhooks"
+)
+
+var knownEventTypes = map[string]bool{
+	string(events.PersonCreated): true,
+	string(events.PersonUpdated): true,
+	string(events.PersonDeleted): true,
+}
+
+// WebhookService management of webhook subscriptions and their delivery log.
+type WebhookService struct {
+	transactions *repository.TxManager
+	allowPrivate bool
+}
+
+func NewWebhookService(transactions *repository.TxManager, allowPrivate bool) *WebhookService {
+	return &WebhookService{transactions: transactions, allowPrivate: allowPrivate}
+}
+
+// CreateSubscription save new subscription, secret is generated when not set.
+func (s *WebhookService) CreateSubscription(ctx context.Context, subscription entity.WebhookSubscription) (entity.WebhookSubscription, error) {
+	const op = "services.CreateSubscription"
+
+	if err := s.validateSubscription(ctx, subscription); err != nil {
+		return entity.WebhookSubscription{}, err
+	}
+
+	if subscription.Secret == "" {
+		secret, err := newSecret()
+		if err != nil {
+			return entity.WebhookSubscription{}, fmt.Errorf("%s: %w", op, err)
+		}
+		subscription.Secret = secret
+	}
+
+	subscription.Id = uuid.New()
+	saved, err := s.transactions.Repositories().Webhooks.SaveSubscription(ctx, subscription)
+	if err != nil {
+		return entity.WebhookSubscription{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return saved, nil
+}
+
+// UpdateSubscription update subscription, empty secret keeps current one.
+func (s *WebhookService) UpdateSubscription(ctx context.Context, subscription entity.WebhookSubscription) (entity.WebhookSubscription, error) {
+	const op = "services.UpdateSubscription"
+
+	if err := s.validateSubscription(ctx, subscription); err != nil {
+		return entity.WebhookSubscription{}, err
+	}
+
+	var updated entity.WebhookSubscription
+	err := s.transactions.WithinTransaction(ctx, repository.TxOptions{}, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		if subscription.Secret == "" {
+			existing, err := uow.Webhooks.FindSubscriptionById(ctx, subscription.Id)
+			if err != nil {
+				return err
+			}
+			subscription.Secret = existing.Secret
+		}
+
+		var err error
+		updated, err = uow.Webhooks.UpdateSubscription(ctx, subscription)
+		return err
+	})
+	if err != nil {
+		return entity.WebhookSubscription{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return updated, nil
+}
+
+// DeleteSubscription delete subscription, returns false for unknown id.
+func (s *WebhookService) DeleteSubscription(ctx context.Context, id uuid.UUID) (bool, error) {
+	return s.transactions.Repositories().Webhooks.DeleteSubscription(ctx, id)
+}
+
+// FindSubscriptionById find subscription by id.
+func (s *WebhookService) FindSubscriptionById(ctx context.Context, id uuid.UUID) (entity.WebhookSubscription, error) {
+	return s.transactions.Repositories().Webhooks.FindSubscriptionById(ctx, id)
+}
+
+// LoadSubscriptions load all subscriptions.
+func (s *WebhookService) LoadSubscriptions(ctx context.Context) ([]entity.WebhookSubscription, error) {
+	return s.transactions.Repositories().Webhooks.LoadSubscriptions(ctx)
+}
+
+// LoadDeliveries load delivery log of subscription, status filters deliveries (dead for dead-letter store).
+func (s *WebhookService) LoadDeliveries(
+	ctx context.Context,
+	subscriptionId uuid.UUID,
+	status string,
+	limit int,
+) ([]entity.WebhookDelivery, map[int64][]entity.WebhookDeliveryAttempt, error) {
+	const op = "services.LoadDeliveries"
+
+	if status != "" && status != entity.DeliveryPending && status != entity.DeliveryDelivered && status != entity.DeliveryDead {
+		return nil, nil, &ValidationError{Message: fmt.Sprintf("Unknown delivery status: %s", status)}
+	}
+
+	repositories := s.transactions.Repositories()
+	if _, err := repositories.Webhooks.FindSubscriptionById(ctx, subscriptionId); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	deliveries, err := repositories.Webhooks.LoadDeliveries(ctx, subscriptionId, status, limit)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ids := make([]int64, len(deliveries))
+	for index, delivery := range deliveries {
+		ids[index] = delivery.Id
+	}
+	attempts, err := repositories.Webhooks.LoadAttempts(ctx, ids)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return deliveries, attempts, nil
+}
+
+// RetryDelivery move delivery from dead-letter store back to queue, returns false when delivery is not dead.
+func (s *WebhookService) RetryDelivery(ctx context.Context, subscriptionId uuid.UUID, deliveryId int64) (bool, error) {
+	return s.transactions.Repositories().Webhooks.RequeueDelivery(ctx, subscriptionId, deliveryId)
+}
+
+// validateSubscription check url and event types, url must not point to private network unless it is allowed.
+func (s *WebhookService) validateSubscription(ctx context.Context, subscription entity.WebhookSubscription) error {
+	parsed, err := url.Parse(subscription.Url)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return &ValidationError{Message: "Field url must be absolute http(s) url"}
+	}
+	if !s.allowPrivate {
+		err = webhooks.CheckHost(ctx, parsed.Hostname())
+		switch {
+		case errors.Is(err, webhooks.ErrPrivateAddress):
+			return &ValidationError{Message: "Field url must not point to loopback, link-local or private address"}
+		case err != nil:
+			return &ValidationError{Message: fmt.Sprintf("Host of url can not be resolved: %s", parsed.Hostname())}
+		}
+	}
+
+	for _, eventType := range subscription.EventTypes {
+		if !knownEventTypes[eventType] {
+			return &ValidationError{Message: fmt.Sprintf("Unknown event type: %s", eventType)}
+		}
+	}
+
+	return nil
+}
+
+func newSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
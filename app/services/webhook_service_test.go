@@ -0,0 +1,29 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"person-service/db/entity"
+	"testing"
+)
+
+func Test_ValidateSubscription(t *testing.T) {
+	service := &WebhookService{}
+
+	for name, url := range map[string]string{
+		"loopback":       "http://127.0.0.1:8080/hook",
+		"metadata":       "http://169.254.169.254/latest/meta-data",
+		"private":        "https://10.0.0.5/hook",
+		"ipv6 loopback":  "http://[::1]/hook",
+		"not http":       "ftp://example.com",
+		"not absolute":   "/hook",
+		"localhost name": "http://localhost/hook",
+	} {
+		var validationErr *ValidationError
+		err := service.validateSubscription(context.Background(), entity.WebhookSubscription{Url: url})
+		assert.ErrorAs(t, err, &validationErr, name)
+	}
+
+	service.allowPrivate = true
+	assert.NoError(t, service.validateSubscription(context.Background(), entity.WebhookSubscription{Url: "http://127.0.0.1:8080/hook"}))
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"person-service/db/entity"
+	"person-service/db/repository"
+	"testing"
+)
+
+func Test_TransactionManager(t *testing.T) {
+
+	t.Run("must rollback all statements when function fails", func(t *testing.T) {
+		var saved entity.Person
+		err := transactions.WithinTransaction(ctx, repository.TxOptions{}, func(ctx context.Context, uow *repository.UnitOfWork) error {
+			var err error
+			saved, err = uow.Persons.SavePerson(ctx, entity.Person{FirstName: "Иван", LastName: "Иванов", Age: 30})
+			if err != nil {
+				return err
+			}
+			return errors.New("rollback")
+		})
+
+		assert.EqualError(t, err, "storage.postgres.WithinTransaction: rollback")
+		_, err = storage.FindPersonById(ctx, saved.Id)
+		assert.ErrorIs(t, err, pgx.ErrNoRows)
+	})
+
+	t.Run("must rollback only savepoint of nested call", func(t *testing.T) {
+		var outer, inner entity.Person
+		err := transactions.WithinTransaction(ctx, repository.TxOptions{}, func(ctx context.Context, uow *repository.UnitOfWork) error {
+			var err error
+			outer, err = uow.Persons.SavePerson(ctx, entity.Person{FirstName: "Иван", LastName: "Петров", Age: 30})
+			if err != nil {
+				return err
+			}
+
+			nestedErr := transactions.WithinTransaction(ctx, repository.TxOptions{}, func(ctx context.Context, uow *repository.UnitOfWork) error {
+				inner, err = uow.Persons.SavePerson(ctx, entity.Person{FirstName: "Пётр", LastName: "Петров", Age: 31})
+				if err != nil {
+					return err
+				}
+				return errors.New("rollback savepoint")
+			})
+			assert.Error(t, nestedErr)
+
+			return nil
+		})
+
+		assert.NoError(t, err)
+		_, err = storage.FindPersonById(ctx, outer.Id)
+		assert.NoError(t, err)
+		_, err = storage.FindPersonById(ctx, inner.Id)
+		assert.ErrorIs(t, err, pgx.ErrNoRows)
+	})
+
+	t.Run("must retry on serialization failure", func(t *testing.T) {
+		attempts := 0
+		err := transactions.WithinTransaction(ctx, repository.TxOptions{IsoLevel: pgx.Serializable}, func(ctx context.Context, uow *repository.UnitOfWork) error {
+			attempts++
+			if attempts < 3 {
+				return &pgconn.PgError{Code: "40001", Message: "could not serialize access"}
+			}
+			_, err := uow.Persons.SavePerson(ctx, entity.Person{FirstName: "Иван", LastName: "Сидоров", Age: 30})
+			return err
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("must not retry other errors", func(t *testing.T) {
+		attempts := 0
+		err := transactions.WithinTransaction(ctx, repository.TxOptions{}, func(ctx context.Context, uow *repository.UnitOfWork) error {
+			attempts++
+			return &pgconn.PgError{Code: "23505", Message: "duplicate key"}
+		})
+
+		assert.Error(t, err)
+		assert.Equal(t, 1, attempts)
+	})
+}
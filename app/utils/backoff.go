@@ -0,0 +1,15 @@
+package utils
+
+import "time"
+
+// Backoff returns exponential delay base * 2^attempts limited by max.
+func Backoff(base time.Duration, max time.Duration, attempts int) time.Duration {
+	delay := base
+	for i := 0; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
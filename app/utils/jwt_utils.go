@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"crypto/rsa"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"strings"
+	"time"
+)
+
+// BearerToken claims of validated token, ExpiresAt is zero when token has no expiration.
+type BearerToken struct {
+	Subject   string
+	ExpiresAt time.Time
+	Claims    map[string]any
+}
+
+// Claim string value of claim, empty when token has no such claim or it is not a string.
+func (t BearerToken) Claim(name string) string {
+	value, _ := t.Claims[name].(string)
+	return value
+}
+
+// ValidateBearerToken verify RS* signed token of Authorization value with or without Bearer prefix,
+// subject of valid token is empty when token has no subject.
+func ValidateBearerToken(key *rsa.PublicKey, authorization string) (BearerToken, error) {
+	token := strings.TrimPrefix(authorization, "Bearer ")
+	claims := &jwt.MapClaims{}
+
+	_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return key, nil
+	})
+	if err != nil {
+		return BearerToken{}, err
+	}
+
+	validated := BearerToken{Claims: *claims}
+	validated.Subject, _ = claims.GetSubject()
+	if expiresAt, _ := claims.GetExpirationTime(); expiresAt != nil {
+		validated.ExpiresAt = expiresAt.Time
+	}
+	return validated, nil
+}
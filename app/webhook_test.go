@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"person-service/config"
+	"person-service/db/entity"
+	"person-service/events"
+	"person-service/model"
+	"person-service/webhooks"
+	"sync"
+	"testing"
+	"time"
+)
+
+// webhookReceiver httptest receiver, verifies signature of every request.
+type webhookReceiver struct {
+	mu       sync.Mutex
+	secret   string
+	status   int
+	received []events.Event
+	errors   []error
+}
+
+func (rc *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	err := webhooks.Verify(rc.secret, r.Header.Get(webhooks.HeaderTimestamp), r.Header.Get(webhooks.HeaderSignature), body, time.Minute)
+	if err != nil {
+		rc.errors = append(rc.errors, err)
+	}
+
+	var event events.Event
+	_ = json.Unmarshal(body, &event)
+	rc.received = append(rc.received, event)
+
+	w.WriteHeader(rc.status)
+}
+
+func Test_Webhooks(t *testing.T) {
+	client := &http.Client{}
+	outbox := config.Outbox{BatchSize: 100, RetryBackoff: time.Millisecond, MaxBackoff: time.Millisecond, Lease: time.Minute}
+	relay := events.NewRelay(logger, transactions, webhooks.NewDispatcher(transactions), outbox)
+	worker := webhooks.NewWorker(logger, transactions, client, config.Webhooks{
+		BatchSize: 20, MaxAttempts: 2, RetryBackoff: time.Millisecond, MaxBackoff: time.Millisecond, Lease: time.Minute,
+	})
+
+	t.Run("must deliver signed event to subscriber", func(t *testing.T) {
+		receiver := &webhookReceiver{secret: "top-secret", status: http.StatusOK}
+		server := httptest.NewServer(receiver)
+		defer server.Close()
+		drain(t, relay)
+
+		subscription := createWebhook(t, fmt.Sprintf(
+			`{"url": "%s", "eventTypes": ["PersonCreated"], "secret": "top-secret"}`, server.URL,
+		))
+		person, err := personService.CreatePerson(ctx, entity.Person{FirstName: "Мария", LastName: "Иванова", Age: 33})
+		assert.NoError(t, err)
+
+		drain(t, relay)
+		_, err = worker.ProcessBatch(ctx)
+		assert.NoError(t, err)
+
+		assert.Empty(t, receiver.errors)
+		assert.Len(t, receiver.received, 1)
+		assert.Equal(t, events.PersonCreated, receiver.received[0].Type)
+		assert.Equal(t, *person.Id, receiver.received[0].PersonId)
+
+		deliveries := loadDeliveries(t, subscription.Id.String(), "")
+		assert.Len(t, deliveries, 1)
+		assert.Equal(t, entity.DeliveryDelivered, deliveries[0].Status)
+		assert.Len(t, deliveries[0].Log, 1)
+		assert.Equal(t, http.StatusOK, *deliveries[0].Log[0].ResponseStatus)
+	})
+
+	t.Run("must park delivery in dead-letter store after max attempts", func(t *testing.T) {
+		receiver := &webhookReceiver{secret: "top-secret", status: http.StatusInternalServerError}
+		server := httptest.NewServer(receiver)
+		defer server.Close()
+		drain(t, relay)
+
+		subscription := createWebhook(t, fmt.Sprintf(`{"url": "%s", "secret": "top-secret"}`, server.URL))
+		_, err := personService.CreatePerson(ctx, entity.Person{FirstName: "Илья", LastName: "Соколов", Age: 27})
+		assert.NoError(t, err)
+
+		drain(t, relay)
+		for i := 0; i < 2; i++ {
+			_, err = worker.ProcessBatch(ctx)
+			assert.NoError(t, err)
+			time.Sleep(5 * time.Millisecond)
+		}
+
+		assert.Len(t, receiver.received, 2)
+		dead := loadDeliveries(t, subscription.Id.String(), entity.DeliveryDead)
+		assert.Len(t, dead, 1)
+		assert.Equal(t, 2, dead[0].Attempts)
+		assert.Len(t, dead[0].Log, 2)
+
+		resp, err := client.Post(
+			fmt.Sprintf("http://localhost:9902/api/v1/webhooks/%s/deliveries/%d/retry", subscription.Id, dead[0].Id),
+			"application/json", nil,
+		)
+		parseResponseBytes(err, t, resp)
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+		assert.Len(t, loadDeliveries(t, subscription.Id.String(), entity.DeliveryPending), 1)
+	})
+
+	t.Run("must not claim delivery again while it is sent", func(t *testing.T) {
+		receiver := &webhookReceiver{secret: "top-secret", status: http.StatusOK}
+		var once sync.Once
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			/* claim of the first batch is committed, other worker skips leased delivery */
+			once.Do(func() { _, _ = worker.ProcessBatch(ctx) })
+			receiver.ServeHTTP(w, r)
+		}))
+		defer server.Close()
+		drain(t, relay)
+
+		subscription := createWebhook(t, fmt.Sprintf(`{"url": "%s", "secret": "top-secret"}`, server.URL))
+		_, err := personService.CreatePerson(ctx, entity.Person{FirstName: "Вера", LastName: "Орлова", Age: 45})
+		assert.NoError(t, err)
+
+		drain(t, relay)
+		_, err = worker.ProcessBatch(ctx)
+		assert.NoError(t, err)
+
+		assert.Len(t, receiver.received, 1)
+		assert.Len(t, loadDeliveries(t, subscription.Id.String(), entity.DeliveryDelivered), 1)
+	})
+
+	t.Run("must return 400 when webhook url is not valid", func(t *testing.T) {
+		resp, err := http.Post(
+			"http://localhost:9902/api/v1/webhooks",
+			"application/json",
+			bytes.NewBufferString(`{"url": "ftp://example.com"}`),
+		)
+		parseResponseBytes(err, t, resp)
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func createWebhook(t *testing.T, body string) model.WebhookResponse {
+	resp, err := http.Post("http://localhost:9902/api/v1/webhooks", "application/json", bytes.NewBufferString(body))
+	responseBytes := parseResponseBytes(err, t, resp)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var subscription model.WebhookResponse
+	if err := json.Unmarshal(responseBytes, &subscription); err != nil {
+		t.Fatalf("Error while parse response: %v", err)
+	}
+	assert.NotEmpty(t, subscription.Secret)
+
+	return subscription
+}
+
+func loadDeliveries(t *testing.T, subscriptionId string, status string) []model.WebhookDeliveryResponse {
+	resp, err := http.Get(fmt.Sprintf("http://localhost:9902/api/v1/webhooks/%s/deliveries?status=%s", subscriptionId, status))
+	responseBytes := parseResponseBytes(err, t, resp)
+
+	var deliveries []model.WebhookDeliveryResponse
+	if err := json.Unmarshal(responseBytes, &deliveries); err != nil {
+		t.Fatalf("Error while parse response: %v", err)
+	}
+
+	return deliveries
+}
@@ -0,0 +1,47 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"person-service/db/entity"
+	"person-service/db/repository"
+	"person-service/events"
+)
+
+// Dispatcher events.Publisher which enqueues delivery of event for every matching active subscription of event tenant.
+// Called by outbox relay, delivery is unique per subscription and event, so republished event is enqueued once.
+type Dispatcher struct {
+	transactions *repository.TxManager
+}
+
+func NewDispatcher(transactions *repository.TxManager) *Dispatcher {
+	return &Dispatcher{transactions: transactions}
+}
+
+func (d *Dispatcher) Publish(ctx context.Context, event events.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return d.transactions.WithinTransaction(ctx, repository.TxOptions{}, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		subscriptions, err := uow.Webhooks.FindActiveSubscriptions(ctx, event.TenantId, string(event.Type))
+		if err != nil {
+			return err
+		}
+
+		for _, subscription := range subscriptions {
+			err := uow.Webhooks.EnqueueDelivery(ctx, entity.WebhookDelivery{
+				SubscriptionId: subscription.Id,
+				EventId:        event.Id,
+				EventType:      string(event.Type),
+				Payload:        payload,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,66 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateAddress subscriber address is loopback, link-local or belongs to private network, subscriptions must not
+// reach internal services or cloud metadata endpoint.
+var ErrPrivateAddress = errors.New("address of private network is not allowed")
+
+// PublicAddress address is routable on public network.
+func PublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() && !addr.IsUnspecified() && !addr.IsLoopback() && !addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() && !addr.IsLinkLocalMulticast() && !addr.IsInterfaceLocalMulticast()
+}
+
+// CheckHost fail with ErrPrivateAddress when host is or resolves to address which is not public.
+func CheckHost(ctx context.Context, host string) error {
+	addrs := make([]netip.Addr, 0, 1)
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addrs = append(addrs, addr)
+	} else if addrs, err = net.DefaultResolver.LookupNetIP(ctx, "ip", host); err != nil {
+		return err
+	}
+
+	for _, addr := range addrs {
+		if !PublicAddress(addr) {
+			return fmt.Errorf("%w: %s", ErrPrivateAddress, addr)
+		}
+	}
+	return nil
+}
+
+// NewClient http client of subscribers, connection to address which is not public is refused unless private
+// networks are allowed. Address is checked after name resolution, so it covers redirects and names re-bound
+// to private addresses after subscription was saved.
+func NewClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if !allowPrivate {
+		dialer.Control = func(network string, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !PublicAddress(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", ErrPrivateAddress, addrPort.Addr())
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	/* proxy would be dialed instead of subscriber and bypass the check */
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
@@ -0,0 +1,38 @@
+package webhooks
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func Test_PrivateNetworks(t *testing.T) {
+	t.Run("must reject addresses of private networks", func(t *testing.T) {
+		for _, address := range []string{"127.0.0.1", "::1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254",
+			"fe80::1", "fd00::1", "0.0.0.0", "::ffff:127.0.0.1"} {
+			assert.False(t, PublicAddress(netip.MustParseAddr(address)), address)
+			assert.ErrorIs(t, CheckHost(context.Background(), address), ErrPrivateAddress, address)
+		}
+		assert.True(t, PublicAddress(netip.MustParseAddr("93.184.216.34")))
+		assert.NoError(t, CheckHost(context.Background(), "93.184.216.34"))
+	})
+
+	t.Run("client must refuse to dial private address unless allowed", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		_, err := NewClient(time.Second, false).Get(server.URL)
+		assert.ErrorIs(t, err, ErrPrivateAddress)
+
+		resp, err := NewClient(time.Second, true).Get(server.URL)
+		assert.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+}
@@ -0,0 +1,48 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	HeaderDeliveryId = "X-Webhook-Id"
+	HeaderEvent      = "X-Webhook-Event"
+	HeaderTimestamp  = "X-Webhook-Timestamp"
+	HeaderSignature  = "X-Webhook-Signature"
+
+	signaturePrefix = "sha256="
+)
+
+// Sign returns signature of payload: hex HMAC-SHA256 over "<unix timestamp>.<body>" with subscription secret.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks signature and timestamp headers of received payload, used by receivers and tests.
+func Verify(secret string, timestampHeader string, signatureHeader string, body []byte, tolerance time.Duration) error {
+	unix, err := strconv.ParseInt(timestampHeader, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp: %w", err)
+	}
+
+	timestamp := time.Unix(unix, 0)
+	if tolerance > 0 && time.Since(timestamp).Abs() > tolerance {
+		return fmt.Errorf("timestamp is outside of tolerance")
+	}
+
+	if !hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signatureHeader)) {
+		return fmt.Errorf("signature mismatch")
+	}
+
+	return nil
+}
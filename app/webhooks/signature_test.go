@@ -0,0 +1,37 @@
+package webhooks
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func Test_Signature(t *testing.T) {
+	body := []byte(`{"id":1,"type":"PersonCreated"}`)
+	timestamp := time.Now()
+	header := strconv.FormatInt(timestamp.Unix(), 10)
+
+	t.Run("must verify own signature", func(t *testing.T) {
+		signature := Sign("secret", timestamp, body)
+
+		assert.Regexp(t, "^sha256=[0-9a-f]{64}$", signature)
+		assert.NoError(t, Verify("secret", header, signature, body, time.Minute))
+	})
+
+	t.Run("must reject signature of other secret or body", func(t *testing.T) {
+		signature := Sign("secret", timestamp, body)
+
+		assert.EqualError(t, Verify("other", header, signature, body, time.Minute), "signature mismatch")
+		assert.EqualError(t, Verify("secret", header, signature, []byte(`{}`), time.Minute), "signature mismatch")
+	})
+
+	t.Run("must reject outdated timestamp", func(t *testing.T) {
+		outdated := timestamp.Add(-time.Hour)
+		signature := Sign("secret", outdated, body)
+
+		err := Verify("secret", strconv.FormatInt(outdated.Unix(), 10), signature, body, time.Minute)
+
+		assert.EqualError(t, err, "timestamp is outside of tolerance")
+	})
+}
@@ -0,0 +1,160 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"golang.org/x/exp/slog"
+	"io"
+	"net/http"
+	"person-service/config"
+	"person-service/db/entity"
+	"person-service/db/repository"
+	"person-service/utils"
+	"strconv"
+	"time"
+)
+
+// Worker sends pending deliveries to subscribers, failed delivery is retried with exponential backoff
+// and parked in dead-letter status after max attempts.
+type Worker struct {
+	logger       *slog.Logger
+	transactions *repository.TxManager
+	client       *http.Client
+	batchSize    int
+	pollInterval time.Duration
+	maxAttempts  int
+	retryBackoff time.Duration
+	maxBackoff   time.Duration
+	lease        time.Duration
+}
+
+func NewWorker(logger *slog.Logger, transactions *repository.TxManager, client *http.Client, webhooks config.Webhooks) *Worker {
+	return &Worker{
+		logger:       logger.With(slog.String("op", "webhooks.worker")),
+		transactions: transactions,
+		client:       client,
+		batchSize:    webhooks.BatchSize,
+		pollInterval: webhooks.PollInterval,
+		maxAttempts:  webhooks.MaxAttempts,
+		retryBackoff: webhooks.RetryBackoff,
+		maxBackoff:   webhooks.MaxBackoff,
+		lease:        webhooks.Lease,
+	}
+}
+
+// Run polls pending deliveries until ctx is done.
+func (w *Worker) Run(ctx context.Context) {
+	w.logger.Info("Webhook worker started")
+
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			processed, err := w.ProcessBatch(ctx)
+			if err != nil {
+				w.logger.Error("Failed to process deliveries", utils.Err(err))
+				break
+			}
+			if processed == 0 {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Webhook worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessBatch send one batch of due deliveries, returns count of claimed deliveries. Deliveries are claimed
+// in one transaction, sent outside of any transaction and result of every one is recorded in its own transaction,
+// so slow subscribers hold neither locks nor connections.
+func (w *Worker) ProcessBatch(ctx context.Context) (int, error) {
+	var deliveries []entity.WebhookDelivery
+	err := w.transactions.WithinTransaction(ctx, repository.TxOptions{}, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		var err error
+		deliveries, err = uow.Webhooks.ClaimDueDeliveries(ctx, w.batchSize, w.lease)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, delivery := range deliveries {
+		started := time.Now()
+		status, sendErr := w.send(ctx, delivery)
+		if err := w.record(ctx, delivery, status, sendErr, time.Since(started)); err != nil {
+			return len(deliveries), err
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+// record save attempt of delivery and mark it delivered, postponed or dead.
+func (w *Worker) record(ctx context.Context, delivery entity.WebhookDelivery, status int, sendErr error, duration time.Duration) error {
+	attempt := entity.WebhookDeliveryAttempt{DeliveryId: delivery.Id, Duration: duration}
+	if status != 0 {
+		attempt.ResponseStatus = &status
+	}
+	if sendErr != nil {
+		reason := sendErr.Error()
+		attempt.Error = &reason
+	}
+
+	dead := delivery.Attempts+1 >= w.maxAttempts
+	if sendErr != nil {
+		w.logger.Warn("Failed to deliver webhook",
+			slog.Int64("delivery_id", delivery.Id),
+			slog.String("subscription_id", delivery.SubscriptionId.String()),
+			slog.Int("attempts", delivery.Attempts+1),
+			slog.Bool("dead", dead),
+			utils.Err(sendErr),
+		)
+	}
+
+	return w.transactions.WithinTransaction(ctx, repository.TxOptions{}, func(ctx context.Context, uow *repository.UnitOfWork) error {
+		if err := uow.Webhooks.SaveAttempt(ctx, attempt); err != nil {
+			return err
+		}
+		if sendErr == nil {
+			return uow.Webhooks.MarkDelivered(ctx, delivery.Id)
+		}
+		retryAfter := utils.Backoff(w.retryBackoff, w.maxBackoff, delivery.Attempts)
+		return uow.Webhooks.MarkFailed(ctx, delivery.Id, sendErr.Error(), dead, retryAfter)
+	})
+}
+
+// send post signed payload, returns response status (0 when request failed).
+func (w *Worker) send(ctx context.Context, delivery entity.WebhookDelivery) (int, error) {
+	timestamp := time.Now()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "person-service-webhooks")
+	req.Header.Set(HeaderDeliveryId, strconv.FormatInt(delivery.Id, 10))
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("subscriber responded with status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}